@@ -0,0 +1,247 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config berisi semua pengaturan aplikasi. Nilai dibaca dari environment variable;
+// file konfigurasi (CONFIG_FILE, default .env bila ada) hanya mengisi yang tidak di-set di environment.
+type Config struct {
+	Port     string
+	GinMode  string
+	Database DatabaseConfig
+	JWT      JWTConfig
+	Features FeatureFlags
+}
+
+// Driver database yang didukung
+const (
+	DriverMySQL  = "mysql"
+	DriverSQLite = "sqlite"
+)
+
+// DatabaseConfig berisi driver, DSN dan pengaturan connection pool
+type DatabaseConfig struct {
+	Driver          string // mysql atau sqlite
+	DSN             string
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	ConnectTimeout  time.Duration // batas waktu ping pertama saat startup
+}
+
+// JWTConfig berisi pengaturan token login
+type JWTConfig struct {
+	Secret     string
+	Issuer     string
+	Audience   string
+	TTL        time.Duration
+	RefreshTTL time.Duration
+}
+
+// FeatureFlags menyalakan/mematikan perilaku opsional
+type FeatureFlags struct {
+	AutoMigrate bool // terapkan migrasi yang belum jalan saat server start
+}
+
+// Panjang minimal JWT_SECRET supaya HS256 tidak memakai kunci yang mudah ditebak
+const minJWTSecretLength = 32
+
+// Load membaca konfigurasi dari environment dan file opsional lalu memvalidasinya
+func Load() (Config, error) {
+	file, err := readConfigFile()
+	if err != nil {
+		return Config{}, err
+	}
+	src := source{file: file}
+
+	cfg := Config{
+		Port:    src.String("PORT", "8080"),
+		GinMode: src.String("GIN_MODE", "debug"),
+		Database: DatabaseConfig{
+			Driver:          src.String("DB_DRIVER", DriverMySQL),
+			DSN:             src.String("DB_DSN", ""),
+			MaxOpenConns:    src.Int("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:    src.Int("DB_MAX_IDLE_CONNS", 10),
+			ConnMaxLifetime: src.Duration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+			ConnMaxIdleTime: src.Duration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute),
+			ConnectTimeout:  src.Duration("DB_CONNECT_TIMEOUT", 10*time.Second),
+		},
+		JWT: JWTConfig{
+			Secret:     src.String("JWT_SECRET", ""),
+			Issuer:     src.String("JWT_ISSUER", "cbt-api"),
+			Audience:   src.String("JWT_AUDIENCE", ""),
+			TTL:        time.Duration(src.Int("JWT_TTL_MINUTES", 15)) * time.Minute,
+			RefreshTTL: time.Duration(src.Int("JWT_REFRESH_TTL_HOURS", 7*24)) * time.Hour,
+		},
+		Features: FeatureFlags{
+			AutoMigrate: src.Bool("FEATURE_AUTO_MIGRATE", true),
+		},
+	}
+
+	if len(src.errs) > 0 {
+		return cfg, errors.Join(src.errs...)
+	}
+	return cfg, cfg.Validate()
+}
+
+// Validate mengembalikan semua kesalahan konfigurasi sekaligus supaya mudah diperbaiki
+func (c Config) Validate() error {
+	var errs []error
+	if c.Port == "" {
+		errs = append(errs, errors.New("PORT must not be empty"))
+	}
+	switch c.GinMode {
+	case "debug", "release", "test":
+	default:
+		errs = append(errs, fmt.Errorf("GIN_MODE must be debug, release or test, got %q", c.GinMode))
+	}
+
+	switch c.Database.Driver {
+	case DriverMySQL, DriverSQLite:
+	default:
+		errs = append(errs, fmt.Errorf("DB_DRIVER must be %s or %s, got %q", DriverMySQL, DriverSQLite, c.Database.Driver))
+	}
+	if c.Database.DSN == "" {
+		errs = append(errs, errors.New("DB_DSN is required"))
+	}
+	if c.Database.MaxOpenConns < 0 {
+		errs = append(errs, errors.New("DB_MAX_OPEN_CONNS must not be negative"))
+	}
+	if c.Database.MaxIdleConns < 0 {
+		errs = append(errs, errors.New("DB_MAX_IDLE_CONNS must not be negative"))
+	}
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		errs = append(errs, errors.New("DB_MAX_IDLE_CONNS must not exceed DB_MAX_OPEN_CONNS"))
+	}
+	if c.Database.ConnectTimeout <= 0 {
+		errs = append(errs, errors.New("DB_CONNECT_TIMEOUT must be positive"))
+	}
+
+	if len(c.JWT.Secret) < minJWTSecretLength {
+		errs = append(errs, fmt.Errorf("JWT_SECRET must be at least %d characters", minJWTSecretLength))
+	}
+	if c.JWT.Issuer == "" {
+		errs = append(errs, errors.New("JWT_ISSUER must not be empty"))
+	}
+	if c.JWT.TTL <= 0 {
+		errs = append(errs, errors.New("JWT_TTL_MINUTES must be positive"))
+	}
+	if c.JWT.RefreshTTL <= c.JWT.TTL {
+		errs = append(errs, errors.New("JWT_REFRESH_TTL_HOURS must be longer than JWT_TTL_MINUTES"))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// readConfigFile membaca file KEY=VALUE dari CONFIG_FILE. Tanpa CONFIG_FILE, .env dipakai bila ada.
+func readConfigFile() (map[string]string, error) {
+	path := os.Getenv("CONFIG_FILE")
+	explicit := path != ""
+	if !explicit {
+		path = ".env"
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+	defer f.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(text, "export "), "=")
+		if !ok {
+			return nil, fmt.Errorf("read config file %s line %d: expected KEY=VALUE", path, line)
+		}
+		value = strings.TrimSpace(value)
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+		values[strings.TrimSpace(key)] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read config file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// source mencari nilai di environment dulu, lalu di file konfigurasi.
+// Kesalahan parsing dikumpulkan supaya semuanya dilaporkan bersama.
+type source struct {
+	file map[string]string
+	errs []error
+}
+
+func (s *source) lookup(key string) (string, bool) {
+	if value, ok := os.LookupEnv(key); ok {
+		return value, true
+	}
+	value, ok := s.file[key]
+	return value, ok
+}
+
+func (s *source) String(key string, fallback string) string {
+	if value, ok := s.lookup(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+func (s *source) Int(key string, fallback int) int {
+	value, ok := s.lookup(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		s.errs = append(s.errs, fmt.Errorf("%s must be an integer, got %q", key, value))
+		return fallback
+	}
+	return n
+}
+
+// Duration menerima format time.ParseDuration, misalnya "15m" atau "168h"
+func (s *source) Duration(key string, fallback time.Duration) time.Duration {
+	value, ok := s.lookup(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		s.errs = append(s.errs, fmt.Errorf("%s must be a duration like 15m or 1h, got %q", key, value))
+		return fallback
+	}
+	return d
+}
+
+func (s *source) Bool(key string, fallback bool) bool {
+	value, ok := s.lookup(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		s.errs = append(s.errs, fmt.Errorf("%s must be true or false, got %q", key, value))
+		return fallback
+	}
+	return b
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const secretTest = "0123456789abcdef0123456789abcdef"
+
+// tulisConfig writes a config file and points CONFIG_FILE at it
+func tulisConfig(t *testing.T, isi string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "cbt.env")
+	if err := os.WriteFile(path, []byte(isi), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+}
+
+func TestLoadFromFileAndEnv(t *testing.T) {
+	tulisConfig(t, `# komentar
+DB_DSN="user:pass@tcp(localhost:3306)/cbt"
+export JWT_SECRET=`+secretTest+`
+PORT=9000
+DB_MAX_OPEN_CONNS=40
+DB_CONNECT_TIMEOUT=3s
+`)
+	// Environment menang atas file
+	t.Setenv("PORT", "9100")
+	t.Setenv("JWT_TTL_MINUTES", "30")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Database.Driver != DriverMySQL {
+		t.Errorf("default driver = %q, want %q", cfg.Database.Driver, DriverMySQL)
+	}
+	if cfg.Port != "9100" || cfg.Database.DSN != "user:pass@tcp(localhost:3306)/cbt" || cfg.JWT.Secret != secretTest {
+		t.Errorf("port %q, dsn %q, secret %q", cfg.Port, cfg.Database.DSN, cfg.JWT.Secret)
+	}
+	if cfg.Database.MaxOpenConns != 40 || cfg.Database.MaxIdleConns != 10 || cfg.Database.ConnectTimeout != 3*time.Second {
+		t.Errorf("pool = open %d, idle %d, timeout %v; want 40, 10, 3s",
+			cfg.Database.MaxOpenConns, cfg.Database.MaxIdleConns, cfg.Database.ConnectTimeout)
+	}
+	if cfg.JWT.TTL != 30*time.Minute || cfg.JWT.RefreshTTL != 7*24*time.Hour || cfg.JWT.Issuer != "cbt-api" {
+		t.Errorf("jwt = ttl %v, refresh %v, issuer %q", cfg.JWT.TTL, cfg.JWT.RefreshTTL, cfg.JWT.Issuer)
+	}
+}
+
+func TestLoadReportsEveryError(t *testing.T) {
+	tulisConfig(t, "DB_MAX_OPEN_CONNS=banyak\nDB_CONNECT_TIMEOUT=10\n")
+	_, err := Load()
+	if err == nil {
+		t.Fatal("Load accepted invalid numbers")
+	}
+	for _, want := range []string{"DB_MAX_OPEN_CONNS", "DB_CONNECT_TIMEOUT"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+
+	tulisConfig(t, "baris tanpa sama dengan\n")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("malformed file = %v, want an error on line 1", err)
+	}
+
+	t.Setenv("CONFIG_FILE", filepath.Join(t.TempDir(), "tidak-ada.env"))
+	if _, err := Load(); err == nil {
+		t.Error("a missing CONFIG_FILE was ignored")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := Config{
+		Port:     "8080",
+		GinMode:  "release",
+		Database: DatabaseConfig{Driver: DriverMySQL, DSN: "dsn", MaxOpenConns: 10, MaxIdleConns: 5, ConnectTimeout: time.Second},
+		JWT:      JWTConfig{Secret: secretTest, Issuer: "cbt-api", TTL: time.Minute, RefreshTTL: time.Hour},
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("valid config rejected: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		ubah func(*Config)
+		want string
+	}{
+		{"gin mode", func(c *Config) { c.GinMode = "prod" }, "GIN_MODE"},
+		{"unknown driver", func(c *Config) { c.Database.Driver = "postgres" }, "DB_DRIVER"},
+		{"no dsn", func(c *Config) { c.Database.DSN = "" }, "DB_DSN"},
+		{"idle above open", func(c *Config) { c.Database.MaxIdleConns = 20 }, "DB_MAX_IDLE_CONNS"},
+		{"short secret", func(c *Config) { c.JWT.Secret = "rahasia" }, "JWT_SECRET"},
+		{"refresh shorter than access", func(c *Config) { c.JWT.RefreshTTL = time.Second }, "JWT_REFRESH_TTL_HOURS"},
+	}
+	for _, tt := range tests {
+		cfg := valid
+		tt.ubah(&cfg)
+		if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s = %v, want an error about %s", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConnectDatabaseSQLite(t *testing.T) {
+	db, err := ConnectDatabase(DatabaseConfig{
+		Driver:         DriverSQLite,
+		DSN:            filepath.Join(t.TempDir(), "cbt.db"),
+		MaxOpenConns:   1,
+		MaxIdleConns:   1,
+		ConnectTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+	if name := db.Dialector.Name(); name != DriverSQLite {
+		t.Errorf("dialector = %q, want %q", name, DriverSQLite)
+	}
+	if stats := sqlDB.Stats(); stats.MaxOpenConnections != 1 {
+		t.Errorf("max open connections = %d, want 1", stats.MaxOpenConnections)
+	}
+}
+
+func TestConnectDatabaseUnknownDriver(t *testing.T) {
+	if _, err := ConnectDatabase(DatabaseConfig{Driver: "postgres", DSN: "dsn", ConnectTimeout: time.Second}); err == nil {
+		t.Error("unknown driver accepted")
+	}
+}
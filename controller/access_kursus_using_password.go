@@ -1,17 +1,21 @@
 package controller
 
 import (
-	"cbt-api/config"
-	"cbt-api/entity"
-	"golang.org/x/crypto/bcrypt" // Untuk hashing dan membandingkan password
+	"cbt-api/service"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"strconv"
 )
 
 // AccessKursus handles access to a course using the password provided by the user
-func EnrollKursus(c *gin.Context) {
+func (kc *kursusController) EnrollKursus(c *gin.Context) {
 	// Get the id_kursus and password from the request parameters
-	idKursus := c.Param("id_kursus") // Ambil id_kursus dari URL
+	idKursus, err := strconv.ParseUint(c.Param("id_kursus"), 10, 64) // Ambil id_kursus dari URL
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kursus not found"})
+		return
+	}
 	var userInput struct {
 		Password string `json:"password"` // Password yang diinput oleh pengguna
 	}
@@ -22,18 +26,15 @@ func EnrollKursus(c *gin.Context) {
 		return
 	}
 
-	// Retrieve the Kursus (course) from the database based on id_kursus
-	var kursus entity.Kursus
-	if err := config.DB.Where("id_kursus = ?", idKursus).First(&kursus).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Kursus not found"})
-		return
-	}
-
-	// Compare the hashed password stored in the database with the input password
-	err := bcrypt.CompareHashAndPassword([]byte(kursus.Password), []byte(userInput.Password))
+	// Retrieve the Kursus (course) and compare the hashed password with the input password
+	kursus, err := kc.kursusService.AccessKursus(idKursus, userInput.Password)
 	if err != nil {
-		// If the password does not match
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Incorrect password"})
+		if errors.Is(err, service.ErrPasswordKursusSalah) {
+			// If the password does not match
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Incorrect password"})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kursus not found"})
 		return
 	}
 
@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetAnalisisButir returns the item analysis report of an ujian with the soal that should be revised flagged
+func (nc *nilaiController) GetAnalisisButir(c *gin.Context) {
+	idUjian, err := strconv.ParseUint(c.Param("id_ujian"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid id_ujian"})
+		return
+	}
+
+	laporan, err := nc.nilaiService.AnalisisButirSoal(idUjian)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ujian tidak ditemukan"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung analisis butir soal", "detail": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Analisis butir soal retrieved successfully",
+		"data":    laporan,
+	})
+}
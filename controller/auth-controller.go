@@ -2,14 +2,32 @@ package controller
 
 import (
     "github.com/gin-gonic/gin"
-    "cbt-api/config"
-    "cbt-api/entity"
-    "cbt-api/auth"
-    "golang.org/x/crypto/bcrypt"
+    "cbt-api/middleware"
+    "cbt-api/service"
+    "errors"
     "net/http"
 )
 
-func Login(c *gin.Context) {
+// AuthController is a contract for auth controller
+type AuthController interface {
+    Login(c *gin.Context)
+    RefreshToken(c *gin.Context)
+    Logout(c *gin.Context)
+    LogoutAll(c *gin.Context)
+}
+
+type authController struct {
+    authService service.AuthService
+}
+
+// NewAuthController creates a new instance of AuthController
+func NewAuthController(authService service.AuthService) AuthController {
+    return &authController{
+        authService: authService,
+    }
+}
+
+func (ac *authController) Login(c *gin.Context) {
     var userInput struct {
         Email    string `json:"email"`
         Password string `json:"password"`
@@ -20,38 +38,98 @@ func Login(c *gin.Context) {
         return
     }
 
-    // 🔍 Cari user berdasarkan email
-    var user entity.Users
-    if err := config.DB.Where("email = ?", userInput.Email).First(&user).Error; err != nil {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+    // 🔍 Cari user, verifikasi password dan tentukan role dari tabel siswa, guru, operator dan admin
+    login, err := ac.authService.Authenticate(userInput.Email, userInput.Password)
+    if err != nil {
+        switch {
+        case errors.Is(err, service.ErrUserNotFound), errors.Is(err, service.ErrIncorrectPassword):
+            c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        case errors.Is(err, service.ErrUserTanpaRole):
+            c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+        default:
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memuat data user"})
+        }
         return
     }
+    subject := login.Subject
 
-    // ✅ Verifikasi password
-    if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(userInput.Password)); err != nil {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Incorrect password"})
+    // 🔐 Buat sesi login: access token berumur pendek + refresh token
+    tokens, err := ac.authService.IssueTokens(subject, c.Request.UserAgent())
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not generate token"})
         return
     }
 
-    // 🔗 Ambil siswa yang terkait dengan user ini
-    var siswa entity.Siswa
-    if err := config.DB.Where("id_user = ?", user.Id).First(&siswa).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Siswa tidak ditemukan untuk user ini"})
+    // 🎯 Kirimkan token, role dan identitas sesuai role
+    response := gin.H{
+        "message":       "Login successful",
+        "token":         tokens.AccessToken,
+        "refresh_token": tokens.RefreshToken,
+        "expires_in":    tokens.ExpiresIn,
+        "roles":         subject.Roles,
+    }
+    if subject.IdSiswa != 0 {
+        response["id_siswa"] = login.Siswa.IdSiswa
+        response["nama_siswa"] = login.Siswa.NamaSiswa
+    }
+    if subject.IdGuru != 0 {
+        response["id_guru"] = login.Guru.IdGuru
+        response["nama_guru"] = login.Guru.NamaGuru
+    }
+    if subject.IdOperator != 0 {
+        response["id_operator"] = login.Operator.IdOperator
+    }
+    c.JSON(http.StatusOK, response)
+}
+
+// RefreshToken tukar refresh token dengan pasangan token baru; refresh token lama langsung tidak berlaku
+func (ac *authController) RefreshToken(c *gin.Context) {
+    var input struct {
+        RefreshToken string `json:"refresh_token"`
+    }
+    if err := c.ShouldBindJSON(&input); err != nil || input.RefreshToken == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "refresh_token must be provided"})
         return
     }
 
-    // 🔐 Buat token JWT
-    token, err := auth.GenerateJWT(user.Id, user.Email)
+    tokens, err := ac.authService.RefreshTokens(input.RefreshToken)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not generate token"})
+        if errors.Is(err, service.ErrInvalidRefreshToken) || errors.Is(err, service.ErrSessionRevoked) {
+            c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not refresh token"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "message":       "Token refreshed",
+        "token":         tokens.AccessToken,
+        "refresh_token": tokens.RefreshToken,
+        "expires_in":    tokens.ExpiresIn,
+    })
+}
+
+// Logout mencabut sesi milik access token yang dipakai
+func (ac *authController) Logout(c *gin.Context) {
+    if err := ac.authService.Logout(c.GetString(middleware.ContextTokenID)); err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not logout"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Logout successful"})
+}
+
+// LogoutAll mencabut semua sesi user, misalnya saat tablet hilang
+func (ac *authController) LogoutAll(c *gin.Context) {
+    revoked, err := ac.authService.RevokeAllSessions(c.GetUint64(middleware.ContextIdUser))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not revoke sessions"})
         return
     }
 
-    // 🎯 Kirimkan token dan id_siswa
     c.JSON(http.StatusOK, gin.H{
-        "message":   "Login successful",
-        "token":     token,
-        "id_siswa":  siswa.IdSiswa,     // Inilah yang kamu cari
-        "nama_siswa":   siswa.NamaSiswa,   // Optional
+        "message":          "All sessions revoked",
+        "revoked_sessions": revoked,
     })
 }
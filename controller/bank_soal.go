@@ -0,0 +1,213 @@
+package controller
+
+import (
+	"cbt-api/helper"
+	"cbt-api/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bankSoalRequest is a soal of the question bank with its classification and topic tags
+type bankSoalRequest struct {
+	soalRequest
+	IdMataPelajaran uint64   `json:"id_mata_pelajaran" binding:"required"`
+	IdKelas         uint64   `json:"id_kelas" binding:"required"`
+	Tag             []string `json:"tag"`
+}
+
+func (r bankSoalRequest) toInput() service.BankSoal {
+	input := service.BankSoal{SoalDenganJawaban: r.soalRequest.toInput(), Tag: r.Tag}
+	input.Soal.IdMataPelajaran = r.IdMataPelajaran
+	input.Soal.IdKelas = r.IdKelas
+	return input
+}
+
+// tautanUjianRequest sets the points of a bank soal in one ujian; nil keeps the soal's own nilai_per_soal
+type tautanUjianRequest struct {
+	NilaiPerSoal *float64 `json:"nilai_per_soal"`
+}
+
+// GetBankSoal lists the question bank, filtered by id_mata_pelajaran, id_kelas and tag
+func (sc *soalController) GetBankSoal(c *gin.Context) {
+	filter := service.FilterBankSoal{Tag: c.Query("tag")}
+	var err error
+	for field, target := range map[string]*uint64{"id_mata_pelajaran": &filter.IdMataPelajaran, "id_kelas": &filter.IdKelas} {
+		if value := c.Query(field); value != "" {
+			if *target, err = strconv.ParseUint(value, 10, 64); err != nil {
+				response := helper.BuildErrorResponse("Failed to process request", "Invalid "+field, helper.EmptyObj{})
+				c.AbortWithStatusJSON(http.StatusBadRequest, response)
+				return
+			}
+		}
+	}
+
+	result, err := sc.soalService.GetBankSoal(filter)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to get bank soal", err.Error(), helper.EmptyObj{})
+		c.JSON(kelolaSoalErrorStatus(err), response)
+		return
+	}
+
+	response := helper.BuildResponse(true, "OK", result)
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateBankSoal adds a soal with its answer options to the question bank
+func (sc *soalController) CreateBankSoal(c *gin.Context) {
+	var request bankSoalRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+
+	result, err := sc.soalService.CreateBankSoal(request.toInput())
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to create bank soal", err.Error(), helper.EmptyObj{})
+		c.JSON(kelolaSoalErrorStatus(err), response)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Bank soal created", result)
+	c.JSON(http.StatusCreated, response)
+}
+
+// UpdateBankSoal replaces a bank soal, its answer options and tags
+func (sc *soalController) UpdateBankSoal(c *gin.Context) {
+	idSoal, err := strconv.ParseUint(c.Param("id_soal"), 10, 64)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", "Invalid id_soal", helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+	var request bankSoalRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+
+	result, err := sc.soalService.UpdateBankSoal(idSoal, request.toInput())
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to update bank soal", err.Error(), helper.EmptyObj{})
+		c.JSON(kelolaSoalErrorStatus(err), response)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Bank soal updated", result)
+	c.JSON(http.StatusOK, response)
+}
+
+// TautkanSoalUjian links a bank soal to an ujian, optionally with its own nilai_per_soal
+func (sc *soalController) TautkanSoalUjian(c *gin.Context) {
+	idUjian, idSoal, ok := idUjianDanSoal(c)
+	if !ok {
+		return
+	}
+	var request tautanUjianRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
+			c.AbortWithStatusJSON(http.StatusBadRequest, response)
+			return
+		}
+	}
+
+	result, err := sc.soalService.TautkanSoalUjian(idUjian, idSoal, request.NilaiPerSoal)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to link soal", err.Error(), helper.EmptyObj{})
+		c.JSON(kelolaSoalErrorStatus(err), response)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Soal linked to ujian", result)
+	c.JSON(http.StatusOK, response)
+}
+
+// LepasSoalUjian removes a bank soal from an ujian
+func (sc *soalController) LepasSoalUjian(c *gin.Context) {
+	idUjian, idSoal, ok := idUjianDanSoal(c)
+	if !ok {
+		return
+	}
+
+	if err := sc.soalService.LepasSoalUjian(idUjian, idSoal); err != nil {
+		response := helper.BuildErrorResponse("Failed to unlink soal", err.Error(), helper.EmptyObj{})
+		c.JSON(kelolaSoalErrorStatus(err), response)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Soal unlinked from ujian", helper.EmptyObj{})
+	c.JSON(http.StatusOK, response)
+}
+
+// TautkanSoalLatihan links a bank soal to a latihan
+func (sc *soalController) TautkanSoalLatihan(c *gin.Context) {
+	idLatihan, idSoal, ok := idLatihanDanSoal(c)
+	if !ok {
+		return
+	}
+
+	result, err := sc.soalService.TautkanSoalLatihan(idLatihan, idSoal)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to link soal", err.Error(), helper.EmptyObj{})
+		c.JSON(kelolaSoalErrorStatus(err), response)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Soal linked to latihan", result)
+	c.JSON(http.StatusOK, response)
+}
+
+// LepasSoalLatihan removes a bank soal from a latihan
+func (sc *soalController) LepasSoalLatihan(c *gin.Context) {
+	idLatihan, idSoal, ok := idLatihanDanSoal(c)
+	if !ok {
+		return
+	}
+
+	if err := sc.soalService.LepasSoalLatihan(idLatihan, idSoal); err != nil {
+		response := helper.BuildErrorResponse("Failed to unlink soal", err.Error(), helper.EmptyObj{})
+		c.JSON(kelolaSoalErrorStatus(err), response)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Soal unlinked from latihan", helper.EmptyObj{})
+	c.JSON(http.StatusOK, response)
+}
+
+// idUjianDanSoal parses the idUjian and id_soal path parameters, aborting with 400 when one is invalid
+func idUjianDanSoal(c *gin.Context) (uint64, uint64, bool) {
+	idUjian, err := strconv.ParseUint(c.Param("idUjian"), 10, 64)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", "Invalid id_ujian", helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return 0, 0, false
+	}
+	idSoal, err := strconv.ParseUint(c.Param("id_soal"), 10, 64)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", "Invalid id_soal", helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return 0, 0, false
+	}
+	return idUjian, idSoal, true
+}
+
+// idLatihanDanSoal parses the id_latihan and id_soal path parameters, aborting with 400 when one is invalid
+func idLatihanDanSoal(c *gin.Context) (uint64, uint64, bool) {
+	idLatihan, err := strconv.ParseUint(c.Param("id_latihan"), 10, 64)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", "Invalid id_latihan", helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return 0, 0, false
+	}
+	idSoal, err := strconv.ParseUint(c.Param("id_soal"), 10, 64)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", "Invalid id_soal", helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return 0, 0, false
+	}
+	return idLatihan, idSoal, true
+}
@@ -1,8 +1,6 @@
 package controller
 
 import (
-	"cbt-api/config"
-	"cbt-api/entity"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"net/http"
@@ -19,55 +17,53 @@ type CheckQuizAttemptResponse struct {
 	Message      string `json:"message,omitempty"`
 }
 
-func CheckQuizAttempt(c *gin.Context) {
+func (nc *nilaiController) CheckQuizAttempt(c *gin.Context) {
 	var input CheckQuizAttemptRequest
 
 	// Try to bind JSON request body
 	if err := c.ShouldBindJSON(&input); err != nil {
-		// Coba dari query jika body gagal
+		// Coba dari query atau parameter URL jika body gagal
 		idUjian := c.Query("id_ujian")
-		idSiswaStr := c.Query("id_siswa")
+		if idUjian == "" {
+			idUjian = c.Param("id_ujian")
+		}
 
-		if idUjian == "" || idSiswaStr == "" {
-			fmt.Println("Missing id_ujian or id_siswa") // Debug log
+		if idUjian == "" {
+			fmt.Println("Missing id_ujian") // Debug log
 			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "id_ujian and id_siswa must be provided",
+				"error": "id_ujian must be provided",
 			})
 			return
 		}
 
-		idSiswa, err := strconv.Atoi(idSiswaStr)
-		if err != nil {
-			fmt.Println("Invalid id_siswa:", err) // Debug log
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid id_siswa"})
-			return
-		}
-
 		input.IDUjian = idUjian
-		input.IDSiswa = idSiswa
+	}
+
+	// id_siswa selalu dari token; id_siswa di body hanya boleh milik siswa yang login
+	idSiswa, err := siswaFromToken(c, uint64(max(input.IDSiswa, 0)))
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
 	}
 
 	fmt.Println("Received input:", input) // Debug log
 
-	// Konversi id_ujian dari string ke int
-	idUjianInt, err := strconv.Atoi(input.IDUjian)
+	// Konversi id_ujian dari string ke uint64
+	idUjian, err := strconv.ParseUint(input.IDUjian, 10, 64)
 	if err != nil {
 		fmt.Println("Invalid id_ujian:", err) // Debug log
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid id_ujian"})
 		return
 	}
 
-	// Query menggunakan GORM
-	var count int64
-	if err := config.DB.Model(&entity.TipeNilai{}).
-		Where("id_ujian = ? AND id_siswa = ?", idUjianInt, input.IDSiswa).
-		Count(&count).Error; err != nil {
+	// Siswa dianggap sudah mengerjakan bila sudah ada tipe_nilai untuk ujian ini
+	hasAttempted, err := nc.nilaiService.HasAttempted(idUjian, idSiswa)
+	if err != nil {
 		fmt.Println("Database error:", err) // Debug log
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error: " + err.Error()})
 		return
 	}
 
-	hasAttempted := count > 0
 	message := "Student has not attempted this quiz yet"
 	if hasAttempted {
 		message = "Student has already attempted this quiz"
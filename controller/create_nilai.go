@@ -3,11 +3,10 @@ package controller
 import (
 	"net/http"
 	"github.com/gin-gonic/gin"
-	"cbt-api/config"
 	"cbt-api/entity"
 )
 
-func CreateTipeNilai(c *gin.Context) {
+func (nc *nilaiController) CreateTipeNilai(c *gin.Context) {
 	// Bind the JSON body to the TipeNilai struct
 	var tipeNilai entity.TipeNilai
 	if err := c.ShouldBindJSON(&tipeNilai); err != nil {
@@ -16,7 +15,8 @@ func CreateTipeNilai(c *gin.Context) {
 	}
 
 	// Save the new TipeNilai to the database
-	if err := config.DB.Create(&tipeNilai).Error; err != nil {
+	tipeNilai, err := nc.nilaiService.CreateTipeNilai(tipeNilai)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save score"})
 		return
 	}
@@ -1,20 +1,15 @@
 package controller
 
 import (
-	"cbt-api/config"
-	"cbt-api/entity"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
 // GetAllKelas - Menampilkan seluruh kelas
-func GetAllKelas(c *gin.Context) {
-	// Inisialisasi slice untuk menampung semua kelas
-	var kelasList []entity.Kelas
-
-	// Query untuk mengambil seluruh data kelas
-	err := config.DB.Find(&kelasList).Error
+func (rc *referensiController) GetAllKelas(c *gin.Context) {
+	// Ambil seluruh data kelas
+	kelasList, err := rc.referensiService.GetAllKelas()
 
 	// Jika terjadi error
 	if err != nil {
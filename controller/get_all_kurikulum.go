@@ -1,19 +1,15 @@
 package controller
 
 import (
-	"cbt-api/config"
-	"cbt-api/entity"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
 // GetAllKurikulum will return all the curriculum records.
-func GetAllKurikulum(c *gin.Context) {
-	var kurikulumList []entity.Kurikulum
-
+func (rc *referensiController) GetAllKurikulum(c *gin.Context) {
 	// Retrieve all curriculum from the database
-	err := config.DB.Find(&kurikulumList).Error
+	kurikulumList, err := rc.referensiService.GetAllKurikulum()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to retrieve curriculum"})
 		return
@@ -1,27 +1,20 @@
 package controller
 
 import (
-	"cbt-api/config"   // Import DB configuration
-	"cbt-api/entity"   // Import Kursus and KursusSiswa entities
 	"net/http"
 	"github.com/gin-gonic/gin"
 )
 
 // GetAvailableKursusForSiswa will return courses that the student is not enrolled in.
-// GetAvailableKursusForSiswa will return courses that the student is not enrolled in.
-func GetAvailableKursusForSiswa(c *gin.Context) {
-    idSiswa := c.Param("id_siswa")
-
-    var kursusList []entity.Kursus
+func (kc *kursusController) GetAvailableKursusForSiswa(c *gin.Context) {
+    idSiswa, err := siswaFromToken(c, 0)
+    if err != nil {
+        c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+        return
+    }
 
     // Ambil kursus yang belum diambil siswa
-    err := config.DB.Table("kursus").
-        Where("kursus.id_kursus NOT IN (?)",
-            config.DB.Table("kursus_siswa").
-                Select("id_kursus").
-                Where("id_siswa = ?", idSiswa),
-        ).Find(&kursusList).Error
-
+    kursusList, err := kc.kursusService.GetAvailableKursus(idSiswa)
     if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{
             "error":   "Unable to retrieve available courses",
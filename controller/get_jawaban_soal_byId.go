@@ -1,15 +1,16 @@
 package controller
 
 import (
-    "cbt-api/config"
-    "cbt-api/entity"
+    "cbt-api/middleware"
+    "cbt-api/service"
+    "errors"
     "net/http"
     "strconv"
 
     "github.com/gin-gonic/gin"
 )
 
-func GetJawabanSoalByID(c *gin.Context) {
+func (sc *soalController) GetJawabanSoalByID(c *gin.Context) {
     idParam := c.Param("id_jawaban_soal")
 
     // Konversi ke uint64
@@ -19,8 +20,26 @@ func GetJawabanSoalByID(c *gin.Context) {
         return
     }
 
-    var jawabanSoal entity.JawabanSoal
-    if err := config.DB.First(&jawabanSoal, "id_jawaban_soal = ?", id).Error; err != nil {
+    // Siswa tidak mendapat flag benar sebelum kunci jawaban boleh ditampilkan
+    if idSiswa, ok := middleware.GetIdSiswa(c); ok {
+        opsi, err := sc.soalService.GetJawabanSoalSiswa(id, idSiswa)
+        if errors.Is(err, service.ErrKunciJawabanTersembunyi) {
+            c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+            return
+        }
+        if err != nil {
+            c.JSON(http.StatusNotFound, gin.H{"error": "Jawaban soal not found", "detail": err.Error()})
+            return
+        }
+        c.JSON(http.StatusOK, gin.H{
+            "message":       "Data retrieved successfully",
+            "jawaban_soal":  opsi,
+        })
+        return
+    }
+
+    jawabanSoal, err := sc.soalService.GetJawabanSoalByID(id)
+    if err != nil {
         c.JSON(http.StatusNotFound, gin.H{"error": "Jawaban soal not found", "detail": err.Error()})
         return
     }
@@ -1,27 +1,31 @@
 package controller
 
 import (
-	"cbt-api/config"
-	"cbt-api/entity"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // GetKursusById will return course details based on id_kursus
-func GetKursusById(c *gin.Context) {
-	idKursus := c.Param("id_kursus") // Ambil id_kursus dari parameter URL
-
-	// Cari kursus berdasarkan id_kursus
-	var kursus entity.Kursus
-	if err := config.DB.Where("id_kursus = ?", idKursus).First(&kursus).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching kursus"})
+func (kc *kursusController) GetKursusById(c *gin.Context) {
+	idKursus, err := strconv.ParseUint(c.Param("id_kursus"), 10, 64) // Ambil id_kursus dari parameter URL
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Kursus not found for the given id_kursus"})
 		return
 	}
 
-	// Jika kursus tidak ditemukan
-	if (kursus == entity.Kursus{}) {
-		c.JSON(http.StatusNotFound, gin.H{"message": "Kursus not found for the given id_kursus"})
+	// Cari kursus berdasarkan id_kursus
+	kursus, err := kc.kursusService.GetKursusByID(idKursus)
+	if err != nil {
+		// Jika kursus tidak ditemukan
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Kursus not found for the given id_kursus"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching kursus"})
 		return
 	}
 
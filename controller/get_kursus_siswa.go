@@ -2,17 +2,18 @@ package controller
 
 import (
     "github.com/gin-gonic/gin"
-    "cbt-api/config"
-    "cbt-api/entity"
     "net/http"
 )
 
-func GetKursusBySiswa(c *gin.Context) {
-    idSiswa := c.Param("id_siswa")
-
-    var kursusSiswa []entity.KursusSiswa
+func (kc *kursusController) GetKursusBySiswa(c *gin.Context) {
+    idSiswa, err := siswaFromToken(c, 0)
+    if err != nil {
+        c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+        return
+    }
 
-    if err := config.DB.Preload("Kursus").Where("id_siswa = ?", idSiswa).Find(&kursusSiswa).Error; err != nil {
+    kursusSiswa, err := kc.kursusService.GetKursusBySiswa(idSiswa)
+    if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data kursus siswa"})
         return
     }
@@ -1,22 +1,26 @@
 package controller
 
 import (
-	"cbt-api/config"
-	"cbt-api/entity"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 // GetLatihanSoal will return LatihanSoal details based on id_kurikulum, id_kelas, and id_mata_pelajaran
-func GetLatihanSoal(c *gin.Context) {
-	idKurikulum := c.Param("id_kurikulum")        // Ambil id_kurikulum dari parameter URL
-	idKelas := c.Param("id_kelas")                // Ambil id_kelas dari parameter URL
-	idMataPelajaran := c.Param("id_mata_pelajaran") // Ambil id_mata_pelajaran dari parameter URL
+func (lc *latihanController) GetLatihanSoal(c *gin.Context) {
+	// Ambil id_kurikulum, id_kelas dan id_mata_pelajaran dari parameter URL
+	idKurikulum, errKurikulum := strconv.ParseUint(c.Param("id_kurikulum"), 10, 64)
+	idKelas, errKelas := strconv.ParseUint(c.Param("id_kelas"), 10, 64)
+	idMataPelajaran, errMapel := strconv.ParseUint(c.Param("id_mata_pelajaran"), 10, 64)
+	if errKurikulum != nil || errKelas != nil || errMapel != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Latihan soal not found for the given id_kurikulum, id_kelas, and id_mata_pelajaran"})
+		return
+	}
 
 	// Cari latihan soal berdasarkan id_kurikulum, id_kelas, dan id_mata_pelajaran
-	var latihanSoal []entity.Latihan
-	if err := config.DB.Where("id_kurikulum = ? AND id_kelas = ? AND id_mata_pelajaran = ?", idKurikulum, idKelas, idMataPelajaran).Find(&latihanSoal).Error; err != nil {
+	latihanSoal, err := lc.latihanService.GetLatihanByFilter(idKurikulum, idKelas, idMataPelajaran)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching latihan soal"})
 		return
 	}
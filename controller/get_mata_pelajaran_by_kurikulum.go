@@ -2,18 +2,21 @@ package controller
 
 import (
     "github.com/gin-gonic/gin"
-    "cbt-api/config"
-    "cbt-api/entity"
     "net/http"
+    "strconv"
 )
 
 // Fungsi untuk mendapatkan mata pelajaran berdasarkan id_kurikulum
-func GetMataPelajaranByKurikulum(c *gin.Context) {
-    idKurikulum := c.Param("id_kurikulum") // Ambil id_kurikulum dari parameter URL
+func (rc *referensiController) GetMataPelajaranByKurikulum(c *gin.Context) {
+    idKurikulum, err := strconv.ParseUint(c.Param("id_kurikulum"), 10, 64) // Ambil id_kurikulum dari parameter URL
+    if err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"message": "No mata pelajaran found for the given id_kurikulum"})
+        return
+    }
 
     // Cari mata pelajaran berdasarkan id_kurikulum
-    var mataPelajaranList []entity.MataPelajaran
-    if err := config.DB.Where("id_kurikulum = ?", idKurikulum).Find(&mataPelajaranList).Error; err != nil {
+    mataPelajaranList, err := rc.referensiService.GetMataPelajaranByKurikulumID(idKurikulum)
+    if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching mata pelajaran"})
         return
     }
@@ -2,35 +2,29 @@ package controller
 
 import (
 	"github.com/gin-gonic/gin"
-	"cbt-api/config"
-	"cbt-api/entity"
 	"net/http"
 	"strconv"
 )
 
-func GetNilaiByKursusAndSiswa(c *gin.Context) {
-	// Ambil id_kursus dan id_siswa dari URL parameter
+func (nc *nilaiController) GetNilaiByKursusAndSiswa(c *gin.Context) {
+	// Ambil id_kursus dari URL parameter, id_siswa dari token
 	idKursusStr := c.Param("id_kursus")
-	idSiswaStr := c.Param("id_siswa")
 
-	// Konversi id_kursus dan id_siswa dari string ke uint64
+	// Konversi id_kursus dari string ke uint64, id_siswa diambil dari token
 	idKursus, err := strconv.ParseUint(idKursusStr, 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid id_kursus, must be a valid number", "detail": err.Error()})
 		return
 	}
 
-	idSiswa, err := strconv.ParseUint(idSiswaStr, 10, 64)
+	idSiswa, err := siswaFromToken(c, 0)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid id_siswa, must be a valid number", "detail": err.Error()})
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Query untuk mendapatkan data nilai berdasarkan id_kursus dan id_siswa
-	var nilai []entity.Nilai
-	err = config.DB.Preload("Kursus").Preload("Siswa").
-		Where("id_kursus = ? AND id_siswa = ?", idKursus, idSiswa).
-		Find(&nilai).Error
+	nilai, err := nc.nilaiService.GetNilaiByKursusAndSiswa(idKursus, idSiswa)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data nilai", "detail": err.Error()})
@@ -81,7 +75,7 @@ func GetNilaiByKursusAndSiswa(c *gin.Context) {
 }
 
 // Alternative function specifically for getting only the total score
-func GetTotalNilaiByKursusAndSiswa(c *gin.Context) {
+func (nc *nilaiController) GetTotalNilaiByKursusAndSiswa(c *gin.Context) {
 	// Ambil id_kursus dan id_siswa dari URL parameter
 	idKursusStr := c.Param("id_kursus")
 	idSiswaStr := c.Param("id_siswa")
@@ -99,29 +93,13 @@ func GetTotalNilaiByKursusAndSiswa(c *gin.Context) {
 		return
 	}
 
-	// Query untuk menghitung total nilai menggunakan SUM
-	var totalNilai float64
-	err = config.DB.Model(&entity.Nilai{}).
-		Where("id_kursus = ? AND id_siswa = ?", idKursus, idSiswa).
-		Select("COALESCE(SUM(nilai_total), 0)").
-		Scan(&totalNilai).Error
-
+	// Hitung total nilai (SUM) dan jumlah record
+	totalNilai, jumlahData, err := nc.nilaiService.GetTotalNilai(idKursus, idSiswa)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil total nilai", "detail": err.Error()})
 		return
 	}
 
-	// Query untuk menghitung jumlah record
-	var jumlahData int64
-	err = config.DB.Model(&entity.Nilai{}).
-		Where("id_kursus = ? AND id_siswa = ?", idKursus, idSiswa).
-		Count(&jumlahData).Error
-
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung jumlah data", "detail": err.Error()})
-		return
-	}
-
 	// Jika tidak ada data yang ditemukan
 	if jumlahData == 0 {
 		c.JSON(http.StatusOK, gin.H{
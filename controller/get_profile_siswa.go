@@ -1,32 +1,29 @@
 package controller
 
 import (
+	"errors"
 	"gorm.io/gorm"
-	"cbt-api/config"
-	"cbt-api/entity"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
-// GetAllSiswa mengembalikan seluruh data siswa beserta data kelas yang terkait
 // GetSiswaWithKelas mengembalikan data siswa beserta kelasnya berdasarkan id_siswa
-func GetSiswaWithKelas(c *gin.Context) {
-	// Ambil id_siswa dari parameter URL
-	id := c.Param("id_siswa")
-
-	// Inisialisasi model Siswa
-	var siswa entity.Siswa
+func (sc *siswaController) GetSiswaWithKelas(c *gin.Context) {
+	// Ambil id_siswa dari token siswa yang login
+	id, err := siswaFromToken(c, 0)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 
-	// Query untuk mengambil data siswa beserta relasi kelas
-	err := config.DB.
-		Preload("Kelas"). // Memastikan relasi Kelas dimuat
-		First(&siswa, "id_siswa = ?", id).Error
+	// Ambil data siswa beserta relasi kelas
+	siswa, err := sc.siswaService.GetSiswaWithKelas(id)
 
 	// Jika terjadi error
 	if err != nil {
 		// Jika error karena data tidak ditemukan
-		if err == gorm.ErrRecordNotFound {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Siswa tidak ditemukan"})
 			return
 		}
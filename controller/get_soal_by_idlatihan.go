@@ -1,46 +1,39 @@
 package controller
 
 import (
-	"cbt-api/config"
-	"gorm.io/gorm"
-	"cbt-api/entity"
+	"cbt-api/middleware"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"strconv"
 )
 
 // GetSoalByLatihan will return questions and answers based on latihan ID
-func GetSoalByLatihan(c *gin.Context) {
+func (sc *soalController) GetSoalByLatihan(c *gin.Context) {
 	// Mendapatkan parameter id_latihan dari URL
-	idLatihan := c.Param("id_latihan")
-
-	// Query soal berdasarkan id_latihan
-	var soal []entity.Soal
-	if err := config.DB.Where("id_latihan = ?", idLatihan).Find(&soal).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"message": "Soal tidak ditemukan"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"message": "Terjadi kesalahan saat mengambil data soal", "error": err.Error()})
-		}
+	idLatihan, err := strconv.ParseUint(c.Param("id_latihan"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid id_latihan"})
 		return
 	}
 
-	// Menyusun data soal dengan jawaban yang terorganisir
-	var soalDenganJawaban []map[string]interface{}
-
-	// Untuk setiap soal, ambil jawaban yang terkait
-	for _, s := range soal {
-		// Query jawaban yang terkait dengan soal berdasarkan id_soal
-		var jawaban []entity.JawabanSoal
-		if err := config.DB.Where("id_soal = ?", s.IdSoal).Find(&jawaban).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"message": "Terjadi kesalahan saat mengambil data jawaban", "error": err.Error()})
+	// Siswa mendapat urutan soal dan opsi miliknya sendiri (diacak jika Acak Aktif) tanpa kunci jawaban;
+	// role lain melihat urutan database beserta kunci
+	if idSiswa, ok := middleware.GetIdSiswa(c); ok {
+		soalSiswa, err := sc.soalService.GetSoalLatihanSiswa(idLatihan, idSiswa)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Terjadi kesalahan saat mengambil data soal", "error": err.Error()})
 			return
 		}
-
-		// Menyusun data soal dengan jawaban
-		soalDenganJawaban = append(soalDenganJawaban, map[string]interface{}{
-			"soal":    s,
-			"jawaban": jawaban,
+		c.JSON(http.StatusOK, gin.H{
+			"data": soalSiswa,
 		})
+		return
+	}
+
+	soalDenganJawaban, err := sc.soalService.GetSoalByLatihan(idLatihan)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Terjadi kesalahan saat mengambil data soal", "error": err.Error()})
+		return
 	}
 
 	// Mengirimkan respon dengan soal dan jawaban
@@ -1,15 +1,15 @@
 package controller
 
 import (
+	"cbt-api/middleware"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"strconv"
+	"errors"
 	"gorm.io/gorm"
-	"cbt-api/config"
-	"cbt-api/entity"
 )
 
-func GetSoalWithJawaban(c *gin.Context) {
+func (sc *soalController) GetSoalWithJawaban(c *gin.Context) {
 	// Mendapatkan parameter id_soal dari URL
 	idSoalStr := c.Param("id_soal")
 	idSoal, err := strconv.ParseUint(idSoalStr, 10, 64)  // Mengubah string ke uint64
@@ -18,28 +18,40 @@ func GetSoalWithJawaban(c *gin.Context) {
 		return
 	}
 
-	// Query soal berdasarkan id_soal
-	var soal entity.Soal
-	if err := config.DB.Where("id_soal = ?", idSoal).First(&soal).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"message": "Soal tidak ditemukan"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"message": "Terjadi kesalahan saat mengambil data soal", "error": err.Error()})
+	// Siswa hanya melihat kunci jawaban setelah mengumpulkan ujian/latihan yang StatusJawaban-nya Aktif
+	if idSiswa, ok := middleware.GetIdSiswa(c); ok {
+		soalSiswa, err := sc.soalService.GetSoalSiswa(idSoal, idSiswa)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"message": "Soal tidak ditemukan"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "Terjadi kesalahan saat mengambil data soal", "error": err.Error()})
+			}
+			return
 		}
+		c.JSON(http.StatusOK, gin.H{
+			"soal":              soalSiswa.Soal,
+			"jawaban":           soalSiswa.Jawaban,
+			"kunci_ditampilkan": soalSiswa.KunciDitampilkan,
+		})
 		return
 	}
 
-	// Query jawaban yang terkait dengan soal berdasarkan id_soal
-	var jawaban []entity.JawabanSoal
-	if err := config.DB.Where("id_soal = ?", idSoal).Find(&jawaban).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Terjadi kesalahan saat mengambil data jawaban", "error": err.Error()})
+	// Query soal dan jawaban yang terkait berdasarkan id_soal
+	soal, err := sc.soalService.GetSoalWithJawaban(idSoal)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Soal tidak ditemukan"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Terjadi kesalahan saat mengambil data soal", "error": err.Error()})
+		}
 		return
 	}
 
 	// Menyusun respon dengan data soal dan jawaban
 	c.JSON(http.StatusOK, gin.H{
-		"soal":    soal,
-		"jawaban": jawaban,
+		"soal":    soal.Soal,
+		"jawaban": soal.Jawaban,
 	})
 }
 
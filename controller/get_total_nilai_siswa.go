@@ -2,31 +2,23 @@ package controller
 
 import (
 	"net/http"
+	"strconv"
 	"github.com/gin-gonic/gin"
-	"cbt-api/config"
-	"cbt-api/entity"
-	"gorm.io/gorm"
 )
 
-func GetTotalNilaiSiswaByUjian(c *gin.Context) {
-	idUjian := c.Param("id_ujian")
-	idSiswa := c.Param("id_siswa")
-
-	var jawabanSiswa []entity.JawabanSiswa
-
-	err := config.DB.
-    Joins("JOIN soal ON soal.id_soal = jawaban_siswa.id_soal").
-    Joins("LEFT JOIN jawaban_soal ON jawaban_soal.id_jawaban_soal = jawaban_siswa.id_jawaban_soal").
-    Joins("LEFT JOIN siswa ON siswa.id_siswa = jawaban_siswa.id_siswa").
-    Preload("Soal").
-    Preload("JawabanSoal", func(db *gorm.DB) *gorm.DB {
-        return db.Select("id_jawaban_soal", "benar") // preload hanya yang perlu
-    }).
-    Preload("Siswa").
-    Where("jawaban_siswa.id_siswa = ? AND soal.id_ujian = ?", idSiswa, idUjian).
-    Find(&jawabanSiswa).Error
-
+func (nc *nilaiController) GetTotalNilaiSiswaByUjian(c *gin.Context) {
+	idUjian, err := strconv.ParseUint(c.Param("id_ujian"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid id_ujian"})
+		return
+	}
+	idSiswa, err := siswaFromToken(c, 0)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 
+	jawabanSiswa, err := nc.nilaiService.GetJawabanSiswa(idUjian, idSiswa)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data jawaban siswa", "detail": err.Error()})
 		return
@@ -40,11 +32,11 @@ func GetTotalNilaiSiswaByUjian(c *gin.Context) {
 		return
 	}
 
-	var totalNilai float64
-	for _, js := range jawabanSiswa {
-		if js.Soal.IdSoal != 0 && js.JawabanSoal.IdJawabanSoal != 0 && js.JawabanSoal.Benar {
-			totalNilai += js.Soal.NilaiPerSoal
-		}
+	// Nilai dihitung oleh service yang sama dengan penilaian ujian, termasuk nilai manual soal Isian
+	totalNilai, err := nc.nilaiService.CalculateScore(idUjian, idSiswa)
+	if err != nil {
+		c.JSON(attemptErrorStatus(err), gin.H{"error": "Gagal menghitung nilai siswa", "detail": err.Error()})
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -2,32 +2,35 @@ package controller
 
 import (
     "github.com/gin-gonic/gin"
-    "cbt-api/config"
-    "cbt-api/entity"
     "net/http"
+    "strconv"
 )
 
 // Fungsi untuk mendapatkan ujian dan materi berdasarkan kursus
-func GetUjianAndMateriByKursus(c *gin.Context) {
-    idKursus := c.Param("id_kursus") // Ambil id_kursus dari parameter URL
+func (kc *kursusController) GetUjianAndMateriByKursus(c *gin.Context) {
+    idKursus, err := strconv.ParseUint(c.Param("id_kursus"), 10, 64) // Ambil id_kursus dari parameter URL
+    if err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": "Kursus not found"})
+        return
+    }
 
     // Cari kursus berdasarkan id_kursus
-    var kursus entity.Kursus
-    if err := config.DB.Where("id_kursus = ?", idKursus).First(&kursus).Error; err != nil {
+    kursus, err := kc.kursusService.GetKursusByID(idKursus)
+    if err != nil {
         c.JSON(http.StatusNotFound, gin.H{"error": "Kursus not found"})
         return
     }
 
     // Ambil semua ujian yang terkait dengan kursus tersebut
-    var ujianList []entity.Ujian
-    if err := config.DB.Where("id_kursus = ?", kursus.IdKursus).Find(&ujianList).Error; err != nil {
+    ujianList, err := kc.ujianService.GetUjianByKursusID(kursus.IdKursus)
+    if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching ujian"})
         return
     }
 
     // Ambil semua materi yang terkait dengan kursus tersebut
-    var materiList []entity.Materi
-    if err := config.DB.Where("id_kursus = ?", kursus.IdKursus).Find(&materiList).Error; err != nil {
+    materiList, err := kc.kursusService.GetMateriByKursusID(kursus.IdKursus)
+    if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching materi"})
         return
     }
@@ -1,22 +1,22 @@
 package controller
 
 import (
-	"cbt-api/config"
-	"cbt-api/entity"
 	"net/http"
+	"strconv"
 	"github.com/gin-gonic/gin"
 )
 
 // GetUjianById - Get Ujian details by idUjian
-func GetUjianById(c *gin.Context) {
+func (uc *ujianController) GetUjianById(c *gin.Context) {
 	// Get the idUjian from the URL parameter
-	idUjian := c.Param("idUjian")
-
-	// Initialize the Ujian variable to hold the result
-	var ujian entity.Ujian
+	idUjian, err := strconv.ParseUint(c.Param("idUjian"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ujian not found"})
+		return
+	}
 
 	// Query the database to find the Ujian with the given idUjian
-	err := config.DB.Where("id_ujian = ?", idUjian).First(&ujian).Error
+	ujian, err := uc.ujianService.GetUjianByID(idUjian)
 
 	// If there's an error while fetching the data
 	if err != nil {
@@ -30,4 +30,4 @@ func GetUjianById(c *gin.Context) {
 		"message": "Ujian retrieved successfully",
 		"ujian":   ujian,
 	})
-}
+}
\ No newline at end of file
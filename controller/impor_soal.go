@@ -0,0 +1,220 @@
+package controller
+
+import (
+	"cbt-api/helper"
+	"cbt-api/service"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// batasUkuranImport is the largest file accepted by ImportSoal
+const batasUkuranImport = 5 << 20
+
+// batasUkuranPaketQTI is the largest package accepted by ImportQTI, which may hold images
+const batasUkuranPaketQTI = 50 << 20
+
+// ImportSoal adds the soal of an uploaded CSV or XLSX template, or a Moodle GIFT or Aiken file, to an
+// ujian or a latihan. The form carries the file in "file", the target in "id_ujian" or "id_latihan"
+// and "dry_run=true" to only validate. "format" (csv, xlsx, gift or aiken) overrides the file
+// extension and "nilai_per_soal" sets the points of GIFT and Aiken soal. Row errors are returned in
+// data.kesalahan.
+func (sc *soalController) ImportSoal(c *gin.Context) {
+	var input service.ImportSoal
+	var err error
+	for field, target := range map[string]*uint64{"id_ujian": &input.IdUjian, "id_latihan": &input.IdLatihan} {
+		if value := c.PostForm(field); value != "" {
+			if *target, err = strconv.ParseUint(value, 10, 64); err != nil {
+				response := helper.BuildErrorResponse("Failed to process request", "Invalid "+field, helper.EmptyObj{})
+				c.AbortWithStatusJSON(http.StatusBadRequest, response)
+				return
+			}
+		}
+	}
+	if value := c.PostForm("nilai_per_soal"); value != "" {
+		if input.NilaiPerSoal, err = strconv.ParseFloat(value, 64); err != nil || input.NilaiPerSoal < 0 {
+			response := helper.BuildErrorResponse("Failed to process request", "Invalid nilai_per_soal", helper.EmptyObj{})
+			c.AbortWithStatusJSON(http.StatusBadRequest, response)
+			return
+		}
+	}
+	if value := c.PostForm("dry_run"); value != "" {
+		if input.DryRun, err = strconv.ParseBool(value); err != nil {
+			response := helper.BuildErrorResponse("Failed to process request", "Invalid dry_run", helper.EmptyObj{})
+			c.AbortWithStatusJSON(http.StatusBadRequest, response)
+			return
+		}
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", "file wajib diunggah", helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+	if fileHeader.Size > batasUkuranImport {
+		response := helper.BuildErrorResponse("Failed to process request", "ukuran file maksimal 5 MB", helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, response)
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+	defer file.Close()
+	if input.Data, err = io.ReadAll(io.LimitReader(file, batasUkuranImport)); err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+	input.NamaFile = fileHeader.Filename
+	input.Format = c.PostForm("format")
+
+	hasil, err := sc.soalService.ImportSoal(input)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to import soal", err.Error(), hasil)
+		c.JSON(kelolaSoalErrorStatus(err), response)
+		return
+	}
+
+	if input.DryRun {
+		response := helper.BuildResponse(true, "Soal valid, belum disimpan", hasil)
+		c.JSON(http.StatusOK, response)
+		return
+	}
+	response := helper.BuildResponse(true, "Soal imported", hasil)
+	c.JSON(http.StatusCreated, response)
+}
+
+// ExportSoal downloads the soal of an ujian or a latihan as a Moodle GIFT or Aiken file. The query
+// carries the source in "id_ujian" or "id_latihan" and "format" (gift or aiken). The ids of soal the
+// format cannot express are listed in the X-Soal-Dilewati header.
+func (sc *soalController) ExportSoal(c *gin.Context) {
+	input := service.EksporSoal{Format: c.DefaultQuery("format", service.FormatGIFT)}
+	var err error
+	for field, target := range map[string]*uint64{"id_ujian": &input.IdUjian, "id_latihan": &input.IdLatihan} {
+		if value := c.Query(field); value != "" {
+			if *target, err = strconv.ParseUint(value, 10, 64); err != nil {
+				response := helper.BuildErrorResponse("Failed to process request", "Invalid "+field, helper.EmptyObj{})
+				c.AbortWithStatusJSON(http.StatusBadRequest, response)
+				return
+			}
+		}
+	}
+
+	hasil, err := sc.soalService.ExportSoal(input)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to export soal", err.Error(), helper.EmptyObj{})
+		c.JSON(kelolaSoalErrorStatus(err), response)
+		return
+	}
+
+	if len(hasil.Dilewati) > 0 {
+		dilewati := make([]string, 0, len(hasil.Dilewati))
+		for _, id := range hasil.Dilewati {
+			dilewati = append(dilewati, strconv.FormatUint(id, 10))
+		}
+		c.Header("X-Soal-Dilewati", strings.Join(dilewati, ","))
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+hasil.NamaFile+`"`)
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", hasil.Data)
+}
+
+// ExportQTI downloads the ujian as an IMS QTI 2.1 zip package. The ids of soal that QTI cannot express
+// are listed in the X-Soal-Dilewati header and image URLs that could not be packaged in X-Media-Gagal.
+func (sc *soalController) ExportQTI(c *gin.Context) {
+	idUjian, err := strconv.ParseUint(c.Param("idUjian"), 10, 64)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", "Invalid id_ujian", helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+
+	hasil, err := sc.soalService.ExportQTI(idUjian)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to export ujian", err.Error(), helper.EmptyObj{})
+		c.JSON(kelolaSoalErrorStatus(err), response)
+		return
+	}
+
+	if len(hasil.Dilewati) > 0 {
+		dilewati := make([]string, 0, len(hasil.Dilewati))
+		for _, id := range hasil.Dilewati {
+			dilewati = append(dilewati, strconv.FormatUint(id, 10))
+		}
+		c.Header("X-Soal-Dilewati", strings.Join(dilewati, ","))
+	}
+	if len(hasil.MediaGagal) > 0 {
+		c.Header("X-Media-Gagal", strings.Join(hasil.MediaGagal, " "))
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+hasil.NamaFile+`"`)
+	c.Data(http.StatusOK, "application/zip", hasil.Data)
+}
+
+// ImportQTI creates an ujian from an uploaded IMS QTI 2.1 zip package. The form carries the package in
+// "file", the ujian in "ujian" as JSON with the fields of create ujian and "dry_run=true" to only
+// validate. nama_ujian, durasi, acak and grade may be left out to take them from the package.
+// Skipped items are returned in data.tidak_didukung and item errors in data.kesalahan.
+func (sc *soalController) ImportQTI(c *gin.Context) {
+	var input service.ImportQTI
+	var err error
+	var request ujianRequest
+	if err := json.Unmarshal([]byte(c.PostForm("ujian")), &request); err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", "Invalid ujian", helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+	input.Ujian = request.toEntity()
+	if value := c.PostForm("dry_run"); value != "" {
+		if input.DryRun, err = strconv.ParseBool(value); err != nil {
+			response := helper.BuildErrorResponse("Failed to process request", "Invalid dry_run", helper.EmptyObj{})
+			c.AbortWithStatusJSON(http.StatusBadRequest, response)
+			return
+		}
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", "file wajib diunggah", helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+	if fileHeader.Size > batasUkuranPaketQTI {
+		response := helper.BuildErrorResponse("Failed to process request", "ukuran paket maksimal 50 MB", helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, response)
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+	defer file.Close()
+	if input.Data, err = io.ReadAll(io.LimitReader(file, batasUkuranPaketQTI)); err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+
+	hasil, err := sc.soalService.ImportQTI(input)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to import ujian", err.Error(), hasil)
+		c.JSON(kelolaSoalErrorStatus(err), response)
+		return
+	}
+
+	if input.DryRun {
+		response := helper.BuildResponse(true, "Paket valid, belum disimpan", hasil)
+		c.JSON(http.StatusOK, response)
+		return
+	}
+	response := helper.BuildResponse(true, "Ujian imported", hasil)
+	c.JSON(http.StatusCreated, response)
+}
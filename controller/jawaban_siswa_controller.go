@@ -42,6 +42,15 @@ func (c *jawabanSiswaController) CreateJawabanSiswa(ctx *gin.Context) {
 		return
 	}
 
+	// Jawaban selalu dicatat atas nama siswa yang login
+	idSiswa, err := siswaFromToken(ctx, jawabanSiswa.IdSiswa)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusForbidden, response)
+		return
+	}
+	jawabanSiswa.IdSiswa = idSiswa
+
 	// Set timestamps
 	jawabanSiswa.CreatedAt = time.Now()
 	jawabanSiswa.UpdatedAt = time.Now()
@@ -49,7 +58,7 @@ func (c *jawabanSiswaController) CreateJawabanSiswa(ctx *gin.Context) {
 	result, err := c.jawabanSiswaService.CreateJawabanSiswa(jawabanSiswa)
 	if err != nil {
 		response := helper.BuildErrorResponse("Failed to create jawaban siswa", err.Error(), helper.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, response)
+		ctx.JSON(submissionErrorStatus(err), response)
 		return
 	}
 
@@ -70,9 +79,16 @@ func (c *jawabanSiswaController) CreateBatchJawabanSiswa(ctx *gin.Context) {
 		return
 	}
 
-	// Set timestamps for all jawaban
+	// Set siswa and timestamps for all jawaban
 	now := time.Now()
 	for i := range request.JawabanList {
+		idSiswa, err := siswaFromToken(ctx, request.JawabanList[i].IdSiswa)
+		if err != nil {
+			response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
+			ctx.AbortWithStatusJSON(http.StatusForbidden, response)
+			return
+		}
+		request.JawabanList[i].IdSiswa = idSiswa
 		request.JawabanList[i].CreatedAt = now
 		request.JawabanList[i].UpdatedAt = now
 	}
@@ -80,7 +96,7 @@ func (c *jawabanSiswaController) CreateBatchJawabanSiswa(ctx *gin.Context) {
 	results, err := c.jawabanSiswaService.CreateBatchJawabanSiswa(request.JawabanList)
 	if err != nil {
 		response := helper.BuildErrorResponse("Failed to create batch jawaban siswa", err.Error(), helper.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, response)
+		ctx.JSON(submissionErrorStatus(err), response)
 		return
 	}
 
@@ -90,7 +106,7 @@ func (c *jawabanSiswaController) CreateBatchJawabanSiswa(ctx *gin.Context) {
 
 // GetJawabanSiswaByID gets a jawaban siswa by ID
 func (c *jawabanSiswaController) GetJawabanSiswaByID(ctx *gin.Context) {
-	id := ctx.Param("id_siswa")
+	id := ctx.Param("id")
 	idUint, err := strconv.ParseUint(id, 10, 64)
 	if err != nil {
 		response := helper.BuildErrorResponse("Failed to process request", "Invalid ID", helper.EmptyObj{})
@@ -105,17 +121,22 @@ func (c *jawabanSiswaController) GetJawabanSiswaByID(ctx *gin.Context) {
 		return
 	}
 
+	if _, err := siswaFromToken(ctx, result.IdSiswa); err != nil {
+		response := helper.BuildErrorResponse("Failed to get jawaban siswa", err.Error(), helper.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusForbidden, response)
+		return
+	}
+
 	response := helper.BuildResponse(true, "Jawaban siswa retrieved", result)
 	ctx.JSON(http.StatusOK, response)
 }
 
-// GetJawabanSiswaBySiswaID gets all jawaban siswa by siswa ID
+// GetJawabanSiswaBySiswaID gets all jawaban siswa of the logged-in siswa
 func (c *jawabanSiswaController) GetJawabanSiswaBySiswaID(ctx *gin.Context) {
-	id := ctx.Param("id_siswa")
-	idUint, err := strconv.ParseUint(id, 10, 64)
+	idUint, err := siswaFromToken(ctx, 0)
 	if err != nil {
-		response := helper.BuildErrorResponse("Failed to process request", "Invalid ID", helper.EmptyObj{})
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response)
+		response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusForbidden, response)
 		return
 	}
 
@@ -130,7 +151,7 @@ func (c *jawabanSiswaController) GetJawabanSiswaBySiswaID(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, response)
 }
 
-// GetJawabanSiswaByUjianID gets all jawaban siswa by ujian ID
+// GetJawabanSiswaByUjianID gets the logged-in siswa's jawaban for an ujian
 func (c *jawabanSiswaController) GetJawabanSiswaByUjianID(ctx *gin.Context) {
 	id := ctx.Param("id_ujian")
 	idUint, err := strconv.ParseUint(id, 10, 64)
@@ -140,7 +161,14 @@ func (c *jawabanSiswaController) GetJawabanSiswaByUjianID(ctx *gin.Context) {
 		return
 	}
 
-	results, err := c.jawabanSiswaService.GetJawabanSiswaByUjianID(idUint)
+	idSiswa, err := siswaFromToken(ctx, 0)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusForbidden, response)
+		return
+	}
+
+	results, err := c.jawabanSiswaService.GetJawabanSiswaByUjianIDAndSiswaID(idUint, idSiswa)
 	if err != nil {
 		response := helper.BuildErrorResponse("Failed to get jawaban siswa", err.Error(), helper.EmptyObj{})
 		ctx.JSON(http.StatusBadRequest, response)
@@ -149,4 +177,12 @@ func (c *jawabanSiswaController) GetJawabanSiswaByUjianID(ctx *gin.Context) {
 
 	response := helper.BuildResponse(true, "Jawaban siswa retrieved", results)
 	ctx.JSON(http.StatusOK, response)
+}
+
+// submissionErrorStatus returns 403 when the jawaban is rejected by the ujian attempt rules
+func submissionErrorStatus(err error) int {
+	if attemptErrorStatus(err) == http.StatusForbidden {
+		return http.StatusForbidden
+	}
+	return http.StatusBadRequest
 }
\ No newline at end of file
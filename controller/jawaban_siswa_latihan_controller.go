@@ -51,6 +51,15 @@ func (c *jawabanLatihanController) CreateJawabanLatihan(ctx *gin.Context) {
 		return
 	}
 
+	// Jawaban selalu dicatat atas nama siswa yang login
+	idSiswa, err := siswaFromToken(ctx, jawabanSiswa.IdSiswa)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusForbidden, response)
+		return
+	}
+	jawabanSiswa.IdSiswa = idSiswa
+
 	// Set timestamps
 	jawabanSiswa.CreatedAt = time.Now()
 	jawabanSiswa.UpdatedAt = time.Now()
@@ -92,9 +101,16 @@ func (c *jawabanLatihanController) CreateBatchJawabanLatihan(ctx *gin.Context) {
 
 	// Debugging: print the received IdLatihan after conversion
 
-	// Set timestamps for all jawaban
+	// Set siswa and timestamps for all jawaban
 	now := time.Now()
 	for i := range request.JawabanList {
+		idSiswa, err := siswaFromToken(ctx, request.JawabanList[i].IdSiswa)
+		if err != nil {
+			response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
+			ctx.AbortWithStatusJSON(http.StatusForbidden, response)
+			return
+		}
+		request.JawabanList[i].IdSiswa = idSiswa
 		request.JawabanList[i].CreatedAt = now
 		request.JawabanList[i].UpdatedAt = now
 	}
@@ -131,17 +147,22 @@ func (c *jawabanLatihanController) GetJawabanLatihanByID(ctx *gin.Context) {
 		return
 	}
 
+	if _, err := siswaFromToken(ctx, result.IdSiswa); err != nil {
+		response := helper.BuildErrorResponse("Failed to get jawaban latihan", err.Error(), helper.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusForbidden, response)
+		return
+	}
+
 	response := helper.BuildResponse(true, "Jawaban latihan retrieved", result)
 	ctx.JSON(http.StatusOK, response)
 }
 
-// GetJawabanLatihanBySiswaID gets all jawaban latihan by siswa ID
+// GetJawabanLatihanBySiswaID gets all jawaban latihan of the logged-in siswa
 func (c *jawabanLatihanController) GetJawabanLatihanBySiswaID(ctx *gin.Context) {
-	id := ctx.Param("id_siswa")
-	idUint, err := strconv.ParseUint(id, 10, 64)
+	idUint, err := siswaFromToken(ctx, 0)
 	if err != nil {
-		response := helper.BuildErrorResponse("Failed to process request", "Invalid ID", helper.EmptyObj{})
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response)
+		response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusForbidden, response)
 		return
 	}
 
@@ -156,7 +177,7 @@ func (c *jawabanLatihanController) GetJawabanLatihanBySiswaID(ctx *gin.Context)
 	ctx.JSON(http.StatusOK, response)
 }
 
-// GetJawabanLatihanByLatihanID gets all jawaban latihan by latihan ID
+// GetJawabanLatihanByLatihanID gets the logged-in siswa's jawaban for a latihan
 func (c *jawabanLatihanController) GetJawabanLatihanByLatihanID(ctx *gin.Context) {
 	id := ctx.Param("id_latihan")
 	idUint, err := strconv.ParseUint(id, 10, 64)
@@ -165,8 +186,15 @@ func (c *jawabanLatihanController) GetJawabanLatihanByLatihanID(ctx *gin.Context
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, response)
 		return
 	}
-	
-	results, err := c.jawabanLatihanService.GetJawabanLatihanByLatihanID(idUint)
+
+	idSiswa, err := siswaFromToken(ctx, 0)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusForbidden, response)
+		return
+	}
+
+	results, err := c.jawabanLatihanService.GetJawabanLatihanByLatihanIDAndSiswaID(idUint, idSiswa)
 	if err != nil {
 		response := helper.BuildErrorResponse("Failed to get jawaban latihan", err.Error(), helper.EmptyObj{})
 		ctx.JSON(http.StatusBadRequest, response)
@@ -198,7 +226,8 @@ func (c *jawabanLatihanController) GetSoalLatihanByID(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, response)
 }
 
-// GetSoalWithJawabanByLatihanID gets all soal with their jawaban options for a specific latihan ID
+// GetSoalWithJawabanByLatihanID gets all soal with their jawaban options for a specific latihan ID,
+// without the answer key until the siswa may see it
 func (c *jawabanLatihanController) GetSoalWithJawabanByLatihanID(ctx *gin.Context) {
 	id := ctx.Param("id_latihan")
 	idUint, err := strconv.ParseUint(id, 10, 64)
@@ -207,42 +236,21 @@ func (c *jawabanLatihanController) GetSoalWithJawabanByLatihanID(ctx *gin.Contex
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, response)
 		return
 	}
-	
-	// Get all soal for this latihan
-	soalList, err := c.soalLatihanService.GetSoalLatihanByID(idUint)
+
+	idSiswa, err := siswaFromToken(ctx, 0)
 	if err != nil {
-		response := helper.BuildErrorResponse("Failed to get soal latihan", err.Error(), helper.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, response)
+		response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusForbidden, response)
 		return
 	}
-	
-	// Get all jawaban options for these soal
-	jawabanMap, err := c.soalLatihanService.GetSoalWithJawabanByLatihanID(idUint)
+
+	result, err := c.soalLatihanService.GetSoalSiswaByLatihanID(idUint, idSiswa)
 	if err != nil {
-		response := helper.BuildErrorResponse("Failed to get jawaban options", err.Error(), helper.EmptyObj{})
+		response := helper.BuildErrorResponse("Failed to get soal latihan", err.Error(), helper.EmptyObj{})
 		ctx.JSON(http.StatusBadRequest, response)
 		return
 	}
-	
-	// Create a response structure that combines soal with their jawaban options
-	type SoalWithJawaban struct {
-		Soal    entity.Soal          `json:"soal"`
-		Jawaban []entity.JawabanSoal `json:"jawaban"`
-	}
-	
-	var result []SoalWithJawaban
-	for _, soal := range soalList {
-		jawaban, exists := jawabanMap[soal.IdSoal]
-		if !exists {
-			jawaban = []entity.JawabanSoal{} // Empty array if no jawaban found
-		}
-		
-		result = append(result, SoalWithJawaban{
-			Soal:    soal,
-			Jawaban: jawaban,
-		})
-	}
 
 	response := helper.BuildResponse(true, "Soal with jawaban retrieved", result)
 	ctx.JSON(http.StatusOK, response)
-}
\ No newline at end of file
+}
@@ -0,0 +1,205 @@
+package controller
+
+import (
+	"cbt-api/entity"
+	"cbt-api/helper"
+	"cbt-api/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// jawabanSoalRequest is one answer option; for Isian soal every correct option is an accepted variant
+type jawabanSoalRequest struct {
+	IdJawabanSoal        uint64  `json:"id_jawaban_soal"` // diisi untuk mengubah opsi yang sudah ada
+	Jawaban              string  `json:"jawaban" binding:"required"`
+	Benar                bool    `json:"benar"`
+	TipePencocokan       string  `json:"tipe_pencocokan"`
+	BedakanHurufBesar    bool    `json:"bedakan_huruf_besar"`
+	PertahankanTandaBaca bool    `json:"pertahankan_tanda_baca"`
+	ToleransiAngka       float64 `json:"toleransi_angka"`
+}
+
+func (r jawabanSoalRequest) toEntity() entity.JawabanSoal {
+	return entity.JawabanSoal{
+		IdJawabanSoal:        r.IdJawabanSoal,
+		Jawaban:              r.Jawaban,
+		Benar:                r.Benar,
+		TipePencocokan:       r.TipePencocokan,
+		BedakanHurufBesar:    r.BedakanHurufBesar,
+		PertahankanTandaBaca: r.PertahankanTandaBaca,
+		ToleransiAngka:       r.ToleransiAngka,
+	}
+}
+
+// soalRequest is a soal together with its complete set of answer options
+type soalRequest struct {
+	Soal          string               `json:"soal" binding:"required"`
+	Image         string               `json:"image"`
+	ImageUrl      string               `json:"image_url"`
+	NilaiPerSoal  float64              `json:"nilai_per_soal"`
+	ModePenilaian string               `json:"mode_penilaian"`
+	IdTipeSoal    uint64               `json:"id_tipe_soal" binding:"required"`
+	Jawaban       []jawabanSoalRequest `json:"jawaban" binding:"dive"`
+}
+
+func (r soalRequest) toInput() service.SoalDenganJawaban {
+	input := service.SoalDenganJawaban{
+		Soal: entity.Soal{
+			Soal:          r.Soal,
+			Image:         r.Image,
+			ImageUrl:      r.ImageUrl,
+			NilaiPerSoal:  r.NilaiPerSoal,
+			ModePenilaian: r.ModePenilaian,
+			IdTipeSoal:    r.IdTipeSoal,
+		},
+		Jawaban: make([]entity.JawabanSoal, 0, len(r.Jawaban)),
+	}
+	for _, jawaban := range r.Jawaban {
+		input.Jawaban = append(input.Jawaban, jawaban.toEntity())
+	}
+	return input
+}
+
+// CreateSoal adds a soal with its answer options to an ujian
+func (sc *soalController) CreateSoal(c *gin.Context) {
+	idUjian, err := strconv.ParseUint(c.Param("idUjian"), 10, 64)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", "Invalid id_ujian", helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+	var request soalRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+
+	result, err := sc.soalService.CreateSoal(idUjian, request.toInput())
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to create soal", err.Error(), helper.EmptyObj{})
+		c.JSON(kelolaSoalErrorStatus(err), response)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Soal created", result)
+	c.JSON(http.StatusCreated, response)
+}
+
+// UpdateSoal replaces a soal and its answer options
+func (sc *soalController) UpdateSoal(c *gin.Context) {
+	idSoal, err := strconv.ParseUint(c.Param("id_soal"), 10, 64)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", "Invalid id_soal", helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+	var request soalRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+
+	result, err := sc.soalService.UpdateSoal(idSoal, request.toInput())
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to update soal", err.Error(), helper.EmptyObj{})
+		c.JSON(kelolaSoalErrorStatus(err), response)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Soal updated", result)
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteSoal deletes a soal that no siswa has answered yet
+func (sc *soalController) DeleteSoal(c *gin.Context) {
+	idSoal, err := strconv.ParseUint(c.Param("id_soal"), 10, 64)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", "Invalid id_soal", helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if err := sc.soalService.DeleteSoal(idSoal); err != nil {
+		response := helper.BuildErrorResponse("Failed to delete soal", err.Error(), helper.EmptyObj{})
+		c.JSON(kelolaSoalErrorStatus(err), response)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Soal deleted", helper.EmptyObj{})
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateJawabanSoal adds one answer option to a soal
+func (sc *soalController) CreateJawabanSoal(c *gin.Context) {
+	idSoal, err := strconv.ParseUint(c.Param("id_soal"), 10, 64)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", "Invalid id_soal", helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+	var request jawabanSoalRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+
+	result, err := sc.soalService.CreateJawabanSoal(idSoal, request.toEntity())
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to create jawaban soal", err.Error(), helper.EmptyObj{})
+		c.JSON(kelolaSoalErrorStatus(err), response)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Jawaban soal created", result)
+	c.JSON(http.StatusCreated, response)
+}
+
+// UpdateJawabanSoal replaces one answer option
+func (sc *soalController) UpdateJawabanSoal(c *gin.Context) {
+	idJawabanSoal, err := strconv.ParseUint(c.Param("id_jawaban_soal"), 10, 64)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", "Invalid id_jawaban_soal", helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+	var request jawabanSoalRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+
+	result, err := sc.soalService.UpdateJawabanSoal(idJawabanSoal, request.toEntity())
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to update jawaban soal", err.Error(), helper.EmptyObj{})
+		c.JSON(kelolaSoalErrorStatus(err), response)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Jawaban soal updated", result)
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteJawabanSoal deletes one answer option that no siswa has chosen yet
+func (sc *soalController) DeleteJawabanSoal(c *gin.Context) {
+	idJawabanSoal, err := strconv.ParseUint(c.Param("id_jawaban_soal"), 10, 64)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", "Invalid id_jawaban_soal", helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if err := sc.soalService.DeleteJawabanSoal(idJawabanSoal); err != nil {
+		response := helper.BuildErrorResponse("Failed to delete jawaban soal", err.Error(), helper.EmptyObj{})
+		c.JSON(kelolaSoalErrorStatus(err), response)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Jawaban soal deleted", helper.EmptyObj{})
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"cbt-api/entity"
+	"cbt-api/helper"
+	"cbt-api/service"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ujianRequest is the body of create and update ujian. Passwords are sent in plain text and stored as
+// bcrypt hashes; on update an empty password keeps the current one.
+type ujianRequest struct {
+	NamaUjian           string    `json:"nama_ujian" binding:"required"`
+	Acak                string    `json:"acak" binding:"required"`
+	StatusJawaban       string    `json:"status_jawaban" binding:"required"`
+	Grade               float64   `json:"grade" binding:"required"`
+	SkorBenar           *float64  `json:"skor_benar"`
+	SkorSalah           float64   `json:"skor_salah"`
+	SkorKosong          float64   `json:"skor_kosong"`
+	IzinkanNilaiNegatif bool      `json:"izinkan_nilai_negatif"`
+	SkalakanKeGrade     bool      `json:"skalakan_ke_grade"`
+	PasswordMasuk       string    `json:"password_masuk"`
+	PasswordKeluar      string    `json:"password_keluar"`
+	WaktuMulai          time.Time `json:"waktu_mulai"`
+	WaktuSelesai        time.Time `json:"waktu_selesai"`
+	Durasi              int       `json:"durasi"`
+	TanggalUjian        time.Time `json:"tanggal_ujian"`
+	IdKursus            uint64    `json:"id_kursus" binding:"required"`
+	IdTipeUjian         uint64    `json:"id_tipe_ujian" binding:"required"`
+}
+
+func (r ujianRequest) toEntity() entity.Ujian {
+	return entity.Ujian{
+		NamaUjian:           r.NamaUjian,
+		Acak:                r.Acak,
+		StatusJawaban:       r.StatusJawaban,
+		Grade:               r.Grade,
+		SkorBenar:           r.SkorBenar,
+		SkorSalah:           r.SkorSalah,
+		SkorKosong:          r.SkorKosong,
+		IzinkanNilaiNegatif: r.IzinkanNilaiNegatif,
+		SkalakanKeGrade:     r.SkalakanKeGrade,
+		PasswordMasuk:       r.PasswordMasuk,
+		PasswordKeluar:      r.PasswordKeluar,
+		WaktuMulai:          r.WaktuMulai,
+		WaktuSelesai:        r.WaktuSelesai,
+		Durasi:              r.Durasi,
+		TanggalUjian:        r.TanggalUjian,
+		IdKursus:            r.IdKursus,
+		IdTipeUjian:         r.IdTipeUjian,
+	}
+}
+
+// CreateUjian creates a new ujian
+func (uc *ujianController) CreateUjian(c *gin.Context) {
+	var request ujianRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+
+	ujian, err := uc.ujianService.CreateUjian(request.toEntity())
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to create ujian", err.Error(), helper.EmptyObj{})
+		c.JSON(kelolaSoalErrorStatus(err), response)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Ujian created", ujian)
+	c.JSON(http.StatusCreated, response)
+}
+
+// UpdateUjian replaces the data of an ujian
+func (uc *ujianController) UpdateUjian(c *gin.Context) {
+	idUjian, err := strconv.ParseUint(c.Param("idUjian"), 10, 64)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", "Invalid id_ujian", helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+	var request ujianRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+
+	input := request.toEntity()
+	input.IdUjian = idUjian
+	ujian, err := uc.ujianService.UpdateUjian(input)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to update ujian", err.Error(), helper.EmptyObj{})
+		c.JSON(kelolaSoalErrorStatus(err), response)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Ujian updated", ujian)
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteUjian deletes an ujian that no siswa has worked on yet, with its soal
+func (uc *ujianController) DeleteUjian(c *gin.Context) {
+	idUjian, err := strconv.ParseUint(c.Param("idUjian"), 10, 64)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", "Invalid id_ujian", helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if err := uc.ujianService.DeleteUjian(idUjian); err != nil {
+		response := helper.BuildErrorResponse("Failed to delete ujian", err.Error(), helper.EmptyObj{})
+		c.JSON(kelolaSoalErrorStatus(err), response)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Ujian deleted", helper.EmptyObj{})
+	c.JSON(http.StatusOK, response)
+}
+
+// ValidasiUjian reports whether the ujian is complete: valid options for every soal and nilai_per_soal
+// adding up to the grade
+func (uc *ujianController) ValidasiUjian(c *gin.Context) {
+	idUjian, err := strconv.ParseUint(c.Param("idUjian"), 10, 64)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", "Invalid id_ujian", helper.EmptyObj{})
+		c.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+
+	result, err := uc.ujianService.ValidasiUjian(idUjian)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to validate ujian", err.Error(), helper.EmptyObj{})
+		c.JSON(kelolaSoalErrorStatus(err), response)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Ujian validated", result)
+	c.JSON(http.StatusOK, response)
+}
+
+// kelolaSoalErrorStatus maps errors of the ujian, soal and jawaban soal write endpoints to HTTP status codes
+func kelolaSoalErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrDataUjianTidakValid),
+		errors.Is(err, service.ErrSoalTidakValid),
+		errors.Is(err, service.ErrOpsiSoalTidakValid),
+		errors.Is(err, service.ErrTotalNilaiMelebihiGrade),
+		errors.Is(err, service.ErrImportSoalTidakValid),
+		errors.Is(err, service.ErrTujuanImportTidakValid),
+		errors.Is(err, service.ErrFormatSpreadsheet),
+		errors.Is(err, service.ErrFormatTidakDikenal),
+		errors.Is(err, service.ErrPaketQTITidakValid),
+		errors.Is(err, service.ErrBukanBankSoal):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrUjianSedangDikerjakan),
+		errors.Is(err, service.ErrUjianSudahDikerjakan),
+		errors.Is(err, service.ErrSudahDijawabSiswa):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
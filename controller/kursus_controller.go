@@ -0,0 +1,43 @@
+// controller/kursus_controller.go
+package controller
+
+import (
+	"cbt-api/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KursusController is a contract for kursus controller
+type KursusController interface {
+	GetKursusById(c *gin.Context)
+	GetKursusBySiswa(c *gin.Context)
+	GetAvailableKursusForSiswa(c *gin.Context)
+	GetUjianAndMateriByKursus(c *gin.Context)
+	GetKursusWithUjianAndNilai(c *gin.Context)
+	EnrollKursus(c *gin.Context)
+	EnrollKursusSiswa(c *gin.Context)
+	GetAllKursusSiswa(c *gin.Context)
+	GetKursusSiswaByID(c *gin.Context)
+	CreateKursusSiswa(c *gin.Context)
+	UpdateKursusSiswa(c *gin.Context)
+	DeleteKursusSiswa(c *gin.Context)
+}
+
+type kursusController struct {
+	kursusService service.KursusService
+	ujianService  service.UjianService
+	nilaiService  service.NilaiService
+}
+
+// NewKursusController creates a new instance of KursusController
+func NewKursusController(
+	kursusService service.KursusService,
+	ujianService service.UjianService,
+	nilaiService service.NilaiService,
+) KursusController {
+	return &kursusController{
+		kursusService: kursusService,
+		ujianService:  ujianService,
+		nilaiService:  nilaiService,
+	}
+}
@@ -0,0 +1,24 @@
+// controller/latihan_controller.go
+package controller
+
+import (
+	"cbt-api/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LatihanController is a contract for latihan controller
+type LatihanController interface {
+	GetLatihanSoal(c *gin.Context)
+}
+
+type latihanController struct {
+	latihanService service.LatihanService
+}
+
+// NewLatihanController creates a new instance of LatihanController
+func NewLatihanController(latihanService service.LatihanService) LatihanController {
+	return &latihanController{
+		latihanService: latihanService,
+	}
+}
@@ -0,0 +1,40 @@
+// controller/nilai_controller.go
+package controller
+
+import (
+	"cbt-api/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NilaiController is a contract for scoring controller: jawaban siswa with their nilai,
+// tipe nilai per ujian, nilai kursus per tipe ujian and the final nilai per kursus
+type NilaiController interface {
+	CreateTipeNilai(c *gin.Context)
+	CalculateScore(c *gin.Context)
+	CheckQuizAttempt(c *gin.Context)
+	GetTotalNilaiSiswaByUjian(c *gin.Context)
+	GetTotalNilaiByTipeUjian(c *gin.Context)
+	GetNilaiByKursusAndSiswa(c *gin.Context)
+	GetTotalNilaiByKursusAndSiswa(c *gin.Context)
+	PostNilaiKursus(c *gin.Context)
+	PutNilaiKursus(c *gin.Context)
+	RecalculateNilaiKursus(c *gin.Context)
+	PostNilai(c *gin.Context)
+	PutNilai(c *gin.Context)
+	RecalculateNilai(c *gin.Context)
+	GetAntrianIsian(c *gin.Context)
+	NilaiJawabanIsian(c *gin.Context)
+	GetAnalisisButir(c *gin.Context)
+}
+
+type nilaiController struct {
+	nilaiService service.NilaiService
+}
+
+// NewNilaiController creates a new instance of NilaiController
+func NewNilaiController(nilaiService service.NilaiService) NilaiController {
+	return &nilaiController{
+		nilaiService: nilaiService,
+	}
+}
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"cbt-api/middleware"
+	"cbt-api/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetAntrianIsian lists the Isian answers of an ujian for manual grading.
+// Query status: "belum" (default) for ungraded answers, "sudah" for graded ones, "semua" for both.
+func (nc *nilaiController) GetAntrianIsian(c *gin.Context) {
+	idUjian, err := strconv.ParseUint(c.Param("id_ujian"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid id_ujian"})
+		return
+	}
+
+	var sudahDinilai *bool
+	switch c.DefaultQuery("status", "belum") {
+	case "belum":
+		belum := false
+		sudahDinilai = &belum
+	case "sudah":
+		sudah := true
+		sudahDinilai = &sudah
+	case "semua":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status harus belum, sudah atau semua"})
+		return
+	}
+
+	antrian, err := nc.nilaiService.GetAntrianIsian(idUjian, sudahDinilai)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil antrian penilaian", "detail": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Antrian penilaian Isian retrieved successfully",
+		"id_ujian": idUjian,
+		"jumlah":   len(antrian),
+		"jawaban":  antrian,
+	})
+}
+
+// NilaiJawabanIsian stores the guru's score and feedback for one Isian answer
+func (nc *nilaiController) NilaiJawabanIsian(c *gin.Context) {
+	idJawabanSiswa, err := strconv.ParseUint(c.Param("id_jawaban_siswa"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid id_jawaban_siswa"})
+		return
+	}
+
+	var request struct {
+		Nilai    *float64 `json:"nilai" binding:"required"`
+		Feedback string   `json:"feedback"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data", "detail": err.Error()})
+		return
+	}
+
+	var idGuru uint64
+	if claims, ok := middleware.GetClaims(c); ok {
+		idGuru = claims.IdGuru
+	}
+
+	hasil, err := nc.nilaiService.NilaiJawabanIsian(idJawabanSiswa, *request.Nilai, request.Feedback, idGuru)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Jawaban siswa tidak ditemukan"})
+		case errors.Is(err, service.ErrBukanJawabanIsian), errors.Is(err, service.ErrNilaiManualTidakValid):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrJawabanBelumDisubmit):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan nilai", "detail": err.Error()})
+		}
+		return
+	}
+
+	// Nilai ujian siswa ikut dihitung ulang dengan nilai manual yang baru
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Jawaban Isian graded successfully",
+		"id_jawaban_siswa": idJawabanSiswa,
+		"nilai":            *request.Nilai,
+		"feedback":         request.Feedback,
+		"hasil":            hasil,
+	})
+}
@@ -1,203 +1,93 @@
 package controller
 
 import (
-	"cbt-api/config"
-	"cbt-api/entity"
+	"cbt-api/service"
 	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
-func PostNilai(c *gin.Context) {
-	// Ambil id_kursus dan id_siswa dari URL parameter
-	idKursusStr := c.Param("id_kursus")
-	idSiswaStr := c.Param("id_siswa")
-
-	// Konversi id_kursus dan id_siswa dari string ke uint64
-	idKursus, err := strconv.ParseUint(idKursusStr, 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid id_kursus, must be a valid number", "detail": err.Error()})
+func (nc *nilaiController) PostNilai(c *gin.Context) {
+	idKursus, idSiswa, ok := parseNilaiParams(c)
+	if !ok {
 		return
 	}
 
-	idSiswa, err := strconv.ParseUint(idSiswaStr, 10, 64)
+	// Jumlahkan semua nilai_kursus siswa lalu update record nilai yang ada atau buat baru
+	result, err := nc.nilaiService.SaveNilai(idKursus, idSiswa)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid id_siswa, must be a valid number", "detail": err.Error()})
-		return
-	}
-
-	// Validasi apakah id_siswa ada di tabel siswa
-	var siswa entity.Siswa
-	err = config.DB.Where("id_siswa = ?", idSiswa).First(&siswa).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		switch {
+		case errors.Is(err, service.ErrSiswaTidakAda):
 			c.JSON(http.StatusNotFound, gin.H{"error": "Siswa tidak ditemukan", "id_siswa": idSiswa})
-			return
+		case errors.Is(err, service.ErrNilaiKursusKosong):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tidak ada nilai untuk siswa pada kursus ini"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan nilai", "detail": err.Error()})
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data siswa", "detail": err.Error()})
 		return
 	}
 
-	// Query untuk mendapatkan semua data nilai_kursus berdasarkan id_kursus dan id_siswa
-	var nilaiKursus []entity.NilaiKursus
-	err = config.DB.
-		Where("id_kursus = ? AND id_siswa = ?", idKursus, idSiswa).
-		Find(&nilaiKursus).Error
-
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data nilai kursus", "detail": err.Error()})
-		return
-	}
-
-	// Cek apakah data nilai_kursus ditemukan
-	if len(nilaiKursus) == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Tidak ada nilai untuk siswa pada kursus ini"})
-		return
-	}
-
-	// Loop untuk menghitung total nilai_kursus
-	var totalNilai float64
-	for _, nilai := range nilaiKursus {
-		totalNilai += nilai.NilaiTipeUjian
-	}
-
 	// Debugging: Cek total nilai yang dihitung
-	fmt.Println("Total Nilai setelah dihitung:", totalNilai)
-
-	// Cek apakah sudah ada record nilai untuk kursus dan siswa ini
-	var existingNilai entity.Nilai
-	result := config.DB.Where("id_kursus = ? AND id_siswa = ?", idKursus, idSiswa).First(&existingNilai)
-
-	now := time.Now()
-
-	if result.Error == nil {
-		// Record sudah ada, update nilai_total
-		oldTotal := existingNilai.NilaiTotal
-		existingNilai.NilaiTotal = totalNilai
-		existingNilai.UpdatedAt = now
-
-		// Update record yang sudah ada
-		if err := config.DB.Save(&existingNilai).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengupdate nilai", "detail": err.Error()})
-			return
-		}
+	fmt.Println("Total Nilai setelah dihitung:", result.NewTotal)
 
+	if !result.Created {
 		// Kembalikan response success untuk update
 		c.JSON(http.StatusOK, gin.H{
 			"message":         "Nilai berhasil diupdate",
-			"nilai":           existingNilai,
-			"previous_total":  oldTotal,
-			"new_total":       totalNilai,
-			"total_difference": totalNilai - oldTotal,
+			"nilai":           result.Nilai,
+			"previous_total":  result.PreviousTotal,
+			"new_total":       result.NewTotal,
+			"total_difference": result.NewTotal - result.PreviousTotal,
 			"operation":       "UPDATE",
 		})
-	} else {
-		// Record belum ada, buat baru
-		nilai := entity.Nilai{
-			IdKursus:   uint64(idKursus),
-			IdSiswa:    uint64(idSiswa),
-			NilaiTotal: totalNilai,
-			CreatedAt:  now,
-			UpdatedAt:  now,
-		}
-
-		// Simpan nilai baru ke dalam tabel nilai
-		if err := config.DB.Create(&nilai).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan nilai", "detail": err.Error()})
-			return
-		}
-
-		// Kembalikan response success untuk create
-		c.JSON(http.StatusOK, gin.H{
-			"message":   "Nilai berhasil disimpan",
-			"nilai":     nilai,
-			"operation": "CREATE",
-		})
+		return
 	}
+
+	// Kembalikan response success untuk create
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Nilai berhasil disimpan",
+		"nilai":     result.Nilai,
+		"operation": "CREATE",
+	})
 }
 
 // PUT method untuk explicit update
-func PutNilai(c *gin.Context) {
-	// Ambil id_kursus dan id_siswa dari URL parameter
-	idKursusStr := c.Param("id_kursus")
-	idSiswaStr := c.Param("id_siswa")
-
-	// Konversi id_kursus dan id_siswa dari string ke uint64
-	idKursus, err := strconv.ParseUint(idKursusStr, 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid id_kursus, must be a valid number", "detail": err.Error()})
+func (nc *nilaiController) PutNilai(c *gin.Context) {
+	idKursus, idSiswa, ok := parseNilaiParams(c)
+	if !ok {
 		return
 	}
 
-	idSiswa, err := strconv.ParseUint(idSiswaStr, 10, 64)
+	// PUT memerlukan record nilai yang sudah ada
+	result, err := nc.nilaiService.UpdateNilai(idKursus, idSiswa)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid id_siswa, must be a valid number", "detail": err.Error()})
-		return
-	}
-
-	// Cek apakah record nilai sudah ada (PUT memerlukan record yang sudah ada)
-	var existingNilai entity.Nilai
-	if err := config.DB.Where("id_kursus = ? AND id_siswa = ?", idKursus, idSiswa).First(&existingNilai).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		switch {
+		case errors.Is(err, service.ErrNilaiTidakAda):
 			c.JSON(http.StatusNotFound, gin.H{"error": "Nilai tidak ditemukan untuk diupdate", "id_kursus": idKursus, "id_siswa": idSiswa})
-			return
+		case errors.Is(err, service.ErrNilaiKursusKosong):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tidak ada nilai untuk siswa pada kursus ini"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengupdate nilai", "detail": err.Error()})
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data nilai", "detail": err.Error()})
-		return
-	}
-
-	// Query untuk mendapatkan semua data nilai_kursus berdasarkan id_kursus dan id_siswa
-	var nilaiKursus []entity.NilaiKursus
-	err = config.DB.
-		Where("id_kursus = ? AND id_siswa = ?", idKursus, idSiswa).
-		Find(&nilaiKursus).Error
-
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data nilai kursus", "detail": err.Error()})
-		return
-	}
-
-	// Cek apakah data nilai_kursus ditemukan
-	if len(nilaiKursus) == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Tidak ada nilai untuk siswa pada kursus ini"})
-		return
-	}
-
-	// Loop untuk menghitung total nilai_kursus
-	var totalNilai float64
-	for _, nilai := range nilaiKursus {
-		totalNilai += nilai.NilaiTipeUjian
-	}
-
-	// Update nilai_total
-	oldTotal := existingNilai.NilaiTotal
-	existingNilai.NilaiTotal = totalNilai
-	existingNilai.UpdatedAt = time.Now()
-
-	// Update record
-	if err := config.DB.Save(&existingNilai).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengupdate nilai", "detail": err.Error()})
 		return
 	}
 
 	// Kembalikan response success
 	c.JSON(http.StatusOK, gin.H{
 		"message":          "Nilai berhasil diupdate via PUT",
-		"nilai":            existingNilai,
-		"previous_total":   oldTotal,
-		"new_total":        totalNilai,
-		"total_difference": totalNilai - oldTotal,
+		"nilai":            result.Nilai,
+		"previous_total":   result.PreviousTotal,
+		"new_total":        result.NewTotal,
+		"total_difference": result.NewTotal - result.PreviousTotal,
 		"operation":        "PUT_UPDATE",
 	})
 }
 
 // Function untuk recalculate semua nilai untuk semua siswa dalam kursus tertentu
-func RecalculateNilai(c *gin.Context) {
+func (nc *nilaiController) RecalculateNilai(c *gin.Context) {
 	idKursusStr := c.Param("id_kursus")
 
 	// Konversi id_kursus dari string ke uint64
@@ -207,85 +97,54 @@ func RecalculateNilai(c *gin.Context) {
 		return
 	}
 
-	// Ambil semua siswa yang memiliki nilai_kursus untuk kursus ini
-	var distinctSiswa []uint64
-	if err := config.DB.Model(&entity.NilaiKursus{}).
-		Where("id_kursus = ?", idKursus).
-		Distinct("id_siswa").
-		Pluck("id_siswa", &distinctSiswa).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil daftar siswa", "detail": err.Error()})
+	// Recalculate untuk setiap siswa yang memiliki nilai_kursus, dalam satu transaksi
+	nilaiResults, err := nc.nilaiService.RecalculateNilai(idKursus)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung ulang nilai", "detail": err.Error()})
 		return
 	}
 
-	var updatedCount int
 	var results []map[string]interface{}
-
-	// Recalculate untuk setiap siswa
-	for _, siswaID := range distinctSiswa {
-		// Query untuk mendapatkan semua data nilai_kursus berdasarkan id_kursus dan id_siswa
-		var nilaiKursus []entity.NilaiKursus
-		err = config.DB.
-			Where("id_kursus = ? AND id_siswa = ?", idKursus, siswaID).
-			Find(&nilaiKursus).Error
-
-		if err != nil {
-			continue // Skip jika error
-		}
-
-		if len(nilaiKursus) == 0 {
-			continue // Skip jika tidak ada data
-		}
-
-		// Hitung total nilai
-		var totalNilai float64
-		for _, nilai := range nilaiKursus {
-			totalNilai += nilai.NilaiTipeUjian
-		}
-
-		// Update atau create record nilai
-		var existingNilai entity.Nilai
-		result := config.DB.Where("id_kursus = ? AND id_siswa = ?", idKursus, siswaID).First(&existingNilai)
-
-		now := time.Now()
-
-		if result.Error == nil {
-			// Update existing record
-			oldTotal := existingNilai.NilaiTotal
-			existingNilai.NilaiTotal = totalNilai
-			existingNilai.UpdatedAt = now
-			config.DB.Save(&existingNilai)
-
+	for _, result := range nilaiResults {
+		if result.Created {
 			results = append(results, map[string]interface{}{
-				"id_siswa":         siswaID,
-				"previous_total":   oldTotal,
-				"new_total":        totalNilai,
-				"total_difference": totalNilai - oldTotal,
-				"operation":        "UPDATED",
-			})
-		} else {
-			// Create new record
-			nilai := entity.Nilai{
-				IdKursus:   idKursus,
-				IdSiswa:    siswaID,
-				NilaiTotal: totalNilai,
-				CreatedAt:  now,
-				UpdatedAt:  now,
-			}
-			config.DB.Create(&nilai)
-
-			results = append(results, map[string]interface{}{
-				"id_siswa":   siswaID,
-				"new_total":  totalNilai,
+				"id_siswa":   result.Nilai.IdSiswa,
+				"new_total":  result.NewTotal,
 				"operation":  "CREATED",
 			})
+			continue
 		}
 
-		updatedCount++
+		results = append(results, map[string]interface{}{
+			"id_siswa":         result.Nilai.IdSiswa,
+			"previous_total":   result.PreviousTotal,
+			"new_total":        result.NewTotal,
+			"total_difference": result.NewTotal - result.PreviousTotal,
+			"operation":        "UPDATED",
+		})
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":       "Recalculation completed successfully",
-		"updated_count": updatedCount,
+		"updated_count": len(nilaiResults),
 		"results":       results,
 	})
+}
+
+// parseNilaiParams membaca id_kursus dan id_siswa dari URL
+func parseNilaiParams(c *gin.Context) (uint64, uint64, bool) {
+	// Konversi id_kursus dan id_siswa dari string ke uint64
+	idKursus, err := strconv.ParseUint(c.Param("id_kursus"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid id_kursus, must be a valid number", "detail": err.Error()})
+		return 0, 0, false
+	}
+
+	idSiswa, err := strconv.ParseUint(c.Param("id_siswa"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid id_siswa, must be a valid number", "detail": err.Error()})
+		return 0, 0, false
+	}
+
+	return idKursus, idSiswa, true
 }
\ No newline at end of file
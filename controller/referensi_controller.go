@@ -0,0 +1,26 @@
+// controller/referensi_controller.go
+package controller
+
+import (
+	"cbt-api/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReferensiController is a contract for reference data controller
+type ReferensiController interface {
+	GetAllKelas(c *gin.Context)
+	GetAllKurikulum(c *gin.Context)
+	GetMataPelajaranByKurikulum(c *gin.Context)
+}
+
+type referensiController struct {
+	referensiService service.ReferensiService
+}
+
+// NewReferensiController creates a new instance of ReferensiController
+func NewReferensiController(referensiService service.ReferensiService) ReferensiController {
+	return &referensiController{
+		referensiService: referensiService,
+	}
+}
@@ -0,0 +1,24 @@
+// controller/siswa_controller.go
+package controller
+
+import (
+	"cbt-api/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SiswaController is a contract for siswa controller
+type SiswaController interface {
+	GetSiswaWithKelas(c *gin.Context)
+}
+
+type siswaController struct {
+	siswaService service.SiswaService
+}
+
+// NewSiswaController creates a new instance of SiswaController
+func NewSiswaController(siswaService service.SiswaService) SiswaController {
+	return &siswaController{
+		siswaService: siswaService,
+	}
+}
@@ -0,0 +1,27 @@
+package controller
+
+import (
+	"cbt-api/middleware"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errBukanSiswa = errors.New("token tidak terkait dengan siswa")
+	errSiswaLain  = errors.New("tidak boleh mengakses data siswa lain")
+)
+
+// siswaFromToken returns the id_siswa of the logged-in siswa. When the request names a siswa
+// itself (e.g. id_siswa in the body), it has to be the same siswa; 0 means "not given".
+// Callers respond with 403 on error.
+func siswaFromToken(c *gin.Context, requested uint64) (uint64, error) {
+	idSiswa, ok := middleware.GetIdSiswa(c)
+	if !ok {
+		return 0, errBukanSiswa
+	}
+	if requested != 0 && requested != idSiswa {
+		return 0, errSiswaLain
+	}
+	return idSiswa, nil
+}
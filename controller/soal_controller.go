@@ -0,0 +1,44 @@
+// controller/soal_controller.go
+package controller
+
+import (
+	"cbt-api/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SoalController is a contract for soal controller
+type SoalController interface {
+	GetSoalWithJawaban(c *gin.Context)
+	GetSoalByUjian(c *gin.Context)
+	GetSoalByLatihan(c *gin.Context)
+	GetJawabanSoalByID(c *gin.Context)
+	CreateSoal(c *gin.Context)
+	UpdateSoal(c *gin.Context)
+	DeleteSoal(c *gin.Context)
+	CreateJawabanSoal(c *gin.Context)
+	UpdateJawabanSoal(c *gin.Context)
+	DeleteJawabanSoal(c *gin.Context)
+	ImportSoal(c *gin.Context)
+	ExportSoal(c *gin.Context)
+	ExportQTI(c *gin.Context)
+	ImportQTI(c *gin.Context)
+	GetBankSoal(c *gin.Context)
+	CreateBankSoal(c *gin.Context)
+	UpdateBankSoal(c *gin.Context)
+	TautkanSoalUjian(c *gin.Context)
+	LepasSoalUjian(c *gin.Context)
+	TautkanSoalLatihan(c *gin.Context)
+	LepasSoalLatihan(c *gin.Context)
+}
+
+type soalController struct {
+	soalService service.SoalService
+}
+
+// NewSoalController creates a new instance of SoalController
+func NewSoalController(soalService service.SoalService) SoalController {
+	return &soalController{
+		soalService: soalService,
+	}
+}
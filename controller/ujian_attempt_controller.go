@@ -0,0 +1,293 @@
+// controller/ujian_attempt_controller.go
+package controller
+
+import (
+	"cbt-api/entity"
+	"cbt-api/helper"
+	"cbt-api/service"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// UjianAttemptController is a contract for ujian attempt controller
+type UjianAttemptController interface {
+	AccessUjian(ctx *gin.Context)
+	ExitUjian(ctx *gin.Context)
+	GetAttemptSiswa(ctx *gin.Context)
+	GetAttemptsByUjianID(ctx *gin.Context)
+	SaveDraftJawaban(ctx *gin.Context)
+	ResumeUjian(ctx *gin.Context)
+	ReviewAttempt(ctx *gin.Context)
+}
+
+type ujianAttemptController struct {
+	ujianAttemptService service.UjianAttemptService
+}
+
+// NewUjianAttemptController creates a new instance of UjianAttemptController
+func NewUjianAttemptController(ujianAttemptService service.UjianAttemptService) UjianAttemptController {
+	return &ujianAttemptController{
+		ujianAttemptService: ujianAttemptService,
+	}
+}
+
+// AccessUjian checks the entry password and starts (or resumes) the siswa's attempt
+func (c *ujianAttemptController) AccessUjian(ctx *gin.Context) {
+	idUjian, err := strconv.ParseUint(ctx.Param("id_ujian"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid id_ujian"})
+		return
+	}
+
+	var userInput struct {
+		PasswordMasuk string `json:"password_masuk"`
+		IdSiswa       uint64 `json:"id_siswa"`
+	}
+	if err := ctx.ShouldBindJSON(&userInput); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data"})
+		return
+	}
+
+	idSiswa, err := siswaFromToken(ctx, userInput.IdSiswa)
+	if err != nil {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	ujian, attempt, err := c.ujianAttemptService.StartAttempt(idUjian, idSiswa, userInput.PasswordMasuk)
+	if err != nil {
+		ctx.JSON(attemptErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":  "Login successful",
+		"ujian":    ujian.NamaUjian,
+		"id_ujian": ujian.IdUjian,
+		"attempt":  attempt,
+	})
+}
+
+// ExitUjian checks the exit password, submits the siswa's attempt and returns the grading result
+func (c *ujianAttemptController) ExitUjian(ctx *gin.Context) {
+	idUjian, err := strconv.ParseUint(ctx.Param("id_ujian"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid id_ujian"})
+		return
+	}
+
+	var userInput struct {
+		PasswordKeluar string `json:"password_keluar"`
+		IdSiswa        uint64 `json:"id_siswa"`
+	}
+	if err := ctx.ShouldBindJSON(&userInput); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data"})
+		return
+	}
+
+	idSiswa, err := siswaFromToken(ctx, userInput.IdSiswa)
+	if err != nil {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	ujian, hasil, err := c.ujianAttemptService.FinishAttempt(idUjian, idSiswa, userInput.PasswordKeluar)
+	if err != nil {
+		ctx.JSON(attemptErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":  "Exit successful",
+		"ujian":    ujian.NamaUjian,
+		"id_ujian": ujian.IdUjian,
+		"attempt":  hasil.Attempt,
+		"hasil":    hasil,
+	})
+}
+
+// GetAttemptSiswa gets the logged-in siswa's latest attempt for an ujian
+func (c *ujianAttemptController) GetAttemptSiswa(ctx *gin.Context) {
+	idUjian, err := strconv.ParseUint(ctx.Param("id_ujian"), 10, 64)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", "Invalid id_ujian", helper.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+	idSiswa, err := siswaFromToken(ctx, 0)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusForbidden, response)
+		return
+	}
+
+	result, err := c.ujianAttemptService.GetLatestAttempt(idUjian, idSiswa)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to get attempt ujian", err.Error(), helper.EmptyObj{})
+		ctx.JSON(attemptErrorStatus(err), response)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Attempt ujian retrieved", result)
+	ctx.JSON(http.StatusOK, response)
+}
+
+// GetAttemptsByUjianID lists every attempt of an ujian so proctors can see who started and finished
+func (c *ujianAttemptController) GetAttemptsByUjianID(ctx *gin.Context) {
+	idUjian, err := strconv.ParseUint(ctx.Param("id_ujian"), 10, 64)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", "Invalid id_ujian", helper.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+
+	results, err := c.ujianAttemptService.GetAttemptsByUjianID(idUjian)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to get attempt ujian", err.Error(), helper.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Attempt ujian retrieved", results)
+	ctx.JSON(http.StatusOK, response)
+}
+
+// SaveDraftJawaban autosaves the logged-in siswa's answer to one soal of the ujian
+func (c *ujianAttemptController) SaveDraftJawaban(ctx *gin.Context) {
+	idUjian, err := strconv.ParseUint(ctx.Param("id_ujian"), 10, 64)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", "Invalid id_ujian", helper.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+	idSoal, err := strconv.ParseUint(ctx.Param("id_soal"), 10, 64)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", "Invalid id_soal", helper.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+
+	var request struct {
+		IdJawabanSoal uint64   `json:"id_jawaban_soal"`
+		Pilihan       []uint64 `json:"pilihan"` // soal Pilihan_Kompleks
+		JawabanSiswa  string   `json:"jawaban_siswa"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+
+	idSiswa, err := siswaFromToken(ctx, 0)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusForbidden, response)
+		return
+	}
+
+	pilihan := make([]entity.JawabanSiswaPilihan, 0, len(request.Pilihan))
+	for _, idJawabanSoal := range request.Pilihan {
+		pilihan = append(pilihan, entity.JawabanSiswaPilihan{IdJawabanSoal: idJawabanSoal})
+	}
+
+	now := time.Now()
+	result, err := c.ujianAttemptService.SaveDraftJawaban(idUjian, entity.JawabanSiswa{
+		IdSoal:        idSoal,
+		IdSiswa:       idSiswa,
+		IdJawabanSoal: request.IdJawabanSoal,
+		JawabanText:   request.JawabanSiswa,
+		Pilihan:       pilihan,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to save jawaban", err.Error(), helper.EmptyObj{})
+		ctx.JSON(attemptErrorStatus(err), response)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Jawaban saved", result)
+	ctx.JSON(http.StatusOK, response)
+}
+
+// ResumeUjian returns the logged-in siswa's attempt in progress with saved answers, remaining time and question order
+func (c *ujianAttemptController) ResumeUjian(ctx *gin.Context) {
+	idUjian, err := strconv.ParseUint(ctx.Param("id_ujian"), 10, 64)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", "Invalid id_ujian", helper.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+	idSiswa, err := siswaFromToken(ctx, 0)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusForbidden, response)
+		return
+	}
+
+	result, err := c.ujianAttemptService.ResumeAttempt(idUjian, idSiswa)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to resume ujian", err.Error(), helper.EmptyObj{})
+		ctx.JSON(attemptErrorStatus(err), response)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Ujian resumed", result)
+	ctx.JSON(http.StatusOK, response)
+}
+
+// ReviewAttempt returns the post-exam review of one of the logged-in siswa's attempts: every soal with
+// the siswa's answer, the answer key, the points earned and the guru's feedback
+func (c *ujianAttemptController) ReviewAttempt(ctx *gin.Context) {
+	idAttempt, err := strconv.ParseUint(ctx.Param("id_ujian_attempt"), 10, 64)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", "Invalid id_ujian_attempt", helper.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+	idSiswa, err := siswaFromToken(ctx, 0)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusForbidden, response)
+		return
+	}
+
+	result, err := c.ujianAttemptService.ReviewAttempt(idAttempt, idSiswa)
+	if err != nil {
+		response := helper.BuildErrorResponse("Failed to get review ujian", err.Error(), helper.EmptyObj{})
+		ctx.JSON(attemptErrorStatus(err), response)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Review ujian retrieved", result)
+	ctx.JSON(http.StatusOK, response)
+}
+
+// attemptErrorStatus maps ujian attempt errors to HTTP status codes
+func attemptErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrPasswordUjianSalah):
+		return http.StatusUnauthorized
+	case errors.Is(err, service.ErrSoalBukanUjian):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrUjianBelumDimulai),
+		errors.Is(err, service.ErrUjianBelumSiap),
+		errors.Is(err, service.ErrUjianSudahBerakhir),
+		errors.Is(err, service.ErrAttemptTidakAda),
+		errors.Is(err, service.ErrAttemptSelesai),
+		errors.Is(err, service.ErrAttemptBelumSelesai),
+		errors.Is(err, service.ErrWaktuAttemptHabis),
+		errors.Is(err, service.ErrPembahasanTidakAktif),
+		errors.Is(err, service.ErrUjianBelumBerakhir):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
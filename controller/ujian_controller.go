@@ -0,0 +1,28 @@
+// controller/ujian_controller.go
+package controller
+
+import (
+	"cbt-api/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UjianController is a contract for ujian controller
+type UjianController interface {
+	GetUjianById(c *gin.Context)
+	CreateUjian(c *gin.Context)
+	UpdateUjian(c *gin.Context)
+	DeleteUjian(c *gin.Context)
+	ValidasiUjian(c *gin.Context)
+}
+
+type ujianController struct {
+	ujianService service.UjianService
+}
+
+// NewUjianController creates a new instance of UjianController
+func NewUjianController(ujianService service.UjianService) UjianController {
+	return &ujianController{
+		ujianService: ujianService,
+	}
+}
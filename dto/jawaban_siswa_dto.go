@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"cbt-api/entity"
+	"time"
+)
+
+// JawabanSiswaDTO is an answer as shown to the siswa who gave it. Whether the chosen option is correct
+// and the guru's grading are only included once KunciDitampilkan is true, under the same rules as the
+// answer key of the soal.
+type JawabanSiswaDTO struct {
+	IdJawabanSiswa   uint64          `json:"id_jawaban_siswa"`
+	JawabanSiswa     string          `json:"jawaban_siswa"`
+	IdSoal           uint64          `json:"id_soal"`
+	IdSiswa          uint64          `json:"id_siswa"`
+	IdJawabanSoal    uint64          `json:"id_jawaban_soal"`
+	IdUjianAttempt   *uint64         `json:"id_ujian_attempt"`
+	Pilihan          []uint64        `json:"pilihan"` // id_jawaban_soal yang dipilih pada soal Pilihan_Kompleks
+	NilaiManual      *float64        `json:"nilai_manual,omitempty"`
+	Feedback         string          `json:"feedback,omitempty"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+	Soal             SoalDTO         `json:"soal"`
+	JawabanSoal      *OpsiJawabanDTO `json:"jawaban_soal,omitempty"`
+	KunciDitampilkan bool            `json:"kunci_ditampilkan"`
+}
+
+// NewJawabanSiswaDTO builds the siswa view of an answer; Soal and JawabanSoal have to be preloaded
+func NewJawabanSiswaDTO(jawaban entity.JawabanSiswa, tampilkanKunci bool) JawabanSiswaDTO {
+	result := JawabanSiswaDTO{
+		IdJawabanSiswa:   jawaban.IdJawabanSiswa,
+		JawabanSiswa:     jawaban.JawabanText,
+		IdSoal:           jawaban.IdSoal,
+		IdSiswa:          jawaban.IdSiswa,
+		IdJawabanSoal:    jawaban.IdJawabanSoal,
+		IdUjianAttempt:   jawaban.IdUjianAttempt,
+		Pilihan:          make([]uint64, 0, len(jawaban.Pilihan)),
+		CreatedAt:        jawaban.CreatedAt,
+		UpdatedAt:        jawaban.UpdatedAt,
+		Soal:             NewSoalDTO(jawaban.Soal),
+		KunciDitampilkan: tampilkanKunci,
+	}
+	for _, pilihan := range jawaban.Pilihan {
+		result.Pilihan = append(result.Pilihan, pilihan.IdJawabanSoal)
+	}
+	if jawaban.IdJawabanSoal != 0 {
+		opsi := NewOpsiJawabanDTO(jawaban.JawabanSoal, tampilkanKunci)
+		result.JawabanSoal = &opsi
+	}
+	if tampilkanKunci {
+		result.NilaiManual = jawaban.NilaiManual
+		result.Feedback = jawaban.Feedback
+	}
+	return result
+}
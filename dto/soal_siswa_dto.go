@@ -0,0 +1,80 @@
+package dto
+
+import "cbt-api/entity"
+
+// SoalSiswaDTO is a soal as shown to a siswa. The answer key is only included once KunciDitampilkan
+// is true; until then options carry no benar flag and the options of an Isian soal, which are the
+// accepted answers, are left out.
+type SoalSiswaDTO struct {
+	Soal             SoalDTO          `json:"soal"`
+	Jawaban          []OpsiJawabanDTO `json:"jawaban"`
+	KunciDitampilkan bool             `json:"kunci_ditampilkan"`
+}
+
+// SoalDTO represents the question part of a soal without relations
+type SoalDTO struct {
+	IdSoal        uint64  `json:"id_soal"`
+	Soal          string  `json:"soal"`
+	Image         string  `json:"image,omitempty"`
+	ImageUrl      string  `json:"image_url"`
+	NilaiPerSoal  float64 `json:"nilai_per_soal"`
+	IdUjian       uint64  `json:"id_ujian"`
+	IdLatihan     uint64  `json:"id_latihan"`
+	IdTipeSoal    uint64  `json:"id_tipe_soal"`
+	TipeSoal      string  `json:"tipe_soal"`
+	ModePenilaian string  `json:"mode_penilaian"`
+}
+
+// OpsiJawabanDTO represents one answer option; Benar is nil while the answer key is hidden
+type OpsiJawabanDTO struct {
+	IdJawabanSoal uint64 `json:"id_jawaban_soal"`
+	IdSoal        uint64 `json:"id_soal"`
+	Jawaban       string `json:"jawaban"`
+	Benar         *bool  `json:"benar,omitempty"`
+}
+
+// NewSoalSiswaDTO builds the siswa view of a soal; the soal's TipeSoal has to be preloaded
+func NewSoalSiswaDTO(soal entity.Soal, opsi []entity.JawabanSoal, tampilkanKunci bool) SoalSiswaDTO {
+	result := SoalSiswaDTO{
+		Soal:             NewSoalDTO(soal),
+		Jawaban:          make([]OpsiJawabanDTO, 0, len(opsi)),
+		KunciDitampilkan: tampilkanKunci,
+	}
+	if soal.TipeSoal.NamaTipeUjian == entity.TipeSoalIsian && !tampilkanKunci {
+		return result
+	}
+	for _, jawabanSoal := range opsi {
+		result.Jawaban = append(result.Jawaban, NewOpsiJawabanDTO(jawabanSoal, tampilkanKunci))
+	}
+	return result
+}
+
+// NewSoalDTO builds the question part of a soal; TipeSoal is empty unless it was preloaded
+func NewSoalDTO(soal entity.Soal) SoalDTO {
+	return SoalDTO{
+		IdSoal:        soal.IdSoal,
+		Soal:          soal.Soal,
+		Image:         soal.Image,
+		ImageUrl:      soal.ImageUrl,
+		NilaiPerSoal:  soal.NilaiPerSoal,
+		IdUjian:       soal.IdUjian,
+		IdLatihan:     soal.IdLatihan,
+		IdTipeSoal:    soal.IdTipeSoal,
+		TipeSoal:      soal.TipeSoal.NamaTipeUjian,
+		ModePenilaian: soal.ModePenilaian,
+	}
+}
+
+// NewOpsiJawabanDTO builds the siswa view of one answer option
+func NewOpsiJawabanDTO(opsi entity.JawabanSoal, tampilkanKunci bool) OpsiJawabanDTO {
+	result := OpsiJawabanDTO{
+		IdJawabanSoal: opsi.IdJawabanSoal,
+		IdSoal:        opsi.IdSoal,
+		Jawaban:       opsi.Jawaban,
+	}
+	if tampilkanKunci {
+		benar := opsi.Benar
+		result.Benar = &benar
+	}
+	return result
+}
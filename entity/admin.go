@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+type Admin struct {
+    IdAdmin   uint64    `gorm:"primary_key;autoIncrement" json:"id_admin"`
+    IdUser    uint64    `gorm:"not null;uniqueIndex" json:"id_user"`
+    CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+    UpdatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
+
+    Users     Users     `gorm:"foreignkey:IdUser;constraint:onUpdate:CASCADE, onDelete:CASCADE" json:"user"`
+}
+
+func (Admin) TableName() string {
+    return "admin" // Nama tabel di database
+}
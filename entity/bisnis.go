@@ -7,7 +7,7 @@ type Bisnis struct {
     NamaSekolah     string    `gorm:"type:varchar(255);not null" json:"nama_sekolah"`
     JumlahPendapatan int      `gorm:"type:int(11);not null" json:"jumlah_pendapatan"`
     CreatedAt        time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
-    UpdatedAt        time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP" json:"updated_at"`
+    UpdatedAt        time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
 }
 
 func (Bisnis) TableName() string {
@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"time"
+)
+
+// IdempotencyKey menyimpan response dari request yang dikirim dengan header Idempotency-Key, agar
+// request ulang (mis. retry karena koneksi putus) mendapat response yang sama tanpa diproses lagi
+type IdempotencyKey struct {
+	IdIdempotencyKey uint64    `gorm:"primary_key;autoIncrement" json:"id_idempotency_key"`
+	IdUser           uint64    `gorm:"not null;uniqueIndex:uq_idempotency_key_user_key,priority:1" json:"id_user"`
+	Key              string    `gorm:"column:idempotency_key;type:varchar(100);not null;uniqueIndex:uq_idempotency_key_user_key,priority:2" json:"key"`
+	Endpoint         string    `gorm:"type:varchar(255);not null" json:"endpoint"`
+	RequestHash      string    `gorm:"type:varchar(64);not null" json:"-"` // sha256 dari body request
+	StatusCode       int       `gorm:"not null" json:"status_code"`
+	ResponseBody     string    `gorm:"type:mediumtext;not null" json:"-"`
+	CreatedAt        time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+func (IdempotencyKey) TableName() string {
+	return "idempotency_key" // Nama tabel di database
+}
@@ -5,15 +5,24 @@ import "time"
 type JawabanSiswa struct {
     IdJawabanSiswa uint64    `gorm:"primary_key;autoIncrement" json:"id_jawaban_siswa"`
     JawabanText    string    `gorm:"type:varchar(255);not null" json:"jawaban_siswa"`
-    IdSoal         uint64    `gorm:"type:bigint(20) unsigned" json:"id_soal"`
-    IdSiswa        uint64    `gorm:"type:bigint(20) unsigned" json:"id_siswa"`
-    IdJawabanSoal  uint64    `gorm:"type:bigint(20) unsigned" json:"id_jawaban_soal"`
+    IdSoal         uint64    `gorm:"index:idx_jawaban_siswa_siswa_soal,priority:2;uniqueIndex:uq_jawaban_siswa_attempt_soal,priority:2" json:"id_soal"`
+    IdSiswa        uint64    `gorm:"index:idx_jawaban_siswa_siswa_soal,priority:1" json:"id_siswa"`
+    IdJawabanSoal  uint64    `json:"id_jawaban_soal"`
+    // Diisi server untuk jawaban ujian; jawaban latihan tidak punya attempt (NULL) sehingga tidak terkena unique index
+    IdUjianAttempt *uint64   `gorm:"uniqueIndex:uq_jawaban_siswa_attempt_soal,priority:1" json:"id_ujian_attempt"`
+    // Penilaian manual guru untuk soal Isian; NilaiManual nil berarti jawaban belum dinilai
+    NilaiManual    *float64   `gorm:"type:decimal(5,2);null" json:"nilai_manual"`
+    Feedback       string     `gorm:"type:text" json:"feedback"`
+    DinilaiOleh    *uint64    `json:"dinilai_oleh"` // id_guru
+    DinilaiPada    *time.Time `gorm:"type:timestamp;null" json:"dinilai_pada"`
     CreatedAt      time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
-    UpdatedAt      time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP" json:"updated_at"`
+    UpdatedAt      time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
 
-    Soal      Soal      `gorm:"foreignkey:IdSoal;constraint:onUpdate:CASCADE, onDelete:CASCADE" json:"soal"`
-    Siswa     Siswa     `gorm:"foreignkey:IdSiswa;constraint:onUpdate:CASCADE, onDelete:CASCADE" json:"siswa"`
-    JawabanSoal JawabanSoal `gorm:"foreignkey:IdJawabanSoal;constraint:onUpdate:CASCADE, onDelete:CASCADE" json:"jawaban_soal"`
+    Soal      Soal      `gorm:"foreignkey:IdSoal;references:IdSoal;constraint:onUpdate:CASCADE, onDelete:CASCADE" json:"soal"`
+    Siswa     Siswa     `gorm:"foreignkey:IdSiswa;references:IdSiswa;constraint:onUpdate:CASCADE, onDelete:CASCADE" json:"siswa"`
+    JawabanSoal JawabanSoal `gorm:"foreignkey:IdJawabanSoal;references:IdJawabanSoal;constraint:onUpdate:CASCADE, onDelete:CASCADE" json:"jawaban_soal"`
+    // Opsi yang dipilih pada soal Pilihan_Kompleks; soal lain memakai IdJawabanSoal
+    Pilihan     []JawabanSiswaPilihan `gorm:"foreignkey:IdJawabanSiswa;references:IdJawabanSiswa;constraint:onUpdate:CASCADE, onDelete:CASCADE" json:"pilihan,omitempty"`
 }
 
 func (JawabanSiswa) TableName() string {
@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+// JawabanSiswaPilihan is one option chosen by the siswa for a Pilihan_Kompleks soal
+type JawabanSiswaPilihan struct {
+    IdJawabanSiswaPilihan uint64    `gorm:"primary_key;autoIncrement" json:"-"`
+    IdJawabanSiswa        uint64    `gorm:"uniqueIndex:uq_jawaban_siswa_pilihan,priority:1" json:"-"`
+    IdJawabanSoal         uint64    `gorm:"uniqueIndex:uq_jawaban_siswa_pilihan,priority:2" json:"id_jawaban_soal"`
+    CreatedAt             time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"-"`
+}
+
+func (JawabanSiswaPilihan) TableName() string {
+    return "jawaban_siswa_pilihan" // Nama tabel di database
+}
@@ -2,14 +2,27 @@ package entity
 
 import "time"
 
+// Nilai kolom TipePencocokan
+const (
+    PencocokanTeks  = "Teks"
+    PencocokanAngka = "Angka"
+    PencocokanRegex = "Regex"
+)
+
 type JawabanSoal struct {
     IdJawabanSoal uint64    `gorm:"primary_key;autoIncrement" json:"id_jawaban_soal"`
     Jawaban       string    `gorm:"type:varchar(255);not null" json:"jawaban"`
     Benar         bool      `gorm:"type:tinyint(1);not null" json:"benar"`
-    IdSoal        uint64    `gorm:"type:bigint(20) unsigned" json:"id_soal"`
-    IdTipeSoal    uint64    `gorm:"type:bigint(20) unsigned" json:"id_tipe_soal"`
+    IdSoal        uint64    `gorm:"index:idx_jawaban_soal_id_soal" json:"id_soal"`
+    IdTipeSoal    uint64    `json:"id_tipe_soal"`
+    // Aturan pencocokan otomatis soal Isian: setiap opsi yang Benar adalah variasi jawaban yang diterima.
+    // Secara default huruf besar/kecil diabaikan dan tanda baca serta spasi berlebih dibuang.
+    TipePencocokan       string  `gorm:"type:varchar(20);not null;default:'Teks';check:chk_jawaban_soal_tipe_pencocokan,tipe_pencocokan IN ('Teks', 'Angka', 'Regex')" json:"tipe_pencocokan"`
+    BedakanHurufBesar    bool    `gorm:"not null;default:false" json:"bedakan_huruf_besar"`
+    PertahankanTandaBaca bool    `gorm:"not null;default:false" json:"pertahankan_tanda_baca"`
+    ToleransiAngka       float64 `gorm:"type:decimal(12,4);not null;default:0" json:"toleransi_angka"` // hanya untuk TipePencocokan Angka
     CreatedAt     time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
-    UpdatedAt     time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP" json:"updated_at"`
+    UpdatedAt     time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
 
     Soal      Soal      `gorm:"foreignkey:IdSoal;constraint:onUpdate:CASCADE, onDelete:CASCADE" json:"soal"`
     TipeSoal  TipeSoal  `gorm:"foreignkey:IdTipeSoal;constraint:onUpdate:CASCADE, onDelete:CASCADE" json:"tipe_soal"`
@@ -8,9 +8,9 @@ type Kursus struct {
     Password    string    `gorm:"type:varchar(255);not null" json:"-"`
     Image       string    `gorm:"type:varchar(255);not null" json:"image"`
     ImageUrl       string    `gorm:"type:varchar(255);not null" json:"image_url"`
-    // IdGuru     uint64    `gorm:"type:bigint(20) unsigned" json:"id_guru"`
+    // IdGuru     uint64    `json:"id_guru"`
     CreatedAt        time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
-    UpdatedAt        time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP" json:"updated_at"`
+    UpdatedAt        time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
 
     // Guru   Guru   `gorm:"foreignkey:IdGuru;constraint:onUpdate:CASCADE, onDelete:CASCADE" json:"guru"`
 }
@@ -5,15 +5,15 @@ import "time"
 type Latihan struct {
     IdLatihan        uint64    `gorm:"primary_key;autoIncrement" json:"id_latihan"`
     Topik      string    `gorm:"type:varchar(255);not null" json:"Topik"`
-    Acak             string    `gorm:"type:enum('Aktif', 'Tidak Aktif');not null" json:"acak"`
-    StatusJawaban    string    `gorm:"type:enum('Aktif', 'Tidak Aktif');not null" json:"status_jawaban"`
+    Acak             string    `gorm:"type:varchar(20);not null;check:chk_latihan_acak,acak IN ('Aktif', 'Tidak Aktif')" json:"acak"`
+    StatusJawaban    string    `gorm:"type:varchar(20);not null;check:chk_latihan_status_jawaban,status_jawaban IN ('Aktif', 'Tidak Aktif')" json:"status_jawaban"`
     Grade            float64   `gorm:"type:double" json:"grade"`
-    IdMataPelajaran  uint64    `gorm:"type:bigint(20) unsigned" json:"id_mata_pelajaran"`
-    IdKelas          uint64    `gorm:"type:bigint(20) unsigned" json:"id_kelas"`
-    IdKurikulum      uint64    `gorm:"type:bigint(20) unsigned" json:"id_kurikulum"`
-    // IdGuru        uint64    `gorm:"type:bigint(20) unsigned" json:"id_guru"`
+    IdMataPelajaran  uint64    `gorm:"index:idx_latihan_filter,priority:3" json:"id_mata_pelajaran"`
+    IdKelas          uint64    `gorm:"index:idx_latihan_filter,priority:2" json:"id_kelas"`
+    IdKurikulum      uint64    `gorm:"index:idx_latihan_filter,priority:1" json:"id_kurikulum"`
+    // IdGuru        uint64    `json:"id_guru"`
     CreatedAt        time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
-    UpdatedAt        time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP" json:"updated_at"`
+    UpdatedAt        time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
 
     // // Guru    Guru    `gorm:"foreignkey:IdGuru;constraint:onUpdate:CASCADE, onDelete:CASCADE" json:"guru"`
     MataPelajaran  MataPelajaran    `gorm:"foreignkey:IdMataPelajaran;constraint:onUpdate:CASCADE, onDelete:CASCADE" json:"mata_pelajaran"`
@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+// LatihanSoal menghubungkan soal dengan latihan. Query jawaban latihan melakukan JOIN ke tabel ini.
+type LatihanSoal struct {
+    IdLatihanSoal uint64    `gorm:"primary_key;autoIncrement" json:"id_latihan_soal"`
+    IdLatihan     uint64    `gorm:"not null;uniqueIndex:uq_latihan_soal,priority:1" json:"id_latihan"`
+    IdSoal        uint64    `gorm:"not null;uniqueIndex:uq_latihan_soal,priority:2;index:idx_latihan_soal_id_soal" json:"id_soal"`
+    CreatedAt     time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+    UpdatedAt     time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
+
+    Latihan Latihan `gorm:"foreignkey:IdLatihan;constraint:onUpdate:CASCADE, onDelete:CASCADE" json:"latihan"`
+    Soal    Soal    `gorm:"foreignkey:IdSoal;constraint:onUpdate:CASCADE, onDelete:CASCADE" json:"soal"`
+}
+
+func (LatihanSoal) TableName() string {
+    return "latihan_soal" // Nama tabel di database
+}
@@ -8,10 +8,10 @@ type Materi struct {
     Deskripsi       string  `gorm:"type:varchar(255);not null" json:"deskripsi"`
     
     FileUrl      string  `gorm:"type:varchar(255);not null" json:"file_url"`
-	IdKursus      uint64   `gorm:"type:bigint(20) unsigned" json:"id_kursus"`
-	// IdGuru      uint64   `gorm:"type:bigint(20) unsigned" json:"id_guru"`
+	IdKursus      uint64   `gorm:"index:idx_materi_id_kursus" json:"id_kursus"`
+	// IdGuru      uint64   `json:"id_guru"`
     CreatedAt        time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
-    UpdatedAt        time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP" json:"updated_at"`
+    UpdatedAt        time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
 
     // Guru   Guru  `gorm:"foreignkey:IdGuru;constraint:onUpdate:CASCADE, onDelete:CASCADE" json:"guru"`
     Kursus  Kursus `gorm:"foreignkey:IdKursus;constraint:onUpdate:CASCADE, onDelete:CASCADE" json:"kursus"`
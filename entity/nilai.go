@@ -7,8 +7,8 @@ import (
 type Nilai struct {
 	IdNilai     uint64    `gorm:"primary_key;autoIncrement" json:"id_nilai"`
 	NilaiTotal  float64   `gorm:"type:decimal(5,0);not null" json:"nilai_total"`
-	IdKursus    uint64    `gorm:"type:bigint(20);not null" json:"id_kursus"`
-	IdSiswa     uint64    `gorm:"type:bigint(20);not null" json:"id_siswa"`
+	IdKursus    uint64    `gorm:"not null;uniqueIndex:uq_nilai_kursus_siswa,priority:1" json:"id_kursus"`
+	IdSiswa     uint64    `gorm:"not null;uniqueIndex:uq_nilai_kursus_siswa,priority:2" json:"id_siswa"`
 	CreatedAt   time.Time `gorm:"type:timestamp;default:current_timestamp" json:"created_at"`
 	UpdatedAt   time.Time `gorm:"type:timestamp;default:current_timestamp" json:"updated_at"`
 
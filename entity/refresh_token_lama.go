@@ -0,0 +1,18 @@
+package entity
+
+import (
+	"time"
+)
+
+// RefreshTokenLama menyimpan hash refresh token yang sudah dirotasi. Token lama yang dipakai lagi
+// berarti token itu bocor, sehingga sesinya dicabut.
+type RefreshTokenLama struct {
+	IdRefreshTokenLama uint64    `gorm:"primary_key;autoIncrement" json:"id_refresh_token_lama"`
+	IdUserSession      uint64    `gorm:"not null;index" json:"id_user_session"`
+	RefreshTokenHash   string    `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	CreatedAt          time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+func (RefreshTokenLama) TableName() string {
+	return "refresh_token_lama" // Nama tabel di database
+}
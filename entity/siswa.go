@@ -6,12 +6,12 @@ type Siswa struct {
     IdSiswa     uint64    `gorm:"primary_key;autoIncrement" json:"id_siswa"`
     NamaSiswa   string    `gorm:"type:varchar(255);not null" json:"nama_siswa"`
     NIS         int       `gorm:"type:int(11);not null" json:"nis"`
-    Status      string    `gorm:"type:enum('Aktif', 'Tidak Aktif');not null" json:"status"`
-    IdUser      uint64    `gorm:"type:bigint(20) unsigned" json:"id_user"`
-    // IdOperator  uint64    `gorm:"type:bigint(20) unsigned" json:"id_operator"`
-    IdKelas     uint64    `gorm:"type:bigint(20) unsigned" json:"id_kelas"`
+    Status      string    `gorm:"type:varchar(20);not null;check:chk_siswa_status,status IN ('Aktif', 'Tidak Aktif')" json:"status"`
+    IdUser      uint64    `gorm:"index:idx_siswa_id_user" json:"id_user"`
+    // IdOperator  uint64    `json:"id_operator"`
+    IdKelas     uint64    `gorm:"index:idx_siswa_id_kelas" json:"id_kelas"`
     CreatedAt   time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
-    UpdatedAt   time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP" json:"updated_at"`
+    UpdatedAt   time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
 
 
     User     Users   `gorm:"foreignkey:IdUser;constraint:onUpdate:CASCADE, onDelete:CASCADE" json:"user"`
@@ -4,21 +4,32 @@ import (
 	"time"
 )
 
+// Nilai kolom ModePenilaian, dipakai soal Pilihan_Kompleks
+const (
+	ModePenilaianSemuaBenar   = "Semua_Benar"  // nilai penuh hanya jika semua opsi benar dipilih tanpa opsi salah
+	ModePenilaianProporsional = "Proporsional" // sebanding dengan opsi benar yang dipilih
+	ModePenilaianPenalti      = "Penalti"      // opsi salah yang dipilih mengurangi nilai
+)
+
 type Soal struct {
 	IdSoal    uint64    `gorm:"primary_key;autoIncrement" json:"id_soal"`
 	Soal      string    `gorm:"type:varchar(255);not null" json:"soal"`
 	Image     string    `gorm:"type:varchar(255);nullable" json:"image,omitempty"`
 	ImageUrl       string    `gorm:"type:varchar(255);not null" json:"image_url"`
 	NilaiPerSoal float64  `gorm:"type:decimal(5,2);nullable" json:"nilai_per_soal"`
-	IdUjian   uint64    `gorm:"type:bigint(20) unsigned" json:"id_ujian"`
-	IdTipeSoal uint64  `gorm:"type:bigint(20) unsigned" json:"id_tipe_soal"`
-	IdLatihan uint64    `gorm:"type:bigint(20) unsigned" json:"id_latihan"`
+	ModePenilaian string  `gorm:"type:varchar(20);not null;default:'Semua_Benar';check:chk_soal_mode_penilaian,mode_penilaian IN ('Semua_Benar', 'Proporsional', 'Penalti')" json:"mode_penilaian"`
+	IdUjian   uint64    `gorm:"index:idx_soal_id_ujian" json:"id_ujian"`
+	IdTipeSoal uint64  `json:"id_tipe_soal"`
+	IdLatihan uint64    `gorm:"index:idx_soal_id_latihan" json:"id_latihan"`
+	// Klasifikasi bank soal; soal bank tidak punya IdUjian maupun IdLatihan dan dipakai lewat ujian_soal/latihan_soal
+	IdMataPelajaran uint64 `gorm:"index:idx_soal_bank,priority:1" json:"id_mata_pelajaran"`
+	IdKelas         uint64 `gorm:"index:idx_soal_bank,priority:2" json:"id_kelas"`
 	CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
-	UpdatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP" json:"updated_at"`
+	UpdatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
 
-	Ujian    Ujian   `gorm:"foreignkey:IdUjian;constraint:onUpdate:CASCADE, onDelete:CASCADE" json:"ujian"`
-	TipeSoal TipeSoal `gorm:"foreignkey:IdTipeSoal;constraint:onUpdate:CASCADE, onDelete:CASCADE" json:"tipe_soal"`
-	Latihan  Latihan `gorm:"foreignkey:IdLatihan;constraint:onUpdate:CASCADE, onDelete:CASCADE" json:"latihan"`
+	Ujian    Ujian   `gorm:"foreignkey:IdUjian;references:IdUjian;constraint:onUpdate:CASCADE, onDelete:CASCADE" json:"ujian"`
+	TipeSoal TipeSoal `gorm:"foreignkey:IdTipeSoal;references:IdTipeSoal;constraint:onUpdate:CASCADE, onDelete:CASCADE" json:"tipe_soal"`
+	Latihan  Latihan `gorm:"foreignkey:IdLatihan;references:IdLatihan;constraint:onUpdate:CASCADE, onDelete:CASCADE" json:"latihan"`
 }
 
 func (Soal) TableName() string {
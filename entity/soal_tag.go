@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// SoalTag adalah tag topik soal bank, dipakai untuk mencari soal di bank
+type SoalTag struct {
+    IdSoalTag uint64    `gorm:"primary_key;autoIncrement" json:"id_soal_tag"`
+    IdSoal    uint64    `gorm:"not null;uniqueIndex:uq_soal_tag,priority:1" json:"id_soal"`
+    Tag       string    `gorm:"type:varchar(50);not null;uniqueIndex:uq_soal_tag,priority:2;index:idx_soal_tag_tag" json:"tag"`
+    CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+
+    Soal Soal `gorm:"foreignkey:IdSoal;constraint:onUpdate:CASCADE, onDelete:CASCADE" json:"soal"`
+}
+
+func (SoalTag) TableName() string {
+    return "soal_tag" // Nama tabel di database
+}
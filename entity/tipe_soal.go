@@ -2,11 +2,19 @@ package entity
 
 import "time"
 
+// Nilai kolom NamaTipeUjian
+const (
+    TipeSoalPilihanBerganda = "Pilihan_Berganda"
+    TipeSoalBenarSalah      = "Benar_Salah"
+    TipeSoalIsian           = "Isian"
+    TipeSoalPilihanKompleks = "Pilihan_Kompleks" // beberapa opsi benar, siswa memilih lebih dari satu
+)
+
 type TipeSoal struct {
     IdTipeSoal   uint64    `gorm:"primary_key;autoIncrement" json:"id_tipe_soal"`
-    NamaTipeUjian string   `gorm:"type:enum('Pilihan_Berganda', 'Benar_Salah', 'Isian');not null" json:"nama_tipe_ujian"`
+    NamaTipeUjian string   `gorm:"type:varchar(20);not null;check:chk_tipe_soal_nama_tipe_ujian,nama_tipe_ujian IN ('Pilihan_Berganda', 'Benar_Salah', 'Isian', 'Pilihan_Kompleks')" json:"nama_tipe_ujian"`
     CreatedAt        time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
-    UpdatedAt        time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP" json:"updated_at"`
+    UpdatedAt        time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
 }
 
 func (TipeSoal) TableName() string {
@@ -4,9 +4,9 @@ import "time"
 
 type TipeUjian struct {
     IdTipeUjian   uint64    `gorm:"primary_key;autoIncrement" json:"id_tipe_ujian"`
-    NamaTipeUjian string    `gorm:"type:enum('Kuis', 'Ujian');not null" json:"nama_tipe_ujian"`
+    NamaTipeUjian string    `gorm:"type:varchar(20);not null;check:chk_tipe_ujian_nama_tipe_ujian,nama_tipe_ujian IN ('Kuis', 'Ujian')" json:"nama_tipe_ujian"`
     CreatedAt        time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
-    UpdatedAt        time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP" json:"updated_at"`
+    UpdatedAt        time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
 }
 
 func (TipeUjian) TableName() string {
@@ -2,26 +2,41 @@ package entity
 
 import "time"
 
+// Nilai kolom Acak dan StatusJawaban
+const (
+    StatusAktif      = "Aktif"
+    StatusTidakAktif = "Tidak Aktif"
+)
+
 type Ujian struct {
     IdUjian        uint64    `gorm:"primary_key;autoIncrement" json:"id_ujian"`
     NamaUjian      string    `gorm:"type:varchar(255);not null" json:"nama_ujian"`
-    Acak           string    `gorm:"type:enum('Aktif', 'Tidak Aktif');not null" json:"acak"`
-    StatusJawaban  string    `gorm:"type:enum('Aktif', 'Tidak Aktif');not null" json:"status_jawaban"`
+    Acak           string    `gorm:"type:varchar(20);not null;check:chk_ujian_acak,acak IN ('Aktif', 'Tidak Aktif')" json:"acak"`
+    StatusJawaban  string    `gorm:"type:varchar(20);not null;check:chk_ujian_status_jawaban,status_jawaban IN ('Aktif', 'Tidak Aktif')" json:"status_jawaban"`
     Grade          float64   `gorm:"type:double" json:"grade"`
+    // Aturan penilaian. SkorBenar kosong berarti memakai nilai_per_soal tiap soal; SkorSalah dan
+    // SkorKosong boleh negatif, mis. -1 untuk jawaban salah pada format tryout. Total nilai tidak
+    // pernah di bawah nol kecuali IzinkanNilaiNegatif, dan diskalakan ke Grade jika SkalakanKeGrade.
+    SkorBenar           *float64 `gorm:"type:decimal(6,2);null" json:"skor_benar"`
+    SkorSalah           float64  `gorm:"type:decimal(6,2);not null;default:0" json:"skor_salah"`
+    SkorKosong          float64  `gorm:"type:decimal(6,2);not null;default:0" json:"skor_kosong"`
+    IzinkanNilaiNegatif bool     `gorm:"not null;default:false" json:"izinkan_nilai_negatif"`
+    SkalakanKeGrade     bool     `gorm:"not null;default:false" json:"skalakan_ke_grade"`
 
-    PasswordMasuk  string    `gorm:"type:varchar(255);not null" json:"password_masuk"`
-    PasswordKeluar string    `gorm:"type:varchar(255);not null" json:"password_keluar"`
+    // Hash bcrypt; tidak pernah dikirim ke klien
+    PasswordMasuk  string    `gorm:"type:varchar(255);not null" json:"-"`
+    PasswordKeluar string    `gorm:"type:varchar(255);not null" json:"-"`
     WaktuMulai     time.Time `gorm:"type:timestamp" json:"waktu_mulai"`
     WaktuSelesai   time.Time `gorm:"type:timestamp" json:"waktu_selesai"`
     Durasi         int       `gorm:"type:int(11)" json:"durasi"`
     TanggalUjian   time.Time `gorm:"type:timestamp;default:current_timestamp" json:"tanggal_ujian"`
 
-    IdKursus       uint64    `gorm:"type:bigint(20) unsigned;not null" json:"id_kursus"`
-    // IdGuru         uint64    `gorm:"type:bigint(20) unsigned;not null" json:"id_guru"`
-    IdTipeUjian    uint64    `gorm:"type:bigint(20) unsigned;not null" json:"id_tipe_ujian"`
+    IdKursus       uint64    `gorm:"not null;index:idx_ujian_id_kursus" json:"id_kursus"`
+    // IdGuru         uint64    `gorm:"not null" json:"id_guru"`
+    IdTipeUjian    uint64    `gorm:"not null" json:"id_tipe_ujian"`
 
     CreatedAt      time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
-    UpdatedAt      time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP" json:"updated_at"`
+    UpdatedAt      time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
 
     // Relations
     Kursus         Kursus     `gorm:"foreignKey:IdKursus;constraint:onUpdate:CASCADE,onDelete:CASCADE" json:"kursus"`
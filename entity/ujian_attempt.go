@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"time"
+)
+
+// Status attempt ujian
+const (
+	StatusAttemptBerlangsung = "Berlangsung"
+	StatusAttemptSelesai     = "Selesai"
+)
+
+type UjianAttempt struct {
+	IdUjianAttempt uint64     `gorm:"primary_key;autoIncrement" json:"id_ujian_attempt"`
+	IdUjian        uint64     `gorm:"not null;index;uniqueIndex:uq_ujian_attempt_ujian_siswa,priority:1" json:"id_ujian"` // satu attempt per siswa per ujian
+	IdSiswa        uint64     `gorm:"not null;index;uniqueIndex:uq_ujian_attempt_ujian_siswa,priority:2" json:"id_siswa"`
+	WaktuMulai     time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"waktu_mulai"`
+	BatasWaktu     *time.Time `gorm:"type:timestamp;null" json:"batas_waktu"` // nil jika ujian tidak punya durasi maupun waktu selesai
+	Status         string     `gorm:"type:varchar(20);not null;default:'Berlangsung';check:chk_ujian_attempt_status,status IN ('Berlangsung', 'Selesai')" json:"status"`
+	WaktuSubmit    *time.Time `gorm:"type:timestamp;null" json:"waktu_submit"`
+	UrutanSoal     string     `gorm:"type:text" json:"-"` // id_soal dipisah koma, urutan soal yang ditampilkan ke siswa
+	UrutanOpsi     string     `gorm:"type:text" json:"-"` // "id_soal:id_jawaban_soal,...;...", urutan opsi tiap soal
+	CreatedAt      time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
+
+	Ujian Ujian `gorm:"foreignKey:IdUjian;constraint:onUpdate:CASCADE,onDelete:CASCADE" json:"-"`
+	Siswa Siswa `gorm:"foreignKey:IdSiswa;constraint:onUpdate:CASCADE,onDelete:CASCADE" json:"-"`
+}
+
+func (UjianAttempt) TableName() string {
+	return "ujian_attempt" // Nama tabel di database
+}
@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// UjianSoal menghubungkan soal bank dengan ujian. NilaiPerSoal mengganti nilai_per_soal soal untuk ujian ini;
+// NULL berarti memakai nilai bawaan soal.
+type UjianSoal struct {
+    IdUjianSoal  uint64    `gorm:"primary_key;autoIncrement" json:"id_ujian_soal"`
+    IdUjian      uint64    `gorm:"not null;uniqueIndex:uq_ujian_soal,priority:1" json:"id_ujian"`
+    IdSoal       uint64    `gorm:"not null;uniqueIndex:uq_ujian_soal,priority:2;index:idx_ujian_soal_id_soal" json:"id_soal"`
+    NilaiPerSoal *float64  `gorm:"type:decimal(5,2);null" json:"nilai_per_soal"`
+    CreatedAt    time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+    UpdatedAt    time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
+
+    Ujian Ujian `gorm:"foreignkey:IdUjian;constraint:onUpdate:CASCADE, onDelete:CASCADE" json:"ujian"`
+    Soal  Soal  `gorm:"foreignkey:IdSoal;constraint:onUpdate:CASCADE, onDelete:CASCADE" json:"soal"`
+}
+
+func (UjianSoal) TableName() string {
+    return "ujian_soal" // Nama tabel di database
+}
@@ -10,5 +10,14 @@ type Users struct {
     Password         string    `gorm:"type:varchar(255);not null" json:"-"` 
     RememberToken    string    `gorm:"type:varchar(100)" json:"remember_token"`
     CreatedAt        time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
-    UpdatedAt        time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP" json:"updated_at"`
+    UpdatedAt        time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
 }
+
+// Role user yang dimuat di token. Role ditentukan dari tabel siswa, guru, operator dan admin
+// yang menunjuk ke users; satu user boleh punya lebih dari satu role.
+const (
+    RoleSiswa    = "siswa"
+    RoleGuru     = "guru"
+    RoleOperator = "operator"
+    RoleAdmin    = "admin"
+)
@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"time"
+)
+
+// UserSession menyimpan satu sesi login: refresh token (hanya hash-nya) dan id token (jti) yang
+// dipakai access token. Sesi yang dicabut membuat semua access token dengan jti tersebut ditolak.
+type UserSession struct {
+	IdUserSession    uint64     `gorm:"primary_key;autoIncrement" json:"id_user_session"`
+	TokenID          string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"token_id"`
+	RefreshTokenHash string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	IdUser           uint64     `gorm:"not null;index" json:"id_user"`
+	Email            string     `gorm:"type:varchar(255);not null" json:"email"`
+	Roles            string     `gorm:"type:varchar(100);not null" json:"roles"` // dipisah koma, mis. "guru,admin"
+	IdSiswa          uint64     `json:"id_siswa"`
+	IdGuru           uint64     `json:"id_guru"`
+	IdOperator       uint64     `json:"id_operator"`
+	UserAgent        string     `gorm:"type:varchar(255)" json:"user_agent"`
+	ExpiresAt        time.Time  `gorm:"type:timestamp;not null" json:"expires_at"`
+	RevokedAt        *time.Time `gorm:"type:timestamp;null" json:"revoked_at"`
+	CreatedAt        time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt        time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
+
+	User Users `gorm:"foreignKey:IdUser;constraint:onUpdate:CASCADE,onDelete:CASCADE" json:"-"`
+}
+
+func (UserSession) TableName() string {
+	return "user_session" // Nama tabel di database
+}
@@ -1,30 +1,101 @@
 package main
 
 import (
+	"log"
 	"os"
 	"github.com/gin-gonic/gin"
 	"cbt-api/config"
 	"cbt-api/controller"
+	"cbt-api/entity"
+	"cbt-api/middleware"
 	"cbt-api/service"
 	"cbt-api/repository"
 )
 
 func main() {
-	db := config.ConnectDatabase() 
+	// Konfigurasi dibaca dan divalidasi sebelum apa pun dijalankan
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Konfigurasi tidak valid: %v", err)
+	}
+
+	db, err := config.ConnectDatabase(cfg.Database)
+	if err != nil {
+		log.Fatalf("Gagal konek ke database: %v", err)
+	}
+
+	// `cbt-api migrate ...` hanya menjalankan migrasi lalu keluar
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(db, os.Args[2:]); err != nil {
+			log.Fatalf("Migrasi gagal: %v", err)
+		}
+		return
+	}
+
+	if cfg.Features.AutoMigrate {
+		if err := runMigrate(db, []string{"up"}); err != nil {
+			log.Fatalf("Migrasi gagal: %v", err)
+		}
+	}
 
 	// Inisialisasi Gin router
+	gin.SetMode(cfg.GinMode)
 	r := gin.Default()
 
 	// Initialize repositories
 	jawabanSiswaRepo := repository.NewJawabanSiswaRepository(db)
 	jawabanLatihanRepo := repository.NewJawabanLatihanRepository(db)
 	soalLatihanRepo := repository.NewSoalLatihanRepository(db)
+	ujianRepo := repository.NewUjianRepository(db)
+	soalRepo := repository.NewSoalRepository(db)
+	ujianAttemptRepo := repository.NewUjianAttemptRepository(db)
+	userSessionRepo := repository.NewUserSessionRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	jawabanSoalRepo := repository.NewJawabanSoalRepository(db)
+	kursusRepo := repository.NewKursusRepository(db)
+	siswaRepo := repository.NewSiswaRepository(db)
+	latihanRepo := repository.NewLatihanRepository(db)
+	referensiRepo := repository.NewReferensiRepository(db)
+	nilaiRepo := repository.NewNilaiRepository(db)
+	nilaiKursusRepo := repository.NewNilaiKursusRepository(db)
+	tipeNilaiRepo := repository.NewTipeNilaiRepository(db)
+	idempotencyKeyRepo := repository.NewIdempotencyKeyRepository(db)
+	bankSoalRepo := repository.NewBankSoalRepository(db)
+	transactor := repository.NewTransactor(db)
 
 	// Initialize services
-	jawabanSiswaService := service.NewJawabanSiswaService(jawabanSiswaRepo)
-	jawabanLatihanService := service.NewJawabanLatihanService(jawabanLatihanRepo)
-	soalLatihanService := service.NewSoalLatihanService(soalLatihanRepo)
-	jwtService := service.NewJWTService()
+	nilaiService := service.NewNilaiService(
+		transactor,
+		nilaiRepo,
+		nilaiKursusRepo,
+		tipeNilaiRepo,
+		jawabanSiswaRepo,
+		siswaRepo,
+		ujianRepo,
+		ujianAttemptRepo,
+		soalRepo,
+		jawabanSoalRepo,
+	)
+	ujianAttemptService := service.NewUjianAttemptService(ujianAttemptRepo, ujianRepo, soalRepo, jawabanSoalRepo, jawabanSiswaRepo, nilaiService)
+	jawabanSiswaService := service.NewJawabanSiswaService(transactor, jawabanSiswaRepo, ujianRepo, ujianAttemptRepo, ujianAttemptService)
+	jawabanLatihanService := service.NewJawabanLatihanService(jawabanLatihanRepo, latihanRepo, soalRepo, ujianRepo)
+	soalLatihanService := service.NewSoalLatihanService(soalLatihanRepo, latihanRepo, soalRepo, ujianRepo)
+	kursusService := service.NewKursusService(kursusRepo, siswaRepo)
+	ujianService := service.NewUjianService(ujianRepo, soalRepo, jawabanSoalRepo, ujianAttemptRepo, kursusRepo)
+	soalService := service.NewSoalService(transactor, soalRepo, jawabanSoalRepo, ujianRepo, latihanRepo, ujianAttemptRepo, kursusRepo, bankSoalRepo)
+	siswaService := service.NewSiswaService(siswaRepo)
+	latihanService := service.NewLatihanService(latihanRepo)
+	referensiService := service.NewReferensiService(referensiRepo)
+	idempotencyService := service.NewIdempotencyService(idempotencyKeyRepo)
+	jwtConfig := service.JWTConfig{
+		Secret:     cfg.JWT.Secret,
+		Issuer:     cfg.JWT.Issuer,
+		Audience:   cfg.JWT.Audience,
+		TTL:        cfg.JWT.TTL,
+		RefreshTTL: cfg.JWT.RefreshTTL,
+	}
+	jwtService := service.NewJWTService(jwtConfig)
+	authService := service.NewAuthService(userRepo, userSessionRepo, jwtService, jwtConfig)
 
 	// Initialize controllers
 	jawabanSiswaController := controller.NewJawabanSiswaController(jawabanSiswaService, jwtService)
@@ -33,62 +104,143 @@ func main() {
 		soalLatihanService,
 		jwtService,
 	)
+	ujianAttemptController := controller.NewUjianAttemptController(ujianAttemptService)
+	authController := controller.NewAuthController(authService)
+	kursusController := controller.NewKursusController(kursusService, ujianService, nilaiService)
+	ujianController := controller.NewUjianController(ujianService)
+	soalController := controller.NewSoalController(soalService)
+	siswaController := controller.NewSiswaController(siswaService)
+	latihanController := controller.NewLatihanController(latihanService)
+	referensiController := controller.NewReferensiController(referensiService)
+	nilaiController := controller.NewNilaiController(nilaiService)
+
+	// Rute publik
+	r.POST("/login", authController.Login)
+	r.POST("/refresh-token", authController.RefreshToken)
+
+	// Semua rute siswa wajib login; id_siswa diambil dari token dan parameter
+	// :id_siswa yang bukan milik siswa tersebut ditolak dengan 403
+	authorized := r.Group("/")
+	authorized.Use(middleware.AuthMiddleware(jwtService, authService))
+	authorized.POST("/logout", authController.Logout)
+	authorized.POST("/logout-all", authController.LogoutAll)
+
+	// Data referensi yang boleh dibaca semua role
+	authorized.GET("/ujian-materi-kursus/:id_kursus", kursusController.GetUjianAndMateriByKursus)
+	authorized.GET("/kelas", referensiController.GetAllKelas)
+	authorized.GET("/kurikulum", referensiController.GetAllKurikulum)
+	authorized.GET("/soal/:id_soal", soalController.GetSoalWithJawaban)
+	authorized.GET("/soal-ujian/:id_ujian", soalController.GetSoalByUjian)
+	authorized.GET("/soal-latihan/:id_latihan", soalController.GetSoalByLatihan)
+	authorized.GET("/mata-pelajaran/:id_kurikulum", referensiController.GetMataPelajaranByKurikulum)
+	authorized.GET("/kursus/:id_kursus", kursusController.GetKursusById)
+	authorized.GET("/api/latihan-soal/:id_kurikulum/:id_kelas/:id_mata_pelajaran", latihanController.GetLatihanSoal)
+	authorized.GET("/api/ujian/:idUjian", ujianController.GetUjianById)
+	authorized.GET("/jawaban-soal/:id_jawaban_soal", soalController.GetJawabanSoalByID)
+
+	// Rute siswa: hanya token siswa, dan :id_siswa harus milik siswa yang login
+	siswa := authorized.Group("/")
+	siswa.Use(middleware.RequireRoles(entity.RoleSiswa), middleware.SiswaParamGuard())
 
 	// Setup routes
-	setupRoutes(r, jawabanSiswaController, jawabanLatihanController)
+	setupRoutes(
+		siswa,
+		jawabanSiswaController,
+		jawabanLatihanController,
+		ujianAttemptController,
+		middleware.Idempotency(idempotencyService),
+	)
 
 	// Rute lainnya
-	r.POST("/login", controller.Login)
-	r.GET("/kursus-siswa/:id_siswa", controller.GetKursusBySiswa)
-	r.GET("/ujian-materi-kursus/:id_kursus", controller.GetUjianAndMateriByKursus)
-	r.GET("/profil/:id_siswa", controller.GetSiswaWithKelas)
-	r.GET("/kelas", controller.GetAllKelas)
-	r.GET("/kurikulum", controller.GetAllKurikulum)
-	r.POST("/login-ujian/:id_ujian", controller.AccessUjian)
-	r.GET("/soal/:id_soal", controller.GetSoalWithJawaban)
-	r.GET("/soal-ujian/:id_ujian", controller.GetSoalByUjian)
-	r.GET("/soal-latihan/:id_latihan", controller.GetSoalByLatihan)
-	r.GET("/mata-pelajaran/:id_kurikulum", controller.GetMataPelajaranByKurikulum)
-	r.GET("/kursus/available/:id_siswa", controller.GetAvailableKursusForSiswa)
-	r.GET("/kursus/:id_kursus", controller.GetKursusById)
-	r.POST("/kursus/access/:id_kursus", controller.EnrollKursus)
-	r.POST("/kursus_siswa/enroll", controller.EnrollKursusSiswa)
-	r.GET("/api/latihan-soal/:id_kurikulum/:id_kelas/:id_mata_pelajaran", controller.GetLatihanSoal)
-	r.GET("/jawaban-siswa/:id_ujian/:id_siswa", controller.GetJawabanSiswa)
-	r.GET("/api/total-nilai-by-ujian/:id_ujian/:id_siswa", controller.GetTotalNilaiSiswaByUjian)
-	r.POST("/api/tipe-nilai", controller.CreateTipeNilai)
-	r.GET("/api/ujian/:idUjian", controller.GetUjianById)
-	r.GET("/nilai-siswa/:id_ujian/:id_siswa", controller.CalculateScore)
-	r.GET("api/calculate-and-save-score/:id_ujian/:id_siswa/:id_tipe_ujian", controller.CalculateScore)
-	r.GET("/jawaban-soal/:id_jawaban_soal", controller.GetJawabanSoalByID)
-	r.POST("/keluar-ujian/:id_ujian", controller.ExitUjian)
-	r.GET("/kursus/detail/:id_siswa/:id_kursus", controller.GetKursusWithUjianAndNilai)
-	r.POST("/check-attempt-ujian", controller.CheckQuizAttempt)
-	r.GET("/check-attempt-ujian/:id_ujian/:id_siswa", controller.CheckQuizAttempt)
-	r.GET("/sum_nilai_ujian_kursus/:id_kursus/:id_siswa", controller.GetTotalNilaiByTipeUjian)
-	r.POST("/nilai_kursus/:id_kursus/:id_siswa", controller.PostNilaiKursus)
-	r.POST("/nilai/:id_kursus/:id_siswa", controller.PostNilai)
-	r.GET("/nilai-kursus-siswa/:id_kursus/:id_siswa", controller.GetNilaiByKursusAndSiswa)
+	siswa.GET("/kursus-siswa/:id_siswa", kursusController.GetKursusBySiswa)
+	siswa.GET("/profil/:id_siswa", siswaController.GetSiswaWithKelas)
+	siswa.GET("/kursus/available/:id_siswa", kursusController.GetAvailableKursusForSiswa)
+	siswa.POST("/kursus/access/:id_kursus", kursusController.EnrollKursus)
+	siswa.POST("/kursus_siswa/enroll", kursusController.EnrollKursusSiswa)
+	siswa.GET("/api/total-nilai-by-ujian/:id_ujian/:id_siswa", nilaiController.GetTotalNilaiSiswaByUjian)
+	siswa.GET("/nilai-siswa/:id_ujian/:id_siswa", nilaiController.CalculateScore)
+	siswa.GET("/kursus/detail/:id_siswa/:id_kursus", kursusController.GetKursusWithUjianAndNilai)
+	siswa.POST("/check-attempt-ujian", nilaiController.CheckQuizAttempt)
+	siswa.GET("/check-attempt-ujian/:id_ujian/:id_siswa", nilaiController.CheckQuizAttempt)
+	siswa.GET("/sum_nilai_ujian_kursus/:id_kursus/:id_siswa", nilaiController.GetTotalNilaiByTipeUjian)
+	siswa.GET("/nilai-kursus-siswa/:id_kursus/:id_siswa", nilaiController.GetNilaiByKursusAndSiswa)
+
+	// Rute guru dan operator: memantau jalannya ujian
+	pengawas := authorized.Group("/")
+	pengawas.Use(middleware.RequireRoles(entity.RoleGuru, entity.RoleOperator))
+	pengawas.GET("/api/ujian-attempt/ujian/:id_ujian", ujianAttemptController.GetAttemptsByUjianID)
+
+	// Rute guru: penilaian
+	guru := authorized.Group("/")
+	guru.Use(middleware.RequireRoles(entity.RoleGuru))
+	guru.POST("/api/tipe-nilai", nilaiController.CreateTipeNilai)
+	guru.POST("/nilai_kursus/:id_kursus/:id_siswa", nilaiController.PostNilaiKursus)
+	guru.PUT("/nilai_kursus/:id_kursus/:id_siswa", nilaiController.PutNilaiKursus)
+	guru.POST("/nilai_kursus/recalculate/:id_kursus/:id_siswa", nilaiController.RecalculateNilaiKursus)
+	guru.POST("/nilai/:id_kursus/:id_siswa", nilaiController.PostNilai)
+	guru.PUT("/nilai/:id_kursus/:id_siswa", nilaiController.PutNilai)
+	guru.POST("/nilai/recalculate/:id_kursus", nilaiController.RecalculateNilai)
+	guru.GET("/api/penilaian-isian/ujian/:id_ujian", nilaiController.GetAntrianIsian)
+	guru.PUT("/api/penilaian-isian/:id_jawaban_siswa", nilaiController.NilaiJawabanIsian)
+	guru.GET("/api/analisis-butir/ujian/:id_ujian", nilaiController.GetAnalisisButir)
+
+	// Rute guru: kelola ujian, soal dan opsi jawaban
+	guru.POST("/api/ujian", ujianController.CreateUjian)
+	guru.PUT("/api/ujian/:idUjian", ujianController.UpdateUjian)
+	guru.DELETE("/api/ujian/:idUjian", ujianController.DeleteUjian)
+	guru.GET("/api/ujian/:idUjian/validasi", ujianController.ValidasiUjian)
+	guru.POST("/api/ujian/:idUjian/soal", soalController.CreateSoal)
+	guru.PUT("/api/soal/:id_soal", soalController.UpdateSoal)
+	guru.DELETE("/api/soal/:id_soal", soalController.DeleteSoal)
+	guru.POST("/api/soal/:id_soal/jawaban-soal", soalController.CreateJawabanSoal)
+	guru.PUT("/api/jawaban-soal/:id_jawaban_soal", soalController.UpdateJawabanSoal)
+	guru.DELETE("/api/jawaban-soal/:id_jawaban_soal", soalController.DeleteJawabanSoal)
+	guru.POST("/api/soal/import", soalController.ImportSoal)
+	guru.GET("/api/soal/export", soalController.ExportSoal)
+	guru.GET("/api/ujian/:idUjian/qti", soalController.ExportQTI)
+	guru.POST("/api/ujian/import-qti", soalController.ImportQTI)
+	guru.GET("/api/bank-soal", soalController.GetBankSoal)
+	guru.POST("/api/bank-soal", soalController.CreateBankSoal)
+	guru.PUT("/api/bank-soal/:id_soal", soalController.UpdateBankSoal)
+	guru.PUT("/api/ujian/:idUjian/bank-soal/:id_soal", soalController.TautkanSoalUjian)
+	guru.DELETE("/api/ujian/:idUjian/bank-soal/:id_soal", soalController.LepasSoalUjian)
+	guru.PUT("/api/latihan/:id_latihan/bank-soal/:id_soal", soalController.TautkanSoalLatihan)
+	guru.DELETE("/api/latihan/:id_latihan/bank-soal/:id_soal", soalController.LepasSoalLatihan)
 
 	// Jalankan server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	if err := r.Run("0.0.0.0:" + cfg.Port); err != nil {
+		log.Fatalf("Server berhenti: %v", err)
 	}
-	r.Run("0.0.0.0:" + port)
 }
 
 // Daftarkan semua endpoint jawaban siswa dan jawaban latihan ke router utama
 func setupRoutes(
-	router *gin.Engine, 
+	router *gin.RouterGroup,
 	jawabanSiswaController controller.JawabanSiswaController,
 	jawabanLatihanController controller.JawabanLatihanController,
+	ujianAttemptController controller.UjianAttemptController,
+	idempotency gin.HandlerFunc,
 ) {
+	// Sesi ujian: masuk, keluar dan status attempt siswa
+	router.POST("/login-ujian/:id_ujian", ujianAttemptController.AccessUjian)
+	router.POST("/keluar-ujian/:id_ujian", ujianAttemptController.ExitUjian)
+	ujianAttemptRoutes := router.Group("api/ujian-attempt")
+	{
+		ujianAttemptRoutes.GET("/:id_ujian/:id_siswa", ujianAttemptController.GetAttemptSiswa)
+		// Autosave per soal dan lanjutkan ujian setelah aplikasi tertutup; submit akhir tetap lewat keluar-ujian
+		ujianAttemptRoutes.PUT("/:id_ujian/jawaban/:id_soal", ujianAttemptController.SaveDraftJawaban)
+		ujianAttemptRoutes.GET("/:id_ujian/resume", ujianAttemptController.ResumeUjian)
+		// Pembahasan per attempt setelah ujian berakhir, menggantikan /jawaban-siswa/:id_ujian/:id_siswa
+		ujianAttemptRoutes.GET("/review/:id_ujian_attempt", ujianAttemptController.ReviewAttempt)
+	}
+
+
 	// Jawaban Siswa routes (for exams)
 	jawabanSiswaRoutes := router.Group("api/jawaban-siswa")
 	{
 		jawabanSiswaRoutes.POST("/", jawabanSiswaController.CreateJawabanSiswa)
-		jawabanSiswaRoutes.POST("/batch", jawabanSiswaController.CreateBatchJawabanSiswa)
+		// Batch boleh dikirim ulang dengan Idempotency-Key yang sama saat koneksi putus
+		jawabanSiswaRoutes.POST("/batch", idempotency, jawabanSiswaController.CreateBatchJawabanSiswa)
 		jawabanSiswaRoutes.GET("/:id", jawabanSiswaController.GetJawabanSiswaByID)
 		jawabanSiswaRoutes.GET("/siswa/:id_siswa", jawabanSiswaController.GetJawabanSiswaBySiswaID)
 		jawabanSiswaRoutes.GET("/ujian/:id_ujian", jawabanSiswaController.GetJawabanSiswaByUjianID)
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+    "bytes"
+    "cbt-api/entity"
+    "crypto/sha256"
+    "encoding/hex"
+    "io"
+    "log"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+)
+
+// HeaderIdempotencyKey dikirim client agar retry request yang sama tidak diproses dua kali
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// IdempotencyStore menyimpan response request yang memakai Idempotency-Key
+type IdempotencyStore interface {
+    FindResponse(userID uint64, key string) (entity.IdempotencyKey, bool, error)
+    SaveResponse(record entity.IdempotencyKey) error
+}
+
+// responseRecorder menyalin body response agar bisa disimpan setelah handler selesai
+type responseRecorder struct {
+    gin.ResponseWriter
+    body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+    w.body.Write(data)
+    return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+    w.body.WriteString(s)
+    return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency memutar ulang response yang tersimpan bila request dengan Idempotency-Key yang sama
+// dikirim lagi oleh user yang sama. Key yang dipakai ulang dengan body berbeda ditolak. Hanya
+// response 2xx yang disimpan, sehingga request yang gagal tetap boleh dicoba lagi.
+// Request tanpa header diproses seperti biasa. Harus dipasang setelah AuthMiddleware.
+func Idempotency(store IdempotencyStore) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        key := c.GetHeader(HeaderIdempotencyKey)
+        if key == "" {
+            c.Next()
+            return
+        }
+        if len(key) > 100 {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key maksimal 100 karakter"})
+            c.Abort()
+            return
+        }
+
+        claims, ok := GetClaims(c)
+        if !ok {
+            c.JSON(http.StatusUnauthorized, gin.H{"error": "Token is missing"})
+            c.Abort()
+            return
+        }
+
+        body, err := io.ReadAll(c.Request.Body)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data"})
+            c.Abort()
+            return
+        }
+        c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+        sum := sha256.Sum256(append([]byte(c.Request.Method+" "+c.FullPath()+"\n"), body...))
+        requestHash := hex.EncodeToString(sum[:])
+
+        stored, found, err := store.FindResponse(claims.UserID, key)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa Idempotency-Key"})
+            c.Abort()
+            return
+        }
+        if found {
+            if stored.RequestHash != requestHash {
+                c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key sudah dipakai untuk request yang berbeda"})
+                c.Abort()
+                return
+            }
+            c.Header("Idempotent-Replayed", "true")
+            c.Data(stored.StatusCode, "application/json; charset=utf-8", []byte(stored.ResponseBody))
+            c.Abort()
+            return
+        }
+
+        recorder := &responseRecorder{ResponseWriter: c.Writer}
+        c.Writer = recorder
+        c.Next()
+
+        status := recorder.Status()
+        if status < 200 || status >= 300 {
+            return
+        }
+        // Gagal menyimpan hanya berarti retry berikutnya diproses ulang; upsert jawaban tetap aman
+        err = store.SaveResponse(entity.IdempotencyKey{
+            IdUser:       claims.UserID,
+            Key:          key,
+            Endpoint:     c.Request.Method + " " + c.FullPath(),
+            RequestHash:  requestHash,
+            StatusCode:   status,
+            ResponseBody: recorder.body.String(),
+        })
+        if err != nil {
+            log.Printf("Gagal menyimpan Idempotency-Key %q: %v", key, err)
+        }
+    }
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"cbt-api/entity"
+	"cbt-api/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// storePalsu keeps the saved responses in a map per user and key
+type storePalsu struct {
+	records map[string]entity.IdempotencyKey
+}
+
+func (s *storePalsu) FindResponse(userID uint64, key string) (entity.IdempotencyKey, bool, error) {
+	record, ok := s.records[key]
+	return record, ok && record.IdUser == userID, nil
+}
+
+func (s *storePalsu) SaveResponse(record entity.IdempotencyKey) error {
+	s.records[record.Key] = record
+	return nil
+}
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &storePalsu{records: make(map[string]entity.IdempotencyKey)}
+	dipanggil := 0
+	r := gin.New()
+	r.POST("/jawaban", AuthMiddleware(jwtTest, sesiPalsu{}), Idempotency(store), func(c *gin.Context) {
+		dipanggil++
+		if c.Query("gagal") != "" {
+			c.JSON(http.StatusConflict, gin.H{"error": "gagal"})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"ke": dipanggil})
+	})
+	token, err := jwtTest.GenerateToken(service.TokenSubject{UserID: 3, IdSiswa: 7, TokenID: "sesi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kirim := func(path, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		if key != "" {
+			req.Header.Set(HeaderIdempotencyKey, key)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	first := kirim("/jawaban", "k1", `{"id_soal":1}`)
+	replay := kirim("/jawaban", "k1", `{"id_soal":1}`)
+	if first.Code != http.StatusCreated || replay.Code != http.StatusCreated || replay.Body.String() != first.Body.String() ||
+		replay.Header().Get("Idempotent-Replayed") != "true" || dipanggil != 1 {
+		t.Errorf("replay = %d %q (handler ran %d times), want the first response %q",
+			replay.Code, replay.Body.String(), dipanggil, first.Body.String())
+	}
+	if w := kirim("/jawaban", "k1", `{"id_soal":2}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("key reused with another body = %d, want 422", w.Code)
+	}
+	if w := kirim("/jawaban", "", `{"id_soal":1}`); w.Code != http.StatusCreated || dipanggil != 2 {
+		t.Errorf("request without a key = %d, handler ran %d times; want it processed", w.Code, dipanggil)
+	}
+
+	// Response gagal tidak disimpan, jadi retry diproses lagi
+	kirim("/jawaban?gagal=1", "k2", `{}`)
+	kirim("/jawaban?gagal=1", "k2", `{}`)
+	if dipanggil != 4 {
+		t.Errorf("failed request replayed: handler ran %d times, want 4", dipanggil)
+	}
+	if w := kirim("/jawaban", strings.Repeat("k", 101), `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("key over 100 characters = %d, want 400", w.Code)
+	}
+}
@@ -2,13 +2,30 @@ package middleware
 
 import (
     "github.com/gin-gonic/gin"
-    "cbt-api/auth"
+    "cbt-api/entity"
+    "cbt-api/service"
     "net/http"
+    "strconv"
+    "strings"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// Key yang disimpan di gin.Context oleh AuthMiddleware
+const (
+    ContextUserClaims = "user"
+    ContextIdUser     = "id_user"
+    ContextIdSiswa    = "id_siswa"
+    ContextRoles      = "roles"
+    ContextTokenID    = "token_id"
+)
+
+// SessionChecker dipakai untuk menolak access token milik sesi yang sudah logout/dicabut
+type SessionChecker interface {
+    IsSessionActive(tokenID string) (bool, error)
+}
+
+func AuthMiddleware(jwtService service.JWTService, sessions SessionChecker) gin.HandlerFunc {
     return func(c *gin.Context) {
-        tokenString := c.GetHeader("Authorization")
+        tokenString := strings.TrimSpace(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "))
         if tokenString == "" {
             c.JSON(http.StatusUnauthorized, gin.H{"error": "Token is missing"})
             c.Abort()
@@ -16,15 +33,107 @@ func AuthMiddleware() gin.HandlerFunc {
         }
 
         // Verifikasi token
-        token, err := auth.VerifyJWT(tokenString)
+        claims, err := jwtService.ValidateToken(tokenString)
         if err != nil {
             c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
             c.Abort()
             return
         }
 
-        // Jika token valid, lanjutkan ke handler berikutnya
-        c.Set("user", token.Claims) // Menyimpan claims token jika diperlukan
+        // Token dari sesi yang sudah dicabut tidak boleh dipakai lagi walaupun belum kedaluwarsa
+        active, err := sessions.IsSessionActive(claims.ID)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa sesi login"})
+            c.Abort()
+            return
+        }
+        if !active {
+            c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+            c.Abort()
+            return
+        }
+
+        // Jika token valid, simpan claims dan identitas user/siswa untuk handler berikutnya
+        c.Set(ContextUserClaims, claims)
+        c.Set(ContextIdUser, claims.UserID)
+        c.Set(ContextRoles, claims.Roles)
+        c.Set(ContextTokenID, claims.ID)
+        if claims.IdSiswa != 0 {
+            c.Set(ContextIdSiswa, claims.IdSiswa)
+        }
         c.Next()
     }
 }
+
+// RequireRoles hanya meneruskan request dari token yang memiliki salah satu role yang diminta.
+// Admin selalu diizinkan. Harus dipasang setelah AuthMiddleware.
+func RequireRoles(roles ...string) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        claims, ok := GetClaims(c)
+        if !ok {
+            c.JSON(http.StatusUnauthorized, gin.H{"error": "Token is missing"})
+            c.Abort()
+            return
+        }
+
+        if claims.HasRole(entity.RoleAdmin) {
+            c.Next()
+            return
+        }
+        for _, role := range roles {
+            if claims.HasRole(role) {
+                c.Next()
+                return
+            }
+        }
+
+        c.JSON(http.StatusForbidden, gin.H{"error": "Role tidak diizinkan mengakses resource ini"})
+        c.Abort()
+    }
+}
+
+// HasRole memeriksa apakah token pada request memiliki role tertentu
+func HasRole(c *gin.Context, role string) bool {
+    claims, ok := GetClaims(c)
+    return ok && claims.HasRole(role)
+}
+
+// SiswaParamGuard menolak request yang parameter :id_siswa-nya bukan milik siswa di token.
+// Route tanpa parameter id_siswa dilewatkan begitu saja.
+func SiswaParamGuard() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        param := c.Param("id_siswa")
+        if param == "" {
+            c.Next()
+            return
+        }
+
+        idSiswa, ok := GetIdSiswa(c)
+        if !ok || param != strconv.FormatUint(idSiswa, 10) {
+            c.JSON(http.StatusForbidden, gin.H{"error": "Tidak boleh mengakses data siswa lain"})
+            c.Abort()
+            return
+        }
+        c.Next()
+    }
+}
+
+// GetIdSiswa mengembalikan id_siswa dari token yang sudah diverifikasi
+func GetIdSiswa(c *gin.Context) (uint64, bool) {
+    value, exists := c.Get(ContextIdSiswa)
+    if !exists {
+        return 0, false
+    }
+    idSiswa, ok := value.(uint64)
+    return idSiswa, ok && idSiswa != 0
+}
+
+// GetClaims mengembalikan seluruh claims token yang sudah diverifikasi
+func GetClaims(c *gin.Context) (*service.JWTClaims, bool) {
+    value, exists := c.Get(ContextUserClaims)
+    if !exists {
+        return nil, false
+    }
+    claims, ok := value.(*service.JWTClaims)
+    return claims, ok
+}
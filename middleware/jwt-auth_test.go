@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"cbt-api/entity"
+	"cbt-api/service"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var jwtTest = service.NewJWTService(service.JWTConfig{Secret: "rahasia", Issuer: "cbt-api", TTL: time.Hour})
+
+// sesiPalsu treats every token id except the revoked one as an active session
+type sesiPalsu struct{ dicabut string }
+
+func (s sesiPalsu) IsSessionActive(tokenID string) (bool, error) {
+	return tokenID != s.dicabut, nil
+}
+
+func routerSiswa() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/siswa/:id_siswa", AuthMiddleware(jwtTest, sesiPalsu{dicabut: "sesi-lama"}), SiswaParamGuard(), func(c *gin.Context) {
+		idSiswa, ok := GetIdSiswa(c)
+		if !ok {
+			c.Status(http.StatusForbidden)
+			return
+		}
+		c.String(http.StatusOK, strconv.FormatUint(idSiswa, 10))
+	})
+	return r
+}
+
+func TestAuthMiddlewareSiswa(t *testing.T) {
+	token, err := jwtTest.GenerateToken(service.TokenSubject{UserID: 3, Email: "siswa@example.com", IdSiswa: 7, TokenID: "sesi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	revoked, err := jwtTest.GenerateToken(service.TokenSubject{UserID: 3, Email: "siswa@example.com", IdSiswa: 7, TokenID: "sesi-lama"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		path   string
+		header string
+		want   int
+		body   string
+	}{
+		{"own data", "/siswa/7", "Bearer " + token, http.StatusOK, "7"},
+		{"another siswa", "/siswa/8", "Bearer " + token, http.StatusForbidden, ""},
+		{"no token", "/siswa/7", "", http.StatusUnauthorized, ""},
+		{"bad token", "/siswa/7", "Bearer bukan.token.jwt", http.StatusUnauthorized, ""},
+		{"revoked session", "/siswa/7", "Bearer " + revoked, http.StatusUnauthorized, ""},
+	}
+	r := routerSiswa()
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.want || (tt.body != "" && w.Body.String() != tt.body) {
+			t.Errorf("%s = %d %q, want %d %q", tt.name, w.Code, w.Body.String(), tt.want, tt.body)
+		}
+	}
+}
+
+func TestGetIdSiswaWithoutSiswa(t *testing.T) {
+	// Token user yang bukan siswa tidak membawa id_siswa
+	token, err := jwtTest.GenerateToken(service.TokenSubject{UserID: 3, Email: "guru@example.com", TokenID: "sesi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/siswa/0", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	routerSiswa().ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("token without id_siswa = %d, want 403", w.Code)
+	}
+}
+
+func TestRequireRoles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/guru", AuthMiddleware(jwtTest, sesiPalsu{}), RequireRoles(entity.RoleGuru, entity.RoleOperator), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name  string
+		roles []string
+		want  int
+	}{
+		{"guru", []string{entity.RoleGuru}, http.StatusOK},
+		{"operator", []string{entity.RoleOperator}, http.StatusOK},
+		{"admin", []string{entity.RoleAdmin}, http.StatusOK},
+		{"siswa who is also guru", []string{entity.RoleSiswa, entity.RoleGuru}, http.StatusOK},
+		{"siswa", []string{entity.RoleSiswa}, http.StatusForbidden},
+		{"no role", nil, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		token, err := jwtTest.GenerateToken(service.TokenSubject{UserID: 3, Roles: tt.roles, TokenID: "sesi"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest(http.MethodGet, "/guru", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s = %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
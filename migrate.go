@@ -0,0 +1,68 @@
+package main
+
+import (
+	"cbt-api/migration"
+	"fmt"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// runMigrate menjalankan subcommand migrate:
+//
+//	migrate up        terapkan semua migrasi yang belum jalan (default)
+//	migrate down [n]  batalkan n migrasi terakhir (default 1)
+//	migrate status    tampilkan migrasi yang sudah dan belum diterapkan
+func runMigrate(db *gorm.DB, args []string) error {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		applied, err := migration.Up(db)
+		for _, m := range applied {
+			fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+		return nil
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("jumlah langkah harus bilangan positif, got %q", args[1])
+			}
+			steps = n
+		}
+		rolledBack, err := migration.Down(db, steps)
+		for _, m := range rolledBack {
+			fmt.Printf("rolled back %04d_%s\n", m.Version, m.Name)
+		}
+		return err
+
+	case "status":
+		statuses, err := migration.Status(db)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-40s %s\n", s.Version, s.Name, appliedAt)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("perintah migrate tidak dikenal %q (pakai up, down atau status)", command)
+	}
+}
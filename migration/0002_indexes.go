@@ -0,0 +1,88 @@
+// migration/0002_indexes.go
+package migration
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type tableIndex struct {
+	model interface{}
+	name  string // nama index pada tag gorm di salinan tabel baseline
+}
+
+// Index dan unique constraint yang dideklarasikan di tag salinan tabel baseline. Tabel yang dibuat
+// oleh baseline sudah memilikinya; tabel lama hanya mendapat index yang belum ada.
+func baselineIndexes() []tableIndex {
+	return []tableIndex{
+		{&siswa0001{}, "idx_siswa_id_user"},
+		{&siswa0001{}, "idx_siswa_id_kelas"},
+		{&guru0001{}, "idx_guru_id_user"},
+		{&operator0001{}, "idx_operator_id_user"},
+		{&admin0001{}, "IdUser"},
+		{&mataPelajaran0001{}, "idx_mata_pelajaran_id_kurikulum"},
+		{&kursusSiswa0001{}, "uq_kursus_siswa"},
+		{&materi0001{}, "idx_materi_id_kursus"},
+		{&ujian0001{}, "idx_ujian_id_kursus"},
+		{&latihan0001{}, "idx_latihan_filter"},
+		{&soal0001{}, "idx_soal_id_ujian"},
+		{&soal0001{}, "idx_soal_id_latihan"},
+		{&latihanSoal0001{}, "uq_latihan_soal"},
+		{&latihanSoal0001{}, "idx_latihan_soal_id_soal"},
+		{&jawabanSoal0001{}, "idx_jawaban_soal_id_soal"},
+		{&jawabanSiswa0001{}, "idx_jawaban_siswa_siswa_soal"},
+		{&tipeNilai0001{}, "idx_tipe_nilai_siswa_ujian"},
+		{&nilai0001{}, "uq_nilai_kursus_siswa"},
+		{&nilaiKursus0001{}, "uq_nilai_kursus"},
+		{&persentase0001{}, "uq_persentase"},
+		{&ujianAttempt0001{}, "IdUjian"},
+		{&ujianAttempt0001{}, "IdSiswa"},
+		{&userSession0001{}, "TokenID"},
+		{&userSession0001{}, "RefreshTokenHash"},
+		{&userSession0001{}, "IdUser"},
+	}
+}
+
+// Unique constraint gagal dibuat bila tabel lama sudah berisi duplikat; bersihkan datanya dulu lalu jalankan ulang.
+func init() {
+	register(Migration{
+		Version: 2,
+		Name:    "indexes_and_unique_constraints",
+		Up: func(tx *gorm.DB) error {
+			return createMissingIndexes(tx, baselineIndexes())
+		},
+		Down: func(tx *gorm.DB) error {
+			// Index milik baseline (ujian_attempt, user_session, admin) tetap dipertahankan
+			for _, idx := range baselineIndexes() {
+				if !isNamedIndex(idx.name) || !tx.Migrator().HasIndex(idx.model, idx.name) {
+					continue
+				}
+				if err := tx.Migrator().DropIndex(idx.model, idx.name); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
+
+// createMissingIndexes creates the indexes that do not exist yet. SQLite rebuilds a table to add a
+// constraint or drop a column and the rebuilt table loses its indexes, so migrations that do either on
+// SQLite put back the indexes the table had before them.
+func createMissingIndexes(tx *gorm.DB, indexes []tableIndex) error {
+	for _, idx := range indexes {
+		if tx.Migrator().HasIndex(idx.model, idx.name) {
+			continue
+		}
+		if err := tx.Migrator().CreateIndex(idx.model, idx.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isNamedIndex membedakan index yang diberi nama di tag (idx_/uq_) dari yang memakai nama field
+func isNamedIndex(name string) bool {
+	return strings.HasPrefix(name, "idx_") || strings.HasPrefix(name, "uq_")
+}
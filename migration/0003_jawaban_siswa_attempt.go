@@ -0,0 +1,101 @@
+// migration/0003_jawaban_siswa_attempt.go
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// jawabanSiswa0003 is the part of jawaban_siswa this migration changes
+type jawabanSiswa0003 struct {
+	IdSoal         uint64  `gorm:"index:idx_jawaban_siswa_siswa_soal,priority:2;uniqueIndex:uq_jawaban_siswa_attempt_soal,priority:2"`
+	IdUjianAttempt *uint64 `gorm:"uniqueIndex:uq_jawaban_siswa_attempt_soal,priority:1"`
+}
+
+func (jawabanSiswa0003) TableName() string { return "jawaban_siswa" }
+
+// idempotencyKey0003 is idempotency_key as this migration creates it
+type idempotencyKey0003 struct {
+	IdIdempotencyKey uint64    `gorm:"primary_key;autoIncrement"`
+	IdUser           uint64    `gorm:"not null;uniqueIndex:uq_idempotency_key_user_key,priority:1"`
+	Key              string    `gorm:"column:idempotency_key;type:varchar(100);not null;uniqueIndex:uq_idempotency_key_user_key,priority:2"`
+	Endpoint         string    `gorm:"type:varchar(255);not null"`
+	RequestHash      string    `gorm:"type:varchar(64);not null"`
+	StatusCode       int       `gorm:"not null"`
+	ResponseBody     string    `gorm:"type:mediumtext;not null"`
+	CreatedAt        time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+}
+
+func (idempotencyKey0003) TableName() string { return "idempotency_key" }
+
+// Jawaban ujian dikunci per (attempt, soal). Jawaban lama dihubungkan ke attempt terakhir siswa pada
+// ujian soal tersebut, lalu duplikatnya dibuang dengan menyimpan jawaban yang paling baru saja.
+func init() {
+	register(Migration{
+		Version: 3,
+		Name:    "jawaban_siswa_per_attempt",
+		Up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&jawabanSiswa0003{}, "IdUjianAttempt") {
+				if err := tx.Migrator().AddColumn(&jawabanSiswa0003{}, "IdUjianAttempt"); err != nil {
+					return err
+				}
+			}
+
+			err := tx.Exec(`
+				UPDATE jawaban_siswa SET id_ujian_attempt = (
+					SELECT MAX(a.id_ujian_attempt)
+					FROM ujian_attempt a
+					JOIN soal s ON s.id_ujian = a.id_ujian
+					WHERE s.id_soal = jawaban_siswa.id_soal AND a.id_siswa = jawaban_siswa.id_siswa
+				)
+				WHERE id_ujian_attempt IS NULL`).Error
+			if err != nil {
+				return err
+			}
+
+			// Subquery dibungkus tabel turunan karena MySQL menolak DELETE yang membaca tabelnya sendiri
+			err = tx.Exec(`
+				DELETE FROM jawaban_siswa
+				WHERE id_ujian_attempt IS NOT NULL
+				AND id_jawaban_siswa NOT IN (
+					SELECT id FROM (
+						SELECT MAX(id_jawaban_siswa) AS id
+						FROM jawaban_siswa
+						WHERE id_ujian_attempt IS NOT NULL
+						GROUP BY id_ujian_attempt, id_soal
+					) AS terbaru
+				)`).Error
+			if err != nil {
+				return err
+			}
+
+			if !tx.Migrator().HasIndex(&jawabanSiswa0003{}, "uq_jawaban_siswa_attempt_soal") {
+				if err := tx.Migrator().CreateIndex(&jawabanSiswa0003{}, "uq_jawaban_siswa_attempt_soal"); err != nil {
+					return err
+				}
+			}
+
+			if tx.Migrator().HasTable(&idempotencyKey0003{}) {
+				return nil
+			}
+			return tx.Migrator().CreateTable(&idempotencyKey0003{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&idempotencyKey0003{}); err != nil {
+				return err
+			}
+			if tx.Migrator().HasIndex(&jawabanSiswa0003{}, "uq_jawaban_siswa_attempt_soal") {
+				if err := tx.Migrator().DropIndex(&jawabanSiswa0003{}, "uq_jawaban_siswa_attempt_soal"); err != nil {
+					return err
+				}
+			}
+			if tx.Migrator().HasColumn(&jawabanSiswa0003{}, "IdUjianAttempt") {
+				if err := tx.Migrator().DropColumn(&jawabanSiswa0003{}, "IdUjianAttempt"); err != nil {
+					return err
+				}
+			}
+			return createMissingIndexes(tx, []tableIndex{{&jawabanSiswa0001{}, "idx_jawaban_siswa_siswa_soal"}})
+		},
+	})
+}
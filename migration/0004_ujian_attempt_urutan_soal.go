@@ -0,0 +1,39 @@
+// migration/0004_ujian_attempt_urutan_soal.go
+package migration
+
+import (
+	"gorm.io/gorm"
+)
+
+// ujianAttempt0004 is the column of ujian_attempt this migration adds
+type ujianAttempt0004 struct {
+	UrutanSoal string `gorm:"type:text"`
+}
+
+func (ujianAttempt0004) TableName() string { return "ujian_attempt" }
+
+// Urutan soal disimpan per attempt agar siswa yang melanjutkan ujian melihat urutan yang sama.
+// Attempt lama dibiarkan kosong dan memakai urutan soal di database.
+func init() {
+	register(Migration{
+		Version: 4,
+		Name:    "ujian_attempt_urutan_soal",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&ujianAttempt0004{}, "UrutanSoal") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&ujianAttempt0004{}, "UrutanSoal")
+		},
+		Down: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&ujianAttempt0004{}, "UrutanSoal") {
+				if err := tx.Migrator().DropColumn(&ujianAttempt0004{}, "UrutanSoal"); err != nil {
+					return err
+				}
+			}
+			return createMissingIndexes(tx, []tableIndex{
+				{&ujianAttempt0001{}, "IdUjian"},
+				{&ujianAttempt0001{}, "IdSiswa"},
+			})
+		},
+	})
+}
@@ -0,0 +1,53 @@
+// migration/0005_jawaban_siswa_penilaian_manual.go
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// jawabanSiswa0005 holds the jawaban_siswa columns this migration adds
+type jawabanSiswa0005 struct {
+	NilaiManual *float64 `gorm:"type:decimal(5,2);null"`
+	Feedback    string   `gorm:"type:text"`
+	DinilaiOleh *uint64
+	DinilaiPada *time.Time `gorm:"type:timestamp;null"`
+}
+
+func (jawabanSiswa0005) TableName() string { return "jawaban_siswa" }
+
+// penilaianManualColumns are the jawaban_siswa columns filled when a guru grades an Isian answer
+var penilaianManualColumns = []string{"NilaiManual", "Feedback", "DinilaiOleh", "DinilaiPada"}
+
+func init() {
+	register(Migration{
+		Version: 5,
+		Name:    "jawaban_siswa_penilaian_manual",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range penilaianManualColumns {
+				if tx.Migrator().HasColumn(&jawabanSiswa0005{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&jawabanSiswa0005{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, column := range penilaianManualColumns {
+				if !tx.Migrator().HasColumn(&jawabanSiswa0005{}, column) {
+					continue
+				}
+				if err := tx.Migrator().DropColumn(&jawabanSiswa0005{}, column); err != nil {
+					return err
+				}
+			}
+			return createMissingIndexes(tx, []tableIndex{
+				{&jawabanSiswa0001{}, "idx_jawaban_siswa_siswa_soal"},
+				{&jawabanSiswa0003{}, "uq_jawaban_siswa_attempt_soal"},
+			})
+		},
+	})
+}
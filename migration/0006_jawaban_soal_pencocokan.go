@@ -0,0 +1,60 @@
+// migration/0006_jawaban_soal_pencocokan.go
+package migration
+
+import (
+	"gorm.io/gorm"
+)
+
+// jawabanSoal0006 holds the jawaban_soal columns and constraint this migration adds
+type jawabanSoal0006 struct {
+	TipePencocokan       string  `gorm:"type:varchar(20);not null;default:'Teks';check:chk_jawaban_soal_tipe_pencocokan,tipe_pencocokan IN ('Teks', 'Angka', 'Regex')"`
+	BedakanHurufBesar    bool    `gorm:"not null;default:false"`
+	PertahankanTandaBaca bool    `gorm:"not null;default:false"`
+	ToleransiAngka       float64 `gorm:"type:decimal(12,4);not null;default:0"`
+}
+
+func (jawabanSoal0006) TableName() string { return "jawaban_soal" }
+
+// pencocokanColumns are the jawaban_soal columns that describe how an Isian answer is matched
+var pencocokanColumns = []string{"TipePencocokan", "BedakanHurufBesar", "PertahankanTandaBaca", "ToleransiAngka"}
+
+// Opsi lama mendapat nilai default, yaitu pencocokan Teks tanpa membedakan huruf besar dan tanda baca
+func init() {
+	register(Migration{
+		Version: 6,
+		Name:    "jawaban_soal_pencocokan",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range pencocokanColumns {
+				if tx.Migrator().HasColumn(&jawabanSoal0006{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&jawabanSoal0006{}, column); err != nil {
+					return err
+				}
+			}
+			if tx.Migrator().HasConstraint(&jawabanSoal0006{}, "chk_jawaban_soal_tipe_pencocokan") {
+				return nil
+			}
+			if err := tx.Migrator().CreateConstraint(&jawabanSoal0006{}, "chk_jawaban_soal_tipe_pencocokan"); err != nil {
+				return err
+			}
+			return createMissingIndexes(tx, []tableIndex{{&jawabanSoal0001{}, "idx_jawaban_soal_id_soal"}})
+		},
+		Down: func(tx *gorm.DB) error {
+			if tx.Migrator().HasConstraint(&jawabanSoal0006{}, "chk_jawaban_soal_tipe_pencocokan") {
+				if err := tx.Migrator().DropConstraint(&jawabanSoal0006{}, "chk_jawaban_soal_tipe_pencocokan"); err != nil {
+					return err
+				}
+			}
+			for _, column := range pencocokanColumns {
+				if !tx.Migrator().HasColumn(&jawabanSoal0006{}, column) {
+					continue
+				}
+				if err := tx.Migrator().DropColumn(&jawabanSoal0006{}, column); err != nil {
+					return err
+				}
+			}
+			return createMissingIndexes(tx, []tableIndex{{&jawabanSoal0001{}, "idx_jawaban_soal_id_soal"}})
+		},
+	})
+}
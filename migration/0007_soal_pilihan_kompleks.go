@@ -0,0 +1,95 @@
+// migration/0007_soal_pilihan_kompleks.go
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// tipeSoal0007 is the tipe_soal constraint as this migration recreates it
+type tipeSoal0007 struct {
+	NamaTipeUjian string `gorm:"type:varchar(20);not null;check:chk_tipe_soal_nama_tipe_ujian,nama_tipe_ujian IN ('Pilihan_Berganda', 'Benar_Salah', 'Isian', 'Pilihan_Kompleks')"`
+}
+
+func (tipeSoal0007) TableName() string { return "tipe_soal" }
+
+// soal0007 holds the soal column and constraint this migration adds
+type soal0007 struct {
+	ModePenilaian string `gorm:"type:varchar(20);not null;default:'Semua_Benar';check:chk_soal_mode_penilaian,mode_penilaian IN ('Semua_Benar', 'Proporsional', 'Penalti')"`
+}
+
+func (soal0007) TableName() string { return "soal" }
+
+// jawabanSiswaPilihan0007 is jawaban_siswa_pilihan as this migration creates it
+type jawabanSiswaPilihan0007 struct {
+	IdJawabanSiswaPilihan uint64    `gorm:"primary_key;autoIncrement"`
+	IdJawabanSiswa        uint64    `gorm:"uniqueIndex:uq_jawaban_siswa_pilihan,priority:1"`
+	IdJawabanSoal         uint64    `gorm:"uniqueIndex:uq_jawaban_siswa_pilihan,priority:2"`
+	CreatedAt             time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+}
+
+func (jawabanSiswaPilihan0007) TableName() string { return "jawaban_siswa_pilihan" }
+
+// Tipe soal Pilihan_Kompleks: constraint tipe_soal dibuat ulang agar menerima nilai baru, soal mendapat
+// mode_penilaian (default Semua_Benar) dan opsi yang dipilih siswa disimpan di jawaban_siswa_pilihan.
+func init() {
+	register(Migration{
+		Version: 7,
+		Name:    "soal_pilihan_kompleks",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasConstraint(&tipeSoal0007{}, "chk_tipe_soal_nama_tipe_ujian") {
+				if err := tx.Migrator().DropConstraint(&tipeSoal0007{}, "chk_tipe_soal_nama_tipe_ujian"); err != nil {
+					return err
+				}
+			}
+			if err := tx.Migrator().CreateConstraint(&tipeSoal0007{}, "chk_tipe_soal_nama_tipe_ujian"); err != nil {
+				return err
+			}
+
+			if !tx.Migrator().HasColumn(&soal0007{}, "ModePenilaian") {
+				if err := tx.Migrator().AddColumn(&soal0007{}, "ModePenilaian"); err != nil {
+					return err
+				}
+			}
+			if !tx.Migrator().HasConstraint(&soal0007{}, "chk_soal_mode_penilaian") {
+				if err := tx.Migrator().CreateConstraint(&soal0007{}, "chk_soal_mode_penilaian"); err != nil {
+					return err
+				}
+			}
+			err := createMissingIndexes(tx, []tableIndex{
+				{&soal0001{}, "idx_soal_id_ujian"},
+				{&soal0001{}, "idx_soal_id_latihan"},
+			})
+			if err != nil {
+				return err
+			}
+
+			if tx.Migrator().HasTable(&jawabanSiswaPilihan0007{}) {
+				return nil
+			}
+			return tx.Migrator().CreateTable(&jawabanSiswaPilihan0007{})
+		},
+		// Constraint tipe_soal dibiarkan menerima Pilihan_Kompleks; nilai tambahan itu tidak mengganggu
+		// kode versi sebelumnya
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&jawabanSiswaPilihan0007{}); err != nil {
+				return err
+			}
+			if tx.Migrator().HasConstraint(&soal0007{}, "chk_soal_mode_penilaian") {
+				if err := tx.Migrator().DropConstraint(&soal0007{}, "chk_soal_mode_penilaian"); err != nil {
+					return err
+				}
+			}
+			if tx.Migrator().HasColumn(&soal0007{}, "ModePenilaian") {
+				if err := tx.Migrator().DropColumn(&soal0007{}, "ModePenilaian"); err != nil {
+					return err
+				}
+			}
+			return createMissingIndexes(tx, []tableIndex{
+				{&soal0001{}, "idx_soal_id_ujian"},
+				{&soal0001{}, "idx_soal_id_latihan"},
+			})
+		},
+	})
+}
@@ -0,0 +1,51 @@
+// migration/0008_ujian_aturan_penilaian.go
+package migration
+
+import (
+	"gorm.io/gorm"
+)
+
+// ujian0008 holds the ujian columns this migration adds
+type ujian0008 struct {
+	SkorBenar           *float64 `gorm:"type:decimal(6,2);null"`
+	SkorSalah           float64  `gorm:"type:decimal(6,2);not null;default:0"`
+	SkorKosong          float64  `gorm:"type:decimal(6,2);not null;default:0"`
+	IzinkanNilaiNegatif bool     `gorm:"not null;default:false"`
+	SkalakanKeGrade     bool     `gorm:"not null;default:false"`
+}
+
+func (ujian0008) TableName() string { return "ujian" }
+
+// aturanPenilaianColumns are the ujian columns of the scoring policy
+var aturanPenilaianColumns = []string{"SkorBenar", "SkorSalah", "SkorKosong", "IzinkanNilaiNegatif", "SkalakanKeGrade"}
+
+// Ujian lama mendapat aturan yang sama dengan perhitungan sebelumnya: nilai_per_soal untuk jawaban
+// benar, nol untuk jawaban salah dan kosong, tanpa skala ke grade
+func init() {
+	register(Migration{
+		Version: 8,
+		Name:    "ujian_aturan_penilaian",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range aturanPenilaianColumns {
+				if tx.Migrator().HasColumn(&ujian0008{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&ujian0008{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, column := range aturanPenilaianColumns {
+				if !tx.Migrator().HasColumn(&ujian0008{}, column) {
+					continue
+				}
+				if err := tx.Migrator().DropColumn(&ujian0008{}, column); err != nil {
+					return err
+				}
+			}
+			return createMissingIndexes(tx, []tableIndex{{&ujian0001{}, "idx_ujian_id_kursus"}})
+		},
+	})
+}
@@ -0,0 +1,39 @@
+// migration/0009_ujian_attempt_urutan_opsi.go
+package migration
+
+import (
+	"gorm.io/gorm"
+)
+
+// ujianAttempt0009 is the column of ujian_attempt this migration adds
+type ujianAttempt0009 struct {
+	UrutanOpsi string `gorm:"type:text"`
+}
+
+func (ujianAttempt0009) TableName() string { return "ujian_attempt" }
+
+// Urutan opsi jawaban disimpan per attempt bersama urutan soal agar ujian yang diacak tetap sama
+// saat dilanjutkan. Attempt lama dibiarkan kosong dan memakai urutan opsi di database.
+func init() {
+	register(Migration{
+		Version: 9,
+		Name:    "ujian_attempt_urutan_opsi",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&ujianAttempt0009{}, "UrutanOpsi") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&ujianAttempt0009{}, "UrutanOpsi")
+		},
+		Down: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&ujianAttempt0009{}, "UrutanOpsi") {
+				if err := tx.Migrator().DropColumn(&ujianAttempt0009{}, "UrutanOpsi"); err != nil {
+					return err
+				}
+			}
+			return createMissingIndexes(tx, []tableIndex{
+				{&ujianAttempt0001{}, "IdUjian"},
+				{&ujianAttempt0001{}, "IdSiswa"},
+			})
+		},
+	})
+}
@@ -0,0 +1,94 @@
+// migration/0010_bank_soal.go
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// soal0010 holds the soal columns and index this migration adds
+type soal0010 struct {
+	IdMataPelajaran uint64 `gorm:"index:idx_soal_bank,priority:1"`
+	IdKelas         uint64 `gorm:"index:idx_soal_bank,priority:2"`
+}
+
+func (soal0010) TableName() string { return "soal" }
+
+// soalTag0010 is soal_tag as this migration creates it
+type soalTag0010 struct {
+	IdSoalTag uint64    `gorm:"primary_key;autoIncrement"`
+	IdSoal    uint64    `gorm:"not null;uniqueIndex:uq_soal_tag,priority:1"`
+	Tag       string    `gorm:"type:varchar(50);not null;uniqueIndex:uq_soal_tag,priority:2;index:idx_soal_tag_tag"`
+	CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+}
+
+func (soalTag0010) TableName() string { return "soal_tag" }
+
+// ujianSoal0010 is ujian_soal as this migration creates it
+type ujianSoal0010 struct {
+	IdUjianSoal  uint64    `gorm:"primary_key;autoIncrement"`
+	IdUjian      uint64    `gorm:"not null;uniqueIndex:uq_ujian_soal,priority:1"`
+	IdSoal       uint64    `gorm:"not null;uniqueIndex:uq_ujian_soal,priority:2;index:idx_ujian_soal_id_soal"`
+	NilaiPerSoal *float64  `gorm:"type:decimal(5,2);null"`
+	CreatedAt    time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+	UpdatedAt    time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+}
+
+func (ujianSoal0010) TableName() string { return "ujian_soal" }
+
+// Bank soal: soal mendapat klasifikasi mata pelajaran dan kelas, tag topik disimpan di soal_tag dan
+// ujian memakai soal bank lewat ujian_soal beserta nilai per soal khusus ujian itu.
+func init() {
+	register(Migration{
+		Version: 10,
+		Name:    "bank_soal",
+		Up: func(tx *gorm.DB) error {
+			for _, kolom := range []string{"IdMataPelajaran", "IdKelas"} {
+				if tx.Migrator().HasColumn(&soal0010{}, kolom) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&soal0010{}, kolom); err != nil {
+					return err
+				}
+			}
+			if !tx.Migrator().HasIndex(&soal0010{}, "idx_soal_bank") {
+				if err := tx.Migrator().CreateIndex(&soal0010{}, "idx_soal_bank"); err != nil {
+					return err
+				}
+			}
+
+			for _, table := range []interface{}{&soalTag0010{}, &ujianSoal0010{}} {
+				if tx.Migrator().HasTable(table) {
+					continue
+				}
+				if err := tx.Migrator().CreateTable(table); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&ujianSoal0010{}, &soalTag0010{}); err != nil {
+				return err
+			}
+			if tx.Migrator().HasIndex(&soal0010{}, "idx_soal_bank") {
+				if err := tx.Migrator().DropIndex(&soal0010{}, "idx_soal_bank"); err != nil {
+					return err
+				}
+			}
+			for _, kolom := range []string{"IdKelas", "IdMataPelajaran"} {
+				if !tx.Migrator().HasColumn(&soal0010{}, kolom) {
+					continue
+				}
+				if err := tx.Migrator().DropColumn(&soal0010{}, kolom); err != nil {
+					return err
+				}
+			}
+			return createMissingIndexes(tx, []tableIndex{
+				{&soal0001{}, "idx_soal_id_ujian"},
+				{&soal0001{}, "idx_soal_id_latihan"},
+			})
+		},
+	})
+}
@@ -0,0 +1,51 @@
+// migration/0011_ujian_attempt_unik.go
+package migration
+
+import (
+	"gorm.io/gorm"
+)
+
+// attemptLama selects the attempts that are not the latest of their siswa and ujian. Dibungkus tabel
+// turunan karena MySQL menolak DELETE yang membaca tabelnya sendiri.
+const attemptLama = `
+	SELECT id FROM (
+		SELECT a.id_ujian_attempt AS id
+		FROM ujian_attempt a
+		WHERE a.id_ujian_attempt < (
+			SELECT MAX(b.id_ujian_attempt) FROM ujian_attempt b
+			WHERE b.id_ujian = a.id_ujian AND b.id_siswa = a.id_siswa
+		)
+	) AS lama`
+
+// Satu siswa hanya punya satu attempt per ujian, dijaga unique index agar StartAttempt yang berjalan
+// bersamaan tidak membuat attempt ganda. Attempt ganda yang sudah ada dibuang beserta jawabannya;
+// attempt terbaru yang selama ini dipakai aplikasi disimpan.
+func init() {
+	register(Migration{
+		Version: 11,
+		Name:    "ujian_attempt_unik",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasIndex("ujian_attempt", "uq_ujian_attempt_ujian_siswa") {
+				return nil
+			}
+			for _, query := range []string{
+				`DELETE FROM jawaban_siswa_pilihan WHERE id_jawaban_siswa IN (
+					SELECT id_jawaban_siswa FROM jawaban_siswa WHERE id_ujian_attempt IN (` + attemptLama + `))`,
+				`DELETE FROM jawaban_siswa WHERE id_ujian_attempt IN (` + attemptLama + `)`,
+				`DELETE FROM ujian_attempt WHERE id_ujian_attempt IN (` + attemptLama + `)`,
+				`CREATE UNIQUE INDEX uq_ujian_attempt_ujian_siswa ON ujian_attempt (id_ujian, id_siswa)`,
+			} {
+				if err := tx.Exec(query).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasIndex("ujian_attempt", "uq_ujian_attempt_ujian_siswa") {
+				return nil
+			}
+			return tx.Migrator().DropIndex("ujian_attempt", "uq_ujian_attempt_ujian_siswa")
+		},
+	})
+}
@@ -0,0 +1,37 @@
+// migration/0012_refresh_token_lama.go
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// refreshTokenLama0012 is refresh_token_lama as this migration creates it
+type refreshTokenLama0012 struct {
+	IdRefreshTokenLama uint64    `gorm:"primary_key;autoIncrement"`
+	IdUserSession      uint64    `gorm:"not null;index"`
+	RefreshTokenHash   string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	CreatedAt          time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+}
+
+func (refreshTokenLama0012) TableName() string {
+	return "refresh_token_lama"
+}
+
+// Refresh token yang sudah dirotasi disimpan hash-nya agar pemakaian ulang terdeteksi dan sesinya dicabut.
+func init() {
+	register(Migration{
+		Version: 12,
+		Name:    "refresh_token_lama",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasTable(&refreshTokenLama0012{}) {
+				return nil
+			}
+			return tx.Migrator().CreateTable(&refreshTokenLama0012{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&refreshTokenLama0012{})
+		},
+	})
+}
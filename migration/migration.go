@@ -0,0 +1,140 @@
+// migration/migration.go
+package migration
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration is one versioned schema change. Version must be unique and only ever increase;
+// a migration that has been applied somewhere must never be edited, add a new one instead.
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// SchemaMigration is a row in schema_migrations: one per applied migration
+type SchemaMigration struct {
+	Version   uint64    `gorm:"primaryKey;autoIncrement:false" json:"version"`
+	Name      string    `gorm:"type:varchar(255);not null" json:"name"`
+	AppliedAt time.Time `gorm:"not null" json:"applied_at"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations" // Nama tabel di database
+}
+
+// MigrationStatus is used by `migrate status`
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// registry is filled by the init functions of the numbered migration files
+var registry []Migration
+
+func register(m Migration) {
+	registry = append(registry, m)
+}
+
+// All returns every known migration ordered by version
+func All() []Migration {
+	migrations := make([]Migration, len(registry))
+	copy(migrations, registry)
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations
+}
+
+// Up applies every pending migration in order and returns the ones it applied.
+// It stops at the first failure; migrations applied before it stay recorded.
+func Up(db *gorm.DB) ([]Migration, error) {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, m := range All() {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// Down rolls back the last `steps` applied migrations, newest first
+func Down(db *gorm.DB, steps int) ([]Migration, error) {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	all := All()
+	var done []Migration
+	for i := len(all) - 1; i >= 0 && len(done) < steps; i-- {
+		m := all[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, m.Version).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("rollback %d_%s: %w", m.Version, m.Name, err)
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// Status lists every known migration and when it was applied (nil = pending)
+func Status(db *gorm.DB) ([]MigrationStatus, error) {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+	for _, m := range All() {
+		status := MigrationStatus{Migration: m}
+		if appliedAt, ok := applied[m.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func appliedVersions(db *gorm.DB) (map[uint64]time.Time, error) {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	var rows []SchemaMigration
+	if err := db.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[uint64]time.Time, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row.AppliedAt
+	}
+	return applied, nil
+}
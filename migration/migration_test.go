@@ -0,0 +1,201 @@
+package migration
+
+import (
+	"cbt-api/config"
+	"cbt-api/entity"
+	"net/url"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func bukaSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := config.ConnectDatabase(config.DatabaseConfig{
+		Driver:         config.DriverSQLite,
+		DSN:            "file:" + url.PathEscape(t.Name()) + "?mode=memory&cache=shared",
+		MaxOpenConns:   1,
+		MaxIdleConns:   1,
+		ConnectTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func TestUpDownSQLite(t *testing.T) {
+	db := bukaSQLite(t)
+
+	applied, err := Up(db)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if len(applied) != len(All()) {
+		t.Fatalf("Up applied %d migrations, want %d", len(applied), len(All()))
+	}
+	if again, err := Up(db); err != nil || len(again) != 0 {
+		t.Fatalf("second Up = %d migrations, %v; want 0, nil", len(again), err)
+	}
+
+	statuses, err := Status(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range statuses {
+		if s.AppliedAt == nil {
+			t.Errorf("migration %d_%s is pending after Up", s.Version, s.Name)
+		}
+	}
+
+	rolledBack, err := Down(db, len(All()))
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if len(rolledBack) != len(All()) {
+		t.Fatalf("Down rolled back %d migrations, want %d", len(rolledBack), len(All()))
+	}
+	if _, err := Up(db); err != nil {
+		t.Fatalf("Up after Down: %v", err)
+	}
+}
+
+// TestUpMatchesEntities checks that the frozen table copies of the migrations still build every column
+// and index the entities declare, so an entity change without a migration is caught here. The schema
+// is rolled back to the baseline first: on SQLite dropping a column rebuilds the table and its indexes
+// have to be put back.
+func TestUpMatchesEntities(t *testing.T) {
+	db := bukaSQLite(t)
+	if _, err := Up(db); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Down(db, len(All())-2); err != nil {
+		t.Fatal(err)
+	}
+	for _, idx := range baselineIndexes() {
+		if !db.Migrator().HasIndex(idx.model, idx.name) {
+			t.Errorf("baseline index %s lost while rolling back", idx.name)
+		}
+	}
+	if _, err := Up(db); err != nil {
+		t.Fatal(err)
+	}
+	models := []interface{}{
+		&entity.Users{}, &entity.Admin{}, &entity.Operator{}, &entity.Guru{}, &entity.Bisnis{},
+		&entity.Kelas{}, &entity.Kurikulum{}, &entity.MataPelajaran{}, &entity.Siswa{},
+		&entity.KurikulumSiswa{}, &entity.MataPelajaranSiswa{}, &entity.Kursus{}, &entity.KursusSiswa{},
+		&entity.Materi{}, &entity.TipeUjian{}, &entity.TipeSoal{}, &entity.Ujian{}, &entity.Latihan{},
+		&entity.Soal{}, &entity.LatihanSoal{}, &entity.JawabanSoal{}, &entity.JawabanSiswa{},
+		&entity.JawabanSiswaPilihan{}, &entity.TipeNilai{}, &entity.Nilai{}, &entity.NilaiKursus{},
+		&entity.Persentase{}, &entity.UjianAttempt{}, &entity.UserSession{}, &entity.RefreshTokenLama{},
+		&entity.IdempotencyKey{}, &entity.SoalTag{}, &entity.UjianSoal{},
+	}
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			t.Fatal(err)
+		}
+		table := stmt.Schema.Table
+		if !db.Migrator().HasTable(table) {
+			t.Errorf("table %s missing after Up", table)
+			continue
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName != "" && !db.Migrator().HasColumn(table, field.DBName) {
+				t.Errorf("column %s.%s missing after Up", table, field.DBName)
+			}
+		}
+		for _, idx := range stmt.Schema.ParseIndexes() {
+			if !db.Migrator().HasIndex(table, idx.Name) {
+				t.Errorf("index %s on %s missing after Up", idx.Name, table)
+			}
+		}
+	}
+}
+
+func TestAllVersionsUnique(t *testing.T) {
+	seen := make(map[uint64]string)
+	for _, m := range All() {
+		if other, ok := seen[m.Version]; ok {
+			t.Errorf("version %d used by %s and %s", m.Version, other, m.Name)
+		}
+		seen[m.Version] = m.Name
+	}
+}
+
+func TestAllOrderedByVersion(t *testing.T) {
+	all := All()
+	if len(all) == 0 {
+		t.Fatal("no migrations registered")
+	}
+	for i, m := range all {
+		if m.Up == nil || m.Down == nil {
+			t.Errorf("migration %d_%s has no Up or Down", m.Version, m.Name)
+		}
+		if i > 0 && all[i-1].Version >= m.Version {
+			t.Errorf("migration %d listed after %d", m.Version, all[i-1].Version)
+		}
+	}
+	// All mengembalikan salinan; mengubahnya tidak mengubah registry
+	all[0].Version = 0
+	if All()[0].Version == 0 {
+		t.Error("All returned the registry itself")
+	}
+}
+
+func TestUjianAttemptUnikDropsDuplicates(t *testing.T) {
+	db := bukaSQLite(t)
+	if _, err := Up(db); err != nil {
+		t.Fatal(err)
+	}
+	// Mundur sampai sebelum 0011_ujian_attempt_unik
+	var setelah int
+	for _, m := range All() {
+		if m.Version >= 11 {
+			setelah++
+		}
+	}
+	if _, err := Down(db, setelah); err != nil {
+		t.Fatal(err)
+	}
+
+	// Attempt ganda dari StartAttempt yang berjalan bersamaan; attempt 2 yang terbaru
+	for _, query := range []string{
+		`INSERT INTO ujian_attempt (id_ujian_attempt, id_ujian, id_siswa, status) VALUES
+			(1, 1, 7, 'Berlangsung'), (2, 1, 7, 'Berlangsung'), (3, 1, 8, 'Selesai'), (4, 2, 7, 'Berlangsung')`,
+		`INSERT INTO jawaban_siswa (id_jawaban_siswa, jawaban_text, id_soal, id_siswa, id_jawaban_soal, id_ujian_attempt) VALUES
+			(1, 'lama', 1, 7, 0, 1), (2, 'baru', 1, 7, 0, 2), (3, 'latihan', 9, 7, 0, NULL)`,
+		`INSERT INTO jawaban_siswa_pilihan (id_jawaban_siswa, id_jawaban_soal) VALUES (1, 5), (2, 5)`,
+	} {
+		if err := db.Exec(query).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := Up(db); err != nil {
+		t.Fatalf("Up with duplicate attempts: %v", err)
+	}
+
+	var attempts, jawaban, pilihan []uint64
+	db.Raw("SELECT id_ujian_attempt FROM ujian_attempt ORDER BY id_ujian_attempt").Scan(&attempts)
+	db.Raw("SELECT id_jawaban_siswa FROM jawaban_siswa ORDER BY id_jawaban_siswa").Scan(&jawaban)
+	db.Raw("SELECT id_jawaban_siswa FROM jawaban_siswa_pilihan ORDER BY id_jawaban_siswa").Scan(&pilihan)
+	if len(attempts) != 3 || attempts[0] != 2 || attempts[1] != 3 || attempts[2] != 4 {
+		t.Errorf("attempts = %v, want [2 3 4]", attempts)
+	}
+	if len(jawaban) != 2 || jawaban[0] != 2 || jawaban[1] != 3 {
+		t.Errorf("jawaban = %v, want [2 3]", jawaban)
+	}
+	if len(pilihan) != 1 || pilihan[0] != 2 {
+		t.Errorf("pilihan = %v, want [2]", pilihan)
+	}
+
+	if err := db.Exec(`INSERT INTO ujian_attempt (id_ujian, id_siswa, status) VALUES (1, 7, 'Berlangsung')`).Error; err == nil {
+		t.Error("second attempt for the same siswa and ujian was accepted")
+	}
+}
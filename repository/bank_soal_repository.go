@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"cbt-api/entity"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BankSoalRepository is a contract for question bank database operations: tags of bank soal and the
+// links that let ujian and latihan use them
+type BankSoalRepository interface {
+	WithTx(tx *gorm.DB) BankSoalRepository
+	FindBankSoal(idMataPelajaran uint64, idKelas uint64, tag string) ([]entity.Soal, error)
+	FindTagByIdSoal(idSoal []uint64) ([]entity.SoalTag, error)
+	ReplaceTag(idSoal uint64, tag []string) error
+	FindUjianSoal(idUjian uint64, idSoal uint64) (entity.UjianSoal, error)
+	FindUjianSoalByIdSoal(idSoal uint64) ([]entity.UjianSoal, error)
+	UpsertUjianSoal(ujianSoal entity.UjianSoal) (entity.UjianSoal, error)
+	DeleteUjianSoal(idUjian uint64, idSoal uint64) error
+	SudahDijawabDiUjian(idUjian uint64, idSoal uint64) (bool, error)
+	CreateLatihanSoal(latihanSoal entity.LatihanSoal) (entity.LatihanSoal, error)
+	DeleteLatihanSoal(idLatihan uint64, idSoal uint64) error
+}
+
+type bankSoalRepository struct {
+	db *gorm.DB
+}
+
+// NewBankSoalRepository creates a new instance of BankSoalRepository
+func NewBankSoalRepository(db *gorm.DB) BankSoalRepository {
+	return &bankSoalRepository{db}
+}
+
+func (r *bankSoalRepository) WithTx(tx *gorm.DB) BankSoalRepository {
+	return &bankSoalRepository{tx}
+}
+
+// FindBankSoal finds the bank soal, those without their own ujian or latihan, with their tipe soal.
+// Zero ids and an empty tag do not filter.
+func (r *bankSoalRepository) FindBankSoal(idMataPelajaran uint64, idKelas uint64, tag string) ([]entity.Soal, error) {
+	query := r.db.Preload("TipeSoal").Where("soal.id_ujian = 0 AND soal.id_latihan = 0")
+	if idMataPelajaran != 0 {
+		query = query.Where("soal.id_mata_pelajaran = ?", idMataPelajaran)
+	}
+	if idKelas != 0 {
+		query = query.Where("soal.id_kelas = ?", idKelas)
+	}
+	if tag != "" {
+		query = query.Where("soal.id_soal IN (?)",
+			r.db.Model(&entity.SoalTag{}).Select("id_soal").Where("tag = ?", tag))
+	}
+	var soalList []entity.Soal
+	err := query.Order("soal.id_soal ASC").Find(&soalList).Error
+	return soalList, err
+}
+
+// FindTagByIdSoal finds the tags of the given soal, ordered by soal and tag
+func (r *bankSoalRepository) FindTagByIdSoal(idSoal []uint64) ([]entity.SoalTag, error) {
+	var tagList []entity.SoalTag
+	if len(idSoal) == 0 {
+		return tagList, nil
+	}
+	err := r.db.Where("id_soal IN ?", idSoal).Order("id_soal ASC, tag ASC").Find(&tagList).Error
+	return tagList, err
+}
+
+// ReplaceTag replaces every tag of the soal
+func (r *bankSoalRepository) ReplaceTag(idSoal uint64, tag []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id_soal = ?", idSoal).Delete(&entity.SoalTag{}).Error; err != nil {
+			return err
+		}
+		if len(tag) == 0 {
+			return nil
+		}
+		now := time.Now()
+		baru := make([]entity.SoalTag, 0, len(tag))
+		for _, t := range tag {
+			baru = append(baru, entity.SoalTag{IdSoal: idSoal, Tag: t, CreatedAt: now})
+		}
+		return tx.Omit(clause.Associations).Create(&baru).Error
+	})
+}
+
+func (r *bankSoalRepository) FindUjianSoal(idUjian uint64, idSoal uint64) (entity.UjianSoal, error) {
+	var ujianSoal entity.UjianSoal
+	err := r.db.Where("id_ujian = ? AND id_soal = ?", idUjian, idSoal).Take(&ujianSoal).Error
+	return ujianSoal, err
+}
+
+// FindUjianSoalByIdSoal finds every link of a bank soal to an ujian
+func (r *bankSoalRepository) FindUjianSoalByIdSoal(idSoal uint64) ([]entity.UjianSoal, error) {
+	var ujianSoalList []entity.UjianSoal
+	err := r.db.Where("id_soal = ?", idSoal).Order("id_ujian ASC").Find(&ujianSoalList).Error
+	return ujianSoalList, err
+}
+
+// UpsertUjianSoal links the soal to the ujian, or replaces the nilai_per_soal of an existing link
+func (r *bankSoalRepository) UpsertUjianSoal(ujianSoal entity.UjianSoal) (entity.UjianSoal, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Omit(clause.Associations).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id_ujian"}, {Name: "id_soal"}},
+			DoUpdates: clause.AssignmentColumns([]string{"nilai_per_soal", "updated_at"}),
+		}).Create(&ujianSoal).Error
+		if err != nil {
+			return err
+		}
+		// Pada konflik MySQL tidak mengembalikan id baris yang sudah ada
+		return tx.Where("id_ujian = ? AND id_soal = ?", ujianSoal.IdUjian, ujianSoal.IdSoal).Take(&ujianSoal).Error
+	})
+	return ujianSoal, err
+}
+
+func (r *bankSoalRepository) DeleteUjianSoal(idUjian uint64, idSoal uint64) error {
+	return r.db.Where("id_ujian = ? AND id_soal = ?", idUjian, idSoal).Delete(&entity.UjianSoal{}).Error
+}
+
+// SudahDijawabDiUjian reports whether any siswa has answered the soal in one of the ujian's attempts
+func (r *bankSoalRepository) SudahDijawabDiUjian(idUjian uint64, idSoal uint64) (bool, error) {
+	var count int64
+	err := r.db.Model(&entity.JawabanSiswa{}).
+		Joins("JOIN ujian_attempt ON ujian_attempt.id_ujian_attempt = jawaban_siswa.id_ujian_attempt").
+		Where("ujian_attempt.id_ujian = ? AND jawaban_siswa.id_soal = ?", idUjian, idSoal).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CreateLatihanSoal links the soal to the latihan; linking it again keeps the existing link
+func (r *bankSoalRepository) CreateLatihanSoal(latihanSoal entity.LatihanSoal) (entity.LatihanSoal, error) {
+	err := r.db.Omit(clause.Associations).
+		Where(entity.LatihanSoal{IdLatihan: latihanSoal.IdLatihan, IdSoal: latihanSoal.IdSoal}).
+		FirstOrCreate(&latihanSoal).Error
+	return latihanSoal, err
+}
+
+func (r *bankSoalRepository) DeleteLatihanSoal(idLatihan uint64, idSoal uint64) error {
+	return r.db.Where("id_latihan = ? AND id_soal = ?", idLatihan, idSoal).Delete(&entity.LatihanSoal{}).Error
+}
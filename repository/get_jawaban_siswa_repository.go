@@ -3,6 +3,7 @@ package repository
 import (
 	"cbt-api/entity"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // GetJawabanSiswaRepository is a contract for jawaban siswa database operations
@@ -13,13 +14,32 @@ type GetJawabanSiswaRepository interface {
 
 // SoalRepository is a contract for soal database operations
 type SoalRepository interface {
+	WithTx(tx *gorm.DB) SoalRepository
 	FindById(id uint64) (entity.Soal, error)
+	FindByIdWithTipeSoal(id uint64) (entity.Soal, error)
 	FindByIdUjian(idUjian uint64) ([]entity.Soal, error)
+	FindByIdUjianWithTipeSoal(idUjian uint64) ([]entity.Soal, error)
+	FindByIdLatihan(idLatihan uint64) ([]entity.Soal, error)
+	FindByIdLatihanWithTipeSoal(idLatihan uint64) ([]entity.Soal, error)
+	FindIdUjianBySoal(idSoal uint64) ([]uint64, error)
+	FindTipeSoalById(idTipeSoal uint64) (entity.TipeSoal, error)
+	FindAllTipeSoal() ([]entity.TipeSoal, error)
+	SudahDijawab(idSoal uint64) (bool, error)
+	CreateSoal(soal entity.Soal) (entity.Soal, error)
+	UpdateSoal(soal entity.Soal) (entity.Soal, error)
+	DeleteSoal(idSoal uint64) error
 }
 
 // JawabanSoalRepository is a contract for jawaban soal database operations
 type JawabanSoalRepository interface {
+	WithTx(tx *gorm.DB) JawabanSoalRepository
 	FindById(id uint64) (entity.JawabanSoal, error)
+	FindByIdSoal(idSoal uint64) ([]entity.JawabanSoal, error)
+	FindByIdUjian(idUjian uint64) ([]entity.JawabanSoal, error)
+	SudahDipilih(id uint64) (bool, error)
+	CreateJawabanSoal(jawabanSoal entity.JawabanSoal) (entity.JawabanSoal, error)
+	UpdateJawabanSoal(jawabanSoal entity.JawabanSoal) (entity.JawabanSoal, error)
+	DeleteJawabanSoal(id uint64) error
 }
 
 // jawabanSiswaRepository is a struct that implements GetJawabanSiswaRepository interface
@@ -65,12 +85,17 @@ func (r *jawabanSiswaRepository) FindByIdSiswaAndIdUjian(idSiswa uint64, idUjian
 	
 	// Using join to get jawaban siswa that match both id_siswa and id_ujian
 	err := r.db.Joins("JOIN soal ON jawaban_siswa.id_soal = soal.id_soal").
-		Where("jawaban_siswa.id_siswa = ? AND soal.id_ujian = ?", idSiswa, idUjian).
+		Where("jawaban_siswa.id_siswa = ?", idSiswa).
+		Where(jawabanUjian(r.db, idUjian)).
 		Find(&jawabanSiswaList).Error
 	
 	return jawabanSiswaList, err
 }
 
+func (r *soalRepository) WithTx(tx *gorm.DB) SoalRepository {
+	return &soalRepository{tx}
+}
+
 // FindById finds soal by id
 func (r *soalRepository) FindById(id uint64) (entity.Soal, error) {
 	var soal entity.Soal
@@ -78,16 +103,196 @@ func (r *soalRepository) FindById(id uint64) (entity.Soal, error) {
 	return soal, err
 }
 
-// FindByIdUjian finds all soal for a specific ujian
+// FindByIdWithTipeSoal finds soal by id with its tipe soal
+func (r *soalRepository) FindByIdWithTipeSoal(id uint64) (entity.Soal, error) {
+	var soal entity.Soal
+	err := r.db.Preload("TipeSoal").Where("id_soal = ?", id).First(&soal).Error
+	return soal, err
+}
+
+// FindByIdUjian finds all soal for a specific ujian, including bank soal linked through ujian_soal
+// with the ujian's nilai_per_soal applied
 func (r *soalRepository) FindByIdUjian(idUjian uint64) ([]entity.Soal, error) {
+	return r.findByIdUjian(r.db, idUjian)
+}
+
+// FindByIdUjianWithTipeSoal finds all soal for a specific ujian with their tipe soal, used for grading
+func (r *soalRepository) FindByIdUjianWithTipeSoal(idUjian uint64) ([]entity.Soal, error) {
+	return r.findByIdUjian(r.db.Preload("TipeSoal"), idUjian)
+}
+
+func (r *soalRepository) findByIdUjian(query *gorm.DB, idUjian uint64) ([]entity.Soal, error) {
 	var soalList []entity.Soal
-	err := r.db.Where("id_ujian = ?", idUjian).Find(&soalList).Error
+	err := query.Where(soalUjian(r.db, idUjian)).Order("soal.id_soal ASC").Find(&soalList).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var tautan []entity.UjianSoal
+	err = r.db.Where("id_ujian = ? AND nilai_per_soal IS NOT NULL", idUjian).Find(&tautan).Error
+	if err != nil || len(tautan) == 0 {
+		return soalList, err
+	}
+	nilai := make(map[uint64]float64, len(tautan))
+	for _, t := range tautan {
+		nilai[t.IdSoal] = *t.NilaiPerSoal
+	}
+	for i := range soalList {
+		if n, ok := nilai[soalList[i].IdSoal]; ok {
+			soalList[i].NilaiPerSoal = n
+		}
+	}
+	return soalList, nil
+}
+
+// FindByIdLatihan finds all soal for a specific latihan, including soal linked through latihan_soal
+func (r *soalRepository) FindByIdLatihan(idLatihan uint64) ([]entity.Soal, error) {
+	var soalList []entity.Soal
+	err := r.db.Where(soalLatihan(r.db, idLatihan)).Find(&soalList).Error
 	return soalList, err
 }
 
+// FindByIdLatihanWithTipeSoal finds all soal for a specific latihan with their tipe soal
+func (r *soalRepository) FindByIdLatihanWithTipeSoal(idLatihan uint64) ([]entity.Soal, error) {
+	var soalList []entity.Soal
+	err := r.db.Preload("TipeSoal").Where(soalLatihan(r.db, idLatihan)).Find(&soalList).Error
+	return soalList, err
+}
+
+// FindIdUjianBySoal returns the ujian that use the soal: its own ujian, or every ujian that links it
+// from the bank. A latihan or unused bank soal has none.
+func (r *soalRepository) FindIdUjianBySoal(idSoal uint64) ([]uint64, error) {
+	soal, err := r.FindById(idSoal)
+	if err != nil {
+		return nil, err
+	}
+	if soal.IdUjian != 0 {
+		return []uint64{soal.IdUjian}, nil
+	}
+	var idUjian []uint64
+	err = r.db.Model(&entity.UjianSoal{}).Where("id_soal = ?", idSoal).
+		Order("id_ujian ASC").Pluck("id_ujian", &idUjian).Error
+	return idUjian, err
+}
+
+// FindTipeSoalById finds a tipe soal by id
+func (r *soalRepository) FindTipeSoalById(idTipeSoal uint64) (entity.TipeSoal, error) {
+	var tipeSoal entity.TipeSoal
+	err := r.db.Where("id_tipe_soal = ?", idTipeSoal).First(&tipeSoal).Error
+	return tipeSoal, err
+}
+
+// FindAllTipeSoal finds every tipe soal
+func (r *soalRepository) FindAllTipeSoal() ([]entity.TipeSoal, error) {
+	var tipeSoalList []entity.TipeSoal
+	err := r.db.Find(&tipeSoalList).Error
+	return tipeSoalList, err
+}
+
+// SudahDijawab reports whether any siswa has answered the soal, in an ujian or a latihan
+func (r *soalRepository) SudahDijawab(idSoal uint64) (bool, error) {
+	var count int64
+	err := r.db.Model(&entity.JawabanSiswa{}).Where("id_soal = ?", idSoal).Count(&count).Error
+	return count > 0, err
+}
+
+// CreateSoal inserts a soal without its relations
+func (r *soalRepository) CreateSoal(soal entity.Soal) (entity.Soal, error) {
+	err := r.db.Omit(clause.Associations).Create(&soal).Error
+	return soal, err
+}
+
+// UpdateSoal saves every column of the soal without its relations
+func (r *soalRepository) UpdateSoal(soal entity.Soal) (entity.Soal, error) {
+	err := r.db.Omit(clause.Associations).Save(&soal).Error
+	return soal, err
+}
+
+// DeleteSoal deletes the soal together with its answer options, tags and links to ujian and latihan
+func (r *soalRepository) DeleteSoal(idSoal uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{&entity.JawabanSoal{}, &entity.SoalTag{}, &entity.UjianSoal{}, &entity.LatihanSoal{}} {
+			if err := tx.Where("id_soal = ?", idSoal).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Where("id_soal = ?", idSoal).Delete(&entity.Soal{}).Error
+	})
+}
+
+func (r *jawabanSoalRepository) WithTx(tx *gorm.DB) JawabanSoalRepository {
+	return &jawabanSoalRepository{tx}
+}
+
 // FindById finds jawaban soal by id
 func (r *jawabanSoalRepository) FindById(id uint64) (entity.JawabanSoal, error) {
 	var jawabanSoal entity.JawabanSoal
 	err := r.db.Where("id_jawaban_soal = ?", id).First(&jawabanSoal).Error
 	return jawabanSoal, err
-}
\ No newline at end of file
+}
+
+// FindByIdSoal finds all answer options of a soal
+func (r *jawabanSoalRepository) FindByIdSoal(idSoal uint64) ([]entity.JawabanSoal, error) {
+	var jawabanSoalList []entity.JawabanSoal
+	err := r.db.Where("id_soal = ?", idSoal).Find(&jawabanSoalList).Error
+	return jawabanSoalList, err
+}
+
+// FindByIdUjian finds the answer options of every soal in an ujian
+func (r *jawabanSoalRepository) FindByIdUjian(idUjian uint64) ([]entity.JawabanSoal, error) {
+	var jawabanSoalList []entity.JawabanSoal
+	err := r.db.Joins("JOIN soal ON soal.id_soal = jawaban_soal.id_soal").
+		Where(soalUjian(r.db, idUjian)).
+		Find(&jawabanSoalList).Error
+	return jawabanSoalList, err
+}
+
+// SudahDipilih reports whether any siswa has chosen the option, as the single answer or as one of
+// the options of a Pilihan_Kompleks answer
+func (r *jawabanSoalRepository) SudahDipilih(id uint64) (bool, error) {
+	var count int64
+	err := r.db.Model(&entity.JawabanSiswa{}).Where("id_jawaban_soal = ?", id).Count(&count).Error
+	if err != nil || count > 0 {
+		return count > 0, err
+	}
+	err = r.db.Model(&entity.JawabanSiswaPilihan{}).Where("id_jawaban_soal = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+// CreateJawabanSoal inserts an answer option without its relations
+func (r *jawabanSoalRepository) CreateJawabanSoal(jawabanSoal entity.JawabanSoal) (entity.JawabanSoal, error) {
+	err := r.db.Omit(clause.Associations).Create(&jawabanSoal).Error
+	return jawabanSoal, err
+}
+
+// UpdateJawabanSoal saves every column of the answer option, including Benar false
+func (r *jawabanSoalRepository) UpdateJawabanSoal(jawabanSoal entity.JawabanSoal) (entity.JawabanSoal, error) {
+	err := r.db.Omit(clause.Associations).Save(&jawabanSoal).Error
+	return jawabanSoal, err
+}
+
+func (r *jawabanSoalRepository) DeleteJawabanSoal(id uint64) error {
+	return r.db.Where("id_jawaban_soal = ?", id).Delete(&entity.JawabanSoal{}).Error
+}
+
+// soalUjian is the condition on the soal table for the soal of an ujian: soal it owns through
+// soal.id_ujian and bank soal linked through ujian_soal
+func soalUjian(db *gorm.DB, idUjian uint64) *gorm.DB {
+	return db.Where("soal.id_ujian = ? OR soal.id_soal IN (?)", idUjian,
+		db.Model(&entity.UjianSoal{}).Select("id_soal").Where("id_ujian = ?", idUjian))
+}
+
+// soalLatihan is the condition on the soal table for the soal of a latihan, owned through
+// soal.id_latihan or linked through latihan_soal
+func soalLatihan(db *gorm.DB, idLatihan uint64) *gorm.DB {
+	return db.Where("soal.id_latihan = ? OR soal.id_soal IN (?)", idLatihan,
+		db.Model(&entity.LatihanSoal{}).Select("id_soal").Where("id_latihan = ?", idLatihan))
+}
+
+// jawabanUjian is the condition on jawaban_siswa for the answers given in an ujian: answers saved in
+// one of its attempts, which covers linked bank soal. Answers without an attempt are latihan answers
+// and never count for an ujian.
+func jawabanUjian(db *gorm.DB, idUjian uint64) *gorm.DB {
+	return db.Where("jawaban_siswa.id_ujian_attempt IN (?)",
+		db.Model(&entity.UjianAttempt{}).Select("id_ujian_attempt").Where("id_ujian = ?", idUjian))
+}
@@ -0,0 +1,35 @@
+// repository/idempotency_key_repository.go
+package repository
+
+import (
+	"cbt-api/entity"
+	"gorm.io/gorm"
+)
+
+// IdempotencyKeyRepository is a contract for stored responses of idempotent requests
+type IdempotencyKeyRepository interface {
+	FindByUserAndKey(userID uint64, key string) (entity.IdempotencyKey, error)
+	CreateIdempotencyKey(record entity.IdempotencyKey) (entity.IdempotencyKey, error)
+}
+
+type idempotencyKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewIdempotencyKeyRepository creates a new instance of IdempotencyKeyRepository
+func NewIdempotencyKeyRepository(db *gorm.DB) IdempotencyKeyRepository {
+	return &idempotencyKeyRepository{
+		db: db,
+	}
+}
+
+func (r *idempotencyKeyRepository) FindByUserAndKey(userID uint64, key string) (entity.IdempotencyKey, error) {
+	var record entity.IdempotencyKey
+	err := r.db.Where("id_user = ? AND idempotency_key = ?", userID, key).Take(&record).Error
+	return record, err
+}
+
+func (r *idempotencyKeyRepository) CreateIdempotencyKey(record entity.IdempotencyKey) (entity.IdempotencyKey, error) {
+	err := r.db.Create(&record).Error
+	return record, err
+}
@@ -12,6 +12,7 @@ type JawabanLatihanRepository interface {
 	GetJawabanLatihanByID(id uint64) (entity.JawabanSiswa, error)
 	GetJawabanLatihanBySiswaID(siswaID uint64) ([]entity.JawabanSiswa, error)
 	GetJawabanLatihanByLatihanID(latihanID uint64) ([]entity.JawabanSiswa, error)
+	GetJawabanLatihanByLatihanIDAndSiswaID(latihanID uint64, siswaID uint64) ([]entity.JawabanSiswa, error)
 }
 
 // SoalLatihanRepository is a contract for soal latihan repository
@@ -98,11 +99,21 @@ func (r *jawabanLatihanRepository) GetJawabanLatihanByLatihanID(latihanID uint64
 	return jawabanSiswa, err
 }
 
+func (r *jawabanLatihanRepository) GetJawabanLatihanByLatihanIDAndSiswaID(latihanID uint64, siswaID uint64) ([]entity.JawabanSiswa, error) {
+	var jawabanSiswa []entity.JawabanSiswa
+	err := r.db.Joins("JOIN soal ON jawaban_siswa.id_soal = soal.id_soal").
+		Joins("JOIN latihan_soal ON soal.id_soal = latihan_soal.id_soal").
+		Preload("Soal").Preload("Siswa").Preload("JawabanSoal").
+		Where("latihan_soal.id_latihan = ? AND jawaban_siswa.id_siswa = ?", latihanID, siswaID).
+		Find(&jawabanSiswa).Error
+	return jawabanSiswa, err
+}
+
 // SoalLatihanRepository functions to handle soal-related operations
 
 func (r *soalLatihanRepository) GetSoalLatihanByID(latihanID uint64) ([]entity.Soal, error) {
 	var soalList []entity.Soal
-	err := r.db.Joins("JOIN latihan_soal ON soal.id_soal = latihan_soal.id_soal").
+	err := r.db.Preload("TipeSoal").Joins("JOIN latihan_soal ON soal.id_soal = latihan_soal.id_soal").
 		Where("latihan_soal.id_latihan = ?", latihanID).
 		Find(&soalList).Error
 	return soalList, err
@@ -3,17 +3,27 @@ package repository
 
 import (
 	"cbt-api/entity"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // JawabanSiswaRepository is a contract for jawaban siswa repository
 type JawabanSiswaRepository interface {
+	WithTx(tx *gorm.DB) JawabanSiswaRepository
 	CreateJawabanSiswa(jawabanSiswa entity.JawabanSiswa) (entity.JawabanSiswa, error)
 	CreateBatchJawabanSiswa(jawabanList []entity.JawabanSiswa) ([]entity.JawabanSiswa, error)
+	UpsertJawabanSiswa(jawabanSiswa entity.JawabanSiswa) (entity.JawabanSiswa, error)
 	GetJawabanSiswaByID(id uint64) (entity.JawabanSiswa, error)
 	GetJawabanSiswaBySiswaID(siswaID uint64) ([]entity.JawabanSiswa, error)
 	GetJawabanSiswaByUjianID(ujianID uint64) ([]entity.JawabanSiswa, error)
 	GetJawabanSiswaByUjianIDAndSiswaID(ujianID uint64, siswaID uint64) ([]entity.JawabanSiswa, error)
+	GetJawabanSiswaWithSoal(ujianID uint64, siswaID uint64) ([]entity.JawabanSiswa, error)
+	FindByUjianAndSiswa(ujianID uint64, siswaID uint64) ([]entity.JawabanSiswa, error)
+	FindByAttempt(attemptID uint64) ([]entity.JawabanSiswa, error)
+	FindByUjian(ujianID uint64) ([]entity.JawabanSiswa, error)
+	GetAntrianIsian(ujianID uint64, sudahDinilai *bool) ([]entity.JawabanSiswa, error)
+	SaveNilaiManual(jawabanSiswa entity.JawabanSiswa) error
 }
 
 type jawabanSiswaRepository struct {
@@ -27,6 +37,10 @@ func NewJawabanSiswaRepository(db *gorm.DB) JawabanSiswaRepository {
 	}
 }
 
+func (r *jawabanSiswaRepository) WithTx(tx *gorm.DB) JawabanSiswaRepository {
+	return &jawabanSiswaRepository{db: tx}
+}
+
 func (r *jawabanSiswaRepository) CreateJawabanSiswa(jawabanSiswa entity.JawabanSiswa) (entity.JawabanSiswa, error) {
 	err := r.db.Create(&jawabanSiswa).Error
 	return jawabanSiswa, err
@@ -37,23 +51,83 @@ func (r *jawabanSiswaRepository) CreateBatchJawabanSiswa(jawabanList []entity.Ja
 	return jawabanList, err
 }
 
+// UpsertJawabanSiswa saves the answer of an ujian attempt: a soal answered again in the same
+// attempt overwrites the previous answer instead of adding a row. Answers without an attempt
+// (latihan) never conflict and are always inserted. The chosen options of a Pilihan_Kompleks soal
+// replace the previously saved ones. The guru's grading belongs to the previous answer and is cleared.
+func (r *jawabanSiswaRepository) UpsertJawabanSiswa(jawabanSiswa entity.JawabanSiswa) (entity.JawabanSiswa, error) {
+	pilihan := jawabanSiswa.Pilihan
+	jawabanSiswa.NilaiManual = nil
+	jawabanSiswa.Feedback = ""
+	jawabanSiswa.DinilaiOleh = nil
+	jawabanSiswa.DinilaiPada = nil
+	saved := jawabanSiswa
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Omit(clause.Associations).Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "id_ujian_attempt"}, {Name: "id_soal"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"jawaban_text", "id_jawaban_soal", "nilai_manual", "feedback", "dinilai_oleh", "dinilai_pada", "updated_at",
+			}),
+		}).Create(&jawabanSiswa).Error
+		if err != nil {
+			return err
+		}
+
+		// Saat terjadi update, id hasil insert tidak bisa dipercaya; ambil ulang baris yang tersimpan
+		saved = jawabanSiswa
+		if jawabanSiswa.IdUjianAttempt != nil {
+			err = tx.Where("id_ujian_attempt = ? AND id_soal = ?", *jawabanSiswa.IdUjianAttempt, jawabanSiswa.IdSoal).
+				Take(&saved).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		saved.Pilihan, err = gantiPilihan(tx, saved.IdJawabanSiswa, pilihan)
+		return err
+	})
+	return saved, err
+}
+
+// gantiPilihan replaces the chosen options of an answer; an option listed twice is saved once
+func gantiPilihan(tx *gorm.DB, jawabanSiswaID uint64, pilihan []entity.JawabanSiswaPilihan) ([]entity.JawabanSiswaPilihan, error) {
+	if err := tx.Where("id_jawaban_siswa = ?", jawabanSiswaID).Delete(&entity.JawabanSiswaPilihan{}).Error; err != nil {
+		return nil, err
+	}
+
+	baru := make([]entity.JawabanSiswaPilihan, 0, len(pilihan))
+	sudahAda := make(map[uint64]bool, len(pilihan))
+	for _, p := range pilihan {
+		if sudahAda[p.IdJawabanSoal] {
+			continue
+		}
+		sudahAda[p.IdJawabanSoal] = true
+		baru = append(baru, entity.JawabanSiswaPilihan{IdJawabanSiswa: jawabanSiswaID, IdJawabanSoal: p.IdJawabanSoal})
+	}
+	if len(baru) == 0 {
+		return nil, nil
+	}
+	err := tx.Create(&baru).Error
+	return baru, err
+}
+
 func (r *jawabanSiswaRepository) GetJawabanSiswaByID(id uint64) (entity.JawabanSiswa, error) {
 	var jawabanSiswa entity.JawabanSiswa
-	err := r.db.Preload("Soal").Preload("Siswa").Preload("JawabanSoal").Where("id_jawaban_siswa = ?", id).Take(&jawabanSiswa).Error
+	err := r.db.Preload("Soal").Preload("Siswa").Preload("JawabanSoal").Preload("Pilihan").Where("id_jawaban_siswa = ?", id).Take(&jawabanSiswa).Error
 	return jawabanSiswa, err
 }
 
 func (r *jawabanSiswaRepository) GetJawabanSiswaBySiswaID(siswaID uint64) ([]entity.JawabanSiswa, error) {
 	var jawabanSiswa []entity.JawabanSiswa
-	err := r.db.Preload("Soal").Preload("Siswa").Preload("JawabanSoal").Where("id_siswa = ?", siswaID).Find(&jawabanSiswa).Error
+	err := r.db.Preload("Soal").Preload("Siswa").Preload("JawabanSoal").Preload("Pilihan").Where("id_siswa = ?", siswaID).Find(&jawabanSiswa).Error
 	return jawabanSiswa, err
 }
 
 func (r *jawabanSiswaRepository) GetJawabanSiswaByUjianID(ujianID uint64) ([]entity.JawabanSiswa, error) {
 	var jawabanSiswa []entity.JawabanSiswa
 	err := r.db.Joins("JOIN soal ON jawaban_siswa.id_soal = soal.id_soal").
-		Preload("Soal").Preload("Siswa").Preload("JawabanSoal").
-		Where("soal.id_ujian = ?", ujianID).
+		Preload("Soal").Preload("Siswa").Preload("JawabanSoal").Preload("Pilihan").
+		Where(jawabanUjian(r.db, ujianID)).
 		Find(&jawabanSiswa).Error
 	return jawabanSiswa, err
 }
@@ -61,10 +135,96 @@ func (r *jawabanSiswaRepository) GetJawabanSiswaByUjianID(ujianID uint64) ([]ent
 func (r *jawabanSiswaRepository) GetJawabanSiswaByUjianIDAndSiswaID(ujianID uint64, siswaID uint64) ([]entity.JawabanSiswa, error) {
 	var jawabanSiswa []entity.JawabanSiswa
 	err := r.db.Joins("JOIN soal ON jawaban_siswa.id_soal = soal.id_soal").
-		Joins("JOIN jawaban_soal ON jawaban_siswa.id_jawaban_soal = jawaban_soal.id_jawaban_soal").
-		Where("soal.id_ujian = ? AND jawaban_siswa.id_siswa = ?", ujianID, siswaID).
+		Joins("LEFT JOIN jawaban_soal ON jawaban_siswa.id_jawaban_soal = jawaban_soal.id_jawaban_soal").
+		Where("jawaban_siswa.id_siswa = ?", siswaID).
+		Where(jawabanUjian(r.db, ujianID)).
 		Preload("Soal").
 		Preload("JawabanSoal").
+		Preload("Pilihan").
 		Find(&jawabanSiswa).Error
 	return jawabanSiswa, err
-}
\ No newline at end of file
+}
+
+// GetJawabanSiswaWithSoal returns every answer of the siswa in the ujian, including answers
+// without a chosen option, with soal, jawaban soal and siswa preloaded
+func (r *jawabanSiswaRepository) GetJawabanSiswaWithSoal(ujianID uint64, siswaID uint64) ([]entity.JawabanSiswa, error) {
+	var jawabanSiswa []entity.JawabanSiswa
+	err := r.db.
+		Joins("JOIN soal ON soal.id_soal = jawaban_siswa.id_soal").
+		Joins("LEFT JOIN jawaban_soal ON jawaban_soal.id_jawaban_soal = jawaban_siswa.id_jawaban_soal").
+		Joins("LEFT JOIN siswa ON siswa.id_siswa = jawaban_siswa.id_siswa").
+		Preload("Soal").
+		Preload("JawabanSoal").
+		Preload("Pilihan").
+		Preload("Siswa").
+		Where("jawaban_siswa.id_siswa = ?", siswaID).
+		Where(jawabanUjian(r.db, ujianID)).
+		Find(&jawabanSiswa).Error
+	return jawabanSiswa, err
+}
+
+// FindByUjianAndSiswa returns the siswa's raw answers in the ujian, oldest first, with only the
+// chosen options preloaded
+func (r *jawabanSiswaRepository) FindByUjianAndSiswa(ujianID uint64, siswaID uint64) ([]entity.JawabanSiswa, error) {
+	var jawabanSiswa []entity.JawabanSiswa
+	err := r.db.Preload("Pilihan").Joins("JOIN soal ON soal.id_soal = jawaban_siswa.id_soal").
+		Where("jawaban_siswa.id_siswa = ?", siswaID).
+		Where(jawabanUjian(r.db, ujianID)).
+		Order("jawaban_siswa.id_jawaban_siswa ASC").
+		Find(&jawabanSiswa).Error
+	return jawabanSiswa, err
+}
+
+// FindByAttempt returns the answers saved so far in one ujian attempt, with only the chosen options preloaded
+func (r *jawabanSiswaRepository) FindByAttempt(attemptID uint64) ([]entity.JawabanSiswa, error) {
+	var jawabanSiswa []entity.JawabanSiswa
+	err := r.db.Preload("Pilihan").Where("id_ujian_attempt = ?", attemptID).
+		Order("id_soal ASC").
+		Find(&jawabanSiswa).Error
+	return jawabanSiswa, err
+}
+
+// FindByUjian returns the raw answers of every siswa in the ujian, oldest first, with only the chosen
+// options preloaded
+func (r *jawabanSiswaRepository) FindByUjian(ujianID uint64) ([]entity.JawabanSiswa, error) {
+	var jawabanSiswa []entity.JawabanSiswa
+	err := r.db.Preload("Pilihan").Joins("JOIN soal ON soal.id_soal = jawaban_siswa.id_soal").
+		Where(jawabanUjian(r.db, ujianID)).
+		Order("jawaban_siswa.id_jawaban_siswa ASC").
+		Find(&jawabanSiswa).Error
+	return jawabanSiswa, err
+}
+
+// GetAntrianIsian returns the answers to Isian soal of the ujian, oldest first, with soal and siswa
+// preloaded. sudahDinilai filters on whether a guru has graded the answer; nil returns both.
+func (r *jawabanSiswaRepository) GetAntrianIsian(ujianID uint64, sudahDinilai *bool) ([]entity.JawabanSiswa, error) {
+	var jawabanSiswa []entity.JawabanSiswa
+	query := r.db.
+		Joins("JOIN soal ON soal.id_soal = jawaban_siswa.id_soal").
+		Joins("JOIN tipe_soal ON tipe_soal.id_tipe_soal = soal.id_tipe_soal").
+		Where("tipe_soal.nama_tipe_ujian = ?", entity.TipeSoalIsian).
+		Where(jawabanUjian(r.db, ujianID))
+	if sudahDinilai != nil && *sudahDinilai {
+		query = query.Where("jawaban_siswa.nilai_manual IS NOT NULL")
+	} else if sudahDinilai != nil {
+		query = query.Where("jawaban_siswa.nilai_manual IS NULL")
+	}
+	err := query.
+		Preload("Soal").
+		Preload("Siswa").
+		Order("jawaban_siswa.id_jawaban_siswa ASC").
+		Find(&jawabanSiswa).Error
+	return jawabanSiswa, err
+}
+
+// SaveNilaiManual stores only the manual grading columns of the answer
+func (r *jawabanSiswaRepository) SaveNilaiManual(jawabanSiswa entity.JawabanSiswa) error {
+	return r.db.Model(&entity.JawabanSiswa{}).
+		Where("id_jawaban_siswa = ?", jawabanSiswa.IdJawabanSiswa).
+		Updates(map[string]interface{}{
+			"nilai_manual": jawabanSiswa.NilaiManual,
+			"feedback":     jawabanSiswa.Feedback,
+			"dinilai_oleh": jawabanSiswa.DinilaiOleh,
+			"dinilai_pada": jawabanSiswa.DinilaiPada,
+		}).Error
+}
@@ -0,0 +1,89 @@
+// repository/kursus_repository.go
+package repository
+
+import (
+	"cbt-api/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// KursusRepository is a contract for kursus, kursus siswa and materi repository
+type KursusRepository interface {
+	GetKursusByID(kursusID uint64) (entity.Kursus, error)
+	GetAvailableKursus(siswaID uint64) ([]entity.Kursus, error)
+	GetMateriByKursusID(kursusID uint64) ([]entity.Materi, error)
+	GetAllKursusSiswa() ([]entity.KursusSiswa, error)
+	GetKursusSiswaByID(id uint64) (entity.KursusSiswa, error)
+	GetKursusSiswaBySiswaID(siswaID uint64) ([]entity.KursusSiswa, error)
+	CreateKursusSiswa(kursusSiswa entity.KursusSiswa) (entity.KursusSiswa, error)
+	UpdateKursusSiswa(kursusSiswa entity.KursusSiswa) (entity.KursusSiswa, error)
+	DeleteKursusSiswa(id uint64) error
+}
+
+type kursusRepository struct {
+	db *gorm.DB
+}
+
+// NewKursusRepository creates a new instance of KursusRepository
+func NewKursusRepository(db *gorm.DB) KursusRepository {
+	return &kursusRepository{
+		db: db,
+	}
+}
+
+func (r *kursusRepository) GetKursusByID(kursusID uint64) (entity.Kursus, error) {
+	var kursus entity.Kursus
+	err := r.db.Where("id_kursus = ?", kursusID).First(&kursus).Error
+	return kursus, err
+}
+
+// GetAvailableKursus returns the kursus the siswa has not enrolled in yet
+func (r *kursusRepository) GetAvailableKursus(siswaID uint64) ([]entity.Kursus, error) {
+	var kursusList []entity.Kursus
+	err := r.db.Table("kursus").
+		Where("kursus.id_kursus NOT IN (?)",
+			r.db.Table("kursus_siswa").
+				Select("id_kursus").
+				Where("id_siswa = ?", siswaID),
+		).Find(&kursusList).Error
+	return kursusList, err
+}
+
+func (r *kursusRepository) GetMateriByKursusID(kursusID uint64) ([]entity.Materi, error) {
+	var materiList []entity.Materi
+	err := r.db.Where("id_kursus = ?", kursusID).Find(&materiList).Error
+	return materiList, err
+}
+
+func (r *kursusRepository) GetAllKursusSiswa() ([]entity.KursusSiswa, error) {
+	var kursusSiswa []entity.KursusSiswa
+	err := r.db.Preload("Kursus").Preload("Siswa").Find(&kursusSiswa).Error
+	return kursusSiswa, err
+}
+
+func (r *kursusRepository) GetKursusSiswaByID(id uint64) (entity.KursusSiswa, error) {
+	var kursusSiswa entity.KursusSiswa
+	err := r.db.Preload("Kursus").Preload("Siswa").First(&kursusSiswa, id).Error
+	return kursusSiswa, err
+}
+
+func (r *kursusRepository) GetKursusSiswaBySiswaID(siswaID uint64) ([]entity.KursusSiswa, error) {
+	var kursusSiswa []entity.KursusSiswa
+	err := r.db.Preload("Kursus").Where("id_siswa = ?", siswaID).Find(&kursusSiswa).Error
+	return kursusSiswa, err
+}
+
+func (r *kursusRepository) CreateKursusSiswa(kursusSiswa entity.KursusSiswa) (entity.KursusSiswa, error) {
+	err := r.db.Create(&kursusSiswa).Error
+	return kursusSiswa, err
+}
+
+// UpdateKursusSiswa only saves the kursus_siswa row, never the preloaded kursus or siswa
+func (r *kursusRepository) UpdateKursusSiswa(kursusSiswa entity.KursusSiswa) (entity.KursusSiswa, error) {
+	err := r.db.Omit(clause.Associations).Save(&kursusSiswa).Error
+	return kursusSiswa, err
+}
+
+func (r *kursusRepository) DeleteKursusSiswa(id uint64) error {
+	return r.db.Delete(&entity.KursusSiswa{}, id).Error
+}
@@ -0,0 +1,62 @@
+// repository/latihan_repository.go
+package repository
+
+import (
+	"cbt-api/entity"
+	"gorm.io/gorm"
+)
+
+// LatihanRepository is a contract for latihan repository
+type LatihanRepository interface {
+	GetLatihanByFilter(kurikulumID uint64, kelasID uint64, mataPelajaranID uint64) ([]entity.Latihan, error)
+	GetLatihanByID(latihanID uint64) (entity.Latihan, error)
+	SudahDikerjakan(latihanID uint64, siswaID uint64) (bool, error)
+	GetLatihanBySoalID(soalID uint64) ([]entity.Latihan, error)
+}
+
+type latihanRepository struct {
+	db *gorm.DB
+}
+
+// NewLatihanRepository creates a new instance of LatihanRepository
+func NewLatihanRepository(db *gorm.DB) LatihanRepository {
+	return &latihanRepository{
+		db: db,
+	}
+}
+
+func (r *latihanRepository) GetLatihanByFilter(kurikulumID uint64, kelasID uint64, mataPelajaranID uint64) ([]entity.Latihan, error) {
+	var latihanList []entity.Latihan
+	err := r.db.Where("id_kurikulum = ? AND id_kelas = ? AND id_mata_pelajaran = ?", kurikulumID, kelasID, mataPelajaranID).
+		Find(&latihanList).Error
+	return latihanList, err
+}
+
+func (r *latihanRepository) GetLatihanByID(latihanID uint64) (entity.Latihan, error) {
+	var latihan entity.Latihan
+	err := r.db.Where("id_latihan = ?", latihanID).Take(&latihan).Error
+	return latihan, err
+}
+
+// SudahDikerjakan reports whether the siswa has submitted any answer to a soal of the latihan,
+// whether the soal is linked through soal.id_latihan or latihan_soal
+func (r *latihanRepository) SudahDikerjakan(latihanID uint64, siswaID uint64) (bool, error) {
+	var count int64
+	err := r.db.Model(&entity.JawabanSiswa{}).
+		Joins("JOIN soal ON soal.id_soal = jawaban_siswa.id_soal").
+		Where("jawaban_siswa.id_siswa = ? AND jawaban_siswa.id_ujian_attempt IS NULL", siswaID).
+		Where("soal.id_latihan = ? OR soal.id_soal IN (?)", latihanID,
+			r.db.Model(&entity.LatihanSoal{}).Select("id_soal").Where("id_latihan = ?", latihanID)).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetLatihanBySoalID finds every latihan that contains the soal, through soal.id_latihan or latihan_soal
+func (r *latihanRepository) GetLatihanBySoalID(soalID uint64) ([]entity.Latihan, error) {
+	var latihanList []entity.Latihan
+	err := r.db.Where("id_latihan IN (?) OR id_latihan IN (?)",
+		r.db.Model(&entity.Soal{}).Select("id_latihan").Where("id_soal = ?", soalID),
+		r.db.Model(&entity.LatihanSoal{}).Select("id_latihan").Where("id_soal = ?", soalID)).
+		Find(&latihanList).Error
+	return latihanList, err
+}
@@ -0,0 +1,72 @@
+// repository/nilai_kursus_repository.go
+package repository
+
+import (
+	"cbt-api/entity"
+	"gorm.io/gorm"
+)
+
+// NilaiKursusRepository is a contract for the weighted nilai per tipe ujian and the persentase behind it
+type NilaiKursusRepository interface {
+	WithTx(tx *gorm.DB) NilaiKursusRepository
+	GetNilaiKursusByKursusAndSiswa(kursusID uint64, siswaID uint64) ([]entity.NilaiKursus, error)
+	FindNilaiKursus(kursusID uint64, siswaID uint64, tipeUjianID uint64) (entity.NilaiKursus, error)
+	GetSiswaIDsByKursus(kursusID uint64) ([]uint64, error)
+	GetPersentase(kursusID uint64, tipeUjianID uint64) (entity.Persentase, error)
+	CreateNilaiKursus(nilaiKursus entity.NilaiKursus) (entity.NilaiKursus, error)
+	UpdateNilaiKursus(nilaiKursus entity.NilaiKursus) (entity.NilaiKursus, error)
+}
+
+type nilaiKursusRepository struct {
+	db *gorm.DB
+}
+
+// NewNilaiKursusRepository creates a new instance of NilaiKursusRepository
+func NewNilaiKursusRepository(db *gorm.DB) NilaiKursusRepository {
+	return &nilaiKursusRepository{
+		db: db,
+	}
+}
+
+func (r *nilaiKursusRepository) WithTx(tx *gorm.DB) NilaiKursusRepository {
+	return &nilaiKursusRepository{db: tx}
+}
+
+func (r *nilaiKursusRepository) GetNilaiKursusByKursusAndSiswa(kursusID uint64, siswaID uint64) ([]entity.NilaiKursus, error) {
+	var nilaiKursus []entity.NilaiKursus
+	err := r.db.Where("id_kursus = ? AND id_siswa = ?", kursusID, siswaID).Find(&nilaiKursus).Error
+	return nilaiKursus, err
+}
+
+func (r *nilaiKursusRepository) FindNilaiKursus(kursusID uint64, siswaID uint64, tipeUjianID uint64) (entity.NilaiKursus, error) {
+	var nilaiKursus entity.NilaiKursus
+	err := r.db.Where("id_kursus = ? AND id_siswa = ? AND id_tipe_ujian = ?", kursusID, siswaID, tipeUjianID).
+		First(&nilaiKursus).Error
+	return nilaiKursus, err
+}
+
+// GetSiswaIDsByKursus returns every siswa that has at least one nilai_kursus in the kursus
+func (r *nilaiKursusRepository) GetSiswaIDsByKursus(kursusID uint64) ([]uint64, error) {
+	var siswaIDs []uint64
+	err := r.db.Model(&entity.NilaiKursus{}).
+		Where("id_kursus = ?", kursusID).
+		Distinct("id_siswa").
+		Pluck("id_siswa", &siswaIDs).Error
+	return siswaIDs, err
+}
+
+func (r *nilaiKursusRepository) GetPersentase(kursusID uint64, tipeUjianID uint64) (entity.Persentase, error) {
+	var persentase entity.Persentase
+	err := r.db.Where("id_tipe_ujian = ? AND id_kursus = ?", tipeUjianID, kursusID).First(&persentase).Error
+	return persentase, err
+}
+
+func (r *nilaiKursusRepository) CreateNilaiKursus(nilaiKursus entity.NilaiKursus) (entity.NilaiKursus, error) {
+	err := r.db.Create(&nilaiKursus).Error
+	return nilaiKursus, err
+}
+
+func (r *nilaiKursusRepository) UpdateNilaiKursus(nilaiKursus entity.NilaiKursus) (entity.NilaiKursus, error) {
+	err := r.db.Save(&nilaiKursus).Error
+	return nilaiKursus, err
+}
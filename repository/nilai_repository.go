@@ -0,0 +1,74 @@
+// repository/nilai_repository.go
+package repository
+
+import (
+	"cbt-api/entity"
+	"gorm.io/gorm"
+)
+
+// NilaiRepository is a contract for the final nilai of a siswa in a kursus
+type NilaiRepository interface {
+	WithTx(tx *gorm.DB) NilaiRepository
+	GetNilaiByKursusAndSiswa(kursusID uint64, siswaID uint64) ([]entity.Nilai, error)
+	FindNilai(kursusID uint64, siswaID uint64) (entity.Nilai, error)
+	SumNilaiTotal(kursusID uint64, siswaID uint64) (float64, int64, error)
+	CreateNilai(nilai entity.Nilai) (entity.Nilai, error)
+	UpdateNilai(nilai entity.Nilai) (entity.Nilai, error)
+}
+
+type nilaiRepository struct {
+	db *gorm.DB
+}
+
+// NewNilaiRepository creates a new instance of NilaiRepository
+func NewNilaiRepository(db *gorm.DB) NilaiRepository {
+	return &nilaiRepository{
+		db: db,
+	}
+}
+
+func (r *nilaiRepository) WithTx(tx *gorm.DB) NilaiRepository {
+	return &nilaiRepository{db: tx}
+}
+
+func (r *nilaiRepository) GetNilaiByKursusAndSiswa(kursusID uint64, siswaID uint64) ([]entity.Nilai, error) {
+	var nilai []entity.Nilai
+	err := r.db.Preload("Kursus").Preload("Siswa").
+		Where("id_kursus = ? AND id_siswa = ?", kursusID, siswaID).
+		Find(&nilai).Error
+	return nilai, err
+}
+
+func (r *nilaiRepository) FindNilai(kursusID uint64, siswaID uint64) (entity.Nilai, error) {
+	var nilai entity.Nilai
+	err := r.db.Where("id_kursus = ? AND id_siswa = ?", kursusID, siswaID).First(&nilai).Error
+	return nilai, err
+}
+
+// SumNilaiTotal returns the sum of nilai_total and the number of nilai rows
+func (r *nilaiRepository) SumNilaiTotal(kursusID uint64, siswaID uint64) (float64, int64, error) {
+	var total float64
+	err := r.db.Model(&entity.Nilai{}).
+		Where("id_kursus = ? AND id_siswa = ?", kursusID, siswaID).
+		Select("COALESCE(SUM(nilai_total), 0)").
+		Scan(&total).Error
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var jumlah int64
+	err = r.db.Model(&entity.Nilai{}).
+		Where("id_kursus = ? AND id_siswa = ?", kursusID, siswaID).
+		Count(&jumlah).Error
+	return total, jumlah, err
+}
+
+func (r *nilaiRepository) CreateNilai(nilai entity.Nilai) (entity.Nilai, error) {
+	err := r.db.Create(&nilai).Error
+	return nilai, err
+}
+
+func (r *nilaiRepository) UpdateNilai(nilai entity.Nilai) (entity.Nilai, error) {
+	err := r.db.Save(&nilai).Error
+	return nilai, err
+}
@@ -0,0 +1,43 @@
+// repository/referensi_repository.go
+package repository
+
+import (
+	"cbt-api/entity"
+	"gorm.io/gorm"
+)
+
+// ReferensiRepository is a contract for reference data: kelas, kurikulum and mata pelajaran
+type ReferensiRepository interface {
+	GetAllKelas() ([]entity.Kelas, error)
+	GetAllKurikulum() ([]entity.Kurikulum, error)
+	GetMataPelajaranByKurikulumID(kurikulumID uint64) ([]entity.MataPelajaran, error)
+}
+
+type referensiRepository struct {
+	db *gorm.DB
+}
+
+// NewReferensiRepository creates a new instance of ReferensiRepository
+func NewReferensiRepository(db *gorm.DB) ReferensiRepository {
+	return &referensiRepository{
+		db: db,
+	}
+}
+
+func (r *referensiRepository) GetAllKelas() ([]entity.Kelas, error) {
+	var kelasList []entity.Kelas
+	err := r.db.Find(&kelasList).Error
+	return kelasList, err
+}
+
+func (r *referensiRepository) GetAllKurikulum() ([]entity.Kurikulum, error) {
+	var kurikulumList []entity.Kurikulum
+	err := r.db.Find(&kurikulumList).Error
+	return kurikulumList, err
+}
+
+func (r *referensiRepository) GetMataPelajaranByKurikulumID(kurikulumID uint64) ([]entity.MataPelajaran, error) {
+	var mataPelajaranList []entity.MataPelajaran
+	err := r.db.Where("id_kurikulum = ?", kurikulumID).Find(&mataPelajaranList).Error
+	return mataPelajaranList, err
+}
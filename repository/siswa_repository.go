@@ -0,0 +1,36 @@
+// repository/siswa_repository.go
+package repository
+
+import (
+	"cbt-api/entity"
+	"gorm.io/gorm"
+)
+
+// SiswaRepository is a contract for siswa repository
+type SiswaRepository interface {
+	GetSiswaByID(siswaID uint64) (entity.Siswa, error)
+	GetSiswaWithKelas(siswaID uint64) (entity.Siswa, error)
+}
+
+type siswaRepository struct {
+	db *gorm.DB
+}
+
+// NewSiswaRepository creates a new instance of SiswaRepository
+func NewSiswaRepository(db *gorm.DB) SiswaRepository {
+	return &siswaRepository{
+		db: db,
+	}
+}
+
+func (r *siswaRepository) GetSiswaByID(siswaID uint64) (entity.Siswa, error) {
+	var siswa entity.Siswa
+	err := r.db.Where("id_siswa = ?", siswaID).First(&siswa).Error
+	return siswa, err
+}
+
+func (r *siswaRepository) GetSiswaWithKelas(siswaID uint64) (entity.Siswa, error) {
+	var siswa entity.Siswa
+	err := r.db.Preload("Kelas").First(&siswa, "id_siswa = ?", siswaID).Error
+	return siswa, err
+}
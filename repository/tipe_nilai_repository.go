@@ -0,0 +1,93 @@
+// repository/tipe_nilai_repository.go
+package repository
+
+import (
+	"cbt-api/entity"
+	"gorm.io/gorm"
+)
+
+// TipeNilaiRepository is a contract for the nilai a siswa got in one ujian
+type TipeNilaiRepository interface {
+	WithTx(tx *gorm.DB) TipeNilaiRepository
+	FindTipeNilai(siswaID uint64, ujianID uint64, tipeUjianID uint64) (entity.TipeNilai, error)
+	CountBySiswaAndUjian(siswaID uint64, ujianID uint64) (int64, error)
+	GetTipeNilaiByKursusAndSiswa(kursusID uint64, siswaID uint64) ([]entity.TipeNilai, error)
+	GetTipeUjianIDsBySiswa(kursusID uint64, siswaID uint64) ([]uint64, error)
+	SumNilaiByTipeUjian(kursusID uint64, siswaID uint64, tipeUjianID uint64) (float64, error)
+	CreateTipeNilai(tipeNilai entity.TipeNilai) (entity.TipeNilai, error)
+	UpdateTipeNilai(tipeNilai entity.TipeNilai) (entity.TipeNilai, error)
+}
+
+type tipeNilaiRepository struct {
+	db *gorm.DB
+}
+
+// NewTipeNilaiRepository creates a new instance of TipeNilaiRepository
+func NewTipeNilaiRepository(db *gorm.DB) TipeNilaiRepository {
+	return &tipeNilaiRepository{
+		db: db,
+	}
+}
+
+func (r *tipeNilaiRepository) WithTx(tx *gorm.DB) TipeNilaiRepository {
+	return &tipeNilaiRepository{db: tx}
+}
+
+func (r *tipeNilaiRepository) FindTipeNilai(siswaID uint64, ujianID uint64, tipeUjianID uint64) (entity.TipeNilai, error) {
+	var tipeNilai entity.TipeNilai
+	err := r.db.Where("id_siswa = ? AND id_ujian = ? AND id_tipe_ujian = ?", siswaID, ujianID, tipeUjianID).
+		First(&tipeNilai).Error
+	return tipeNilai, err
+}
+
+func (r *tipeNilaiRepository) CountBySiswaAndUjian(siswaID uint64, ujianID uint64) (int64, error) {
+	var count int64
+	err := r.db.Model(&entity.TipeNilai{}).
+		Where("id_ujian = ? AND id_siswa = ?", ujianID, siswaID).
+		Count(&count).Error
+	return count, err
+}
+
+// GetTipeNilaiByKursusAndSiswa returns the siswa's nilai for every ujian of the kursus
+func (r *tipeNilaiRepository) GetTipeNilaiByKursusAndSiswa(kursusID uint64, siswaID uint64) ([]entity.TipeNilai, error) {
+	var tipeNilai []entity.TipeNilai
+	err := r.db.Joins("JOIN ujian ON ujian.id_ujian = tipe_nilai.id_ujian").
+		Where("ujian.id_kursus = ? AND tipe_nilai.id_siswa = ?", kursusID, siswaID).
+		Preload("Siswa").
+		Preload("TipeUjian").
+		Preload("Ujian").
+		Find(&tipeNilai).Error
+	return tipeNilai, err
+}
+
+// GetTipeUjianIDsBySiswa returns the tipe ujian the siswa has a nilai for in the kursus
+func (r *tipeNilaiRepository) GetTipeUjianIDsBySiswa(kursusID uint64, siswaID uint64) ([]uint64, error) {
+	var tipeUjianIDs []uint64
+	err := r.db.Model(&entity.TipeNilai{}).
+		Joins("JOIN ujian ON ujian.id_ujian = tipe_nilai.id_ujian").
+		Where("ujian.id_kursus = ? AND tipe_nilai.id_siswa = ?", kursusID, siswaID).
+		Distinct("tipe_nilai.id_tipe_ujian").
+		Pluck("tipe_nilai.id_tipe_ujian", &tipeUjianIDs).Error
+	return tipeUjianIDs, err
+}
+
+// SumNilaiByTipeUjian sums the siswa's nilai of one tipe ujian over the ujian of the kursus
+func (r *tipeNilaiRepository) SumNilaiByTipeUjian(kursusID uint64, siswaID uint64, tipeUjianID uint64) (float64, error) {
+	var total float64
+	err := r.db.Model(&entity.TipeNilai{}).
+		Joins("JOIN ujian ON ujian.id_ujian = tipe_nilai.id_ujian").
+		Where("ujian.id_kursus = ? AND tipe_nilai.id_tipe_ujian = ? AND tipe_nilai.id_siswa = ?", kursusID, tipeUjianID, siswaID).
+		Select("COALESCE(SUM(tipe_nilai.nilai), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *tipeNilaiRepository) CreateTipeNilai(tipeNilai entity.TipeNilai) (entity.TipeNilai, error) {
+	err := r.db.Create(&tipeNilai).Error
+	return tipeNilai, err
+}
+
+func (r *tipeNilaiRepository) UpdateTipeNilai(tipeNilai entity.TipeNilai) (entity.TipeNilai, error) {
+	err := r.db.Save(&tipeNilai).Error
+	return tipeNilai, err
+}
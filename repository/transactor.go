@@ -0,0 +1,28 @@
+// repository/transactor.go
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+// Transactor runs fn inside one database transaction. Repositories that take part in it are
+// obtained through their WithTx method, so services own the transaction boundary while fakes
+// in tests can simply call fn.
+type Transactor interface {
+	WithinTransaction(fn func(tx *gorm.DB) error) error
+}
+
+type transactor struct {
+	db *gorm.DB
+}
+
+// NewTransactor creates a new instance of Transactor
+func NewTransactor(db *gorm.DB) Transactor {
+	return &transactor{
+		db: db,
+	}
+}
+
+func (t *transactor) WithinTransaction(fn func(tx *gorm.DB) error) error {
+	return t.db.Transaction(fn)
+}
@@ -0,0 +1,91 @@
+// repository/ujian_attempt_repository.go
+package repository
+
+import (
+	"cbt-api/entity"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UjianAttemptRepository is a contract for ujian attempt repository
+type UjianAttemptRepository interface {
+	WithTx(tx *gorm.DB) UjianAttemptRepository
+	CreateAttempt(attempt entity.UjianAttempt) (entity.UjianAttempt, error)
+	UpdateAttempt(attempt entity.UjianAttempt) (entity.UjianAttempt, error)
+	CloseAttempt(id uint64, waktuSubmit time.Time) (bool, error)
+	GetAttemptByID(id uint64) (entity.UjianAttempt, error)
+	GetLatestAttempt(ujianID uint64, siswaID uint64) (entity.UjianAttempt, error)
+	GetAttemptsByUjianID(ujianID uint64) ([]entity.UjianAttempt, error)
+	CountByUjian(ujianID uint64, status string) (int64, error)
+}
+
+type ujianAttemptRepository struct {
+	db *gorm.DB
+}
+
+// NewUjianAttemptRepository creates a new instance of UjianAttemptRepository
+func NewUjianAttemptRepository(db *gorm.DB) UjianAttemptRepository {
+	return &ujianAttemptRepository{
+		db: db,
+	}
+}
+
+func (r *ujianAttemptRepository) WithTx(tx *gorm.DB) UjianAttemptRepository {
+	return &ujianAttemptRepository{db: tx}
+}
+
+func (r *ujianAttemptRepository) CreateAttempt(attempt entity.UjianAttempt) (entity.UjianAttempt, error) {
+	err := r.db.Create(&attempt).Error
+	return attempt, err
+}
+
+func (r *ujianAttemptRepository) UpdateAttempt(attempt entity.UjianAttempt) (entity.UjianAttempt, error) {
+	err := r.db.Save(&attempt).Error
+	return attempt, err
+}
+
+// CloseAttempt marks the attempt as Selesai only if it is still Berlangsung, so two concurrent
+// submissions cannot both grade the same attempt. It reports whether this call closed it.
+func (r *ujianAttemptRepository) CloseAttempt(id uint64, waktuSubmit time.Time) (bool, error) {
+	result := r.db.Model(&entity.UjianAttempt{}).
+		Where("id_ujian_attempt = ? AND status = ?", id, entity.StatusAttemptBerlangsung).
+		Updates(map[string]interface{}{
+			"status":       entity.StatusAttemptSelesai,
+			"waktu_submit": waktuSubmit,
+			"updated_at":   waktuSubmit,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *ujianAttemptRepository) GetAttemptByID(id uint64) (entity.UjianAttempt, error) {
+	var attempt entity.UjianAttempt
+	err := r.db.Where("id_ujian_attempt = ?", id).Take(&attempt).Error
+	return attempt, err
+}
+
+// GetLatestAttempt returns the most recent attempt of a siswa for the given ujian
+func (r *ujianAttemptRepository) GetLatestAttempt(ujianID uint64, siswaID uint64) (entity.UjianAttempt, error) {
+	var attempt entity.UjianAttempt
+	err := r.db.Where("id_ujian = ? AND id_siswa = ?", ujianID, siswaID).
+		Order("id_ujian_attempt DESC").
+		Take(&attempt).Error
+	return attempt, err
+}
+
+func (r *ujianAttemptRepository) GetAttemptsByUjianID(ujianID uint64) ([]entity.UjianAttempt, error) {
+	var attempts []entity.UjianAttempt
+	err := r.db.Where("id_ujian = ?", ujianID).Order("waktu_mulai ASC").Find(&attempts).Error
+	return attempts, err
+}
+
+// CountByUjian counts the attempts of an ujian with the given status; an empty status counts all of them
+func (r *ujianAttemptRepository) CountByUjian(ujianID uint64, status string) (int64, error) {
+	var count int64
+	query := r.db.Model(&entity.UjianAttempt{}).Where("id_ujian = ?", ujianID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,74 @@
+// repository/ujian_repository.go
+package repository
+
+import (
+	"cbt-api/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UjianRepository is a contract for ujian repository
+type UjianRepository interface {
+	WithTx(tx *gorm.DB) UjianRepository
+	GetUjianByID(ujianID uint64) (entity.Ujian, error)
+	GetUjianByKursusID(kursusID uint64) ([]entity.Ujian, error)
+	CreateUjian(ujian entity.Ujian) (entity.Ujian, error)
+	UpdateUjian(ujian entity.Ujian) (entity.Ujian, error)
+	DeleteUjian(ujianID uint64) error
+}
+
+type ujianRepository struct {
+	db *gorm.DB
+}
+
+// NewUjianRepository creates a new instance of UjianRepository
+func NewUjianRepository(db *gorm.DB) UjianRepository {
+	return &ujianRepository{
+		db: db,
+	}
+}
+
+func (r *ujianRepository) WithTx(tx *gorm.DB) UjianRepository {
+	return &ujianRepository{db: tx}
+}
+
+func (r *ujianRepository) GetUjianByID(ujianID uint64) (entity.Ujian, error) {
+	var ujian entity.Ujian
+	err := r.db.Where("id_ujian = ?", ujianID).First(&ujian).Error
+	return ujian, err
+}
+
+// GetUjianByKursusID returns every ujian of a kursus together with its tipe ujian
+func (r *ujianRepository) GetUjianByKursusID(kursusID uint64) ([]entity.Ujian, error) {
+	var ujianList []entity.Ujian
+	err := r.db.Where("id_kursus = ?", kursusID).Preload("TipeUjian").Find(&ujianList).Error
+	return ujianList, err
+}
+
+func (r *ujianRepository) CreateUjian(ujian entity.Ujian) (entity.Ujian, error) {
+	err := r.db.Omit(clause.Associations).Create(&ujian).Error
+	return ujian, err
+}
+
+// UpdateUjian saves every column of the ujian, including zero values such as SkorSalah 0
+func (r *ujianRepository) UpdateUjian(ujian entity.Ujian) (entity.Ujian, error) {
+	err := r.db.Omit(clause.Associations).Save(&ujian).Error
+	return ujian, err
+}
+
+// DeleteUjian deletes the ujian with its soal and their answer options; linked bank soal stay in the bank
+func (r *ujianRepository) DeleteUjian(ujianID uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id_ujian = ?", ujianID).Delete(&entity.UjianSoal{}).Error; err != nil {
+			return err
+		}
+		soalIDs := tx.Model(&entity.Soal{}).Select("id_soal").Where("id_ujian = ?", ujianID)
+		if err := tx.Where("id_soal IN (?)", soalIDs).Delete(&entity.JawabanSoal{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id_ujian = ?", ujianID).Delete(&entity.Soal{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id_ujian = ?", ujianID).Delete(&entity.Ujian{}).Error
+	})
+}
@@ -0,0 +1,58 @@
+// repository/user_repository.go
+package repository
+
+import (
+	"cbt-api/entity"
+	"gorm.io/gorm"
+)
+
+// UserRepository is a contract for users and the role tables linked to them.
+// The Find*ByUserID methods return a zero value (not an error) when the user has no such role.
+type UserRepository interface {
+	FindByEmail(email string) (entity.Users, error)
+	FindSiswaByUserID(userID uint64) (entity.Siswa, error)
+	FindGuruByUserID(userID uint64) (entity.Guru, error)
+	FindOperatorByUserID(userID uint64) (entity.Operator, error)
+	FindAdminByUserID(userID uint64) (entity.Admin, error)
+}
+
+type userRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository creates a new instance of UserRepository
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{
+		db: db,
+	}
+}
+
+func (r *userRepository) FindByEmail(email string) (entity.Users, error) {
+	var user entity.Users
+	err := r.db.Where("email = ?", email).First(&user).Error
+	return user, err
+}
+
+func (r *userRepository) FindSiswaByUserID(userID uint64) (entity.Siswa, error) {
+	var siswa entity.Siswa
+	err := r.db.Where("id_user = ?", userID).Limit(1).Find(&siswa).Error
+	return siswa, err
+}
+
+func (r *userRepository) FindGuruByUserID(userID uint64) (entity.Guru, error) {
+	var guru entity.Guru
+	err := r.db.Where("id_user = ?", userID).Limit(1).Find(&guru).Error
+	return guru, err
+}
+
+func (r *userRepository) FindOperatorByUserID(userID uint64) (entity.Operator, error) {
+	var operator entity.Operator
+	err := r.db.Where("id_user = ?", userID).Limit(1).Find(&operator).Error
+	return operator, err
+}
+
+func (r *userRepository) FindAdminByUserID(userID uint64) (entity.Admin, error) {
+	var admin entity.Admin
+	err := r.db.Where("id_user = ?", userID).Limit(1).Find(&admin).Error
+	return admin, err
+}
@@ -0,0 +1,97 @@
+// repository/user_session_repository.go
+package repository
+
+import (
+	"cbt-api/entity"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserSessionRepository is a contract for user session repository
+type UserSessionRepository interface {
+	CreateSession(session entity.UserSession) (entity.UserSession, error)
+	RotateRefreshToken(session entity.UserSession, oldHash string) (bool, error)
+	GetSessionByTokenID(tokenID string) (entity.UserSession, error)
+	GetSessionByRefreshTokenHash(hash string) (entity.UserSession, error)
+	GetSessionByUsedRefreshTokenHash(hash string) (entity.UserSession, error)
+	RevokeSession(tokenID string, revokedAt time.Time) error
+	RevokeAllSessionsByUserID(userID uint64, revokedAt time.Time) (int64, error)
+}
+
+type userSessionRepository struct {
+	db *gorm.DB
+}
+
+// NewUserSessionRepository creates a new instance of UserSessionRepository
+func NewUserSessionRepository(db *gorm.DB) UserSessionRepository {
+	return &userSessionRepository{
+		db: db,
+	}
+}
+
+func (r *userSessionRepository) CreateSession(session entity.UserSession) (entity.UserSession, error) {
+	err := r.db.Create(&session).Error
+	return session, err
+}
+
+// RotateRefreshToken stores the session's new refresh token hash and expiry only while oldHash is still
+// its current hash and the session is not revoked, then keeps oldHash in refresh_token_lama. It returns
+// false when another refresh or a logout got there first.
+func (r *userSessionRepository) RotateRefreshToken(session entity.UserSession, oldHash string) (bool, error) {
+	var rotated bool
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.UserSession{}).
+			Where("id_user_session = ? AND refresh_token_hash = ? AND revoked_at IS NULL", session.IdUserSession, oldHash).
+			Updates(map[string]interface{}{
+				"refresh_token_hash": session.RefreshTokenHash,
+				"expires_at":         session.ExpiresAt,
+				"updated_at":         session.UpdatedAt,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		rotated = true
+		return tx.Create(&entity.RefreshTokenLama{
+			IdUserSession:    session.IdUserSession,
+			RefreshTokenHash: oldHash,
+			CreatedAt:        session.UpdatedAt,
+		}).Error
+	})
+	return rotated && err == nil, err
+}
+
+func (r *userSessionRepository) GetSessionByTokenID(tokenID string) (entity.UserSession, error) {
+	var session entity.UserSession
+	err := r.db.Where("token_id = ?", tokenID).Take(&session).Error
+	return session, err
+}
+
+func (r *userSessionRepository) GetSessionByRefreshTokenHash(hash string) (entity.UserSession, error) {
+	var session entity.UserSession
+	err := r.db.Where("refresh_token_hash = ?", hash).Take(&session).Error
+	return session, err
+}
+
+// GetSessionByUsedRefreshTokenHash finds the session a rotated refresh token belonged to
+func (r *userSessionRepository) GetSessionByUsedRefreshTokenHash(hash string) (entity.UserSession, error) {
+	var session entity.UserSession
+	err := r.db.Joins("JOIN refresh_token_lama ON refresh_token_lama.id_user_session = user_session.id_user_session").
+		Where("refresh_token_lama.refresh_token_hash = ?", hash).
+		Take(&session).Error
+	return session, err
+}
+
+func (r *userSessionRepository) RevokeSession(tokenID string, revokedAt time.Time) error {
+	return r.db.Model(&entity.UserSession{}).
+		Where("token_id = ? AND revoked_at IS NULL", tokenID).
+		Updates(map[string]interface{}{"revoked_at": revokedAt, "updated_at": revokedAt}).Error
+}
+
+// RevokeAllSessionsByUserID revokes every active session of a user and returns how many were revoked
+func (r *userSessionRepository) RevokeAllSessionsByUserID(userID uint64, revokedAt time.Time) (int64, error) {
+	result := r.db.Model(&entity.UserSession{}).
+		Where("id_user = ? AND revoked_at IS NULL", userID).
+		Updates(map[string]interface{}{"revoked_at": revokedAt, "updated_at": revokedAt})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,173 @@
+// service/acak_soal.go
+package service
+
+import (
+	"cbt-api/entity"
+	"encoding/binary"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// seedAcak derives the shuffle seed of one siswa for an ujian or latihan. The same siswa always gets
+// the same order, so reloading or resuming never reshuffles the questions.
+func seedAcak(jenis string, id uint64, siswaID uint64) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(jenis))
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[:8], id)
+	binary.BigEndian.PutUint64(buf[8:], siswaID)
+	h.Write(buf[:])
+	return int64(h.Sum64())
+}
+
+// opsiDiacak reports whether the options of the soal may be shuffled. Benar_Salah keeps its fixed
+// order and the options of an Isian soal are accepted answers, not choices.
+func opsiDiacak(soal entity.Soal) bool {
+	switch soal.TipeSoal.NamaTipeUjian {
+	case entity.TipeSoalPilihanBerganda, entity.TipeSoalPilihanKompleks:
+		return true
+	default:
+		return false
+	}
+}
+
+// susunUrutan returns the soal order and the option order per soal. Without acak both follow the id
+// order; with acak the soal and the options of choice soal are shuffled using seed.
+func susunUrutan(soalList []entity.Soal, kunci []entity.JawabanSoal, acak bool, seed int64) ([]uint64, map[uint64][]uint64) {
+	soalList = append([]entity.Soal(nil), soalList...)
+	sort.Slice(soalList, func(i, j int) bool { return soalList[i].IdSoal < soalList[j].IdSoal })
+	opsiPerSoal := kunciPerSoal(kunci)
+	rng := rand.New(rand.NewSource(seed))
+
+	urutanSoal := make([]uint64, 0, len(soalList))
+	urutanOpsi := make(map[uint64][]uint64, len(soalList))
+	for _, soal := range soalList {
+		urutanSoal = append(urutanSoal, soal.IdSoal)
+
+		opsi := make([]uint64, 0, len(opsiPerSoal[soal.IdSoal]))
+		for _, jawabanSoal := range opsiPerSoal[soal.IdSoal] {
+			opsi = append(opsi, jawabanSoal.IdJawabanSoal)
+		}
+		sort.Slice(opsi, func(i, j int) bool { return opsi[i] < opsi[j] })
+		if acak && opsiDiacak(soal) {
+			rng.Shuffle(len(opsi), func(i, j int) { opsi[i], opsi[j] = opsi[j], opsi[i] })
+		}
+		urutanOpsi[soal.IdSoal] = opsi
+	}
+	if acak {
+		rng.Shuffle(len(urutanSoal), func(i, j int) { urutanSoal[i], urutanSoal[j] = urutanSoal[j], urutanSoal[i] })
+	}
+	return urutanSoal, urutanOpsi
+}
+
+// urutanUjianSiswa returns the soal and option order the siswa sees in the ujian. An attempt keeps
+// the order stored when it started; before the siswa starts, the order the attempt will get is returned.
+func urutanUjianSiswa(ujian entity.Ujian, siswaID uint64, attempt *entity.UjianAttempt, soalList []entity.Soal, kunci []entity.JawabanSoal) ([]uint64, map[uint64][]uint64) {
+	urutanSoal, urutanOpsi := susunUrutan(soalList, kunci, ujian.Acak == entity.StatusAktif, seedAcak("ujian", ujian.IdUjian, siswaID))
+	if attempt == nil {
+		return urutanSoal, urutanOpsi
+	}
+
+	// Attempt yang dimulai sebelum urutan opsi disimpan tetap memakai urutan database
+	tersimpan := parseUrutanOpsi(attempt.UrutanOpsi)
+	for idSoal, opsi := range kunciPerSoal(kunci) {
+		ids := make([]uint64, 0, len(opsi))
+		for _, jawabanSoal := range opsi {
+			ids = append(ids, jawabanSoal.IdJawabanSoal)
+		}
+		urutanOpsi[idSoal] = terapkanUrutan(tersimpan[idSoal], ids)
+	}
+	return urutanSoalAttempt(*attempt, soalList), urutanOpsi
+}
+
+// terapkanUrutan orders ids by the stored order. Ids missing from the stored order are appended in
+// their current order and stored ids that no longer exist are dropped.
+func terapkanUrutan(tersimpan []uint64, ids []uint64) []uint64 {
+	ada := make(map[uint64]bool, len(ids))
+	for _, id := range ids {
+		ada[id] = true
+	}
+
+	urutan := make([]uint64, 0, len(ids))
+	sudahMasuk := make(map[uint64]bool, len(ids))
+	for _, id := range tersimpan {
+		if !ada[id] || sudahMasuk[id] {
+			continue
+		}
+		urutan = append(urutan, id)
+		sudahMasuk[id] = true
+	}
+	for _, id := range ids {
+		if !sudahMasuk[id] {
+			urutan = append(urutan, id)
+		}
+	}
+	return urutan
+}
+
+// urutkanSoalDenganJawaban arranges soal and their options following the given order
+func urutkanSoalDenganJawaban(soalList []entity.Soal, kunci []entity.JawabanSoal, urutanSoal []uint64, urutanOpsi map[uint64][]uint64) []SoalDenganJawaban {
+	soalByID := make(map[uint64]entity.Soal, len(soalList))
+	for _, soal := range soalList {
+		soalByID[soal.IdSoal] = soal
+	}
+	opsiByID := make(map[uint64]entity.JawabanSoal, len(kunci))
+	for _, jawabanSoal := range kunci {
+		opsiByID[jawabanSoal.IdJawabanSoal] = jawabanSoal
+	}
+
+	result := make([]SoalDenganJawaban, 0, len(urutanSoal))
+	for _, idSoal := range urutanSoal {
+		soal, ok := soalByID[idSoal]
+		if !ok {
+			continue
+		}
+		jawaban := make([]entity.JawabanSoal, 0, len(urutanOpsi[idSoal]))
+		for _, idJawabanSoal := range urutanOpsi[idSoal] {
+			if opsi, ok := opsiByID[idJawabanSoal]; ok {
+				jawaban = append(jawaban, opsi)
+			}
+		}
+		result = append(result, SoalDenganJawaban{Soal: soal, Jawaban: jawaban})
+	}
+	return result
+}
+
+// formatUrutanOpsi stores the option order as "id_soal:id_jawaban_soal,...;id_soal:..." following the soal order
+func formatUrutanOpsi(urutanSoal []uint64, urutanOpsi map[uint64][]uint64) string {
+	bagian := make([]string, 0, len(urutanSoal))
+	for _, idSoal := range urutanSoal {
+		bagian = append(bagian, strconv.FormatUint(idSoal, 10)+":"+formatUrutanSoal(urutanOpsi[idSoal]))
+	}
+	return strings.Join(bagian, ";")
+}
+
+func parseUrutanOpsi(teks string) map[uint64][]uint64 {
+	urutanOpsi := make(map[uint64][]uint64)
+	for _, bagian := range strings.Split(teks, ";") {
+		idSoalTeks, opsiTeks, ok := strings.Cut(bagian, ":")
+		if !ok {
+			continue
+		}
+		idSoal, err := strconv.ParseUint(strings.TrimSpace(idSoalTeks), 10, 64)
+		if err != nil {
+			continue
+		}
+		urutanOpsi[idSoal] = parseUrutanSoal(opsiTeks)
+	}
+	return urutanOpsi
+}
+
+func parseUrutanSoal(teks string) []uint64 {
+	var ids []uint64
+	for _, part := range strings.Split(teks, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 64)
+		if err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
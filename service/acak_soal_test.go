@@ -0,0 +1,149 @@
+package service
+
+import (
+	"cbt-api/entity"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// soalAcakTest builds n Pilihan_Berganda soal with four options each, plus one Benar_Salah and one Isian soal
+func soalAcakTest(n int) ([]entity.Soal, []entity.JawabanSoal) {
+	pg := entity.TipeSoal{NamaTipeUjian: entity.TipeSoalPilihanBerganda}
+	var soalList []entity.Soal
+	var kunci []entity.JawabanSoal
+	for i := 1; i <= n; i++ {
+		id := uint64(i)
+		soalList = append(soalList, entity.Soal{IdSoal: id, TipeSoal: pg})
+		for j := uint64(1); j <= 4; j++ {
+			kunci = append(kunci, entity.JawabanSoal{IdJawabanSoal: id*10 + j, IdSoal: id})
+		}
+	}
+	soalList = append(soalList,
+		entity.Soal{IdSoal: 100, TipeSoal: entity.TipeSoal{NamaTipeUjian: entity.TipeSoalBenarSalah}},
+		entity.Soal{IdSoal: 101, TipeSoal: entity.TipeSoal{NamaTipeUjian: entity.TipeSoalIsian}},
+	)
+	kunci = append(kunci,
+		entity.JawabanSoal{IdJawabanSoal: 1001, IdSoal: 100}, entity.JawabanSoal{IdJawabanSoal: 1002, IdSoal: 100},
+		entity.JawabanSoal{IdJawabanSoal: 1011, IdSoal: 101}, entity.JawabanSoal{IdJawabanSoal: 1012, IdSoal: 101},
+		entity.JawabanSoal{IdJawabanSoal: 1013, IdSoal: 101},
+	)
+	return soalList, kunci
+}
+
+func TestSusunUrutanTanpaAcak(t *testing.T) {
+	soalList, kunci := soalAcakTest(5)
+	// Urutan masukan tidak berpengaruh
+	sort.Slice(soalList, func(i, j int) bool { return soalList[i].IdSoal > soalList[j].IdSoal })
+
+	urutanSoal, urutanOpsi := susunUrutan(soalList, kunci, false, 42)
+	if want := []uint64{1, 2, 3, 4, 5, 100, 101}; !reflect.DeepEqual(urutanSoal, want) {
+		t.Errorf("soal order = %v, want %v", urutanSoal, want)
+	}
+	if want := []uint64{31, 32, 33, 34}; !reflect.DeepEqual(urutanOpsi[3], want) {
+		t.Errorf("option order = %v, want %v", urutanOpsi[3], want)
+	}
+}
+
+func TestSusunUrutanAcakDeterministik(t *testing.T) {
+	soalList, kunci := soalAcakTest(20)
+	seed := seedAcak("ujian", 9, 7)
+
+	urutanSoal, urutanOpsi := susunUrutan(soalList, kunci, true, seed)
+	reversed := append([]entity.Soal(nil), soalList...)
+	sort.Slice(reversed, func(i, j int) bool { return reversed[i].IdSoal > reversed[j].IdSoal })
+	lagiSoal, lagiOpsi := susunUrutan(reversed, kunci, true, seed)
+	if !reflect.DeepEqual(urutanSoal, lagiSoal) || !reflect.DeepEqual(urutanOpsi, lagiOpsi) {
+		t.Fatal("the same seed gave a different order")
+	}
+
+	sorted := append([]uint64(nil), urutanSoal...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	if reflect.DeepEqual(sorted, urutanSoal) {
+		t.Error("soal were not shuffled")
+	}
+	if len(urutanSoal) != len(soalList) {
+		t.Errorf("got %d soal, want %d", len(urutanSoal), len(soalList))
+	}
+
+	if want := []uint64{1001, 1002}; !reflect.DeepEqual(urutanOpsi[100], want) {
+		t.Errorf("Benar_Salah options = %v, want %v", urutanOpsi[100], want)
+	}
+	if want := []uint64{1011, 1012, 1013}; !reflect.DeepEqual(urutanOpsi[101], want) {
+		t.Errorf("Isian variants = %v, want %v", urutanOpsi[101], want)
+	}
+	diacak := false
+	for id := uint64(1); id <= 20; id++ {
+		if !reflect.DeepEqual(urutanOpsi[id], []uint64{id*10 + 1, id*10 + 2, id*10 + 3, id*10 + 4}) {
+			diacak = true
+		}
+	}
+	if !diacak {
+		t.Error("Pilihan_Berganda options were not shuffled")
+	}
+
+	lainSoal, _ := susunUrutan(soalList, kunci, true, seedAcak("ujian", 9, 8))
+	if reflect.DeepEqual(urutanSoal, lainSoal) {
+		t.Error("two siswa got the same soal order")
+	}
+}
+
+func TestSeedAcak(t *testing.T) {
+	if seedAcak("ujian", 1, 2) != seedAcak("ujian", 1, 2) {
+		t.Error("seed is not stable")
+	}
+	seeds := map[int64]string{}
+	for name, seed := range map[string]int64{
+		"ujian 1 siswa 2":   seedAcak("ujian", 1, 2),
+		"ujian 2 siswa 1":   seedAcak("ujian", 2, 1),
+		"latihan 1 siswa 2": seedAcak("latihan", 1, 2),
+	} {
+		if other, ok := seeds[seed]; ok {
+			t.Errorf("%s and %s share a seed", name, other)
+		}
+		seeds[seed] = name
+	}
+}
+
+func TestTerapkanUrutan(t *testing.T) {
+	tests := []struct {
+		name      string
+		tersimpan []uint64
+		ids       []uint64
+		want      []uint64
+	}{
+		{"stored order", []uint64{3, 1, 2}, []uint64{1, 2, 3}, []uint64{3, 1, 2}},
+		{"nothing stored", nil, []uint64{1, 2, 3}, []uint64{1, 2, 3}},
+		{"new ids appended", []uint64{3, 1}, []uint64{1, 2, 3, 4}, []uint64{3, 1, 2, 4}},
+		{"deleted ids dropped", []uint64{3, 9, 1, 2}, []uint64{1, 2, 3}, []uint64{3, 1, 2}},
+		{"duplicates dropped", []uint64{2, 2, 1}, []uint64{1, 2}, []uint64{2, 1}},
+	}
+	for _, tt := range tests {
+		if got := terapkanUrutan(tt.tersimpan, tt.ids); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUrutanOpsiRoundTrip(t *testing.T) {
+	urutanSoal := []uint64{2, 1, 3}
+	urutanOpsi := map[uint64][]uint64{1: {12, 11}, 2: {21, 23, 22}, 3: nil}
+
+	teks := formatUrutanOpsi(urutanSoal, urutanOpsi)
+	if teks != "2:21,23,22;1:12,11;3:" {
+		t.Errorf("formatUrutanOpsi = %q", teks)
+	}
+	got := parseUrutanOpsi(teks)
+	for _, idSoal := range urutanSoal {
+		if !reflect.DeepEqual(got[idSoal], urutanOpsi[idSoal]) {
+			t.Errorf("soal %d options = %v, want %v", idSoal, got[idSoal], urutanOpsi[idSoal])
+		}
+	}
+
+	if got := parseUrutanOpsi("x:1;2:a,5, 6"); len(got) != 1 || !reflect.DeepEqual(got[2], []uint64{5, 6}) {
+		t.Errorf("malformed parts not skipped: %v", got)
+	}
+	if got := parseUrutanSoal(formatUrutanSoal([]uint64{5, 3, 4})); !reflect.DeepEqual(got, []uint64{5, 3, 4}) {
+		t.Errorf("soal order round trip = %v", got)
+	}
+}
@@ -0,0 +1,354 @@
+// service/analisis_butir.go
+package service
+
+import (
+	"cbt-api/entity"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Batas yang membuat butir soal ditandai perlu direvisi
+const (
+	proporsiKelompok     = 0.27 // kelompok atas dan bawah, masing-masing 27% peserta
+	batasTerlaluSulit    = 0.20
+	batasTerlaluMudah    = 0.90
+	batasDayaBeda        = 0.20
+	batasPointBiserial   = 0.20
+	batasPengecohBerguna = 0.05 // pengecoh dipilih kurang dari 5% peserta dianggap tidak berfungsi
+)
+
+// AnalisisOpsi is the distractor analysis of one jawaban_soal option
+type AnalisisOpsi struct {
+	IdJawabanSoal uint64  `json:"id_jawaban_soal"`
+	Jawaban       string  `json:"jawaban"`
+	Benar         bool    `json:"benar"`
+	JumlahPemilih int     `json:"jumlah_pemilih"`
+	Proporsi      float64 `json:"proporsi"`
+	PemilihAtas   int     `json:"pemilih_kelompok_atas"`
+	PemilihBawah  int     `json:"pemilih_kelompok_bawah"`
+}
+
+// AnalisisButir holds the item statistics of one soal. Statistics are nil when there are not enough
+// graded answers to compute them; Isian answers still waiting for manual grading are left out.
+type AnalisisButir struct {
+	IdSoal                  uint64         `json:"id_soal"`
+	Soal                    string         `json:"soal"`
+	TipeSoal                string         `json:"tipe_soal"`
+	JumlahPeserta           int            `json:"jumlah_peserta"`
+	JumlahTidakDijawab      int            `json:"jumlah_tidak_dijawab"`
+	JumlahMenungguPenilaian int            `json:"jumlah_menunggu_penilaian"`
+	TingkatKesukaran        *float64       `json:"tingkat_kesukaran"` // p-value: rata-rata bagian poin yang diperoleh
+	DayaBeda                *float64       `json:"daya_beda"`         // p kelompok atas - p kelompok bawah
+	KorelasiPointBiserial   *float64       `json:"korelasi_point_biserial"`
+	Opsi                    []AnalisisOpsi `json:"opsi"`
+	PerluRevisi             bool           `json:"perlu_revisi"`
+	Catatan                 []string       `json:"catatan"`
+}
+
+// AnalisisUjian is the item analysis report of an ujian, computed over the siswa who finished it
+type AnalisisUjian struct {
+	IdUjian           uint64          `json:"id_ujian"`
+	NamaUjian         string          `json:"nama_ujian"`
+	JumlahPeserta     int             `json:"jumlah_peserta"`
+	UkuranKelompok    int             `json:"ukuran_kelompok"` // jumlah peserta di kelompok atas maupun bawah
+	RataRataNilai     float64         `json:"rata_rata_nilai"`
+	JumlahPerluRevisi int             `json:"jumlah_perlu_revisi"`
+	Butir             []AnalisisButir `json:"butir"`
+}
+
+// pesertaAnalisis is one graded siswa; total is the raw score used to rank the groups
+type pesertaAnalisis struct {
+	idSiswa  uint64
+	total    float64
+	nilai    map[uint64]NilaiSoal
+	kelompok int // 1 atas, -1 bawah, 0 tengah
+}
+
+// AnalisisButirSoal computes classic item statistics for every soal of the ujian: difficulty index,
+// discrimination index between the upper and lower 27% of siswa ranked by raw score, point-biserial
+// correlation with the raw score and distractor analysis per option. Each siswa counts once, with
+// the answers of their latest finished attempt; answers saved before attempts existed are used for
+// siswa without any attempt.
+func (s *nilaiService) AnalisisButirSoal(ujianID uint64) (AnalisisUjian, error) {
+	ujian, err := s.ujianRepository.GetUjianByID(ujianID)
+	if err != nil {
+		return AnalisisUjian{}, err
+	}
+	soalList, err := s.soalRepository.FindByIdUjianWithTipeSoal(ujianID)
+	if err != nil {
+		return AnalisisUjian{}, err
+	}
+	kunci, err := s.jawabanSoalRepository.FindByIdUjian(ujianID)
+	if err != nil {
+		return AnalisisUjian{}, err
+	}
+	attempts, err := s.ujianAttemptRepository.GetAttemptsByUjianID(ujianID)
+	if err != nil {
+		return AnalisisUjian{}, err
+	}
+	jawabanList, err := s.jawabanSiswaRepository.FindByUjian(ujianID)
+	if err != nil {
+		return AnalisisUjian{}, err
+	}
+
+	peserta := pesertaUjian(ujian, soalList, kunci, attempts, jawabanList)
+	ukuran := tandaiKelompok(peserta)
+
+	laporan := AnalisisUjian{
+		IdUjian:        ujian.IdUjian,
+		NamaUjian:      ujian.NamaUjian,
+		JumlahPeserta:  len(peserta),
+		UkuranKelompok: ukuran,
+		Butir:          make([]AnalisisButir, 0, len(soalList)),
+	}
+	for _, p := range peserta {
+		laporan.RataRataNilai += p.total
+	}
+	if len(peserta) > 0 {
+		laporan.RataRataNilai /= float64(len(peserta))
+	}
+
+	opsiPerSoal := kunciPerSoal(kunci)
+	for _, soal := range soalList {
+		butir := analisisSoal(soal, opsiPerSoal[soal.IdSoal], peserta, ukuran)
+		if butir.PerluRevisi {
+			laporan.JumlahPerluRevisi++
+		}
+		laporan.Butir = append(laporan.Butir, butir)
+	}
+	return laporan, nil
+}
+
+// pesertaUjian grades the answers of every siswa who finished the ujian
+func pesertaUjian(ujian entity.Ujian, soalList []entity.Soal, kunci []entity.JawabanSoal, attempts []entity.UjianAttempt, jawabanList []entity.JawabanSiswa) []*pesertaAnalisis {
+	// Attempt terurut dari yang paling awal, jadi attempt Selesai terakhir yang dipakai
+	adaAttempt := make(map[uint64]bool)
+	attemptSelesai := make(map[uint64]uint64)
+	for _, attempt := range attempts {
+		adaAttempt[attempt.IdSiswa] = true
+		if attempt.Status == entity.StatusAttemptSelesai {
+			attemptSelesai[attempt.IdSiswa] = attempt.IdUjianAttempt
+		}
+	}
+
+	jawabanPerSiswa := make(map[uint64][]entity.JawabanSiswa)
+	var urutanSiswa []uint64
+	for _, jawaban := range jawabanList {
+		if jawaban.IdUjianAttempt == nil {
+			if adaAttempt[jawaban.IdSiswa] {
+				continue
+			}
+		} else if attemptSelesai[jawaban.IdSiswa] != *jawaban.IdUjianAttempt {
+			continue
+		}
+		if _, ok := jawabanPerSiswa[jawaban.IdSiswa]; !ok {
+			urutanSiswa = append(urutanSiswa, jawaban.IdSiswa)
+		}
+		jawabanPerSiswa[jawaban.IdSiswa] = append(jawabanPerSiswa[jawaban.IdSiswa], jawaban)
+	}
+	// Siswa yang menyelesaikan ujian tanpa menjawab satu soal pun tetap dihitung
+	for _, attempt := range attempts {
+		if attemptSelesai[attempt.IdSiswa] == attempt.IdUjianAttempt {
+			if _, ok := jawabanPerSiswa[attempt.IdSiswa]; !ok {
+				jawabanPerSiswa[attempt.IdSiswa] = nil
+				urutanSiswa = append(urutanSiswa, attempt.IdSiswa)
+			}
+		}
+	}
+
+	peserta := make([]*pesertaAnalisis, 0, len(urutanSiswa))
+	for _, idSiswa := range urutanSiswa {
+		hasil := HasilUjian{IdUjian: ujian.IdUjian, IdSiswa: idSiswa}
+		nilaiUjian(&hasil, ujian, soalList, kunci, jawabanPerSiswa[idSiswa])
+
+		p := &pesertaAnalisis{idSiswa: idSiswa, total: hasil.NilaiMentah, nilai: make(map[uint64]NilaiSoal, len(hasil.Soal))}
+		for _, nilaiSoal := range hasil.Soal {
+			p.nilai[nilaiSoal.IdSoal] = nilaiSoal
+		}
+		peserta = append(peserta, p)
+	}
+	return peserta
+}
+
+// tandaiKelompok ranks the peserta by raw score and marks the upper and lower 27%. It returns the
+// size of each group, 0 when there are fewer than two peserta.
+func tandaiKelompok(peserta []*pesertaAnalisis) int {
+	if len(peserta) < 2 {
+		return 0
+	}
+	urut := make([]*pesertaAnalisis, len(peserta))
+	copy(urut, peserta)
+	sort.SliceStable(urut, func(i, j int) bool {
+		if urut[i].total != urut[j].total {
+			return urut[i].total > urut[j].total
+		}
+		return urut[i].idSiswa < urut[j].idSiswa
+	})
+
+	ukuran := int(math.Round(proporsiKelompok * float64(len(urut))))
+	if ukuran < 1 {
+		ukuran = 1
+	}
+	for i := 0; i < ukuran; i++ {
+		urut[i].kelompok = 1
+		urut[len(urut)-1-i].kelompok = -1
+	}
+	return ukuran
+}
+
+// analisisSoal computes the statistics of one soal and flags it when it should be revised
+func analisisSoal(soal entity.Soal, opsiSoal []entity.JawabanSoal, peserta []*pesertaAnalisis, ukuranKelompok int) AnalisisButir {
+	butir := AnalisisButir{
+		IdSoal:   soal.IdSoal,
+		Soal:     soal.Soal,
+		TipeSoal: soal.TipeSoal.NamaTipeUjian,
+		Opsi:     make([]AnalisisOpsi, 0, len(opsiSoal)),
+		Catatan:  make([]string, 0),
+	}
+	isian := soal.TipeSoal.NamaTipeUjian == entity.TipeSoalIsian
+
+	var skor, total []float64
+	var jumlahAtas, jumlahBawah int
+	var skorAtas, skorBawah float64
+	pemilih := make(map[uint64]*AnalisisOpsi, len(opsiSoal))
+	if !isian {
+		for _, opsi := range opsiSoal {
+			butir.Opsi = append(butir.Opsi, AnalisisOpsi{IdJawabanSoal: opsi.IdJawabanSoal, Jawaban: opsi.Jawaban, Benar: opsi.Benar})
+		}
+		for i := range butir.Opsi {
+			pemilih[butir.Opsi[i].IdJawabanSoal] = &butir.Opsi[i]
+		}
+	}
+
+	for _, p := range peserta {
+		nilaiSoal := p.nilai[soal.IdSoal]
+		if nilaiSoal.MenungguPenilaian {
+			butir.JumlahMenungguPenilaian++
+			continue
+		}
+		butir.JumlahPeserta++
+		if !nilaiSoal.Dijawab {
+			butir.JumlahTidakDijawab++
+		}
+		skor = append(skor, nilaiSoal.proporsi)
+		total = append(total, p.total)
+		switch p.kelompok {
+		case 1:
+			jumlahAtas++
+			skorAtas += nilaiSoal.proporsi
+		case -1:
+			jumlahBawah++
+			skorBawah += nilaiSoal.proporsi
+		}
+
+		dipilih := nilaiSoal.Pilihan
+		if nilaiSoal.IdJawabanSoal != 0 {
+			dipilih = []uint64{nilaiSoal.IdJawabanSoal}
+		}
+		for _, id := range dipilih {
+			opsi, ok := pemilih[id]
+			if !ok {
+				continue
+			}
+			opsi.JumlahPemilih++
+			switch p.kelompok {
+			case 1:
+				opsi.PemilihAtas++
+			case -1:
+				opsi.PemilihBawah++
+			}
+		}
+	}
+
+	if butir.JumlahPeserta == 0 {
+		return butir
+	}
+	for i := range butir.Opsi {
+		butir.Opsi[i].Proporsi = float64(butir.Opsi[i].JumlahPemilih) / float64(butir.JumlahPeserta)
+	}
+
+	p := rataRata(skor)
+	butir.TingkatKesukaran = &p
+	if ukuranKelompok > 0 && jumlahAtas > 0 && jumlahBawah > 0 {
+		dayaBeda := skorAtas/float64(jumlahAtas) - skorBawah/float64(jumlahBawah)
+		butir.DayaBeda = &dayaBeda
+	}
+	butir.KorelasiPointBiserial = korelasi(skor, total)
+
+	tandaiRevisi(&butir, isian)
+	return butir
+}
+
+// tandaiRevisi adds a note for every reason the soal should be revised
+func tandaiRevisi(butir *AnalisisButir, isian bool) {
+	switch {
+	case *butir.TingkatKesukaran < batasTerlaluSulit:
+		butir.Catatan = append(butir.Catatan, fmt.Sprintf("terlalu sulit: p = %.2f", *butir.TingkatKesukaran))
+	case *butir.TingkatKesukaran > batasTerlaluMudah:
+		butir.Catatan = append(butir.Catatan, fmt.Sprintf("terlalu mudah: p = %.2f", *butir.TingkatKesukaran))
+	}
+	if butir.DayaBeda != nil {
+		switch {
+		case *butir.DayaBeda < 0:
+			butir.Catatan = append(butir.Catatan, fmt.Sprintf("daya beda negatif: kelompok bawah lebih banyak menjawab benar (D = %.2f)", *butir.DayaBeda))
+		case *butir.DayaBeda < batasDayaBeda:
+			butir.Catatan = append(butir.Catatan, fmt.Sprintf("daya beda rendah: D = %.2f", *butir.DayaBeda))
+		}
+	}
+	if butir.KorelasiPointBiserial != nil && *butir.KorelasiPointBiserial < batasPointBiserial {
+		butir.Catatan = append(butir.Catatan, fmt.Sprintf("korelasi point-biserial rendah: r = %.2f", *butir.KorelasiPointBiserial))
+	}
+
+	if !isian {
+		adaKunci := false
+		for _, opsi := range butir.Opsi {
+			if opsi.Benar {
+				adaKunci = true
+				continue
+			}
+			if opsi.Proporsi < batasPengecohBerguna {
+				butir.Catatan = append(butir.Catatan, fmt.Sprintf("pengecoh %q tidak berfungsi: dipilih %d peserta", opsi.Jawaban, opsi.JumlahPemilih))
+			}
+			if opsi.PemilihAtas > opsi.PemilihBawah {
+				butir.Catatan = append(butir.Catatan, fmt.Sprintf("pengecoh %q lebih banyak dipilih kelompok atas", opsi.Jawaban))
+			}
+		}
+		if !adaKunci {
+			butir.Catatan = append(butir.Catatan, "soal tidak punya kunci jawaban")
+		}
+	}
+	butir.PerluRevisi = len(butir.Catatan) > 0
+}
+
+func rataRata(nilai []float64) float64 {
+	if len(nilai) == 0 {
+		return 0
+	}
+	var jumlah float64
+	for _, n := range nilai {
+		jumlah += n
+	}
+	return jumlah / float64(len(nilai))
+}
+
+// korelasi returns the Pearson correlation of x and y, which for a right/wrong item score equals
+// the point-biserial correlation. It is nil when either side has no variance.
+func korelasi(x []float64, y []float64) *float64 {
+	if len(x) < 2 || len(x) != len(y) {
+		return nil
+	}
+	rataX, rataY := rataRata(x), rataRata(y)
+	var kovarians, variansX, variansY float64
+	for i := range x {
+		dx, dy := x[i]-rataX, y[i]-rataY
+		kovarians += dx * dy
+		variansX += dx * dx
+		variansY += dy * dy
+	}
+	if variansX == 0 || variansY == 0 {
+		return nil
+	}
+	r := kovarians / math.Sqrt(variansX*variansY)
+	return &r
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"cbt-api/entity"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestTandaiKelompok(t *testing.T) {
+	tests := []struct {
+		name       string
+		total      []float64 // peserta ke-i punya idSiswa i+1
+		wantUkuran int
+		want       []int
+	}{
+		{"no peserta", nil, 0, nil},
+		{"single siswa", []float64{10}, 0, []int{0}},
+		{"two siswa", []float64{10, 20}, 1, []int{-1, 1}},
+		// 27% dari 5 = 1.35, dibulatkan 1
+		{"five siswa", []float64{20, 10, 10, 0, 20}, 1, []int{1, 0, 0, -1, 0}},
+		// 27% dari 10 = 2.7, dibulatkan 3
+		{"ten siswa", []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 3, []int{-1, -1, -1, 0, 0, 0, 0, 1, 1, 1}},
+		// Nilai sama diurutkan menurut idSiswa, jadi siswa dengan id terkecil masuk kelompok atas
+		{"ties", []float64{5, 5, 5}, 1, []int{1, 0, -1}},
+	}
+	for _, tt := range tests {
+		peserta := make([]*pesertaAnalisis, 0, len(tt.total))
+		for i, total := range tt.total {
+			peserta = append(peserta, &pesertaAnalisis{idSiswa: uint64(i + 1), total: total})
+		}
+		ukuran := tandaiKelompok(peserta)
+		got := make([]int, 0, len(peserta))
+		for _, p := range peserta {
+			got = append(got, p.kelompok)
+		}
+		if ukuran != tt.wantUkuran || (len(tt.want) > 0 && !reflect.DeepEqual(got, tt.want)) {
+			t.Errorf("%s = size %d, groups %v; want %d, %v", tt.name, ukuran, got, tt.wantUkuran, tt.want)
+		}
+	}
+}
+
+func TestAnalisisSoal(t *testing.T) {
+	pg := entity.Soal{IdSoal: 1, TipeSoal: entity.TipeSoal{NamaTipeUjian: entity.TipeSoalPilihanBerganda}}
+	isian := entity.Soal{IdSoal: 1, TipeSoal: entity.TipeSoal{NamaTipeUjian: entity.TipeSoalIsian}}
+	opsi := []entity.JawabanSoal{
+		{IdJawabanSoal: 11, Jawaban: "A", Benar: true},
+		{IdJawabanSoal: 12, Jawaban: "B"},
+		{IdJawabanSoal: 13, Jawaban: "C"},
+	}
+	pilih := func(id uint64) NilaiSoal {
+		nilaiSoal := NilaiSoal{IdSoal: 1, IdJawabanSoal: id, Dijawab: true}
+		if id == 11 {
+			nilaiSoal.proporsi = 1
+		}
+		return nilaiSoal
+	}
+	peserta := func(id uint64, total float64, kelompok int, nilaiSoal NilaiSoal) *pesertaAnalisis {
+		return &pesertaAnalisis{idSiswa: id, total: total, kelompok: kelompok, nilai: map[uint64]NilaiSoal{1: nilaiSoal}}
+	}
+
+	tests := []struct {
+		name                           string
+		soal                           entity.Soal
+		opsi                           []entity.JawabanSoal
+		peserta                        []*pesertaAnalisis
+		ukuran                         int
+		jumlah, tidakDijawab, menunggu int
+		kesukaran, dayaBeda, korelasi  *float64
+		opsiWant                       []AnalisisOpsi
+		catatan                        []string
+	}{
+		{
+			// Benar: siswa 2 dan 4, jadi p = 2/4. Kelompok atas (siswa 1) salah, kelompok bawah
+			// (siswa 4) benar: D = 0 - 1. r = -7.5 / sqrt(1 * 218.75).
+			name: "upper group favours a distractor",
+			soal: pg, opsi: opsi, ukuran: 1,
+			peserta: []*pesertaAnalisis{
+				peserta(1, 30, 1, pilih(12)),
+				peserta(2, 20, 0, pilih(11)),
+				peserta(3, 15, 0, pilih(13)),
+				peserta(4, 10, -1, pilih(11)),
+			},
+			jumlah:    4,
+			kesukaran: ptr(0.5), dayaBeda: ptr(-1), korelasi: ptr(-7.5 / math.Sqrt(218.75)),
+			opsiWant: []AnalisisOpsi{
+				{IdJawabanSoal: 11, Jawaban: "A", Benar: true, JumlahPemilih: 2, Proporsi: 0.5, PemilihBawah: 1},
+				{IdJawabanSoal: 12, Jawaban: "B", JumlahPemilih: 1, Proporsi: 0.25, PemilihAtas: 1},
+				{IdJawabanSoal: 13, Jawaban: "C", JumlahPemilih: 1, Proporsi: 0.25},
+			},
+			catatan: []string{
+				"daya beda negatif: kelompok bawah lebih banyak menjawab benar (D = -1.00)",
+				"korelasi point-biserial rendah: r = -0.51",
+				`pengecoh "B" lebih banyak dipilih kelompok atas`,
+			},
+		},
+		{
+			name: "single siswa",
+			soal: pg, opsi: opsi, ukuran: 0,
+			peserta:   []*pesertaAnalisis{peserta(1, 10, 0, pilih(11))},
+			jumlah:    1,
+			kesukaran: ptr(1),
+			opsiWant: []AnalisisOpsi{
+				{IdJawabanSoal: 11, Jawaban: "A", Benar: true, JumlahPemilih: 1, Proporsi: 1},
+				{IdJawabanSoal: 12, Jawaban: "B"},
+				{IdJawabanSoal: 13, Jawaban: "C"},
+			},
+			catatan: []string{
+				"terlalu mudah: p = 1.00",
+				`pengecoh "B" tidak berfungsi: dipilih 0 peserta`,
+				`pengecoh "C" tidak berfungsi: dipilih 0 peserta`,
+			},
+		},
+		{
+			name: "no correct option",
+			soal: pg, opsi: opsi[1:], ukuran: 1,
+			peserta: []*pesertaAnalisis{
+				peserta(1, 20, 1, pilih(12)),
+				peserta(2, 10, -1, pilih(13)),
+			},
+			jumlah:    2,
+			kesukaran: ptr(0), dayaBeda: ptr(0),
+			opsiWant: []AnalisisOpsi{
+				{IdJawabanSoal: 12, Jawaban: "B", JumlahPemilih: 1, Proporsi: 0.5, PemilihAtas: 1},
+				{IdJawabanSoal: 13, Jawaban: "C", JumlahPemilih: 1, Proporsi: 0.5, PemilihBawah: 1},
+			},
+			catatan: []string{
+				"terlalu sulit: p = 0.00",
+				"daya beda rendah: D = 0.00",
+				`pengecoh "B" lebih banyak dipilih kelompok atas`,
+				"soal tidak punya kunci jawaban",
+			},
+		},
+		{
+			// Jawaban yang menunggu guru tidak dihitung; dua peserta tersisa menentukan p, D dan r
+			name: "Isian waiting for the guru",
+			soal: isian, opsi: []entity.JawabanSoal{{IdJawabanSoal: 21, Jawaban: "Jakarta", Benar: true}}, ukuran: 1,
+			peserta: []*pesertaAnalisis{
+				peserta(1, 20, 1, NilaiSoal{IdSoal: 1, Isian: true, Dijawab: true, proporsi: 1}),
+				peserta(2, 15, 0, NilaiSoal{IdSoal: 1, Isian: true, Dijawab: true, MenungguPenilaian: true}),
+				peserta(3, 0, -1, NilaiSoal{IdSoal: 1, Isian: true}),
+			},
+			jumlah: 2, tidakDijawab: 1, menunggu: 1,
+			kesukaran: ptr(0.5), dayaBeda: ptr(1), korelasi: ptr(1),
+			opsiWant: []AnalisisOpsi{},
+			catatan:  []string{},
+		},
+		{
+			name: "every answer waiting for the guru",
+			soal: isian, ukuran: 0,
+			peserta:  []*pesertaAnalisis{peserta(1, 0, 0, NilaiSoal{IdSoal: 1, Isian: true, Dijawab: true, MenungguPenilaian: true})},
+			menunggu: 1,
+			opsiWant: []AnalisisOpsi{},
+			catatan:  []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			butir := analisisSoal(tt.soal, tt.opsi, tt.peserta, tt.ukuran)
+			if butir.JumlahPeserta != tt.jumlah || butir.JumlahTidakDijawab != tt.tidakDijawab || butir.JumlahMenungguPenilaian != tt.menunggu {
+				t.Errorf("peserta %d, tidak dijawab %d, menunggu %d; want %d, %d, %d",
+					butir.JumlahPeserta, butir.JumlahTidakDijawab, butir.JumlahMenungguPenilaian, tt.jumlah, tt.tidakDijawab, tt.menunggu)
+			}
+			cekAngka(t, "tingkat kesukaran", butir.TingkatKesukaran, tt.kesukaran)
+			cekAngka(t, "daya beda", butir.DayaBeda, tt.dayaBeda)
+			cekAngka(t, "point-biserial", butir.KorelasiPointBiserial, tt.korelasi)
+			if !reflect.DeepEqual(butir.Opsi, tt.opsiWant) {
+				t.Errorf("opsi = %+v\nwant %+v", butir.Opsi, tt.opsiWant)
+			}
+			if !reflect.DeepEqual(butir.Catatan, tt.catatan) || butir.PerluRevisi != (len(tt.catatan) > 0) {
+				t.Errorf("catatan = %q (revisi %v), want %q", butir.Catatan, butir.PerluRevisi, tt.catatan)
+			}
+		})
+	}
+}
+
+// Lima siswa menyelesaikan ujian tiga soal; siswa 6 belum submit dan tidak ikut dihitung
+func TestAnalisisButirPeserta(t *testing.T) {
+	pg := entity.TipeSoal{NamaTipeUjian: entity.TipeSoalPilihanBerganda}
+	ujian := entity.Ujian{IdUjian: 1, Grade: 30}
+	soalList := []entity.Soal{
+		{IdSoal: 1, NilaiPerSoal: 10, TipeSoal: pg},
+		{IdSoal: 2, NilaiPerSoal: 10, TipeSoal: pg},
+		{IdSoal: 3, NilaiPerSoal: 10, TipeSoal: pg}, // tidak dijawab siapa pun
+	}
+	kunci := []entity.JawabanSoal{
+		{IdJawabanSoal: 11, IdSoal: 1, Jawaban: "A", Benar: true}, {IdJawabanSoal: 12, IdSoal: 1, Jawaban: "B"}, {IdJawabanSoal: 13, IdSoal: 1, Jawaban: "C"},
+		{IdJawabanSoal: 21, IdSoal: 2, Jawaban: "D", Benar: true}, {IdJawabanSoal: 22, IdSoal: 2, Jawaban: "E"},
+		{IdJawabanSoal: 31, IdSoal: 3, Jawaban: "F", Benar: true}, {IdJawabanSoal: 32, IdSoal: 3, Jawaban: "G"},
+	}
+	var attempts []entity.UjianAttempt
+	var jawabanList []entity.JawabanSiswa
+	// Pilihan siswa 1-5 untuk soal 1 dan 2; nilai mentah 20, 10, 10, 0, 20
+	pilihan := [][2]uint64{{11, 21}, {11, 22}, {12, 21}, {12, 22}, {11, 21}, {11, 21}}
+	for i, p := range pilihan {
+		siswaID, attemptID := uint64(i+1), uint64(100+i+1)
+		status := entity.StatusAttemptSelesai
+		if siswaID == 6 {
+			status = entity.StatusAttemptBerlangsung
+		}
+		attempts = append(attempts, entity.UjianAttempt{IdUjianAttempt: attemptID, IdUjian: 1, IdSiswa: siswaID, Status: status})
+		for soalID, opsiID := range p {
+			jawabanList = append(jawabanList, entity.JawabanSiswa{
+				IdSoal: uint64(soalID + 1), IdSiswa: siswaID, IdJawabanSoal: opsiID, IdUjianAttempt: &attemptID,
+			})
+		}
+	}
+
+	peserta := pesertaUjian(ujian, soalList, kunci, attempts, jawabanList)
+	var total []float64
+	for _, p := range peserta {
+		total = append(total, p.total)
+	}
+	if !reflect.DeepEqual(total, []float64{20, 10, 10, 0, 20}) {
+		t.Fatalf("peserta totals = %v, want [20 10 10 0 20]", total)
+	}
+	// Urutan: siswa 1 dan 5 (20), 2 dan 3 (10), 4 (0); kelompok masing-masing satu siswa
+	if ukuran := tandaiKelompok(peserta); ukuran != 1 || peserta[0].kelompok != 1 || peserta[3].kelompok != -1 || peserta[4].kelompok != 0 {
+		t.Fatalf("size %d, siswa 1 group %d, siswa 4 group %d, siswa 5 group %d; want 1, 1, -1, 0",
+			ukuran, peserta[0].kelompok, peserta[3].kelompok, peserta[4].kelompok)
+	}
+
+	opsiPerSoal := kunciPerSoal(kunci)
+	// Soal 1: benar 3 dari 5, p = 0.6; atas benar, bawah salah, D = 1.
+	// Skor [1 1 0 0 1] terhadap total [20 10 10 0 20]: kovarians 14, varians 1.2 dan 280.
+	soal1 := analisisSoal(soalList[0], opsiPerSoal[1], peserta, 1)
+	cekAngka(t, "soal 1 p", soal1.TingkatKesukaran, ptr(0.6))
+	cekAngka(t, "soal 1 D", soal1.DayaBeda, ptr(1))
+	cekAngka(t, "soal 1 r", soal1.KorelasiPointBiserial, ptr(14/math.Sqrt(1.2*280)))
+	if want := []int{3, 2, 0}; soal1.Opsi[0].JumlahPemilih != want[0] || soal1.Opsi[1].JumlahPemilih != want[1] || soal1.Opsi[2].JumlahPemilih != want[2] {
+		t.Errorf("soal 1 pemilih = %+v, want %v", soal1.Opsi, want)
+	}
+	if !reflect.DeepEqual(soal1.Catatan, []string{`pengecoh "C" tidak berfungsi: dipilih 0 peserta`}) {
+		t.Errorf("soal 1 catatan = %q", soal1.Catatan)
+	}
+
+	// Soal 2 punya p, D dan r yang sama tanpa pengecoh mati
+	soal2 := analisisSoal(soalList[1], opsiPerSoal[2], peserta, 1)
+	cekAngka(t, "soal 2 p", soal2.TingkatKesukaran, ptr(0.6))
+	cekAngka(t, "soal 2 D", soal2.DayaBeda, ptr(1))
+	cekAngka(t, "soal 2 r", soal2.KorelasiPointBiserial, ptr(14/math.Sqrt(1.2*280)))
+	if soal2.PerluRevisi {
+		t.Errorf("soal 2 flagged: %q", soal2.Catatan)
+	}
+
+	soal3 := analisisSoal(soalList[2], opsiPerSoal[3], peserta, 1)
+	if soal3.JumlahPeserta != 5 || soal3.JumlahTidakDijawab != 5 || soal3.KorelasiPointBiserial != nil {
+		t.Errorf("unanswered soal = peserta %d, tidak dijawab %d, r %v; want 5, 5, nil",
+			soal3.JumlahPeserta, soal3.JumlahTidakDijawab, soal3.KorelasiPointBiserial)
+	}
+	cekAngka(t, "soal 3 p", soal3.TingkatKesukaran, ptr(0))
+	cekAngka(t, "soal 3 D", soal3.DayaBeda, ptr(0))
+	if want := []string{"terlalu sulit: p = 0.00", "daya beda rendah: D = 0.00", `pengecoh "G" tidak berfungsi: dipilih 0 peserta`}; !reflect.DeepEqual(soal3.Catatan, want) {
+		t.Errorf("soal 3 catatan = %q, want %q", soal3.Catatan, want)
+	}
+}
+
+func ptr(v float64) *float64 { return &v }
+
+func cekAngka(t *testing.T, nama string, got *float64, want *float64) {
+	t.Helper()
+	if (got == nil) != (want == nil) || (got != nil && math.Abs(*got-*want) > 1e-9) {
+		t.Errorf("%s = %v, want %v", nama, tampilAngka(got), tampilAngka(want))
+	}
+}
+
+func tampilAngka(v *float64) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
@@ -0,0 +1,47 @@
+// service/aturan_penilaian.go
+package service
+
+import (
+	"cbt-api/entity"
+)
+
+// poinSoal returns the points of a fully correct answer: the ujian's SkorBenar when set, otherwise
+// the soal's own nilai_per_soal
+func poinSoal(ujian entity.Ujian, soal entity.Soal) float64 {
+	if ujian.SkorBenar != nil {
+		return *ujian.SkorBenar
+	}
+	return soal.NilaiPerSoal
+}
+
+// nilaiSoalDenganAturan turns the share of points earned on one soal into its score: blank answers
+// get SkorKosong, answers that earn nothing get SkorSalah and Isian answers waiting for the guru
+// count as zero until graded
+func nilaiSoalDenganAturan(ujian entity.Ujian, nilaiSoal NilaiSoal, proporsi float64) float64 {
+	switch {
+	case nilaiSoal.MenungguPenilaian:
+		return 0
+	case !nilaiSoal.Dijawab:
+		return ujian.SkorKosong
+	case proporsi <= 0:
+		return ujian.SkorSalah
+	default:
+		return proporsi * nilaiSoal.NilaiPerSoal
+	}
+}
+
+// terapkanAturanUjian derives NilaiUjian from NilaiMentah: negative totals are raised to zero unless
+// the ujian allows them, and with SkalakanKeGrade the score is scaled so that NilaiMaksimal becomes Grade
+func terapkanAturanUjian(hasil *HasilUjian, ujian entity.Ujian) {
+	hasil.NilaiUjian = hasil.NilaiMentah
+	if !ujian.IzinkanNilaiNegatif && hasil.NilaiUjian < 0 {
+		hasil.NilaiUjian = 0
+	}
+	if !ujian.SkalakanKeGrade || ujian.Grade <= 0 {
+		return
+	}
+	if hasil.NilaiMaksimal > 0 {
+		hasil.NilaiUjian = hasil.NilaiUjian / hasil.NilaiMaksimal * ujian.Grade
+	}
+	hasil.NilaiMaksimal = ujian.Grade
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"cbt-api/entity"
+	"math"
+	"testing"
+)
+
+func TestPoinSoal(t *testing.T) {
+	soal := entity.Soal{NilaiPerSoal: 15}
+	if got := poinSoal(entity.Ujian{}, soal); got != 15 {
+		t.Errorf("without SkorBenar = %v, want nilai_per_soal 15", got)
+	}
+	skor := 4.0
+	if got := poinSoal(entity.Ujian{SkorBenar: &skor}, soal); got != 4 {
+		t.Errorf("with SkorBenar = %v, want 4", got)
+	}
+	nol := 0.0
+	if got := poinSoal(entity.Ujian{SkorBenar: &nol}, soal); got != 0 {
+		t.Errorf("with SkorBenar 0 = %v, want 0", got)
+	}
+}
+
+func TestNilaiSoalDenganAturan(t *testing.T) {
+	ujian := entity.Ujian{SkorSalah: -1, SkorKosong: -0.5}
+	tests := []struct {
+		name      string
+		nilaiSoal NilaiSoal
+		proporsi  float64
+		want      float64
+	}{
+		{"correct", NilaiSoal{Dijawab: true, NilaiPerSoal: 4}, 1, 4},
+		{"partial", NilaiSoal{Dijawab: true, NilaiPerSoal: 4}, 0.5, 2},
+		{"wrong", NilaiSoal{Dijawab: true, NilaiPerSoal: 4}, 0, -1},
+		{"blank", NilaiSoal{NilaiPerSoal: 4}, 0, -0.5},
+		{"Isian waiting for the guru", NilaiSoal{Dijawab: true, Isian: true, MenungguPenilaian: true, NilaiPerSoal: 4}, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := nilaiSoalDenganAturan(ujian, tt.nilaiSoal, tt.proporsi); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTerapkanAturanUjian(t *testing.T) {
+	tests := []struct {
+		name                string
+		ujian               entity.Ujian
+		mentah, maksimal    float64
+		wantNilai, wantMaks float64
+	}{
+		{"raw score kept", entity.Ujian{Grade: 100}, 30, 40, 30, 40},
+		{"negative raised to zero", entity.Ujian{Grade: 100}, -3, 40, 0, 40},
+		{"negative allowed", entity.Ujian{Grade: 100, IzinkanNilaiNegatif: true}, -3, 40, -3, 40},
+		{"scaled to grade", entity.Ujian{Grade: 100, SkalakanKeGrade: true}, 30, 40, 75, 100},
+		{"negative scaled", entity.Ujian{Grade: 100, SkalakanKeGrade: true, IzinkanNilaiNegatif: true}, -4, 40, -10, 100},
+		{"zero floor before scaling", entity.Ujian{Grade: 100, SkalakanKeGrade: true}, -4, 40, 0, 100},
+		{"no points to scale", entity.Ujian{Grade: 100, SkalakanKeGrade: true}, 0, 0, 0, 100},
+		{"grade zero is not scaled", entity.Ujian{SkalakanKeGrade: true}, 30, 40, 30, 40},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasil := HasilUjian{NilaiMentah: tt.mentah, NilaiMaksimal: tt.maksimal}
+			terapkanAturanUjian(&hasil, tt.ujian)
+			if math.Abs(hasil.NilaiUjian-tt.wantNilai) > 1e-9 || hasil.NilaiMaksimal != tt.wantMaks {
+				t.Errorf("nilai %v of %v, want %v of %v", hasil.NilaiUjian, hasil.NilaiMaksimal, tt.wantNilai, tt.wantMaks)
+			}
+			if hasil.NilaiMentah != tt.mentah {
+				t.Errorf("NilaiMentah changed to %v", hasil.NilaiMentah)
+			}
+		})
+	}
+}
+
+// Format tryout: benar +4, salah -1, kosong 0
+func TestNilaiUjianDenganAturanTryout(t *testing.T) {
+	pg := entity.TipeSoal{NamaTipeUjian: entity.TipeSoalPilihanBerganda}
+	soalList := []entity.Soal{
+		{IdSoal: 1, NilaiPerSoal: 10, TipeSoal: pg},
+		{IdSoal: 2, NilaiPerSoal: 10, TipeSoal: pg},
+		{IdSoal: 3, NilaiPerSoal: 10, TipeSoal: pg},
+	}
+	kunci := []entity.JawabanSoal{
+		{IdJawabanSoal: 11, IdSoal: 1, Benar: true}, {IdJawabanSoal: 12, IdSoal: 1},
+		{IdJawabanSoal: 21, IdSoal: 2, Benar: true}, {IdJawabanSoal: 22, IdSoal: 2},
+		{IdJawabanSoal: 31, IdSoal: 3, Benar: true}, {IdJawabanSoal: 32, IdSoal: 3},
+	}
+	skorBenar := 4.0
+	ujian := entity.Ujian{Grade: 100, SkorBenar: &skorBenar, SkorSalah: -1, SkalakanKeGrade: true}
+
+	var hasil HasilUjian
+	nilaiUjian(&hasil, ujian, soalList, kunci, []entity.JawabanSiswa{
+		{IdSoal: 1, IdJawabanSoal: 11},
+		{IdSoal: 2, IdJawabanSoal: 22},
+	})
+	if hasil.NilaiMentah != 3 || hasil.NilaiMaksimal != 100 || math.Abs(hasil.NilaiUjian-25) > 1e-9 {
+		t.Errorf("mentah %v, nilai %v of %v; want 3, 25 of 100", hasil.NilaiMentah, hasil.NilaiUjian, hasil.NilaiMaksimal)
+	}
+
+	hasil = HasilUjian{}
+	nilaiUjian(&hasil, ujian, soalList, kunci, []entity.JawabanSiswa{
+		{IdSoal: 1, IdJawabanSoal: 12},
+		{IdSoal: 2, IdJawabanSoal: 22},
+	})
+	if hasil.NilaiMentah != -2 || hasil.NilaiUjian != 0 {
+		t.Errorf("all wrong: mentah %v, nilai %v; want -2, 0", hasil.NilaiMentah, hasil.NilaiUjian)
+	}
+}
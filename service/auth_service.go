@@ -0,0 +1,290 @@
+// service/auth_service.go
+package service
+
+import (
+	"cbt-api/entity"
+	"cbt-api/repository"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrSessionRevoked      = errors.New("session has been revoked")
+	ErrUserNotFound        = errors.New("User not found")
+	ErrIncorrectPassword   = errors.New("Incorrect password")
+	ErrUserTanpaRole       = errors.New("User tidak memiliki role")
+)
+
+// TokenPair is returned on login and on every refresh
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"` // detik sampai access token kedaluwarsa
+}
+
+// LoginResult is the identity found for an email/password pair: the token subject
+// plus the role rows whose names are shown to the client
+type LoginResult struct {
+	Subject  TokenSubject
+	Siswa    entity.Siswa
+	Guru     entity.Guru
+	Operator entity.Operator
+}
+
+// AuthService is a contract for login sessions: issuing, rotating and revoking tokens
+type AuthService interface {
+	Authenticate(email string, password string) (LoginResult, error)
+	IssueTokens(subject TokenSubject, userAgent string) (TokenPair, error)
+	RefreshTokens(refreshToken string) (TokenPair, error)
+	Logout(tokenID string) error
+	RevokeAllSessions(userID uint64) (int64, error)
+	IsSessionActive(tokenID string) (bool, error)
+}
+
+type authService struct {
+	userRepository        repository.UserRepository
+	userSessionRepository repository.UserSessionRepository
+	jwtService            JWTService
+	config                JWTConfig
+}
+
+// NewAuthService creates a new instance of AuthService
+func NewAuthService(
+	userRepo repository.UserRepository,
+	userSessionRepo repository.UserSessionRepository,
+	jwtService JWTService,
+	config JWTConfig,
+) AuthService {
+	return &authService{
+		userRepository:        userRepo,
+		userSessionRepository: userSessionRepo,
+		jwtService:            jwtService,
+		config:                config,
+	}
+}
+
+// Authenticate checks the password and resolves the roles from the siswa, guru, operator
+// and admin rows linked to the user
+func (s *authService) Authenticate(email string, password string) (LoginResult, error) {
+	user, err := s.userRepository.FindByEmail(email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return LoginResult{}, ErrUserNotFound
+		}
+		return LoginResult{}, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return LoginResult{}, ErrIncorrectPassword
+	}
+
+	result := LoginResult{
+		Subject: TokenSubject{
+			UserID: user.Id,
+			Email:  user.Email,
+		},
+	}
+
+	if result.Siswa, err = s.userRepository.FindSiswaByUserID(user.Id); err != nil {
+		return LoginResult{}, err
+	}
+	if result.Siswa.IdSiswa != 0 {
+		result.Subject.Roles = append(result.Subject.Roles, entity.RoleSiswa)
+		result.Subject.IdSiswa = result.Siswa.IdSiswa
+	}
+
+	if result.Guru, err = s.userRepository.FindGuruByUserID(user.Id); err != nil {
+		return LoginResult{}, err
+	}
+	if result.Guru.IdGuru != 0 {
+		result.Subject.Roles = append(result.Subject.Roles, entity.RoleGuru)
+		result.Subject.IdGuru = result.Guru.IdGuru
+	}
+
+	if result.Operator, err = s.userRepository.FindOperatorByUserID(user.Id); err != nil {
+		return LoginResult{}, err
+	}
+	if result.Operator.IdOperator != 0 {
+		result.Subject.Roles = append(result.Subject.Roles, entity.RoleOperator)
+		result.Subject.IdOperator = result.Operator.IdOperator
+	}
+
+	admin, err := s.userRepository.FindAdminByUserID(user.Id)
+	if err != nil {
+		return LoginResult{}, err
+	}
+	if admin.IdAdmin != 0 {
+		result.Subject.Roles = append(result.Subject.Roles, entity.RoleAdmin)
+	}
+
+	if len(result.Subject.Roles) == 0 {
+		return result, ErrUserTanpaRole
+	}
+	return result, nil
+}
+
+// IssueTokens starts a new session and returns its first access/refresh token pair
+func (s *authService) IssueTokens(subject TokenSubject, userAgent string) (TokenPair, error) {
+	tokenID, err := randomToken(16)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	now := time.Now()
+	session := entity.UserSession{
+		TokenID:          tokenID,
+		RefreshTokenHash: hashToken(refreshToken),
+		IdUser:           subject.UserID,
+		Email:            subject.Email,
+		Roles:            strings.Join(subject.Roles, ","),
+		IdSiswa:          subject.IdSiswa,
+		IdGuru:           subject.IdGuru,
+		IdOperator:       subject.IdOperator,
+		UserAgent:        truncate(userAgent, 255),
+		ExpiresAt:        now.Add(s.config.RefreshTTL),
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if _, err := s.userSessionRepository.CreateSession(session); err != nil {
+		return TokenPair{}, err
+	}
+
+	return s.tokenPair(session, refreshToken)
+}
+
+// RefreshTokens rotates the refresh token: the presented token stops working and a new pair is issued.
+// The session (and its jti) stays the same so logout still covers every access token it produced.
+// Presenting a token that was already rotated means it leaked, so the whole session is revoked.
+func (s *authService) RefreshTokens(refreshToken string) (TokenPair, error) {
+	oldHash := hashToken(refreshToken)
+	session, err := s.userSessionRepository.GetSessionByRefreshTokenHash(oldHash)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return TokenPair{}, s.revokeReusedToken(oldHash)
+	}
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	now := time.Now()
+	if session.RevokedAt != nil {
+		return TokenPair{}, ErrSessionRevoked
+	}
+	if now.After(session.ExpiresAt) {
+		return TokenPair{}, ErrInvalidRefreshToken
+	}
+
+	newRefreshToken, err := randomToken(32)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	session.RefreshTokenHash = hashToken(newRefreshToken)
+	session.ExpiresAt = now.Add(s.config.RefreshTTL)
+	session.UpdatedAt = now
+	rotated, err := s.userSessionRepository.RotateRefreshToken(session, oldHash)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if !rotated {
+		// Refresh lain dengan token yang sama menang lebih dulu: token ini sekarang token lama
+		return TokenPair{}, s.revokeReusedToken(oldHash)
+	}
+
+	return s.tokenPair(session, newRefreshToken)
+}
+
+// revokeReusedToken revokes the session of a refresh token that was already rotated and returns
+// ErrSessionRevoked, or ErrInvalidRefreshToken when the token is unknown
+func (s *authService) revokeReusedToken(hash string) error {
+	session, err := s.userSessionRepository.GetSessionByUsedRefreshTokenHash(hash)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		return err
+	}
+	if err := s.userSessionRepository.RevokeSession(session.TokenID, time.Now()); err != nil {
+		return err
+	}
+	return ErrSessionRevoked
+}
+
+// Logout revokes the session the access token belongs to
+func (s *authService) Logout(tokenID string) error {
+	return s.userSessionRepository.RevokeSession(tokenID, time.Now())
+}
+
+// RevokeAllSessions logs a user out of every device
+func (s *authService) RevokeAllSessions(userID uint64) (int64, error) {
+	return s.userSessionRepository.RevokeAllSessionsByUserID(userID, time.Now())
+}
+
+// IsSessionActive is used by the auth middleware to reject access tokens of revoked sessions
+func (s *authService) IsSessionActive(tokenID string) (bool, error) {
+	if tokenID == "" {
+		return false, nil
+	}
+	session, err := s.userSessionRepository.GetSessionByTokenID(tokenID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return session.RevokedAt == nil && time.Now().Before(session.ExpiresAt), nil
+}
+
+func (s *authService) tokenPair(session entity.UserSession, refreshToken string) (TokenPair, error) {
+	accessToken, err := s.jwtService.GenerateToken(TokenSubject{
+		UserID:     session.IdUser,
+		Email:      session.Email,
+		Roles:      strings.Split(session.Roles, ","),
+		IdSiswa:    session.IdSiswa,
+		IdGuru:     session.IdGuru,
+		IdOperator: session.IdOperator,
+		TokenID:    session.TokenID,
+	})
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	return TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.config.TTL.Seconds()),
+	}, nil
+}
+
+// randomToken returns n random bytes encoded as URL-safe base64
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken hashes a refresh token so the raw value is never stored
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
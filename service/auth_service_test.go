@@ -0,0 +1,285 @@
+package service
+
+import (
+	"cbt-api/entity"
+	"cbt-api/repository"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// sesiRepoPalsu keeps sessions in memory, indexed by id, and the rotated refresh token hashes in lama
+type sesiRepoPalsu struct {
+	repository.UserSessionRepository
+	sesi []entity.UserSession
+	lama map[string]uint64
+}
+
+func (r *sesiRepoPalsu) CreateSession(session entity.UserSession) (entity.UserSession, error) {
+	session.IdUserSession = uint64(len(r.sesi) + 1)
+	r.sesi = append(r.sesi, session)
+	return session, nil
+}
+
+func (r *sesiRepoPalsu) RotateRefreshToken(session entity.UserSession, oldHash string) (bool, error) {
+	lama := r.sesi[session.IdUserSession-1]
+	if lama.RefreshTokenHash != oldHash || lama.RevokedAt != nil {
+		return false, nil
+	}
+	r.sesi[session.IdUserSession-1] = session
+	if r.lama == nil {
+		r.lama = make(map[string]uint64)
+	}
+	r.lama[oldHash] = session.IdUserSession
+	return true, nil
+}
+
+func (r *sesiRepoPalsu) cari(cocok func(entity.UserSession) bool) (entity.UserSession, error) {
+	for _, session := range r.sesi {
+		if cocok(session) {
+			return session, nil
+		}
+	}
+	return entity.UserSession{}, gorm.ErrRecordNotFound
+}
+
+func (r *sesiRepoPalsu) GetSessionByTokenID(tokenID string) (entity.UserSession, error) {
+	return r.cari(func(s entity.UserSession) bool { return s.TokenID == tokenID })
+}
+
+func (r *sesiRepoPalsu) GetSessionByRefreshTokenHash(hash string) (entity.UserSession, error) {
+	return r.cari(func(s entity.UserSession) bool { return s.RefreshTokenHash == hash })
+}
+
+func (r *sesiRepoPalsu) GetSessionByUsedRefreshTokenHash(hash string) (entity.UserSession, error) {
+	id, ok := r.lama[hash]
+	if !ok {
+		return entity.UserSession{}, gorm.ErrRecordNotFound
+	}
+	return r.sesi[id-1], nil
+}
+
+func (r *sesiRepoPalsu) RevokeSession(tokenID string, revokedAt time.Time) error {
+	for i := range r.sesi {
+		if r.sesi[i].TokenID == tokenID && r.sesi[i].RevokedAt == nil {
+			r.sesi[i].RevokedAt = &revokedAt
+		}
+	}
+	return nil
+}
+
+func (r *sesiRepoPalsu) RevokeAllSessionsByUserID(userID uint64, revokedAt time.Time) (int64, error) {
+	var n int64
+	for i := range r.sesi {
+		if r.sesi[i].IdUser == userID && r.sesi[i].RevokedAt == nil {
+			r.sesi[i].RevokedAt = &revokedAt
+			n++
+		}
+	}
+	return n, nil
+}
+
+func newAuthServiceTest() (AuthService, JWTService, *sesiRepoPalsu) {
+	config := JWTConfig{Secret: "rahasia", Issuer: "cbt-api", TTL: time.Minute, RefreshTTL: time.Hour}
+	jwtService := NewJWTService(config)
+	repo := &sesiRepoPalsu{}
+	return NewAuthService(nil, repo, jwtService, config), jwtService, repo
+}
+
+func TestRefreshTokensRotates(t *testing.T) {
+	auth, jwtService, _ := newAuthServiceTest()
+	pair, err := auth.IssueTokens(TokenSubject{UserID: 3, Email: "siswa@example.com", Roles: []string{"siswa", "guru"}, IdSiswa: 7, IdGuru: 4}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := jwtService.ValidateToken(pair.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pair.ExpiresIn != 60 || claims.ID == "" || claims.IdSiswa != 7 {
+		t.Errorf("pair = expires_in %d, jti %q, id_siswa %d", pair.ExpiresIn, claims.ID, claims.IdSiswa)
+	}
+
+	baru, err := auth.RefreshTokens(pair.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if baru.RefreshToken == pair.RefreshToken {
+		t.Error("the refresh token was not rotated")
+	}
+	claimsBaru, err := jwtService.ValidateToken(baru.AccessToken)
+	if err != nil || claimsBaru.ID != claims.ID {
+		t.Fatalf("refreshed access token jti = %v, %v; want the session's %q", claimsBaru, err, claims.ID)
+	}
+	if !claimsBaru.HasRole("siswa") || !claimsBaru.HasRole("guru") || claimsBaru.IdGuru != 4 {
+		t.Errorf("refreshed access token roles %v, id_guru %d; want siswa and guru, 4", claimsBaru.Roles, claimsBaru.IdGuru)
+	}
+	if _, err := auth.RefreshTokens("tidak-dikenal"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("unknown refresh token = %v, want ErrInvalidRefreshToken", err)
+	}
+
+	// Token lama yang dipakai ulang mencabut sesi, termasuk token penggantinya
+	if _, err := auth.RefreshTokens(pair.RefreshToken); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("replayed refresh token = %v, want ErrSessionRevoked", err)
+	}
+	if active, _ := auth.IsSessionActive(claims.ID); active {
+		t.Error("session still active after a rotated refresh token was replayed")
+	}
+	if _, err := auth.RefreshTokens(baru.RefreshToken); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("refresh with the current token after a replay = %v, want ErrSessionRevoked", err)
+	}
+}
+
+func TestRefreshTokensConcurrentRotatesOnce(t *testing.T) {
+	db := bukaBasisData(t)
+	config := JWTConfig{Secret: "rahasia", Issuer: "cbt-api", TTL: time.Minute, RefreshTTL: time.Hour}
+	sessionRepo := repository.NewUserSessionRepository(db)
+	auth := NewAuthService(nil, sessionRepo, NewJWTService(config), config)
+	pair, err := auth.IssueTokens(TokenSubject{UserID: 3, Roles: []string{"siswa"}}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Dua refresh dengan token yang sama: hanya satu yang boleh merotasi, yang lain dianggap pemakaian ulang
+	const n = 4
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = auth.RefreshTokens(pair.RefreshToken)
+		}(i)
+	}
+	wg.Wait()
+
+	var berhasil int
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			berhasil++
+		case !errors.Is(err, ErrSessionRevoked):
+			t.Errorf("losing refresh = %v, want ErrSessionRevoked", err)
+		}
+	}
+	if berhasil != 1 {
+		t.Errorf("%d refreshes rotated the token, want 1", berhasil)
+	}
+	session, err := sessionRepo.GetSessionByUsedRefreshTokenHash(hashToken(pair.RefreshToken))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.RevokedAt == nil {
+		t.Error("session still active after the refresh token was used twice")
+	}
+}
+
+func TestRefreshTokensExpired(t *testing.T) {
+	auth, _, repo := newAuthServiceTest()
+	pair, err := auth.IssueTokens(TokenSubject{UserID: 3, Roles: []string{"siswa"}}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.sesi[0].ExpiresAt = time.Now().Add(-time.Second)
+	if _, err := auth.RefreshTokens(pair.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("expired session = %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestLogoutRevokesSession(t *testing.T) {
+	auth, jwtService, _ := newAuthServiceTest()
+	pair, err := auth.IssueTokens(TokenSubject{UserID: 3, Roles: []string{"siswa"}}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lain, err := auth.IssueTokens(TokenSubject{UserID: 3, Roles: []string{"siswa"}}, "perangkat lain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, _ := jwtService.ValidateToken(pair.AccessToken)
+	claimsLain, _ := jwtService.ValidateToken(lain.AccessToken)
+
+	if active, err := auth.IsSessionActive(claims.ID); err != nil || !active {
+		t.Fatalf("new session active = %v, %v", active, err)
+	}
+	if err := auth.Logout(claims.ID); err != nil {
+		t.Fatal(err)
+	}
+	if active, _ := auth.IsSessionActive(claims.ID); active {
+		t.Error("session still active after logout")
+	}
+	if _, err := auth.RefreshTokens(pair.RefreshToken); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("refresh after logout = %v, want ErrSessionRevoked", err)
+	}
+	if active, _ := auth.IsSessionActive(claimsLain.ID); !active {
+		t.Error("logout ended the other device's session")
+	}
+
+	if n, err := auth.RevokeAllSessions(3); err != nil || n != 1 {
+		t.Errorf("RevokeAllSessions = %d, %v; want 1", n, err)
+	}
+	if active, _ := auth.IsSessionActive(claimsLain.ID); active {
+		t.Error("session still active after revoking all sessions")
+	}
+	if active, _ := auth.IsSessionActive(""); active {
+		t.Error("a token without jti counts as an active session")
+	}
+}
+
+func TestAuthenticateResolvesRoles(t *testing.T) {
+	db := bukaBasisData(t)
+	hash, err := bcrypt.GenerateFromPassword([]byte("rahasia"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buatUser := func(email string) entity.Users {
+		user := entity.Users{Name: email, Email: email, Password: string(hash)}
+		buatBaris(t, db, &user)
+		return user
+	}
+	siswaGuru := buatUser("siswa.guru@example.com")
+	siswa := entity.Siswa{NamaSiswa: "Siswa", Status: "Aktif", IdUser: siswaGuru.Id}
+	guru := entity.Guru{NamaGuru: "Guru", Status: "Aktif", IdUser: siswaGuru.Id}
+	buatBaris(t, db, &siswa)
+	buatBaris(t, db, &guru)
+	admin := buatUser("admin@example.com")
+	buatBaris(t, db, &entity.Admin{IdUser: admin.Id})
+	buatUser("tanpa.role@example.com")
+
+	config := JWTConfig{Secret: "rahasia", Issuer: "cbt-api", TTL: time.Minute, RefreshTTL: time.Hour}
+	auth := NewAuthService(repository.NewUserRepository(db), repository.NewUserSessionRepository(db), NewJWTService(config), config)
+
+	result, err := auth.Authenticate("siswa.guru@example.com", "rahasia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := result.Subject; len(s.Roles) != 2 || s.Roles[0] != entity.RoleSiswa || s.Roles[1] != entity.RoleGuru ||
+		s.IdSiswa != siswa.IdSiswa || s.IdGuru != guru.IdGuru || s.IdOperator != 0 {
+		t.Errorf("subject = %+v, want siswa %d and guru %d", s, siswa.IdSiswa, guru.IdGuru)
+	}
+	if result, err := auth.Authenticate("admin@example.com", "rahasia"); err != nil || len(result.Subject.Roles) != 1 || result.Subject.Roles[0] != entity.RoleAdmin {
+		t.Errorf("admin roles = %v, %v", result.Subject.Roles, err)
+	}
+	if _, err := auth.Authenticate("tanpa.role@example.com", "rahasia"); !errors.Is(err, ErrUserTanpaRole) {
+		t.Errorf("user without a role = %v, want ErrUserTanpaRole", err)
+	}
+	if _, err := auth.Authenticate("siswa.guru@example.com", "salah"); !errors.Is(err, ErrIncorrectPassword) {
+		t.Errorf("wrong password = %v, want ErrIncorrectPassword", err)
+	}
+	if _, err := auth.Authenticate("tidak.ada@example.com", "rahasia"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("unknown email = %v, want ErrUserNotFound", err)
+	}
+
+	// Sesi tersimpan di database dan bisa dirotasi
+	pair, err := auth.IssueTokens(result.Subject, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := auth.RefreshTokens(pair.RefreshToken); err != nil {
+		t.Errorf("refresh on the database = %v", err)
+	}
+}
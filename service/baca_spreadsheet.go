@@ -0,0 +1,248 @@
+// service/baca_spreadsheet.go
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+var ErrFormatSpreadsheet = errors.New("format spreadsheet tidak dapat dibaca")
+
+// Batas lembar kerja Excel; nomor baris atau sel di luarnya berarti file rusak
+const (
+	batasBarisXLSX = 1 << 20 // 1.048.576 baris
+	batasKolomXLSX = 1 << 14 // 16.384 kolom, sampai XFD
+	// batasSelXLSX caps the cells kept for the whole sheet, empty cells before a value included, so a
+	// small file with values far to the right on many rows cannot take up a lot of memory
+	batasSelXLSX = 1 << 21
+)
+
+// bacaCSV reads every record of a CSV file. Both comma and semicolon separated files are accepted,
+// since Excel with Indonesian regional settings exports with semicolons.
+func bacaCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	barisPertama := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		barisPertama = data[:i]
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	if bytes.Count(barisPertama, []byte(";")) > bytes.Count(barisPertama, []byte(",")) {
+		reader.Comma = ';'
+	}
+	baris, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFormatSpreadsheet, err)
+	}
+	return baris, nil
+}
+
+// Bagian XML dari file xlsx yang dibutuhkan untuk membaca nilai sel
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxTeks struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxTeks) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.T)
+	}
+	return b.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxTeks `xml:"si"`
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		R     string `xml:"r,attr"`
+		Cells []struct {
+			R      string   `xml:"r,attr"`
+			T      string   `xml:"t,attr"`
+			V      string   `xml:"v"`
+			Inline xlsxTeks `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// bacaXLSX reads the cell values of the first worksheet of an xlsx file as text. Empty rows are kept
+// so that the index of a row plus one is its row number in the spreadsheet.
+func bacaXLSX(data []byte) ([][]string, error) {
+	arsip, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFormatSpreadsheet, err)
+	}
+	files := make(map[string]*zip.File, len(arsip.File))
+	for _, f := range arsip.File {
+		files[f.Name] = f
+	}
+
+	lokasiSheet, err := sheetPertama(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := bacaXMLZip(f, &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	var sheet xlsxWorksheet
+	f, ok := files[lokasiSheet]
+	if !ok {
+		return nil, fmt.Errorf("%w: worksheet %s tidak ada", ErrFormatSpreadsheet, lokasiSheet)
+	}
+	if err := bacaXMLZip(f, &sheet); err != nil {
+		return nil, err
+	}
+
+	var baris [][]string
+	jumlahSel := 0
+	for i, row := range sheet.Rows {
+		nomor := i + 1
+		if row.R != "" {
+			nomor, err = strconv.Atoi(row.R)
+			if err != nil || nomor < 1 {
+				return nil, fmt.Errorf("%w: nomor baris %q", ErrFormatSpreadsheet, row.R)
+			}
+		}
+		if nomor > batasBarisXLSX {
+			return nil, fmt.Errorf("%w: baris %d melewati batas %d baris", ErrFormatSpreadsheet, nomor, batasBarisXLSX)
+		}
+		for len(baris) < nomor {
+			baris = append(baris, nil)
+		}
+
+		var sel []string
+		for j, cell := range row.Cells {
+			kolom := j
+			if cell.R != "" {
+				var ok bool
+				if kolom, ok = indeksKolom(cell.R); !ok {
+					return nil, fmt.Errorf("%w: referensi sel %q", ErrFormatSpreadsheet, cell.R)
+				}
+			}
+			if kolom >= batasKolomXLSX {
+				return nil, fmt.Errorf("%w: sel pada baris %d melewati batas %d kolom", ErrFormatSpreadsheet, nomor, batasKolomXLSX)
+			}
+			if kolom >= len(sel) {
+				jumlahSel += kolom + 1 - len(sel)
+				if jumlahSel > batasSelXLSX {
+					return nil, fmt.Errorf("%w: lebih dari %d sel", ErrFormatSpreadsheet, batasSelXLSX)
+				}
+			}
+			for len(sel) <= kolom {
+				sel = append(sel, "")
+			}
+
+			switch cell.T {
+			case "s":
+				idx, err := strconv.Atoi(cell.V)
+				if err != nil || idx < 0 || idx >= len(shared.Items) {
+					return nil, fmt.Errorf("%w: shared string %q pada sel %s", ErrFormatSpreadsheet, cell.V, cell.R)
+				}
+				sel[kolom] = shared.Items[idx].String()
+			case "inlineStr":
+				sel[kolom] = cell.Inline.String()
+			case "b":
+				sel[kolom] = map[string]string{"1": "TRUE", "0": "FALSE"}[cell.V]
+			default:
+				sel[kolom] = cell.V
+			}
+		}
+		baris[nomor-1] = sel
+	}
+	return baris, nil
+}
+
+// sheetPertama returns the path of the first worksheet listed in the workbook
+func sheetPertama(files map[string]*zip.File) (string, error) {
+	const cadangan = "xl/worksheets/sheet1.xml"
+
+	var workbook xlsxWorkbook
+	f, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", fmt.Errorf("%w: bukan file xlsx", ErrFormatSpreadsheet)
+	}
+	if err := bacaXMLZip(f, &workbook); err != nil {
+		return "", err
+	}
+	var rels xlsxRelationships
+	f, ok = files["xl/_rels/workbook.xml.rels"]
+	if !ok || len(workbook.Sheets) == 0 {
+		return cadangan, nil
+	}
+	if err := bacaXMLZip(f, &rels); err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].RelID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return cadangan, nil
+}
+
+func bacaXMLZip(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrFormatSpreadsheet, err)
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(io.LimitReader(rc, 64<<20)).Decode(v); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrFormatSpreadsheet, f.Name, err)
+	}
+	return nil
+}
+
+// indeksKolom turns a cell reference such as "C12" into the zero-based column index 2. It reports false
+// when the reference has no column letters or its column lies beyond batasKolomXLSX.
+func indeksKolom(ref string) (int, bool) {
+	kolom := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		kolom = kolom*26 + int(r-'A'+1)
+		if kolom > batasKolomXLSX {
+			return 0, false
+		}
+	}
+	return kolom - 1, kolom > 0
+}
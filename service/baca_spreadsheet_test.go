@@ -0,0 +1,119 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBacaCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want [][]string
+	}{
+		{"comma", "soal,kunci\nIbu kota?,A\n", [][]string{{"soal", "kunci"}, {"Ibu kota?", "A"}}},
+		{"semicolon from Excel", "\xef\xbb\xbfsoal;kunci\n\"Satu, dua\";A,B\n", [][]string{{"soal", "kunci"}, {"Satu, dua", "A,B"}}},
+		{"ragged rows", "a,b,c\n1\n", [][]string{{"a", "b", "c"}, {"1"}}},
+	}
+	for _, tt := range tests {
+		got, err := bacaCSV([]byte(tt.data))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// buatXLSX zips a minimal workbook whose first sheet is sheetData, listed through the relationships
+func buatXLSX(t *testing.T, sheetData string, sharedStrings string) []byte {
+	t.Helper()
+	files := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+			`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Soal" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Target="worksheets/soal.xml"/></Relationships>`,
+		"xl/worksheets/soal.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<sheetData>` + sheetData + `</sheetData></worksheet>`,
+	}
+	if sharedStrings != "" {
+		files["xl/sharedStrings.xml"] = `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` + sharedStrings + `</sst>`
+	}
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, isi := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(isi)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestBacaXLSX(t *testing.T) {
+	data := buatXLSX(t,
+		`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>`+
+			// Baris 2 kosong dan sel B3 dilewati
+			`<row r="3"><c r="A3" t="inlineStr"><is><t>Ibu kota?</t></is></c><c r="C3"><v>2.5</v></c><c r="D3" t="b"><v>1</v></c></row>`+
+			`<row r="4"><c r="AA4" t="s"><v>2</v></c></row>`,
+		`<si><t>soal</t></si><si><t>kunci</t></si><si><r><t>kaya </t></r><r><t>teks</t></r></si>`)
+
+	got, err := bacaXLSX(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kolomAA := make([]string, 27)
+	kolomAA[26] = "kaya teks"
+	want := [][]string{{"soal", "kunci"}, nil, {"Ibu kota?", "", "2.5", "TRUE"}, kolomAA}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bacaXLSX = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"not a zip", []byte("soal,kunci\n")},
+		{"shared string out of range", buatXLSX(t, `<row r="1"><c r="A1" t="s"><v>5</v></c></row>`, `<si><t>soal</t></si>`)},
+		{"row number zero", buatXLSX(t, `<row r="0"><c r="A1"><v>1</v></c></row>`, "")},
+		{"negative row number", buatXLSX(t, `<row r="-3"><c r="A1"><v>1</v></c></row>`, "")},
+		{"row number that is not a number", buatXLSX(t, `<row r="satu"><c r="A1"><v>1</v></c></row>`, "")},
+		{"row beyond the Excel limit", buatXLSX(t, `<row r="1048577"><c r="A1048577"><v>1</v></c></row>`, "")},
+		{"row number that overflows", buatXLSX(t, `<row r="99999999999999999999"><c r="A1"><v>1</v></c></row>`, "")},
+		{"column beyond XFD", buatXLSX(t, `<row r="1"><c r="XFE1"><v>1</v></c></row>`, "")},
+		{"column that overflows", buatXLSX(t, `<row r="1"><c r="ZZZZZZZZZZZZZZZ1"><v>1</v></c></row>`, "")},
+		{"cell reference without column", buatXLSX(t, `<row r="1"><c r="12"><v>1</v></c></row>`, "")},
+		{"too many cells", buatXLSX(t, strings.Repeat(`<row><c r="XFD1"><v>1</v></c></row>`, batasSelXLSX/batasKolomXLSX+1), "")},
+	}
+	for _, tt := range tests {
+		if _, err := bacaXLSX(tt.data); !errors.Is(err, ErrFormatSpreadsheet) {
+			t.Errorf("%s = %v, want ErrFormatSpreadsheet", tt.name, err)
+		}
+	}
+}
+
+func TestIndeksKolom(t *testing.T) {
+	for ref, want := range map[string]int{"A1": 0, "C12": 2, "Z9": 25, "AA4": 26, "AZ1": 51, "XFD1": 16383} {
+		if got, ok := indeksKolom(ref); !ok || got != want {
+			t.Errorf("indeksKolom(%q) = %d, %v; want %d", ref, got, ok, want)
+		}
+	}
+	for _, ref := range []string{"", "12", "a1", "XFE1", "ZZZZZZZZZZZZZZZ1"} {
+		if got, ok := indeksKolom(ref); ok {
+			t.Errorf("indeksKolom(%q) = %d, want not ok", ref, got)
+		}
+	}
+}
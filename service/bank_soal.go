@@ -0,0 +1,231 @@
+// service/bank_soal.go
+package service
+
+import (
+	"cbt-api/entity"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrBukanBankSoal = errors.New("soal bukan soal bank; soal milik ujian atau latihan tidak dapat ditautkan")
+
+// panjangTagMaks follows varchar(50) of soal_tag.tag
+const panjangTagMaks = 50
+
+// BankSoal is a soal of the question bank with its answer options and topic tags. A bank soal has no
+// id_ujian or id_latihan of its own; ujian use it through ujian_soal and latihan through latihan_soal.
+type BankSoal struct {
+	SoalDenganJawaban
+	Tag []string `json:"tag"`
+}
+
+// FilterBankSoal narrows the question bank; zero values do not filter
+type FilterBankSoal struct {
+	IdMataPelajaran uint64
+	IdKelas         uint64
+	Tag             string
+}
+
+// GetBankSoal lists the bank soal with their answer options and tags
+func (s *soalService) GetBankSoal(filter FilterBankSoal) ([]BankSoal, error) {
+	soalList, err := s.bankSoalRepository.FindBankSoal(filter.IdMataPelajaran, filter.IdKelas, normalisasiTag(filter.Tag))
+	if err != nil {
+		return nil, err
+	}
+	idSoal := make([]uint64, 0, len(soalList))
+	for _, soal := range soalList {
+		idSoal = append(idSoal, soal.IdSoal)
+	}
+	tagList, err := s.bankSoalRepository.FindTagByIdSoal(idSoal)
+	if err != nil {
+		return nil, err
+	}
+	tagBySoal := make(map[uint64][]string)
+	for _, t := range tagList {
+		tagBySoal[t.IdSoal] = append(tagBySoal[t.IdSoal], t.Tag)
+	}
+
+	denganJawaban, err := s.withJawaban(soalList)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]BankSoal, 0, len(denganJawaban))
+	for _, soal := range denganJawaban {
+		tag := tagBySoal[soal.Soal.IdSoal]
+		if tag == nil {
+			tag = []string{}
+		}
+		result = append(result, BankSoal{SoalDenganJawaban: soal, Tag: tag})
+	}
+	return result, nil
+}
+
+// CreateBankSoal adds a soal with its answer options and tags to the question bank
+func (s *soalService) CreateBankSoal(input BankSoal) (BankSoal, error) {
+	if err := siapkanBankSoal(&input); err != nil {
+		return BankSoal{}, err
+	}
+	soal := input.Soal
+	soal.IdSoal = 0
+	soal.IdUjian = 0
+	soal.IdLatihan = 0
+	tipeSoal, err := s.siapkanSoal(&soal, input.Jawaban)
+	if err != nil {
+		return BankSoal{}, err
+	}
+
+	now := time.Now()
+	soal.CreatedAt = now
+	soal.UpdatedAt = now
+	var result BankSoal
+	err = s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		created, err := s.soalRepository.WithTx(tx).CreateSoal(soal)
+		if err != nil {
+			return err
+		}
+		opsi, err := s.simpanOpsi(tx, created, input.Jawaban, nil)
+		if err != nil {
+			return err
+		}
+		created.TipeSoal = tipeSoal
+		result = BankSoal{SoalDenganJawaban: SoalDenganJawaban{Soal: created, Jawaban: opsi}, Tag: input.Tag}
+		return s.bankSoalRepository.WithTx(tx).ReplaceTag(created.IdSoal, input.Tag)
+	})
+	return result, err
+}
+
+// UpdateBankSoal replaces a bank soal, its answer options, classification and tags with the rules of
+// UpdateSoal. Every ujian that links the soal sees the change.
+func (s *soalService) UpdateBankSoal(soalID uint64, input BankSoal) (BankSoal, error) {
+	return s.updateSoal(soalID, input, true)
+}
+
+// TautkanSoalUjian links a bank soal to an ujian. nilaiPerSoal overrides the soal's nilai_per_soal for
+// this ujian; nil uses the soal's own value. Linking an already linked soal replaces the override.
+func (s *soalService) TautkanSoalUjian(ujianID uint64, soalID uint64, nilaiPerSoal *float64) (entity.UjianSoal, error) {
+	soal, err := s.soalRepository.FindById(soalID)
+	if err != nil {
+		return entity.UjianSoal{}, err
+	}
+	if !adalahBankSoal(soal) {
+		return entity.UjianSoal{}, ErrBukanBankSoal
+	}
+	ujian, err := s.ujianRepository.GetUjianByID(ujianID)
+	if err != nil {
+		return entity.UjianSoal{}, err
+	}
+	if err := s.cekUjianBolehDiubah(ujianID); err != nil {
+		return entity.UjianSoal{}, err
+	}
+
+	nilai := soal.NilaiPerSoal
+	if nilaiPerSoal != nil {
+		if *nilaiPerSoal < 0 {
+			return entity.UjianSoal{}, fmt.Errorf("%w: nilai_per_soal tidak boleh negatif", ErrSoalTidakValid)
+		}
+		nilai = *nilaiPerSoal
+	}
+	if err := s.cekTotalNilai(ujian, soalID, nilai); err != nil {
+		return entity.UjianSoal{}, err
+	}
+
+	now := time.Now()
+	return s.bankSoalRepository.UpsertUjianSoal(entity.UjianSoal{
+		IdUjian:      ujianID,
+		IdSoal:       soalID,
+		NilaiPerSoal: nilaiPerSoal,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	})
+}
+
+// LepasSoalUjian removes a bank soal from an ujian; the soal stays in the bank. Soal that a siswa
+// already answered in the ujian are kept so their grades do not change.
+func (s *soalService) LepasSoalUjian(ujianID uint64, soalID uint64) error {
+	if _, err := s.bankSoalRepository.FindUjianSoal(ujianID, soalID); err != nil {
+		return err
+	}
+	if err := s.cekUjianBolehDiubah(ujianID); err != nil {
+		return err
+	}
+	dijawab, err := s.bankSoalRepository.SudahDijawabDiUjian(ujianID, soalID)
+	if err != nil {
+		return err
+	}
+	if dijawab {
+		return fmt.Errorf("soal %w", ErrSudahDijawabSiswa)
+	}
+	return s.bankSoalRepository.DeleteUjianSoal(ujianID, soalID)
+}
+
+// TautkanSoalLatihan links a bank soal to a latihan through latihan_soal
+func (s *soalService) TautkanSoalLatihan(latihanID uint64, soalID uint64) (entity.LatihanSoal, error) {
+	soal, err := s.soalRepository.FindById(soalID)
+	if err != nil {
+		return entity.LatihanSoal{}, err
+	}
+	if !adalahBankSoal(soal) {
+		return entity.LatihanSoal{}, ErrBukanBankSoal
+	}
+	if _, err := s.latihanRepository.GetLatihanByID(latihanID); err != nil {
+		return entity.LatihanSoal{}, err
+	}
+
+	now := time.Now()
+	return s.bankSoalRepository.CreateLatihanSoal(entity.LatihanSoal{
+		IdLatihan: latihanID,
+		IdSoal:    soalID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+// LepasSoalLatihan removes a bank soal from a latihan; the soal stays in the bank
+func (s *soalService) LepasSoalLatihan(latihanID uint64, soalID uint64) error {
+	return s.bankSoalRepository.DeleteLatihanSoal(latihanID, soalID)
+}
+
+// adalahBankSoal reports whether the soal belongs to the question bank rather than to one ujian or latihan
+func adalahBankSoal(soal entity.Soal) bool {
+	return soal.IdUjian == 0 && soal.IdLatihan == 0
+}
+
+// siapkanBankSoal validates the classification of a bank soal and normalises its tags: trimmed, lower
+// case and without duplicates
+func siapkanBankSoal(input *BankSoal) error {
+	var masalah []string
+	if input.Soal.IdMataPelajaran == 0 {
+		masalah = append(masalah, "id_mata_pelajaran wajib diisi")
+	}
+	if input.Soal.IdKelas == 0 {
+		masalah = append(masalah, "id_kelas wajib diisi")
+	}
+
+	tag := make([]string, 0, len(input.Tag))
+	ada := make(map[string]bool, len(input.Tag))
+	for _, t := range input.Tag {
+		t = normalisasiTag(t)
+		switch {
+		case t == "" || ada[t]:
+			continue
+		case len([]rune(t)) > panjangTagMaks:
+			masalah = append(masalah, fmt.Sprintf("tag %q lebih dari %d karakter", t, panjangTagMaks))
+		}
+		ada[t] = true
+		tag = append(tag, t)
+	}
+	input.Tag = tag
+
+	if len(masalah) > 0 {
+		return fmt.Errorf("%w: %s", ErrSoalTidakValid, strings.Join(masalah, "; "))
+	}
+	return nil
+}
+
+func normalisasiTag(tag string) string {
+	return strings.ToLower(strings.Join(strings.Fields(tag), " "))
+}
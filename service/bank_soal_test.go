@@ -0,0 +1,188 @@
+package service
+
+import (
+	"cbt-api/entity"
+	"cbt-api/repository"
+	"errors"
+	"testing"
+)
+
+func TestSiapkanBankSoal(t *testing.T) {
+	input := BankSoal{
+		SoalDenganJawaban: SoalDenganJawaban{Soal: entity.Soal{IdMataPelajaran: 1, IdKelas: 2}},
+		Tag:               []string{"  Aljabar ", "aljabar", "", "Persamaan   Linear"},
+	}
+	if err := siapkanBankSoal(&input); err != nil {
+		t.Fatal(err)
+	}
+	if len(input.Tag) != 2 || input.Tag[0] != "aljabar" || input.Tag[1] != "persamaan linear" {
+		t.Errorf("tag = %q, want [aljabar persamaan linear]", input.Tag)
+	}
+
+	tanpaKelas := BankSoal{SoalDenganJawaban: SoalDenganJawaban{Soal: entity.Soal{IdMataPelajaran: 1}}}
+	if err := siapkanBankSoal(&tanpaKelas); !errors.Is(err, ErrSoalTidakValid) {
+		t.Errorf("bank soal without kelas = %v, want ErrSoalTidakValid", err)
+	}
+}
+
+// buatBankSoal adds a PG soal worth nilai to the question bank through the service
+func (env *lingkunganTest) buatBankSoal(t *testing.T, nilai float64, tag ...string) entity.Soal {
+	t.Helper()
+	created, err := env.soal.CreateBankSoal(BankSoal{
+		SoalDenganJawaban: SoalDenganJawaban{
+			Soal: entity.Soal{
+				Soal:            "Soal bank",
+				NilaiPerSoal:    nilai,
+				IdTipeSoal:      env.tipeSoal[entity.TipeSoalPilihanBerganda].IdTipeSoal,
+				IdMataPelajaran: 1,
+				IdKelas:         1,
+			},
+			Jawaban: []entity.JawabanSoal{{Jawaban: "Benar", Benar: true}, {Jawaban: "Salah"}},
+		},
+		Tag: tag,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return created.Soal
+}
+
+func TestTautkanSoalUjian(t *testing.T) {
+	env := newLingkunganTest(t)
+	ujian := env.buatUjian(t, nil)
+	milikUjian, _ := env.buatSoal(t, ujian, entity.TipeSoalIsian, 50)
+	bank := env.buatBankSoal(t, 30, "Aljabar")
+
+	daftar, err := env.soal.GetBankSoal(FilterBankSoal{Tag: "ALJABAR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(daftar) != 1 || daftar[0].Soal.IdSoal != bank.IdSoal || len(daftar[0].Tag) != 1 {
+		t.Errorf("bank filtered by tag = %+v, want the bank soal only", daftar)
+	}
+
+	if _, err := env.soal.TautkanSoalUjian(ujian.IdUjian, milikUjian.IdSoal, nil); !errors.Is(err, ErrBukanBankSoal) {
+		t.Errorf("linking a soal owned by the ujian = %v, want ErrBukanBankSoal", err)
+	}
+
+	// Tanpa nilai pengganti soal bank dihitung dengan nilai bawaannya: 50 + 30
+	if _, err := env.soal.TautkanSoalUjian(ujian.IdUjian, bank.IdSoal, nil); err != nil {
+		t.Fatal(err)
+	}
+	soalList, err := repository.NewSoalRepository(env.db).FindByIdUjian(ujian.IdUjian)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := totalNilaiPerSoal(soalList, 0, 0); len(soalList) != 2 || got != 80 {
+		t.Errorf("ujian soal = %d with total %v, want 2 with total 80", len(soalList), got)
+	}
+	if _, err := env.soal.CreateSoal(ujian.IdUjian, SoalDenganJawaban{
+		Soal: entity.Soal{Soal: "Isian", NilaiPerSoal: 30, IdTipeSoal: env.tipeSoal[entity.TipeSoalIsian].IdTipeSoal},
+	}); !errors.Is(err, ErrTotalNilaiMelebihiGrade) {
+		t.Errorf("own soal beyond the grade with a linked soal = %v, want ErrTotalNilaiMelebihiGrade", err)
+	}
+	// Mengubah nilai bawaan soal bank diperiksa terhadap setiap ujian yang memakainya tanpa pengganti
+	diubah, err := env.soal.GetSoalWithJawaban(bank.IdSoal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diubah.Soal.NilaiPerSoal = 60
+	if _, err := env.soal.UpdateBankSoal(bank.IdSoal, BankSoal{SoalDenganJawaban: diubah}); !errors.Is(err, ErrTotalNilaiMelebihiGrade) {
+		t.Errorf("raising a linked bank soal beyond the grade = %v, want ErrTotalNilaiMelebihiGrade", err)
+	}
+
+	// Nilai pengganti per ujian dipakai FindByIdUjian dan oleh batas grade
+	lebih := 60.0
+	if _, err := env.soal.TautkanSoalUjian(ujian.IdUjian, bank.IdSoal, &lebih); !errors.Is(err, ErrTotalNilaiMelebihiGrade) {
+		t.Errorf("override 60 next to 50 with grade 100 = %v, want ErrTotalNilaiMelebihiGrade", err)
+	}
+	pengganti := 50.0
+	tautan, err := env.soal.TautkanSoalUjian(ujian.IdUjian, bank.IdSoal, &pengganti)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tautan.NilaiPerSoal == nil || *tautan.NilaiPerSoal != 50 {
+		t.Errorf("relinked nilai_per_soal = %v, want 50", tautan.NilaiPerSoal)
+	}
+	soalList, err = repository.NewSoalRepository(env.db).FindByIdUjian(ujian.IdUjian)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, soal := range soalList {
+		if soal.IdSoal == bank.IdSoal && soal.NilaiPerSoal != 50 {
+			t.Errorf("linked soal nilai_per_soal = %v, want the override 50", soal.NilaiPerSoal)
+		}
+	}
+	if validasi, _ := env.ujian.ValidasiUjian(ujian.IdUjian); !validasi.Siap {
+		t.Errorf("validasi with 50 + override 50 = %+v, want ready", validasi)
+	}
+	if asli, _ := env.soal.GetSoalWithJawaban(bank.IdSoal); asli.Soal.NilaiPerSoal != 30 {
+		t.Errorf("bank soal nilai_per_soal = %v, the override must not change it", asli.Soal.NilaiPerSoal)
+	}
+}
+
+func TestLepasSoalUjian(t *testing.T) {
+	env := newLingkunganTest(t)
+	ujian := env.buatUjian(t, nil)
+	bank := env.buatBankSoal(t, 20)
+	dijawab := env.buatBankSoal(t, 20)
+	for _, soal := range []entity.Soal{bank, dijawab} {
+		if _, err := env.soal.TautkanSoalUjian(ujian.IdUjian, soal.IdSoal, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	attempt := entity.UjianAttempt{IdUjian: ujian.IdUjian, IdSiswa: 7, Status: entity.StatusAttemptSelesai}
+	env.buat(t, &attempt)
+	env.buat(t, &entity.JawabanSiswa{JawabanText: "Benar", IdSoal: dijawab.IdSoal, IdSiswa: 7, IdUjianAttempt: &attempt.IdUjianAttempt})
+
+	if err := env.soal.LepasSoalUjian(ujian.IdUjian, dijawab.IdSoal); !errors.Is(err, ErrSudahDijawabSiswa) {
+		t.Errorf("unlinking an answered soal = %v, want ErrSudahDijawabSiswa", err)
+	}
+	if err := env.soal.LepasSoalUjian(ujian.IdUjian, bank.IdSoal); err != nil {
+		t.Fatal(err)
+	}
+	soalList, err := repository.NewSoalRepository(env.db).FindByIdUjian(ujian.IdUjian)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(soalList) != 1 || soalList[0].IdSoal != dijawab.IdSoal {
+		t.Errorf("ujian soal after unlinking = %+v, want only the answered soal", soalList)
+	}
+	if _, err := env.soal.GetSoalWithJawaban(bank.IdSoal); err != nil {
+		t.Errorf("unlinked soal left the bank: %v", err)
+	}
+	if err := env.soal.LepasSoalUjian(ujian.IdUjian, bank.IdSoal); err == nil {
+		t.Error("unlinking a soal that is not linked succeeded")
+	}
+}
+
+func TestTautkanSoalLatihan(t *testing.T) {
+	env := newLingkunganTest(t)
+	latihan := entity.Latihan{Topik: "Aljabar", Acak: entity.StatusTidakAktif, StatusJawaban: entity.StatusAktif}
+	env.buat(t, &latihan)
+	ujian := env.buatUjian(t, nil)
+	milikUjian, _ := env.buatSoal(t, ujian, entity.TipeSoalIsian, 10)
+	bank := env.buatBankSoal(t, 10)
+
+	if _, err := env.soal.TautkanSoalLatihan(latihan.IdLatihan, milikUjian.IdSoal); !errors.Is(err, ErrBukanBankSoal) {
+		t.Errorf("linking a soal owned by an ujian = %v, want ErrBukanBankSoal", err)
+	}
+	if _, err := env.soal.TautkanSoalLatihan(latihan.IdLatihan, bank.IdSoal); err != nil {
+		t.Fatal(err)
+	}
+	soalList, err := env.soal.GetSoalByLatihan(latihan.IdLatihan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(soalList) != 1 || soalList[0].Soal.IdSoal != bank.IdSoal {
+		t.Errorf("latihan soal = %+v, want the linked bank soal", soalList)
+	}
+
+	if err := env.soal.LepasSoalLatihan(latihan.IdLatihan, bank.IdSoal); err != nil {
+		t.Fatal(err)
+	}
+	if soalList, _ := env.soal.GetSoalByLatihan(latihan.IdLatihan); len(soalList) != 0 {
+		t.Errorf("latihan soal after unlinking = %+v, want none", soalList)
+	}
+}
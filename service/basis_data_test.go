@@ -0,0 +1,148 @@
+package service
+
+import (
+	"cbt-api/config"
+	"cbt-api/entity"
+	"cbt-api/migration"
+	"cbt-api/repository"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// bukaBasisData opens a migrated SQLite database in the test's temp directory
+func bukaBasisData(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := config.ConnectDatabase(config.DatabaseConfig{
+		Driver:         config.DriverSQLite,
+		DSN:            filepath.Join(t.TempDir(), "cbt.db") + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)",
+		MaxOpenConns:   4,
+		MaxIdleConns:   4,
+		ConnectTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	if _, err := migration.Up(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// buatBaris inserts the row without its associations
+func buatBaris(t *testing.T, db *gorm.DB, value interface{}) {
+	t.Helper()
+	if err := db.Omit(clause.Associations).Create(value).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// lingkunganTest wires the services the way main does, on a SQLite database in the test's temp directory
+type lingkunganTest struct {
+	db           *gorm.DB
+	nilai        NilaiService
+	ujianAttempt UjianAttemptService
+	jawabanSiswa JawabanSiswaService
+	ujian        UjianService
+	soal         SoalService
+	tipeSoal     map[string]entity.TipeSoal
+}
+
+func newLingkunganTest(t *testing.T) *lingkunganTest {
+	t.Helper()
+	db := bukaBasisData(t)
+
+	jawabanSiswaRepo := repository.NewJawabanSiswaRepository(db)
+	soalRepo := repository.NewSoalRepository(db)
+	ujianAttemptRepo := repository.NewUjianAttemptRepository(db)
+	ujianRepo := repository.NewUjianRepository(db)
+	jawabanSoalRepo := repository.NewJawabanSoalRepository(db)
+
+	kursusRepo := repository.NewKursusRepository(db)
+	transactor := repository.NewTransactor(db)
+
+	env := &lingkunganTest{db: db, tipeSoal: make(map[string]entity.TipeSoal)}
+	env.nilai = NewNilaiService(
+		transactor,
+		repository.NewNilaiRepository(db),
+		repository.NewNilaiKursusRepository(db),
+		repository.NewTipeNilaiRepository(db),
+		jawabanSiswaRepo,
+		repository.NewSiswaRepository(db),
+		ujianRepo,
+		ujianAttemptRepo,
+		soalRepo,
+		jawabanSoalRepo,
+	)
+	env.ujianAttempt = NewUjianAttemptService(ujianAttemptRepo, ujianRepo, soalRepo, jawabanSoalRepo, jawabanSiswaRepo, env.nilai)
+	env.jawabanSiswa = NewJawabanSiswaService(transactor, jawabanSiswaRepo, ujianRepo, ujianAttemptRepo, env.ujianAttempt)
+	env.ujian = NewUjianService(ujianRepo, soalRepo, jawabanSoalRepo, ujianAttemptRepo, kursusRepo)
+	env.soal = NewSoalService(transactor, soalRepo, jawabanSoalRepo, ujianRepo, repository.NewLatihanRepository(db),
+		ujianAttemptRepo, kursusRepo, repository.NewBankSoalRepository(db))
+
+	for _, nama := range []string{entity.TipeSoalPilihanBerganda, entity.TipeSoalBenarSalah, entity.TipeSoalIsian, entity.TipeSoalPilihanKompleks} {
+		tipe := entity.TipeSoal{NamaTipeUjian: nama}
+		env.buat(t, &tipe)
+		env.tipeSoal[nama] = tipe
+	}
+	return env
+}
+
+// buat inserts the row into the test database without its associations
+func (env *lingkunganTest) buat(t *testing.T, value interface{}) {
+	t.Helper()
+	buatBaris(t, env.db, value)
+}
+
+// buatUjian creates an ujian with the passwords "masuk" and "keluar"; ubah adjusts it before saving
+func (env *lingkunganTest) buatUjian(t *testing.T, ubah func(*entity.Ujian)) entity.Ujian {
+	t.Helper()
+	hashMasuk, _ := bcrypt.GenerateFromPassword([]byte("masuk"), bcrypt.MinCost)
+	hashKeluar, _ := bcrypt.GenerateFromPassword([]byte("keluar"), bcrypt.MinCost)
+	ujian := entity.Ujian{
+		NamaUjian:      "Ujian",
+		Acak:           entity.StatusTidakAktif,
+		StatusJawaban:  entity.StatusAktif,
+		Grade:          100,
+		PasswordMasuk:  string(hashMasuk),
+		PasswordKeluar: string(hashKeluar),
+		IdKursus:       1,
+		IdTipeUjian:    1,
+	}
+	if ubah != nil {
+		ubah(&ujian)
+	}
+	env.buat(t, &ujian)
+	return ujian
+}
+
+// buatSoal creates a soal of the ujian with the given options; the options are returned with their ids
+func (env *lingkunganTest) buatSoal(t *testing.T, ujian entity.Ujian, tipe string, nilai float64, opsi ...entity.JawabanSoal) (entity.Soal, []entity.JawabanSoal) {
+	t.Helper()
+	soal := entity.Soal{
+		Soal:          "Soal " + tipe,
+		NilaiPerSoal:  nilai,
+		ModePenilaian: entity.ModePenilaianSemuaBenar,
+		IdUjian:       ujian.IdUjian,
+		IdTipeSoal:    env.tipeSoal[tipe].IdTipeSoal,
+	}
+	env.buat(t, &soal)
+	for i := range opsi {
+		opsi[i].IdSoal = soal.IdSoal
+		opsi[i].IdTipeSoal = soal.IdTipeSoal
+		if opsi[i].TipePencocokan == "" {
+			opsi[i].TipePencocokan = entity.PencocokanTeks
+		}
+		env.buat(t, &opsi[i])
+	}
+	return soal, opsi
+}
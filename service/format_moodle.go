@@ -0,0 +1,616 @@
+// service/format_moodle.go
+package service
+
+import (
+	"cbt-api/entity"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// GIFT and Aiken are the plain text question formats of Moodle. Only the question types that map onto
+// a tipe soal are read and written:
+//
+//	GIFT  pilihan ganda ({=a ~b}), benar/salah ({T}), jawaban singkat ({=a =b}), numerik ({#3:0.1})
+//	      dan esai ({}), yang menjadi soal Isian tanpa variasi jawaban sehingga dinilai manual
+//	Aiken pilihan ganda; dua opsi Benar dan Salah dibaca sebagai Benar_Salah
+//
+// Neither format carries points, so imported soal get the NilaiPerSoal of the import.
+
+// EksporSoal selects the soal written by ExportSoal
+type EksporSoal struct {
+	IdUjian   uint64
+	IdLatihan uint64
+	Format    string // gift atau aiken
+}
+
+// HasilEksporSoal is the exported file. Soal that the format cannot express are left out and listed
+// in Dilewati; images that could not be put into a QTI package are listed in MediaGagal.
+type HasilEksporSoal struct {
+	NamaFile   string
+	Data       []byte
+	Dilewati   []uint64
+	MediaGagal []string
+}
+
+// ExportSoal writes the soal of an ujian or a latihan as a GIFT or Aiken file
+func (s *soalService) ExportSoal(input EksporSoal) (HasilEksporSoal, error) {
+	if (input.IdUjian == 0) == (input.IdLatihan == 0) {
+		return HasilEksporSoal{}, ErrTujuanImportTidakValid
+	}
+
+	var soalList []entity.Soal
+	var nama string
+	var err error
+	if input.IdUjian != 0 {
+		if _, err := s.ujianRepository.GetUjianByID(input.IdUjian); err != nil {
+			return HasilEksporSoal{}, err
+		}
+		soalList, err = s.soalRepository.FindByIdUjianWithTipeSoal(input.IdUjian)
+		nama = fmt.Sprintf("soal-ujian-%d", input.IdUjian)
+	} else {
+		if _, err := s.latihanRepository.GetLatihanByID(input.IdLatihan); err != nil {
+			return HasilEksporSoal{}, err
+		}
+		soalList, err = s.soalRepository.FindByIdLatihanWithTipeSoal(input.IdLatihan)
+		nama = fmt.Sprintf("soal-latihan-%d", input.IdLatihan)
+	}
+	if err != nil {
+		return HasilEksporSoal{}, err
+	}
+	daftar, err := s.withJawaban(soalList)
+	if err != nil {
+		return HasilEksporSoal{}, err
+	}
+
+	hasil := HasilEksporSoal{Dilewati: make([]uint64, 0)}
+	switch strings.ToLower(input.Format) {
+	case FormatGIFT:
+		hasil.NamaFile = nama + ".gift"
+		hasil.Data, hasil.Dilewati = tulisGIFT(daftar)
+	case FormatAiken:
+		hasil.NamaFile = nama + ".txt"
+		hasil.Data, hasil.Dilewati = tulisAiken(daftar)
+	default:
+		return HasilEksporSoal{}, fmt.Errorf("%w: gunakan format gift atau aiken", ErrFormatTidakDikenal)
+	}
+	return hasil, nil
+}
+
+// blokSoal is the text of one question in a GIFT or Aiken file and the line it starts on
+type blokSoal struct {
+	baris int
+	teks  string
+}
+
+// pisahBlokSoal splits a GIFT or Aiken file into questions, which are separated by blank lines.
+// Lines for which abaikan returns true are dropped.
+func pisahBlokSoal(data string, abaikan func(baris string) bool) []blokSoal {
+	data = strings.TrimPrefix(data, "\ufeff")
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+
+	var daftar []blokSoal
+	var isi []string
+	mulai := 0
+	tutup := func() {
+		if len(isi) > 0 {
+			daftar = append(daftar, blokSoal{baris: mulai, teks: strings.Join(isi, "\n")})
+			isi = nil
+		}
+	}
+	for i, baris := range strings.Split(data, "\n") {
+		teks := strings.TrimSpace(baris)
+		if teks == "" {
+			tutup()
+			continue
+		}
+		if abaikan(teks) {
+			continue
+		}
+		if len(isi) == 0 {
+			mulai = i + 1
+		}
+		isi = append(isi, teks)
+	}
+	tutup()
+	return daftar
+}
+
+// parseGIFT reads the questions of a GIFT file. Errors are reported by the line the question starts on.
+func parseGIFT(data string, tipeByNama map[string]entity.TipeSoal, nilai float64) ([]soalImport, []KesalahanBaris) {
+	kesalahan := make([]KesalahanBaris, 0)
+	var daftar []soalImport
+	blok := pisahBlokSoal(data, func(baris string) bool {
+		return strings.HasPrefix(baris, "//") || strings.HasPrefix(baris, "$CATEGORY:")
+	})
+	for _, b := range blok {
+		soal, err := soalGIFT(b.teks, tipeByNama)
+		if err != nil {
+			kesalahan = append(kesalahan, KesalahanBaris{Baris: b.baris, Pesan: []string{err.Error()}})
+			continue
+		}
+		soal.Soal.NilaiPerSoal = nilai
+		daftar = append(daftar, soalImport{baris: b.baris, SoalDenganJawaban: soal})
+	}
+	return daftar, kesalahan
+}
+
+// soalGIFT reads one GIFT question: an optional ::judul::, the question text and the answer block in
+// braces. Text after the answer block is kept behind a blank, as in Moodle's missing word questions.
+func soalGIFT(teks string, tipeByNama map[string]entity.TipeSoal) (SoalDenganJawaban, error) {
+	if strings.HasPrefix(teks, "::") {
+		akhir := indeksTakTerescape(teks[2:], "::")
+		if akhir < 0 {
+			return SoalDenganJawaban{}, errors.New("judul soal tidak ditutup dengan ::")
+		}
+		teks = strings.TrimSpace(teks[akhir+4:])
+	}
+	buka := indeksTakTerescape(teks, "{")
+	if buka < 0 {
+		return SoalDenganJawaban{}, errors.New("blok jawaban {…} tidak ditemukan")
+	}
+	tutup := indeksTakTerescape(teks[buka+1:], "}")
+	if tutup < 0 {
+		return SoalDenganJawaban{}, errors.New("blok jawaban tidak ditutup dengan }")
+	}
+	tutup += buka + 1
+
+	pertanyaan := strings.TrimSpace(teks[:buka])
+	if sisa := strings.TrimSpace(teks[tutup+1:]); sisa != "" {
+		pertanyaan += " _____ " + sisa
+	}
+	pertanyaan = unescapeGIFT(hapusPenandaFormat(pertanyaan))
+
+	namaTipe, opsi, err := opsiGIFT(strings.TrimSpace(teks[buka+1 : tutup]))
+	if err != nil {
+		return SoalDenganJawaban{}, err
+	}
+	tipe, err := cariTipeSoal(tipeByNama, namaTipe)
+	if err != nil {
+		return SoalDenganJawaban{}, err
+	}
+	return SoalDenganJawaban{
+		Soal:    entity.Soal{Soal: pertanyaan, IdTipeSoal: tipe.IdTipeSoal},
+		Jawaban: opsi,
+	}, nil
+}
+
+// opsiGIFT reads the answer block of a GIFT question into the tipe soal and its options
+func opsiGIFT(jawaban string) (string, []entity.JawabanSoal, error) {
+	if jawaban == "" {
+		return entity.TipeSoalIsian, nil, nil
+	}
+	if strings.HasPrefix(jawaban, "#") {
+		opsi, err := opsiNumerikGIFT(strings.TrimSpace(jawaban[1:]))
+		return entity.TipeSoalIsian, opsi, err
+	}
+	switch strings.ToUpper(strings.TrimSpace(potongFeedbackGIFT(jawaban))) {
+	case "T", "TRUE":
+		return entity.TipeSoalBenarSalah, opsiBenarSalah(true), nil
+	case "F", "FALSE":
+		return entity.TipeSoalBenarSalah, opsiBenarSalah(false), nil
+	}
+
+	awal, token := pisahJawabanGIFT(jawaban)
+	if strings.TrimSpace(awal) != "" || len(token) == 0 {
+		return "", nil, errors.New("setiap jawaban harus diawali = atau ~")
+	}
+	var opsi []entity.JawabanSoal
+	pilihan, benar := false, 0
+	for _, t := range token {
+		if strings.Contains(t.teks, "->") {
+			return "", nil, errors.New("soal menjodohkan tidak didukung")
+		}
+		teks, bobot := bobotGIFT(potongFeedbackGIFT(t.teks))
+		o := entity.JawabanSoal{Jawaban: unescapeGIFT(teks), Benar: t.penanda == '=' || bobot > 0}
+		if t.penanda == '~' {
+			pilihan = true
+		}
+		if o.Benar {
+			benar++
+		}
+		opsi = append(opsi, o)
+	}
+
+	switch {
+	case !pilihan:
+		// Hanya jawaban = adalah soal jawaban singkat
+		return entity.TipeSoalIsian, opsi, nil
+	case benar > 1:
+		return entity.TipeSoalPilihanKompleks, opsi, nil
+	default:
+		return entity.TipeSoalPilihanBerganda, opsi, nil
+	}
+}
+
+// opsiNumerikGIFT reads a numerical answer block without its leading #: a single answer, or several
+// answers each starting with =. An answer is a value with an optional tolerance (3.14:0.01) or a range
+// (3.1..3.2).
+func opsiNumerikGIFT(isi string) ([]entity.JawabanSoal, error) {
+	var nilaiList []string
+	if strings.HasPrefix(isi, "=") || strings.HasPrefix(isi, "~") {
+		_, token := pisahJawabanGIFT(isi)
+		for _, t := range token {
+			teks, bobot := bobotGIFT(potongFeedbackGIFT(t.teks))
+			if t.penanda == '=' || bobot > 0 {
+				nilaiList = append(nilaiList, teks)
+			}
+		}
+	} else {
+		nilaiList = append(nilaiList, potongFeedbackGIFT(isi))
+	}
+
+	opsi := make([]entity.JawabanSoal, 0, len(nilaiList))
+	for _, teks := range nilaiList {
+		nilai, toleransi, err := angkaGIFT(strings.TrimSpace(teks))
+		if err != nil {
+			return nil, err
+		}
+		opsi = append(opsi, entity.JawabanSoal{
+			Jawaban:        strconv.FormatFloat(nilai, 'f', -1, 64),
+			Benar:          true,
+			TipePencocokan: entity.PencocokanAngka,
+			ToleransiAngka: toleransi,
+		})
+	}
+	if len(opsi) == 0 {
+		return nil, errors.New("soal numerik butuh minimal 1 jawaban benar")
+	}
+	return opsi, nil
+}
+
+func angkaGIFT(teks string) (float64, float64, error) {
+	bawah, atas, rentang := strings.Cut(teks, "..")
+	if !rentang {
+		bawah, atas, _ = strings.Cut(teks, ":")
+	}
+	nilai, err := parseAngka(bawah)
+	if err != nil {
+		return 0, 0, fmt.Errorf("jawaban numerik %q bukan angka", teks)
+	}
+	if strings.TrimSpace(atas) == "" {
+		return nilai, 0, nil
+	}
+	kedua, err := parseAngka(atas)
+	if err != nil {
+		return 0, 0, fmt.Errorf("jawaban numerik %q bukan angka", teks)
+	}
+	if rentang {
+		return (nilai + kedua) / 2, math.Abs(kedua-nilai) / 2, nil
+	}
+	return nilai, kedua, nil
+}
+
+// opsiGIFTMentah is one answer of a GIFT answer block with the = or ~ it started with
+type opsiGIFTMentah struct {
+	penanda byte
+	teks    string
+}
+
+// pisahJawabanGIFT splits an answer block at every unescaped = and ~. Text before the first one is
+// returned as awal.
+func pisahJawabanGIFT(isi string) (string, []opsiGIFTMentah) {
+	var awal string
+	var token []opsiGIFTMentah
+	var penanda byte
+	var teks strings.Builder
+	simpan := func() {
+		if penanda == 0 {
+			awal = teks.String()
+		} else {
+			token = append(token, opsiGIFTMentah{penanda: penanda, teks: strings.TrimSpace(teks.String())})
+		}
+		teks.Reset()
+	}
+	for i := 0; i < len(isi); i++ {
+		switch c := isi[i]; {
+		case c == '\\' && i+1 < len(isi):
+			teks.WriteString(isi[i : i+2])
+			i++
+		case c == '=' || c == '~':
+			simpan()
+			penanda = c
+		default:
+			teks.WriteByte(c)
+		}
+	}
+	simpan()
+	return awal, token
+}
+
+// bobotGIFT strips the %bobot% prefix of an answer and returns it; answers without one weigh 0
+func bobotGIFT(teks string) (string, float64) {
+	teks = strings.TrimSpace(teks)
+	if !strings.HasPrefix(teks, "%") {
+		return teks, 0
+	}
+	angka, sisa, ada := strings.Cut(teks[1:], "%")
+	bobot, err := parseAngka(angka)
+	if !ada || err != nil {
+		return teks, 0
+	}
+	return strings.TrimSpace(sisa), bobot
+}
+
+// potongFeedbackGIFT drops the #feedback that may follow an answer
+func potongFeedbackGIFT(teks string) string {
+	if i := indeksTakTerescape(teks, "#"); i >= 0 {
+		return teks[:i]
+	}
+	return teks
+}
+
+// indeksTakTerescape finds the first occurrence of sub in s that is not escaped with a backslash
+func indeksTakTerescape(s string, sub string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(s[i:], sub) {
+			return i
+		}
+	}
+	return -1
+}
+
+// hapusPenandaFormat drops the [html], [moodle], [plain] or [markdown] marker before a GIFT text
+func hapusPenandaFormat(teks string) string {
+	for _, penanda := range []string{"[html]", "[moodle]", "[plain]", "[markdown]"} {
+		if strings.HasPrefix(teks, penanda) {
+			return strings.TrimSpace(teks[len(penanda):])
+		}
+	}
+	return teks
+}
+
+var (
+	unescaperGIFT = strings.NewReplacer(`\\`, `\`, `\~`, `~`, `\=`, `=`, `\#`, `#`, `\{`, `{`, `\}`, `}`, `\:`, `:`, `\n`, "\n")
+	escaperGIFT   = strings.NewReplacer(`\`, `\\`, `~`, `\~`, `=`, `\=`, `#`, `\#`, `{`, `\{`, `}`, `\}`, `:`, `\:`, "\r\n", `\n`, "\n", `\n`)
+)
+
+func unescapeGIFT(teks string) string {
+	return strings.TrimSpace(unescaperGIFT.Replace(teks))
+}
+
+// tulisGIFT writes the soal as GIFT questions titled by their id. Isian soal matched by regex, or by
+// text and number at once, cannot be written and are skipped.
+func tulisGIFT(daftar []SoalDenganJawaban) ([]byte, []uint64) {
+	var b strings.Builder
+	dilewati := make([]uint64, 0)
+	for _, item := range daftar {
+		jawaban, ok := jawabanGIFT(item)
+		if !ok {
+			dilewati = append(dilewati, item.Soal.IdSoal)
+			continue
+		}
+		fmt.Fprintf(&b, "// id_soal: %d, nilai_per_soal: %s\n", item.Soal.IdSoal, strconv.FormatFloat(item.Soal.NilaiPerSoal, 'f', -1, 64))
+		fmt.Fprintf(&b, "::Soal %d::%s %s\n\n", item.Soal.IdSoal, escaperGIFT.Replace(item.Soal.Soal), jawaban)
+	}
+	return []byte(b.String()), dilewati
+}
+
+// jawabanGIFT writes the answer block of one soal
+func jawabanGIFT(item SoalDenganJawaban) (string, bool) {
+	var b strings.Builder
+	switch item.Soal.TipeSoal.NamaTipeUjian {
+	case entity.TipeSoalBenarSalah:
+		benar, ok := kunciBenarSalah(item.Jawaban)
+		if !ok {
+			return "", false
+		}
+		if benar {
+			return "{TRUE}", true
+		}
+		return "{FALSE}", true
+	case entity.TipeSoalPilihanBerganda:
+		b.WriteString("{\n")
+		for _, o := range item.Jawaban {
+			penanda := "~"
+			if o.Benar {
+				penanda = "="
+			}
+			fmt.Fprintf(&b, "\t%s%s\n", penanda, escaperGIFT.Replace(o.Jawaban))
+		}
+	case entity.TipeSoalPilihanKompleks:
+		// Bobot opsi benar dibagi rata, opsi salah mengurangi nilai
+		benar := 0
+		for _, o := range item.Jawaban {
+			if o.Benar {
+				benar++
+			}
+		}
+		if benar == 0 {
+			return "", false
+		}
+		bobot := strconv.FormatFloat(math.Round(100/float64(benar)*1e5)/1e5, 'f', -1, 64)
+		b.WriteString("{\n")
+		for _, o := range item.Jawaban {
+			if o.Benar {
+				fmt.Fprintf(&b, "\t~%%%s%%%s\n", bobot, escaperGIFT.Replace(o.Jawaban))
+			} else {
+				fmt.Fprintf(&b, "\t~%%-100%%%s\n", escaperGIFT.Replace(o.Jawaban))
+			}
+		}
+	case entity.TipeSoalIsian:
+		var teks, angka []entity.JawabanSoal
+		for _, o := range item.Jawaban {
+			if !o.Benar {
+				continue
+			}
+			switch o.TipePencocokan {
+			case entity.PencocokanAngka:
+				angka = append(angka, o)
+			case entity.PencocokanRegex:
+				return "", false
+			default:
+				teks = append(teks, o)
+			}
+		}
+		switch {
+		case len(teks) > 0 && len(angka) > 0:
+			return "", false
+		case len(angka) > 0:
+			b.WriteString("{#\n")
+			for _, o := range angka {
+				fmt.Fprintf(&b, "\t=%s:%s\n", o.Jawaban, strconv.FormatFloat(o.ToleransiAngka, 'f', -1, 64))
+			}
+		case len(teks) > 0:
+			b.WriteString("{\n")
+			for _, o := range teks {
+				fmt.Fprintf(&b, "\t=%s\n", escaperGIFT.Replace(o.Jawaban))
+			}
+		default:
+			// Tanpa variasi jawaban: esai, dinilai manual
+			return "{}", true
+		}
+	default:
+		return "", false
+	}
+	b.WriteString("}")
+	return b.String(), true
+}
+
+// parseAiken reads the questions of an Aiken file. Errors are reported by the line the question starts on.
+func parseAiken(data string, tipeByNama map[string]entity.TipeSoal, nilai float64) ([]soalImport, []KesalahanBaris) {
+	kesalahan := make([]KesalahanBaris, 0)
+	var daftar []soalImport
+	for _, b := range pisahBlokSoal(data, func(string) bool { return false }) {
+		soal, err := soalAiken(b.teks, tipeByNama)
+		if err != nil {
+			kesalahan = append(kesalahan, KesalahanBaris{Baris: b.baris, Pesan: []string{err.Error()}})
+			continue
+		}
+		soal.Soal.NilaiPerSoal = nilai
+		daftar = append(daftar, soalImport{baris: b.baris, SoalDenganJawaban: soal})
+	}
+	return daftar, kesalahan
+}
+
+var (
+	opsiAiken  = regexp.MustCompile(`^([A-Z])[.)]\s+(.*)$`)
+	kunciAiken = regexp.MustCompile(`^ANSWER:\s*([A-Z])$`)
+)
+
+// soalAiken reads one Aiken question: the question text, options labelled A. or A) in order and a
+// closing ANSWER: line
+func soalAiken(teks string, tipeByNama map[string]entity.TipeSoal) (SoalDenganJawaban, error) {
+	baris := strings.Split(teks, "\n")
+	pertanyaan := []string{baris[0]}
+	var opsi []entity.JawabanSoal
+	kunci := -1
+	for _, b := range baris[1:] {
+		if kunci >= 0 {
+			return SoalDenganJawaban{}, errors.New("baris ANSWER: harus menjadi baris terakhir soal")
+		}
+		if m := kunciAiken.FindStringSubmatch(b); m != nil {
+			kunci = int(m[1][0] - 'A')
+			continue
+		}
+		if m := opsiAiken.FindStringSubmatch(b); m != nil && int(m[1][0]-'A') == len(opsi) {
+			opsi = append(opsi, entity.JawabanSoal{Jawaban: strings.TrimSpace(m[2])})
+			continue
+		}
+		if len(opsi) > 0 {
+			return SoalDenganJawaban{}, fmt.Errorf("baris %q bukan opsi berikutnya atau ANSWER:", b)
+		}
+		pertanyaan = append(pertanyaan, b)
+	}
+	if kunci < 0 {
+		return SoalDenganJawaban{}, errors.New("baris ANSWER: tidak ditemukan")
+	}
+	if kunci >= len(opsi) {
+		return SoalDenganJawaban{}, fmt.Errorf("ANSWER: %c tidak cocok dengan opsi mana pun", 'A'+kunci)
+	}
+	opsi[kunci].Benar = true
+
+	namaTipe := entity.TipeSoalPilihanBerganda
+	if adalahBenarSalah(opsi) {
+		namaTipe = entity.TipeSoalBenarSalah
+	}
+	tipe, err := cariTipeSoal(tipeByNama, namaTipe)
+	if err != nil {
+		return SoalDenganJawaban{}, err
+	}
+	return SoalDenganJawaban{
+		Soal:    entity.Soal{Soal: strings.Join(pertanyaan, "\n"), IdTipeSoal: tipe.IdTipeSoal},
+		Jawaban: opsi,
+	}, nil
+}
+
+// tulisAiken writes the Pilihan_Berganda and Benar_Salah soal as Aiken questions; other soal are skipped
+func tulisAiken(daftar []SoalDenganJawaban) ([]byte, []uint64) {
+	var b strings.Builder
+	dilewati := make([]uint64, 0)
+	satuBaris := strings.NewReplacer("\r\n", " ", "\n", " ")
+	for _, item := range daftar {
+		tipe := item.Soal.TipeSoal.NamaTipeUjian
+		if (tipe != entity.TipeSoalPilihanBerganda && tipe != entity.TipeSoalBenarSalah) || len(item.Jawaban) > 26 {
+			dilewati = append(dilewati, item.Soal.IdSoal)
+			continue
+		}
+		kunci := -1
+		for i, o := range item.Jawaban {
+			if o.Benar {
+				kunci = i
+			}
+		}
+		if kunci < 0 {
+			dilewati = append(dilewati, item.Soal.IdSoal)
+			continue
+		}
+		fmt.Fprintf(&b, "%s\n", satuBaris.Replace(item.Soal.Soal))
+		for i, o := range item.Jawaban {
+			fmt.Fprintf(&b, "%c. %s\n", 'A'+i, satuBaris.Replace(o.Jawaban))
+		}
+		fmt.Fprintf(&b, "ANSWER: %c\n\n", 'A'+kunci)
+	}
+	return []byte(b.String()), dilewati
+}
+
+// opsiBenarSalah returns the options Benar and Salah with the given one correct
+func opsiBenarSalah(benar bool) []entity.JawabanSoal {
+	return []entity.JawabanSoal{{Jawaban: "Benar", Benar: benar}, {Jawaban: "Salah", Benar: !benar}}
+}
+
+// kunciBenarSalah reports whether the correct option of a Benar_Salah soal means true. Options are
+// recognised by their text, falling back to the first option meaning true.
+func kunciBenarSalah(opsi []entity.JawabanSoal) (bool, bool) {
+	for i, o := range opsi {
+		if !o.Benar {
+			continue
+		}
+		switch normalisasiNama(o.Jawaban) {
+		case "benar", "true", "b", "t":
+			return true, true
+		case "salah", "false", "s", "f":
+			return false, true
+		}
+		return i == 0, true
+	}
+	return false, false
+}
+
+// adalahBenarSalah reports whether the options are exactly Benar and Salah (or True and False)
+func adalahBenarSalah(opsi []entity.JawabanSoal) bool {
+	if len(opsi) != 2 {
+		return false
+	}
+	pasangan := normalisasiNama(opsi[0].Jawaban) + "/" + normalisasiNama(opsi[1].Jawaban)
+	switch pasangan {
+	case "benar/salah", "salah/benar", "true/false", "false/true":
+		return true
+	}
+	return false
+}
+
+func cariTipeSoal(tipeByNama map[string]entity.TipeSoal, nama string) (entity.TipeSoal, error) {
+	tipe, ada := tipeByNama[normalisasiNama(nama)]
+	if !ada {
+		return tipe, fmt.Errorf("tipe soal %s belum terdaftar", nama)
+	}
+	return tipe, nil
+}
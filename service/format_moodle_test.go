@@ -0,0 +1,233 @@
+package service
+
+import (
+	"cbt-api/entity"
+	"reflect"
+	"testing"
+)
+
+// ringkasanSoal is the part of an imported soal the format tests compare
+type ringkasanSoal struct {
+	Tipe string
+	Soal string
+	Opsi []ringkasanOpsi
+}
+
+type ringkasanOpsi struct {
+	Jawaban    string
+	Benar      bool
+	Pencocokan string
+	Toleransi  float64
+}
+
+func ringkas(soal SoalDenganJawaban, tipeByID map[uint64]string) ringkasanSoal {
+	tipe := soal.Soal.TipeSoal.NamaTipeUjian
+	if tipe == "" {
+		tipe = tipeByID[soal.Soal.IdTipeSoal]
+	}
+	hasil := ringkasanSoal{Tipe: tipe, Soal: soal.Soal.Soal}
+	for _, o := range soal.Jawaban {
+		pencocokan := o.TipePencocokan
+		if pencocokan == "" {
+			pencocokan = entity.PencocokanTeks
+		}
+		hasil.Opsi = append(hasil.Opsi, ringkasanOpsi{o.Jawaban, o.Benar, pencocokan, o.ToleransiAngka})
+	}
+	return hasil
+}
+
+func ringkasImport(daftar []soalImport) []ringkasanSoal {
+	tipeByID := make(map[uint64]string)
+	for _, tipe := range tipeSoalTest() {
+		tipeByID[tipe.IdTipeSoal] = tipe.NamaTipeUjian
+	}
+	var hasil []ringkasanSoal
+	for _, soal := range daftar {
+		hasil = append(hasil, ringkas(soal.SoalDenganJawaban, tipeByID))
+	}
+	return hasil
+}
+
+func barisKesalahan(kesalahan []KesalahanBaris) []int {
+	var baris []int
+	for _, k := range kesalahan {
+		baris = append(baris, k.Baris)
+	}
+	return baris
+}
+
+func teksOpsi(teks ...string) []ringkasanOpsi {
+	var opsi []ringkasanOpsi
+	for i, t := range teks {
+		opsi = append(opsi, ringkasanOpsi{Jawaban: t, Benar: i == 0, Pencocokan: entity.PencocokanTeks})
+	}
+	return opsi
+}
+
+func TestParseGIFT(t *testing.T) {
+	data := "// komentar\n" +
+		"$CATEGORY: ujian\n" +
+		"\n" +
+		"::Q1:: Ibu kota Indonesia? {=Jakarta ~Bandung ~Medan}\n" +
+		"\n" +
+		"Bumi datar. {F}\n" +
+		"\n" +
+		"::Q3::[html]Ibu kota Jawa Barat? {=Bandung =Kota Bandung}\n" +
+		"\n" +
+		"Nilai pi? {#3.14:0.01}\n" +
+		"\n" +
+		"Bilangan prima {~%50%2 ~%50%3 ~%-100%4#bukan prima}\n" +
+		"\n" +
+		"Ceritakan liburanmu. {}\n" +
+		"\n" +
+		"Ibu kota {=Jakarta} adalah kota terbesar.\n" +
+		"\n" +
+		"Apakah a\\=b? {=ya ~tidak}\n" +
+		"\n" +
+		"Pasangkan {=a -> b =c -> d}\n" +
+		"\n" +
+		"Tanpa blok jawaban\n" +
+		"\n" +
+		"Rentang {#1..3}\n"
+
+	daftar, kesalahan := parseGIFT(data, tipeSoalTest(), 2)
+	want := []ringkasanSoal{
+		{entity.TipeSoalPilihanBerganda, "Ibu kota Indonesia?", teksOpsi("Jakarta", "Bandung", "Medan")},
+		{entity.TipeSoalBenarSalah, "Bumi datar.", []ringkasanOpsi{{"Benar", false, entity.PencocokanTeks, 0}, {"Salah", true, entity.PencocokanTeks, 0}}},
+		{entity.TipeSoalIsian, "Ibu kota Jawa Barat?", []ringkasanOpsi{{"Bandung", true, entity.PencocokanTeks, 0}, {"Kota Bandung", true, entity.PencocokanTeks, 0}}},
+		{entity.TipeSoalIsian, "Nilai pi?", []ringkasanOpsi{{"3.14", true, entity.PencocokanAngka, 0.01}}},
+		{entity.TipeSoalPilihanKompleks, "Bilangan prima", []ringkasanOpsi{{"2", true, entity.PencocokanTeks, 0}, {"3", true, entity.PencocokanTeks, 0}, {"4", false, entity.PencocokanTeks, 0}}},
+		{entity.TipeSoalIsian, "Ceritakan liburanmu.", nil},
+		{entity.TipeSoalIsian, "Ibu kota _____ adalah kota terbesar.", []ringkasanOpsi{{"Jakarta", true, entity.PencocokanTeks, 0}}},
+		{entity.TipeSoalPilihanBerganda, "Apakah a=b?", teksOpsi("ya", "tidak")},
+		{entity.TipeSoalIsian, "Rentang", []ringkasanOpsi{{"2", true, entity.PencocokanAngka, 1}}},
+	}
+	if got := ringkasImport(daftar); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseGIFT =\n%+v\nwant\n%+v", got, want)
+	}
+	for _, soal := range daftar {
+		if soal.Soal.NilaiPerSoal != 2 {
+			t.Errorf("soal on line %d got nilai %v, want the import's 2", soal.baris, soal.Soal.NilaiPerSoal)
+		}
+	}
+	if got := barisKesalahan(kesalahan); !reflect.DeepEqual(got, []int{20, 22}) {
+		t.Errorf("errors on lines %v, want [20 22]: %+v", got, kesalahan)
+	}
+}
+
+func TestParseAiken(t *testing.T) {
+	data := "Ibu kota Indonesia?\n" +
+		"A. Bandung\n" +
+		"B) Jakarta\n" +
+		"ANSWER: B\n" +
+		"\n" +
+		"Bumi bulat?\n" +
+		"A. Benar\n" +
+		"B. Salah\n" +
+		"ANSWER: A\n" +
+		"\n" +
+		"Kunci di luar opsi\n" +
+		"A. x\n" +
+		"B. y\n" +
+		"ANSWER: C\n" +
+		"\n" +
+		"Tanpa kunci\n" +
+		"A. x\n" +
+		"\n" +
+		"Opsi melompat\n" +
+		"A. x\n" +
+		"C. y\n" +
+		"ANSWER: A\n" +
+		"\n" +
+		"Baris satu\n" +
+		"baris dua\n" +
+		"A. ya\n" +
+		"B. tidak\n" +
+		"ANSWER: B\n"
+
+	daftar, kesalahan := parseAiken(data, tipeSoalTest(), 1)
+	want := []ringkasanSoal{
+		{entity.TipeSoalPilihanBerganda, "Ibu kota Indonesia?", []ringkasanOpsi{{"Bandung", false, entity.PencocokanTeks, 0}, {"Jakarta", true, entity.PencocokanTeks, 0}}},
+		{entity.TipeSoalBenarSalah, "Bumi bulat?", teksOpsi("Benar", "Salah")},
+		{entity.TipeSoalPilihanBerganda, "Baris satu\nbaris dua", []ringkasanOpsi{{"ya", false, entity.PencocokanTeks, 0}, {"tidak", true, entity.PencocokanTeks, 0}}},
+	}
+	if got := ringkasImport(daftar); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseAiken =\n%+v\nwant\n%+v", got, want)
+	}
+	if got := barisKesalahan(kesalahan); !reflect.DeepEqual(got, []int{11, 16, 19}) {
+		t.Errorf("errors on lines %v, want [11 16 19]: %+v", got, kesalahan)
+	}
+}
+
+func soalEkspor(id uint64, tipe string, teks string, opsi ...entity.JawabanSoal) SoalDenganJawaban {
+	return SoalDenganJawaban{
+		Soal:    entity.Soal{IdSoal: id, Soal: teks, NilaiPerSoal: 5, TipeSoal: entity.TipeSoal{NamaTipeUjian: tipe}},
+		Jawaban: opsi,
+	}
+}
+
+func TestGIFTRoundTrip(t *testing.T) {
+	daftar := []SoalDenganJawaban{
+		soalEkspor(1, entity.TipeSoalPilihanBerganda, "Jika a = b {dan} b: c, maka #a ~ c?\nBaris kedua",
+			entity.JawabanSoal{Jawaban: "ya = benar", Benar: true}, entity.JawabanSoal{Jawaban: "tidak ~ salah"}),
+		soalEkspor(2, entity.TipeSoalBenarSalah, "Bumi bulat",
+			entity.JawabanSoal{Jawaban: "Benar", Benar: true}, entity.JawabanSoal{Jawaban: "Salah"}),
+		soalEkspor(3, entity.TipeSoalIsian, "Ibu kota?",
+			entity.JawabanSoal{Jawaban: "Jakarta", Benar: true, TipePencocokan: entity.PencocokanTeks},
+			entity.JawabanSoal{Jawaban: "DKI Jakarta", Benar: true, TipePencocokan: entity.PencocokanTeks}),
+		soalEkspor(4, entity.TipeSoalIsian, "Nilai pi?",
+			entity.JawabanSoal{Jawaban: "3.14", Benar: true, TipePencocokan: entity.PencocokanAngka, ToleransiAngka: 0.01}),
+		soalEkspor(5, entity.TipeSoalPilihanKompleks, "Bilangan prima",
+			entity.JawabanSoal{Jawaban: "2", Benar: true}, entity.JawabanSoal{Jawaban: "4"}, entity.JawabanSoal{Jawaban: "5", Benar: true}),
+		soalEkspor(6, entity.TipeSoalIsian, "Ceritakan liburanmu."),
+		soalEkspor(7, entity.TipeSoalIsian, "Regex",
+			entity.JawabanSoal{Jawaban: "a+", Benar: true, TipePencocokan: entity.PencocokanRegex}),
+		soalEkspor(8, entity.TipeSoalIsian, "Campuran",
+			entity.JawabanSoal{Jawaban: "tiga", Benar: true, TipePencocokan: entity.PencocokanTeks},
+			entity.JawabanSoal{Jawaban: "3", Benar: true, TipePencocokan: entity.PencocokanAngka}),
+	}
+
+	data, dilewati := tulisGIFT(daftar)
+	if !reflect.DeepEqual(dilewati, []uint64{7, 8}) {
+		t.Errorf("skipped %v, want [7 8]", dilewati)
+	}
+	parsed, kesalahan := parseGIFT(string(data), tipeSoalTest(), 5)
+	if len(kesalahan) > 0 {
+		t.Fatalf("reading the export back: %+v\n%s", kesalahan, data)
+	}
+
+	var want []ringkasanSoal
+	for _, soal := range daftar[:6] {
+		want = append(want, ringkas(soal, nil))
+	}
+	if got := ringkasImport(parsed); !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip =\n%+v\nwant\n%+v\nfile:\n%s", got, want, data)
+	}
+}
+
+func TestAikenRoundTrip(t *testing.T) {
+	daftar := []SoalDenganJawaban{
+		soalEkspor(1, entity.TipeSoalPilihanBerganda, "Ibu kota\nIndonesia?",
+			entity.JawabanSoal{Jawaban: "Bandung"}, entity.JawabanSoal{Jawaban: "Jakarta", Benar: true}),
+		soalEkspor(2, entity.TipeSoalBenarSalah, "Bumi bulat",
+			entity.JawabanSoal{Jawaban: "Benar"}, entity.JawabanSoal{Jawaban: "Salah", Benar: true}),
+		soalEkspor(3, entity.TipeSoalIsian, "Ibu kota?", entity.JawabanSoal{Jawaban: "Jakarta", Benar: true}),
+		soalEkspor(4, entity.TipeSoalPilihanKompleks, "Prima", entity.JawabanSoal{Jawaban: "2", Benar: true}),
+		soalEkspor(5, entity.TipeSoalPilihanBerganda, "Tanpa kunci", entity.JawabanSoal{Jawaban: "x"}),
+	}
+
+	data, dilewati := tulisAiken(daftar)
+	if !reflect.DeepEqual(dilewati, []uint64{3, 4, 5}) {
+		t.Errorf("skipped %v, want [3 4 5]", dilewati)
+	}
+	parsed, kesalahan := parseAiken(string(data), tipeSoalTest(), 5)
+	if len(kesalahan) > 0 {
+		t.Fatalf("reading the export back: %+v\n%s", kesalahan, data)
+	}
+
+	want := []ringkasanSoal{ringkas(daftar[0], nil), ringkas(daftar[1], nil)}
+	want[0].Soal = "Ibu kota Indonesia?" // Aiken menulis soal dalam satu baris
+	if got := ringkasImport(parsed); !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip =\n%+v\nwant\n%+v\nfile:\n%s", got, want, data)
+	}
+}
@@ -0,0 +1,41 @@
+// service/idempotency_service.go
+package service
+
+import (
+	"cbt-api/entity"
+	"cbt-api/repository"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// IdempotencyService is a contract for remembering the response of requests sent with an Idempotency-Key
+type IdempotencyService interface {
+	FindResponse(userID uint64, key string) (entity.IdempotencyKey, bool, error)
+	SaveResponse(record entity.IdempotencyKey) error
+}
+
+type idempotencyService struct {
+	idempotencyKeyRepository repository.IdempotencyKeyRepository
+}
+
+// NewIdempotencyService creates a new instance of IdempotencyService
+func NewIdempotencyService(idempotencyKeyRepo repository.IdempotencyKeyRepository) IdempotencyService {
+	return &idempotencyService{
+		idempotencyKeyRepository: idempotencyKeyRepo,
+	}
+}
+
+// FindResponse returns the stored response for the user's key; found is false when the key is new
+func (s *idempotencyService) FindResponse(userID uint64, key string) (entity.IdempotencyKey, bool, error) {
+	record, err := s.idempotencyKeyRepository.FindByUserAndKey(userID, key)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return record, false, nil
+	}
+	return record, err == nil, err
+}
+
+func (s *idempotencyService) SaveResponse(record entity.IdempotencyKey) error {
+	_, err := s.idempotencyKeyRepository.CreateIdempotencyKey(record)
+	return err
+}
@@ -0,0 +1,365 @@
+// service/impor_soal.go
+package service
+
+import (
+	"cbt-api/entity"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrImportSoalTidakValid   = errors.New("file import soal tidak valid")
+	ErrTujuanImportTidakValid = errors.New("isi salah satu dari id_ujian atau id_latihan")
+	ErrFormatTidakDikenal     = errors.New("format file soal tidak dikenal")
+)
+
+// Format file soal yang dapat diimpor
+const (
+	FormatCSV   = "csv"
+	FormatXLSX  = "xlsx"
+	FormatGIFT  = "gift"
+	FormatAiken = "aiken"
+)
+
+// ImportSoal is one uploaded question file and where its soal go
+type ImportSoal struct {
+	IdUjian   uint64
+	IdLatihan uint64
+	NamaFile  string
+	Format    string // kosong: ditentukan dari ekstensi NamaFile (.csv, .xlsx, .gift)
+	Data      []byte
+	DryRun    bool
+	// NilaiPerSoal is given to soal from GIFT and Aiken files, which do not carry points; default 1
+	NilaiPerSoal float64
+}
+
+// KesalahanBaris lists the validation errors of one row or line of the file; Baris 0 is about the whole file
+type KesalahanBaris struct {
+	Baris int      `json:"baris"`
+	Pesan []string `json:"pesan"`
+}
+
+// HasilImportSoal reports what an import did, or would do in dry-run mode
+type HasilImportSoal struct {
+	DryRun     bool                `json:"dry_run"`
+	JumlahSoal int                 `json:"jumlah_soal"`
+	Disimpan   bool                `json:"disimpan"`
+	Kesalahan  []KesalahanBaris    `json:"kesalahan"`
+	Soal       []SoalDenganJawaban `json:"soal"`
+}
+
+// soalImport is a parsed row waiting to be saved
+type soalImport struct {
+	baris int
+	SoalDenganJawaban
+}
+
+// ImportSoal reads a question file into soal and jawaban_soal of an ujian or a latihan. Besides the
+// GIFT and Aiken formats of Moodle, a CSV or XLSX spreadsheet following the import template is
+// accepted. Its first row holds the column names:
+//
+//	soal            teks soal (wajib)
+//	tipe_soal       Pilihan_Berganda, Benar_Salah, Isian atau Pilihan_Kompleks (wajib)
+//	opsi_a, opsi_b… opsi jawaban, dilabeli A, B, … sesuai urutan kolom
+//	kunci           label atau teks opsi yang benar, dipisah koma untuk Pilihan_Kompleks; untuk Isian
+//	                variasi jawaban yang diterima dipisah "|"
+//	nilai_per_soal  poin soal (wajib)
+//	mode_penilaian  Semua_Benar, Proporsional atau Penalti (opsional)
+//	image_url       gambar soal (opsional)
+//
+// Every row is validated before anything is written and the soal are saved in one transaction, so an
+// import either adds all soal or none. With DryRun nothing is saved.
+func (s *soalService) ImportSoal(input ImportSoal) (HasilImportSoal, error) {
+	hasil := HasilImportSoal{DryRun: input.DryRun, Kesalahan: make([]KesalahanBaris, 0), Soal: make([]SoalDenganJawaban, 0)}
+	if (input.IdUjian == 0) == (input.IdLatihan == 0) {
+		return hasil, ErrTujuanImportTidakValid
+	}
+
+	var ujian entity.Ujian
+	var err error
+	if input.IdUjian != 0 {
+		if ujian, err = s.ujianRepository.GetUjianByID(input.IdUjian); err != nil {
+			return hasil, err
+		}
+		if err := s.cekUjianBolehDiubah(input.IdUjian); err != nil {
+			return hasil, err
+		}
+	} else if _, err := s.latihanRepository.GetLatihanByID(input.IdLatihan); err != nil {
+		return hasil, err
+	}
+
+	daftar, kesalahan, err := s.bacaFileSoal(input)
+	if err != nil {
+		return hasil, err
+	}
+	hasil.Kesalahan = kesalahan
+
+	// Validasi yang sama dengan endpoint soal, per baris
+	var total float64
+	for i := range daftar {
+		soal := &daftar[i]
+		soal.Soal.IdUjian = input.IdUjian
+		soal.Soal.IdLatihan = input.IdLatihan
+		tipeSoal, err := s.siapkanSoal(&soal.Soal, soal.Jawaban)
+		if err != nil {
+			if !errors.Is(err, ErrSoalTidakValid) && !errors.Is(err, ErrOpsiSoalTidakValid) {
+				return hasil, err
+			}
+			hasil.Kesalahan = append(hasil.Kesalahan, KesalahanBaris{Baris: soal.baris, Pesan: []string{err.Error()}})
+		}
+		soal.Soal.TipeSoal = tipeSoal
+		total += soal.Soal.NilaiPerSoal
+	}
+	if len(daftar) == 0 && len(hasil.Kesalahan) == 0 {
+		hasil.Kesalahan = append(hasil.Kesalahan, KesalahanBaris{Pesan: []string{"file tidak berisi soal"}})
+	}
+	if input.IdUjian != 0 {
+		if err := s.cekTotalNilai(ujian, 0, total); errors.Is(err, ErrTotalNilaiMelebihiGrade) {
+			hasil.Kesalahan = append(hasil.Kesalahan, KesalahanBaris{Pesan: []string{err.Error()}})
+		} else if err != nil {
+			return hasil, err
+		}
+	}
+
+	hasil.JumlahSoal = len(daftar)
+	for _, soal := range daftar {
+		hasil.Soal = append(hasil.Soal, soal.SoalDenganJawaban)
+	}
+	if len(hasil.Kesalahan) > 0 {
+		return hasil, ErrImportSoalTidakValid
+	}
+	if input.DryRun {
+		return hasil, nil
+	}
+
+	now := time.Now()
+	err = s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		soalRepo := s.soalRepository.WithTx(tx)
+		for i := range hasil.Soal {
+			soal := hasil.Soal[i].Soal
+			soal.CreatedAt = now
+			soal.UpdatedAt = now
+			created, err := soalRepo.CreateSoal(soal)
+			if err != nil {
+				return fmt.Errorf("baris %d: %w", daftar[i].baris, err)
+			}
+			opsi, err := s.simpanOpsi(tx, created, hasil.Soal[i].Jawaban, nil)
+			if err != nil {
+				return fmt.Errorf("baris %d: %w", daftar[i].baris, err)
+			}
+			created.TipeSoal = soal.TipeSoal
+			hasil.Soal[i] = SoalDenganJawaban{Soal: created, Jawaban: opsi}
+		}
+		return nil
+	})
+	if err != nil {
+		return hasil, err
+	}
+	hasil.Disimpan = true
+	return hasil, nil
+}
+
+// bacaFileSoal parses the uploaded file according to its format
+func (s *soalService) bacaFileSoal(input ImportSoal) ([]soalImport, []KesalahanBaris, error) {
+	format := strings.ToLower(input.Format)
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(input.NamaFile)), ".")
+	}
+	nilai := input.NilaiPerSoal
+	if nilai == 0 {
+		nilai = 1
+	}
+
+	tipeSoalList, err := s.soalRepository.FindAllTipeSoal()
+	if err != nil {
+		return nil, nil, err
+	}
+	var baris [][]string
+	switch format {
+	case FormatCSV:
+		baris, err = bacaCSV(input.Data)
+	case FormatXLSX:
+		baris, err = bacaXLSX(input.Data)
+	case FormatGIFT:
+		daftar, kesalahan := parseGIFT(string(input.Data), petaTipeSoal(tipeSoalList), nilai)
+		return daftar, kesalahan, nil
+	case FormatAiken:
+		daftar, kesalahan := parseAiken(string(input.Data), petaTipeSoal(tipeSoalList), nilai)
+		return daftar, kesalahan, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: gunakan file .csv, .xlsx atau .gift, atau isi format gift atau aiken", ErrFormatTidakDikenal)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	daftar, kesalahan := parseTemplateSoal(baris, petaTipeSoal(tipeSoalList))
+	return daftar, kesalahan, nil
+}
+
+// parseTemplateSoal turns the spreadsheet rows into soal with their options. Rows that cannot be read
+// are reported by their row number; empty rows are skipped.
+func parseTemplateSoal(baris [][]string, tipeByNama map[string]entity.TipeSoal) ([]soalImport, []KesalahanBaris) {
+	kesalahan := make([]KesalahanBaris, 0)
+	if len(baris) == 0 {
+		return nil, append(kesalahan, KesalahanBaris{Pesan: []string{"file kosong"}})
+	}
+
+	kolom := make(map[string]int)
+	var kolomOpsi []int
+	for i, nama := range baris[0] {
+		nama = normalisasiNama(nama)
+		if strings.HasPrefix(nama, "opsi") {
+			kolomOpsi = append(kolomOpsi, i)
+			continue
+		}
+		if _, ada := kolom[nama]; !ada && nama != "" {
+			kolom[nama] = i
+		}
+	}
+	var kurang []string
+	for _, wajib := range []string{"soal", "tipe_soal", "nilai_per_soal"} {
+		if _, ada := kolom[wajib]; !ada {
+			kurang = append(kurang, wajib)
+		}
+	}
+	if len(kurang) > 0 {
+		return nil, append(kesalahan, KesalahanBaris{Baris: 1, Pesan: []string{"kolom wajib tidak ada: " + strings.Join(kurang, ", ")}})
+	}
+
+	var daftar []soalImport
+	for i, row := range baris[1:] {
+		nomor := i + 2
+		sel := func(nama string) string {
+			idx, ada := kolom[nama]
+			if !ada || idx >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[idx])
+		}
+		if strings.TrimSpace(strings.Join(row, "")) == "" {
+			continue
+		}
+
+		var pesan []string
+		var opsiTeks []string
+		for _, idx := range kolomOpsi {
+			if idx < len(row) && strings.TrimSpace(row[idx]) != "" {
+				opsiTeks = append(opsiTeks, strings.TrimSpace(row[idx]))
+			}
+		}
+
+		tipe, ada := tipeByNama[normalisasiNama(sel("tipe_soal"))]
+		if !ada {
+			pesan = append(pesan, fmt.Sprintf("tipe_soal %q tidak dikenal", sel("tipe_soal")))
+		}
+		nilai, err := parseAngka(sel("nilai_per_soal"))
+		if err != nil {
+			pesan = append(pesan, fmt.Sprintf("nilai_per_soal %q bukan angka", sel("nilai_per_soal")))
+		}
+
+		var opsi []entity.JawabanSoal
+		if ada {
+			opsi, err = opsiDariTemplate(tipe.NamaTipeUjian, opsiTeks, sel("kunci"))
+			if err != nil {
+				pesan = append(pesan, err.Error())
+			}
+		}
+		if len(pesan) > 0 {
+			kesalahan = append(kesalahan, KesalahanBaris{Baris: nomor, Pesan: pesan})
+			continue
+		}
+
+		daftar = append(daftar, soalImport{
+			baris: nomor,
+			SoalDenganJawaban: SoalDenganJawaban{
+				Soal: entity.Soal{
+					Soal:          sel("soal"),
+					ImageUrl:      sel("image_url"),
+					NilaiPerSoal:  nilai,
+					ModePenilaian: sel("mode_penilaian"),
+					IdTipeSoal:    tipe.IdTipeSoal,
+				},
+				Jawaban: opsi,
+			},
+		})
+	}
+	return daftar, kesalahan
+}
+
+// opsiDariTemplate builds the options of one row and marks the ones named in kunci as correct. A
+// Benar_Salah row without options gets the options Benar and Salah.
+func opsiDariTemplate(tipeSoal string, opsiTeks []string, kunci string) ([]entity.JawabanSoal, error) {
+	if tipeSoal == entity.TipeSoalIsian {
+		opsi := make([]entity.JawabanSoal, 0, len(opsiTeks))
+		for _, teks := range append(opsiTeks, strings.Split(kunci, "|")...) {
+			if teks = strings.TrimSpace(teks); teks != "" {
+				opsi = append(opsi, entity.JawabanSoal{Jawaban: teks, Benar: true})
+			}
+		}
+		return opsi, nil
+	}
+
+	if tipeSoal == entity.TipeSoalBenarSalah && len(opsiTeks) == 0 {
+		opsiTeks = []string{"Benar", "Salah"}
+	}
+	opsi := make([]entity.JawabanSoal, 0, len(opsiTeks))
+	for _, teks := range opsiTeks {
+		opsi = append(opsi, entity.JawabanSoal{Jawaban: teks})
+	}
+
+	var token []string
+	for _, t := range strings.FieldsFunc(kunci, func(r rune) bool { return r == ',' || r == ';' }) {
+		if t = strings.TrimSpace(t); t != "" {
+			token = append(token, t)
+		}
+	}
+	if len(token) == 0 {
+		return opsi, errors.New("kunci wajib diisi")
+	}
+	for _, t := range token {
+		idx := indeksOpsiKunci(t, opsiTeks)
+		if idx < 0 {
+			return opsi, fmt.Errorf("kunci %q tidak cocok dengan opsi mana pun", t)
+		}
+		opsi[idx].Benar = true
+	}
+	return opsi, nil
+}
+
+// indeksOpsiKunci finds the option named by a kunci token, by its text first and then by its letter
+func indeksOpsiKunci(token string, opsiTeks []string) int {
+	for i, teks := range opsiTeks {
+		if strings.EqualFold(teks, token) {
+			return i
+		}
+	}
+	if len(token) == 1 {
+		huruf := strings.ToUpper(token)[0]
+		if idx := int(huruf - 'A'); huruf >= 'A' && huruf <= 'Z' && idx < len(opsiTeks) {
+			return idx
+		}
+	}
+	return -1
+}
+
+// petaTipeSoal indexes the tipe soal by normalised name and by id, so tipe_soal may hold either
+func petaTipeSoal(tipeSoalList []entity.TipeSoal) map[string]entity.TipeSoal {
+	tipeByNama := make(map[string]entity.TipeSoal, 2*len(tipeSoalList))
+	for _, tipe := range tipeSoalList {
+		tipeByNama[normalisasiNama(tipe.NamaTipeUjian)] = tipe
+		tipeByNama[strconv.FormatUint(tipe.IdTipeSoal, 10)] = tipe
+	}
+	return tipeByNama
+}
+
+// normalisasiNama makes column and tipe soal names comparable: "Pilihan Berganda" matches "Pilihan_Berganda"
+func normalisasiNama(nama string) string {
+	nama = strings.ToLower(strings.TrimSpace(nama))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(nama)
+}
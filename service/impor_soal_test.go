@@ -0,0 +1,127 @@
+package service
+
+import (
+	"cbt-api/entity"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func tipeSoalTest() map[string]entity.TipeSoal {
+	return petaTipeSoal([]entity.TipeSoal{
+		{IdTipeSoal: 1, NamaTipeUjian: entity.TipeSoalPilihanBerganda},
+		{IdTipeSoal: 2, NamaTipeUjian: entity.TipeSoalBenarSalah},
+		{IdTipeSoal: 3, NamaTipeUjian: entity.TipeSoalIsian},
+		{IdTipeSoal: 4, NamaTipeUjian: entity.TipeSoalPilihanKompleks},
+	})
+}
+
+func TestParseTemplateSoal(t *testing.T) {
+	baris := [][]string{
+		{"Soal", "Tipe Soal", "opsi_a", "opsi_b", "opsi_c", "kunci", "nilai_per_soal", "mode_penilaian"},
+		{"Ibu kota Indonesia?", "Pilihan Berganda", "Bandung", "Jakarta", "Medan", "B", "10", ""},
+		{"Bumi bulat", "Benar_Salah", "", "", "", "Benar", "5", ""},
+		{"Ibu kota Jawa Barat?", "3", "", "", "", "Bandung|Kota Bandung", "2,5", ""},
+		{"Bilangan prima?", "Pilihan_Kompleks", "2", "4", "5", "A, c", "10", "Proporsional"},
+		{"", "", "", "", "", "", "", ""},
+		{"Tanpa kunci", "Pilihan_Berganda", "x", "y", "", "", "1", ""},
+		{"Kunci salah", "Pilihan_Berganda", "x", "y", "", "Z", "1", ""},
+		{"Tipe asing", "Esai", "", "", "", "", "1", ""},
+		{"Nilai NaN", "Pilihan_Berganda", "x", "y", "", "A", "NaN", ""},
+	}
+	daftar, kesalahan := parseTemplateSoal(baris, tipeSoalTest())
+
+	if len(daftar) != 4 {
+		t.Fatalf("parsed %d soal, want 4", len(daftar))
+	}
+	type opsiRingkas struct {
+		Jawaban string
+		Benar   bool
+	}
+	want := []struct {
+		baris int
+		tipe  uint64
+		nilai float64
+		mode  string
+		opsi  []opsiRingkas
+	}{
+		{2, 1, 10, "", []opsiRingkas{{"Bandung", false}, {"Jakarta", true}, {"Medan", false}}},
+		{3, 2, 5, "", []opsiRingkas{{"Benar", true}, {"Salah", false}}},
+		{4, 3, 2.5, "", []opsiRingkas{{"Bandung", true}, {"Kota Bandung", true}}},
+		{5, 4, 10, "Proporsional", []opsiRingkas{{"2", true}, {"4", false}, {"5", true}}},
+	}
+	for i, w := range want {
+		got := daftar[i]
+		var opsi []opsiRingkas
+		for _, o := range got.Jawaban {
+			opsi = append(opsi, opsiRingkas{o.Jawaban, o.Benar})
+		}
+		if got.baris != w.baris || got.Soal.IdTipeSoal != w.tipe || got.Soal.NilaiPerSoal != w.nilai ||
+			got.Soal.ModePenilaian != w.mode || !reflect.DeepEqual(opsi, w.opsi) {
+			t.Errorf("row %d = baris %d, tipe %d, nilai %v, mode %q, opsi %v; want %+v",
+				i, got.baris, got.Soal.IdTipeSoal, got.Soal.NilaiPerSoal, got.Soal.ModePenilaian, opsi, w)
+		}
+	}
+
+	wantBaris := []int{7, 8, 9, 10}
+	var gotBaris []int
+	for _, k := range kesalahan {
+		gotBaris = append(gotBaris, k.Baris)
+	}
+	if !reflect.DeepEqual(gotBaris, wantBaris) {
+		t.Errorf("errors on rows %v, want %v: %+v", gotBaris, wantBaris, kesalahan)
+	}
+}
+
+func TestParseTemplateSoalKolomWajib(t *testing.T) {
+	_, kesalahan := parseTemplateSoal([][]string{{"soal", "kunci"}}, tipeSoalTest())
+	if len(kesalahan) != 1 || kesalahan[0].Baris != 1 || !strings.Contains(kesalahan[0].Pesan[0], "tipe_soal, nilai_per_soal") {
+		t.Errorf("missing columns reported as %+v", kesalahan)
+	}
+	if _, kesalahan := parseTemplateSoal(nil, tipeSoalTest()); len(kesalahan) != 1 || kesalahan[0].Baris != 0 {
+		t.Errorf("empty file reported as %+v", kesalahan)
+	}
+}
+
+func TestImportSoalCSV(t *testing.T) {
+	env := newLingkunganTest(t)
+	ujian := env.buatUjian(t, nil)
+	csv := "soal;tipe_soal;opsi_a;opsi_b;kunci;nilai_per_soal\n" +
+		"Ibu kota Indonesia?;Pilihan_Berganda;Bandung;Jakarta;B;40\n" +
+		"Bumi bulat;Benar_Salah;;;Benar;60\n"
+	input := ImportSoal{IdUjian: ujian.IdUjian, NamaFile: "soal.csv", Data: []byte(csv), DryRun: true}
+
+	hasil, err := env.soal.ImportSoal(input)
+	if err != nil {
+		t.Fatalf("dry run: %v %+v", err, hasil.Kesalahan)
+	}
+	if hasil.JumlahSoal != 2 || hasil.Disimpan || jumlahSoalUjian(t, env, ujian.IdUjian) != 0 {
+		t.Fatalf("dry run saved soal or missed some: %+v", hasil)
+	}
+
+	input.DryRun = false
+	if hasil, err = env.soal.ImportSoal(input); err != nil || !hasil.Disimpan {
+		t.Fatalf("import = %v, disimpan %v", err, hasil.Disimpan)
+	}
+	if n := jumlahSoalUjian(t, env, ujian.IdUjian); n != 2 {
+		t.Errorf("ujian has %d soal, want 2", n)
+	}
+
+	// Total nilai melebihi grade: tidak ada yang disimpan
+	if _, err := env.soal.ImportSoal(input); !errors.Is(err, ErrImportSoalTidakValid) {
+		t.Errorf("import over the grade = %v, want ErrImportSoalTidakValid", err)
+	}
+	if n := jumlahSoalUjian(t, env, ujian.IdUjian); n != 2 {
+		t.Errorf("ujian has %d soal after a rejected import, want 2", n)
+	}
+}
+
+func jumlahSoalUjian(t *testing.T, env *lingkunganTest, ujianID uint64) int64 {
+	t.Helper()
+	var n int64
+	if err := env.db.Model(&entity.Soal{}).Where("id_ujian = ?", ujianID).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
@@ -2,71 +2,247 @@
 package service
 
 import (
+	"cbt-api/dto"
 	"cbt-api/entity"
 	"cbt-api/repository"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
 )
 
+var ErrSoalBukanLatihan = errors.New("soal bukan soal latihan")
+
 // JawabanLatihanService is a contract for jawaban latihan service
 type JawabanLatihanService interface {
 	CreateJawabanLatihan(jawabanSiswa entity.JawabanSiswa) (entity.JawabanSiswa, error)
 	CreateBatchJawabanLatihan(jawabanList []entity.JawabanSiswa, idLatihan uint64) ([]entity.JawabanSiswa, error)
-	GetJawabanLatihanByID(id uint64) (entity.JawabanSiswa, error)
-	GetJawabanLatihanBySiswaID(siswaID uint64) ([]entity.JawabanSiswa, error)
+	GetJawabanLatihanByID(id uint64) (dto.JawabanSiswaDTO, error)
+	GetJawabanLatihanBySiswaID(siswaID uint64) ([]dto.JawabanSiswaDTO, error)
 	GetJawabanLatihanByLatihanID(latihanID uint64) ([]entity.JawabanSiswa, error)
+	GetJawabanLatihanByLatihanIDAndSiswaID(latihanID uint64, siswaID uint64) ([]dto.JawabanSiswaDTO, error)
 }
 
 // SoalLatihanService is a contract for soal latihan service
 type SoalLatihanService interface {
 	GetSoalLatihanByID(latihanID uint64) ([]entity.Soal, error)
 	GetSoalWithJawabanByLatihanID(latihanID uint64) (map[uint64][]entity.JawabanSoal, error)
+	GetSoalSiswaByLatihanID(latihanID uint64, siswaID uint64) ([]dto.SoalSiswaDTO, error)
 }
 
 type jawabanLatihanService struct {
 	jawabanLatihanRepository repository.JawabanLatihanRepository
+	latihanRepository        repository.LatihanRepository
+	soalRepository           repository.SoalRepository
+	kunciLatihan             kunciLatihan
 }
 
 type soalLatihanService struct {
 	soalLatihanRepository repository.SoalLatihanRepository
+	latihanRepository     repository.LatihanRepository
+	kunciLatihan          kunciLatihan
 }
 
 // NewJawabanLatihanService creates a new instance of JawabanLatihanService
-func NewJawabanLatihanService(jawabanLatihanRepo repository.JawabanLatihanRepository) JawabanLatihanService {
+func NewJawabanLatihanService(
+	jawabanLatihanRepo repository.JawabanLatihanRepository,
+	latihanRepo repository.LatihanRepository,
+	soalRepo repository.SoalRepository,
+	ujianRepo repository.UjianRepository,
+) JawabanLatihanService {
 	return &jawabanLatihanService{
 		jawabanLatihanRepository: jawabanLatihanRepo,
+		latihanRepository:        latihanRepo,
+		soalRepository:           soalRepo,
+		kunciLatihan:             kunciLatihan{latihanRepository: latihanRepo, soalRepository: soalRepo, ujianRepository: ujianRepo},
 	}
 }
 
 // NewSoalLatihanService creates a new instance of SoalLatihanService
-func NewSoalLatihanService(soalLatihanRepo repository.SoalLatihanRepository) SoalLatihanService {
+func NewSoalLatihanService(
+	soalLatihanRepo repository.SoalLatihanRepository,
+	latihanRepo repository.LatihanRepository,
+	soalRepo repository.SoalRepository,
+	ujianRepo repository.UjianRepository,
+) SoalLatihanService {
 	return &soalLatihanService{
 		soalLatihanRepository: soalLatihanRepo,
+		latihanRepository:     latihanRepo,
+		kunciLatihan:          kunciLatihan{latihanRepository: latihanRepo, soalRepository: soalRepo, ujianRepository: ujianRepo},
 	}
 }
 
+// CreateJawabanLatihan saves an answer to a soal of any latihan; see cekSoalLatihan
 func (s *jawabanLatihanService) CreateJawabanLatihan(jawabanSiswa entity.JawabanSiswa) (entity.JawabanSiswa, error) {
-	return s.jawabanLatihanRepository.CreateJawabanLatihan(jawabanSiswa)
+	jawabanList := []entity.JawabanSiswa{jawabanSiswa}
+	if err := s.cekSoalLatihan(jawabanList, 0); err != nil {
+		return entity.JawabanSiswa{}, err
+	}
+	return s.jawabanLatihanRepository.CreateJawabanLatihan(jawabanList[0])
 }
 
+// CreateBatchJawabanLatihan saves answers to the soal of the latihan idLatihan; see cekSoalLatihan
 func (s *jawabanLatihanService) CreateBatchJawabanLatihan(jawabanList []entity.JawabanSiswa, idLatihan uint64) ([]entity.JawabanSiswa, error) {
+	if err := s.cekSoalLatihan(jawabanList, idLatihan); err != nil {
+		return nil, err
+	}
 	return s.jawabanLatihanRepository.CreateBatchJawabanLatihan(jawabanList, idLatihan)
 }
 
-func (s *jawabanLatihanService) GetJawabanLatihanByID(id uint64) (entity.JawabanSiswa, error) {
-	return s.jawabanLatihanRepository.GetJawabanLatihanByID(id)
+// cekSoalLatihan makes sure every answer is to a soal of latihanID, or of any latihan when latihanID is
+// 0, and clears the attempt sent by the client. Soal that an ujian owns or links from the bank are only
+// answered through an attempt, so the exam window cannot be bypassed through a latihan.
+func (s *jawabanLatihanService) cekSoalLatihan(jawabanList []entity.JawabanSiswa, latihanID uint64) error {
+	diperiksa := make(map[uint64]bool)
+	for i := range jawabanList {
+		jawabanList[i].IdUjianAttempt = nil
+		soalID := jawabanList[i].IdSoal
+		if diperiksa[soalID] {
+			continue
+		}
+
+		ujianIDs, err := s.soalRepository.FindIdUjianBySoal(soalID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: soal %d tidak ditemukan", ErrSoalBukanLatihan, soalID)
+		}
+		if err != nil {
+			return err
+		}
+		if len(ujianIDs) > 0 {
+			return fmt.Errorf("%w: soal %d dipakai ujian", ErrSoalBukanLatihan, soalID)
+		}
+
+		latihanList, err := s.latihanRepository.GetLatihanBySoalID(soalID)
+		if err != nil {
+			return err
+		}
+		ada := latihanID == 0 && len(latihanList) > 0
+		for _, latihan := range latihanList {
+			ada = ada || latihan.IdLatihan == latihanID
+		}
+		if !ada && latihanID == 0 {
+			return fmt.Errorf("%w: soal %d tidak ada di latihan mana pun", ErrSoalBukanLatihan, soalID)
+		}
+		if !ada {
+			return fmt.Errorf("%w: soal %d tidak ada di latihan %d", ErrSoalBukanLatihan, soalID, latihanID)
+		}
+		diperiksa[soalID] = true
+	}
+	return nil
 }
 
-func (s *jawabanLatihanService) GetJawabanLatihanBySiswaID(siswaID uint64) ([]entity.JawabanSiswa, error) {
-	return s.jawabanLatihanRepository.GetJawabanLatihanBySiswaID(siswaID)
+// GetJawabanLatihanByID returns one answer as shown to the siswa, with the answer key under the rules
+// of kunciSoalLatihanTerlihat
+func (s *jawabanLatihanService) GetJawabanLatihanByID(id uint64) (dto.JawabanSiswaDTO, error) {
+	jawaban, err := s.jawabanLatihanRepository.GetJawabanLatihanByID(id)
+	if err != nil {
+		return dto.JawabanSiswaDTO{}, err
+	}
+	result, err := s.jawabanLatihanDTO([]entity.JawabanSiswa{jawaban})
+	if err != nil {
+		return dto.JawabanSiswaDTO{}, err
+	}
+	return result[0], nil
+}
+
+func (s *jawabanLatihanService) GetJawabanLatihanBySiswaID(siswaID uint64) ([]dto.JawabanSiswaDTO, error) {
+	jawabanList, err := s.jawabanLatihanRepository.GetJawabanLatihanBySiswaID(siswaID)
+	if err != nil {
+		return nil, err
+	}
+	return s.jawabanLatihanDTO(jawabanList)
 }
 
 func (s *jawabanLatihanService) GetJawabanLatihanByLatihanID(latihanID uint64) ([]entity.JawabanSiswa, error) {
 	return s.jawabanLatihanRepository.GetJawabanLatihanByLatihanID(latihanID)
 }
 
+// GetJawabanLatihanByLatihanIDAndSiswaID returns the siswa's answers to the latihan; the answer key is
+// included under the rules of kunciLatihan, as in GetSoalSiswaByLatihanID
+func (s *jawabanLatihanService) GetJawabanLatihanByLatihanIDAndSiswaID(latihanID uint64, siswaID uint64) ([]dto.JawabanSiswaDTO, error) {
+	latihan, err := s.latihanRepository.GetLatihanByID(latihanID)
+	if err != nil {
+		return nil, err
+	}
+	jawabanList, err := s.jawabanLatihanRepository.GetJawabanLatihanByLatihanIDAndSiswaID(latihanID, siswaID)
+	if err != nil {
+		return nil, err
+	}
+	soalIDs := make([]uint64, 0, len(jawabanList))
+	for _, jawaban := range jawabanList {
+		soalIDs = append(soalIDs, jawaban.IdSoal)
+	}
+	terlihat, err := s.kunciLatihan.latihanTerlihat(latihan, soalIDs, siswaID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	result := make([]dto.JawabanSiswaDTO, 0, len(jawabanList))
+	for _, jawaban := range jawabanList {
+		result = append(result, dto.NewJawabanSiswaDTO(jawaban, terlihat))
+	}
+	return result, nil
+}
+
+// jawabanLatihanDTO builds the siswa view of answers whose latihan is not known up front
+func (s *jawabanLatihanService) jawabanLatihanDTO(jawabanList []entity.JawabanSiswa) ([]dto.JawabanSiswaDTO, error) {
+	terlihatBySoal := make(map[uint64]bool)
+	result := make([]dto.JawabanSiswaDTO, 0, len(jawabanList))
+	for _, jawaban := range jawabanList {
+		terlihat, ok := terlihatBySoal[jawaban.IdSoal]
+		if !ok {
+			var err error
+			if terlihat, err = s.kunciSoalLatihanTerlihat(jawaban.IdSoal); err != nil {
+				return nil, err
+			}
+			terlihatBySoal[jawaban.IdSoal] = terlihat
+		}
+		result = append(result, dto.NewJawabanSiswaDTO(jawaban, terlihat))
+	}
+	return result, nil
+}
+
+// kunciSoalLatihanTerlihat reports whether the answer key of a soal the siswa answered may be shown,
+// under the rules of kunciLatihan.soalTerlihat
+func (s *jawabanLatihanService) kunciSoalLatihanTerlihat(soalID uint64) (bool, error) {
+	return s.kunciLatihan.soalTerlihat([]uint64{soalID}, time.Now())
+}
+
 func (s *soalLatihanService) GetSoalLatihanByID(latihanID uint64) ([]entity.Soal, error) {
 	return s.soalLatihanRepository.GetSoalLatihanByID(latihanID)
 }
 
 func (s *soalLatihanService) GetSoalWithJawabanByLatihanID(latihanID uint64) (map[uint64][]entity.JawabanSoal, error) {
 	return s.soalLatihanRepository.GetSoalWithJawabanByLatihanID(latihanID)
-}
\ No newline at end of file
+}
+// GetSoalSiswaByLatihanID returns the soal of the latihan with their options as shown to the siswa;
+// the answer key is included under the rules of kunciLatihan
+func (s *soalLatihanService) GetSoalSiswaByLatihanID(latihanID uint64, siswaID uint64) ([]dto.SoalSiswaDTO, error) {
+	latihan, err := s.latihanRepository.GetLatihanByID(latihanID)
+	if err != nil {
+		return nil, err
+	}
+	soalList, err := s.soalLatihanRepository.GetSoalLatihanByID(latihanID)
+	if err != nil {
+		return nil, err
+	}
+	jawabanMap, err := s.soalLatihanRepository.GetSoalWithJawabanByLatihanID(latihanID)
+	if err != nil {
+		return nil, err
+	}
+
+	soalIDs := make([]uint64, 0, len(soalList))
+	for _, soal := range soalList {
+		soalIDs = append(soalIDs, soal.IdSoal)
+	}
+	terlihat, err := s.kunciLatihan.latihanTerlihat(latihan, soalIDs, siswaID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.SoalSiswaDTO, 0, len(soalList))
+	for _, soal := range soalList {
+		result = append(result, dto.NewSoalSiswaDTO(soal, jawabanMap[soal.IdSoal], terlihat))
+	}
+	return result, nil
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"cbt-api/entity"
+	"cbt-api/repository"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestJawabanLatihanOnlyForSoalLatihan(t *testing.T) {
+	env := newLingkunganTest(t)
+	jawabanLatihan := NewJawabanLatihanService(repository.NewJawabanLatihanRepository(env.db), repository.NewLatihanRepository(env.db),
+		repository.NewSoalRepository(env.db), repository.NewUjianRepository(env.db))
+	latihan := entity.Latihan{Topik: "Aljabar", Acak: entity.StatusTidakAktif, StatusJawaban: entity.StatusAktif}
+	lain := entity.Latihan{Topik: "Geometri", Acak: entity.StatusTidakAktif, StatusJawaban: entity.StatusAktif}
+	env.buat(t, &latihan)
+	env.buat(t, &lain)
+	// Ujian yang belum dimulai; soalnya tidak boleh dijawab lewat latihan
+	ujian := env.buatUjian(t, func(u *entity.Ujian) {
+		u.WaktuMulai = time.Now().Add(time.Hour)
+		u.WaktuSelesai = time.Now().Add(2 * time.Hour)
+	})
+	soalUjian, _ := env.buatSoal(t, ujian, entity.TipeSoalIsian, 10)
+	// Soal bank yang dipakai latihan dan juga ditautkan ke ujian
+	ditautkan, _ := env.buatSoal(t, entity.Ujian{}, entity.TipeSoalIsian, 10)
+	env.buat(t, &entity.LatihanSoal{IdLatihan: latihan.IdLatihan, IdSoal: ditautkan.IdSoal})
+	env.buat(t, &entity.UjianSoal{IdUjian: ujian.IdUjian, IdSoal: ditautkan.IdSoal})
+	soalLatihan, _ := env.buatSoal(t, entity.Ujian{}, entity.TipeSoalIsian, 10)
+	env.buat(t, &entity.LatihanSoal{IdLatihan: latihan.IdLatihan, IdSoal: soalLatihan.IdSoal})
+	soalLain, _ := env.buatSoal(t, entity.Ujian{}, entity.TipeSoalIsian, 10)
+	env.buat(t, &entity.LatihanSoal{IdLatihan: lain.IdLatihan, IdSoal: soalLain.IdSoal})
+	bankSaja, _ := env.buatSoal(t, entity.Ujian{}, entity.TipeSoalIsian, 10)
+
+	tests := []struct {
+		name string
+		soal entity.Soal
+	}{
+		{"soal owned by an ujian", soalUjian},
+		{"bank soal linked to an ujian", ditautkan},
+		{"bank soal outside any latihan", bankSaja},
+	}
+	for _, tt := range tests {
+		if _, err := jawabanLatihan.CreateJawabanLatihan(entity.JawabanSiswa{IdSoal: tt.soal.IdSoal, IdSiswa: 7, JawabanText: "x"}); !errors.Is(err, ErrSoalBukanLatihan) {
+			t.Errorf("%s = %v, want ErrSoalBukanLatihan", tt.name, err)
+		}
+		if _, err := jawabanLatihan.CreateBatchJawabanLatihan([]entity.JawabanSiswa{{IdSoal: tt.soal.IdSoal, IdSiswa: 7, JawabanText: "x"}}, latihan.IdLatihan); !errors.Is(err, ErrSoalBukanLatihan) {
+			t.Errorf("batch with %s = %v, want ErrSoalBukanLatihan", tt.name, err)
+		}
+	}
+	if _, err := jawabanLatihan.CreateBatchJawabanLatihan([]entity.JawabanSiswa{
+		{IdSoal: soalLatihan.IdSoal, IdSiswa: 7, JawabanText: "x"},
+		{IdSoal: soalLain.IdSoal, IdSiswa: 7, JawabanText: "x"},
+	}, latihan.IdLatihan); !errors.Is(err, ErrSoalBukanLatihan) {
+		t.Errorf("batch with a soal of another latihan = %v, want ErrSoalBukanLatihan", err)
+	}
+
+	// Attempt kiriman klien tidak pernah disimpan pada jawaban latihan
+	attempt := entity.UjianAttempt{IdUjian: ujian.IdUjian, IdSiswa: 7, Status: entity.StatusAttemptBerlangsung}
+	env.buat(t, &attempt)
+	jawaban, err := jawabanLatihan.CreateJawabanLatihan(entity.JawabanSiswa{
+		IdSoal: soalLain.IdSoal, IdSiswa: 7, JawabanText: "x", IdUjianAttempt: &attempt.IdUjianAttempt,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch, err := jawabanLatihan.CreateBatchJawabanLatihan([]entity.JawabanSiswa{
+		{IdSoal: soalLatihan.IdSoal, IdSiswa: 7, JawabanText: "x", IdUjianAttempt: &attempt.IdUjianAttempt},
+	}, latihan.IdLatihan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jawaban.IdUjianAttempt != nil || batch[0].IdUjianAttempt != nil {
+		t.Errorf("latihan answers stored with attempts %v and %v, want none", jawaban.IdUjianAttempt, batch[0].IdUjianAttempt)
+	}
+	// Jawaban tanpa attempt tidak dihitung untuk ujian, juga untuk soal milik ujian itu sendiri
+	env.buat(t, &entity.JawabanSiswa{JawabanText: "x", IdSoal: soalUjian.IdSoal, IdSiswa: 7})
+	list, err := repository.NewJawabanSiswaRepository(env.db).GetJawabanSiswaByUjianIDAndSiswaID(ujian.IdUjian, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 0 {
+		t.Errorf("ujian answers = %+v, answers without an attempt must not count", list)
+	}
+}
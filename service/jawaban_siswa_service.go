@@ -2,51 +2,199 @@
 package service
 
 import (
+	"cbt-api/dto"
 	"cbt-api/entity"
 	"cbt-api/repository"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
 )
 
 // JawabanSiswaService is a contract for jawaban siswa service
 type JawabanSiswaService interface {
 	CreateJawabanSiswa(jawabanSiswa entity.JawabanSiswa) (entity.JawabanSiswa, error)
 	CreateBatchJawabanSiswa(jawabanList []entity.JawabanSiswa) ([]entity.JawabanSiswa, error)
-	GetJawabanSiswaByID(id uint64) (entity.JawabanSiswa, error)
-	GetJawabanSiswaBySiswaID(siswaID uint64) ([]entity.JawabanSiswa, error)
+	GetJawabanSiswaByID(id uint64) (dto.JawabanSiswaDTO, error)
+	GetJawabanSiswaBySiswaID(siswaID uint64) ([]dto.JawabanSiswaDTO, error)
 	GetJawabanSiswaByUjianID(ujianID uint64) ([]entity.JawabanSiswa, error)
-	GetJawabanSiswaByUjianIDAndSiswaID(ujianID uint64, siswaID uint64) ([]entity.JawabanSiswa, error)
+	GetJawabanSiswaByUjianIDAndSiswaID(ujianID uint64, siswaID uint64) ([]dto.JawabanSiswaDTO, error)
 }
 
 type jawabanSiswaService struct {
+	transactor             repository.Transactor
 	jawabanSiswaRepository repository.JawabanSiswaRepository
+	ujianRepository        repository.UjianRepository
+	ujianAttemptRepository repository.UjianAttemptRepository
+	ujianAttemptService    UjianAttemptService
 }
 
 // NewJawabanSiswaService creates a new instance of JawabanSiswaService
-func NewJawabanSiswaService(jawabanSiswaRepo repository.JawabanSiswaRepository) JawabanSiswaService {
+func NewJawabanSiswaService(
+	transactor repository.Transactor,
+	jawabanSiswaRepo repository.JawabanSiswaRepository,
+	ujianRepo repository.UjianRepository,
+	ujianAttemptRepo repository.UjianAttemptRepository,
+	ujianAttemptService UjianAttemptService,
+) JawabanSiswaService {
 	return &jawabanSiswaService{
+		transactor:             transactor,
 		jawabanSiswaRepository: jawabanSiswaRepo,
+		ujianRepository:        ujianRepo,
+		ujianAttemptRepository: ujianAttemptRepo,
+		ujianAttemptService:    ujianAttemptService,
 	}
 }
 
+// CreateJawabanSiswa saves one answer. Answering a soal again in the same attempt replaces the
+// previous answer, so retries and changed answers never add duplicate rows.
 func (s *jawabanSiswaService) CreateJawabanSiswa(jawabanSiswa entity.JawabanSiswa) (entity.JawabanSiswa, error) {
-	return s.jawabanSiswaRepository.CreateJawabanSiswa(jawabanSiswa)
+	jawabanList := []entity.JawabanSiswa{jawabanSiswa}
+	if err := s.ujianAttemptService.ValidateSubmission(jawabanList); err != nil {
+		return jawabanSiswa, err
+	}
+	return s.jawabanSiswaRepository.UpsertJawabanSiswa(jawabanList[0])
 }
 
+// CreateBatchJawabanSiswa saves every answer in one transaction with the same upsert rules as
+// CreateJawabanSiswa; when a soal appears twice in the batch the later answer wins
 func (s *jawabanSiswaService) CreateBatchJawabanSiswa(jawabanList []entity.JawabanSiswa) ([]entity.JawabanSiswa, error) {
-	return s.jawabanSiswaRepository.CreateBatchJawabanSiswa(jawabanList)
+	if err := s.ujianAttemptService.ValidateSubmission(jawabanList); err != nil {
+		return nil, err
+	}
+
+	jawabanList = jawabanTerakhirPerSoal(jawabanList)
+	results := make([]entity.JawabanSiswa, 0, len(jawabanList))
+	err := s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		jawabanSiswaRepo := s.jawabanSiswaRepository.WithTx(tx)
+		for _, jawaban := range jawabanList {
+			saved, err := jawabanSiswaRepo.UpsertJawabanSiswa(jawaban)
+			if err != nil {
+				return err
+			}
+			results = append(results, saved)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
 }
 
-func (s *jawabanSiswaService) GetJawabanSiswaByID(id uint64) (entity.JawabanSiswa, error) {
-	return s.jawabanSiswaRepository.GetJawabanSiswaByID(id)
+// jawabanTerakhirPerSoal drops answers of the same attempt and soal that are followed by a later
+// one in the batch, keeping the order of the remaining answers
+func jawabanTerakhirPerSoal(jawabanList []entity.JawabanSiswa) []entity.JawabanSiswa {
+	type soalKey struct {
+		attemptID uint64
+		soalID    uint64
+	}
+
+	terakhir := make(map[soalKey]int)
+	for i, jawaban := range jawabanList {
+		if jawaban.IdUjianAttempt != nil {
+			terakhir[soalKey{*jawaban.IdUjianAttempt, jawaban.IdSoal}] = i
+		}
+	}
+
+	result := make([]entity.JawabanSiswa, 0, len(jawabanList))
+	for i, jawaban := range jawabanList {
+		if jawaban.IdUjianAttempt != nil && terakhir[soalKey{*jawaban.IdUjianAttempt, jawaban.IdSoal}] != i {
+			continue
+		}
+		result = append(result, jawaban)
+	}
+	return result
 }
 
-func (s *jawabanSiswaService) GetJawabanSiswaBySiswaID(siswaID uint64) ([]entity.JawabanSiswa, error) {
-	return s.jawabanSiswaRepository.GetJawabanSiswaBySiswaID(siswaID)
+// GetJawabanSiswaByID returns one answer as shown to the siswa; see jawabanSiswaDTO for the answer key
+func (s *jawabanSiswaService) GetJawabanSiswaByID(id uint64) (dto.JawabanSiswaDTO, error) {
+	jawaban, err := s.jawabanSiswaRepository.GetJawabanSiswaByID(id)
+	if err != nil {
+		return dto.JawabanSiswaDTO{}, err
+	}
+	result, err := s.jawabanSiswaDTO([]entity.JawabanSiswa{jawaban})
+	if err != nil {
+		return dto.JawabanSiswaDTO{}, err
+	}
+	return result[0], nil
+}
+
+func (s *jawabanSiswaService) GetJawabanSiswaBySiswaID(siswaID uint64) ([]dto.JawabanSiswaDTO, error) {
+	jawabanList, err := s.jawabanSiswaRepository.GetJawabanSiswaBySiswaID(siswaID)
+	if err != nil {
+		return nil, err
+	}
+	return s.jawabanSiswaDTO(jawabanList)
 }
 
 func (s *jawabanSiswaService) GetJawabanSiswaByUjianID(ujianID uint64) ([]entity.JawabanSiswa, error) {
 	return s.jawabanSiswaRepository.GetJawabanSiswaByUjianID(ujianID)
 }
 
-func (s *jawabanSiswaService) GetJawabanSiswaByUjianIDAndSiswaID(ujianID uint64, siswaID uint64) ([]entity.JawabanSiswa, error) {
-	return s.jawabanSiswaRepository.GetJawabanSiswaByUjianIDAndSiswaID(ujianID, siswaID)
-}
\ No newline at end of file
+func (s *jawabanSiswaService) GetJawabanSiswaByUjianIDAndSiswaID(ujianID uint64, siswaID uint64) ([]dto.JawabanSiswaDTO, error) {
+	jawabanList, err := s.jawabanSiswaRepository.GetJawabanSiswaByUjianIDAndSiswaID(ujianID, siswaID)
+	if err != nil {
+		return nil, err
+	}
+	return s.jawabanSiswaDTO(jawabanList)
+}
+
+// jawabanSiswaDTO builds the siswa view of the answers. Whether an option is correct and the guru's
+// grading follow the answer key of the ujian (kunciUjianTerlihat) for the attempt the answer belongs
+// to; answers without an attempt use the siswa's latest attempt of the soal's ujian.
+func (s *jawabanSiswaService) jawabanSiswaDTO(jawabanList []entity.JawabanSiswa) ([]dto.JawabanSiswaDTO, error) {
+	now := time.Now()
+	ujianByID := make(map[uint64]entity.Ujian)
+	terlihatByAttempt := make(map[uint64]bool)
+	terlihatByUjian := make(map[attemptKey]bool)
+
+	kunciTerlihat := func(attempt entity.UjianAttempt) (bool, error) {
+		ujian, ok := ujianByID[attempt.IdUjian]
+		if !ok {
+			var err error
+			if ujian, err = s.ujianRepository.GetUjianByID(attempt.IdUjian); err != nil {
+				return false, err
+			}
+			ujianByID[attempt.IdUjian] = ujian
+		}
+		return kunciUjianTerlihat(ujian, &attempt, now), nil
+	}
+
+	result := make([]dto.JawabanSiswaDTO, 0, len(jawabanList))
+	for _, jawaban := range jawabanList {
+		terlihat := false
+		switch {
+		case jawaban.IdUjianAttempt != nil:
+			var ok bool
+			if terlihat, ok = terlihatByAttempt[*jawaban.IdUjianAttempt]; !ok {
+				attempt, err := s.ujianAttemptRepository.GetAttemptByID(*jawaban.IdUjianAttempt)
+				if err != nil {
+					return nil, err
+				}
+				if terlihat, err = kunciTerlihat(attempt); err != nil {
+					return nil, err
+				}
+				terlihatByAttempt[*jawaban.IdUjianAttempt] = terlihat
+			}
+		case jawaban.Soal.IdUjian != 0:
+			key := attemptKey{jawaban.Soal.IdUjian, jawaban.IdSiswa}
+			var ok bool
+			if terlihat, ok = terlihatByUjian[key]; !ok {
+				attempt, err := s.ujianAttemptRepository.GetLatestAttempt(key.ujianID, key.siswaID)
+				switch {
+				case errors.Is(err, gorm.ErrRecordNotFound):
+				case err != nil:
+					return nil, err
+				default:
+					if terlihat, err = kunciTerlihat(attempt); err != nil {
+						return nil, err
+					}
+				}
+				terlihatByUjian[key] = terlihat
+			}
+		}
+		result = append(result, dto.NewJawabanSiswaDTO(jawaban, terlihat))
+	}
+	return result, nil
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"cbt-api/entity"
+	"testing"
+)
+
+func TestJawabanTerakhirPerSoal(t *testing.T) {
+	a, b := uint64(1), uint64(2)
+	jawabanList := []entity.JawabanSiswa{
+		{IdSoal: 10, IdUjianAttempt: &a, JawabanText: "lama"},
+		{IdSoal: 11, IdUjianAttempt: &a, JawabanText: "x"},
+		{IdSoal: 10, IdUjianAttempt: &b, JawabanText: "attempt lain"},
+		{IdSoal: 10, IdUjianAttempt: &a, JawabanText: "baru"},
+		{IdSoal: 12, JawabanText: "latihan"},
+		{IdSoal: 12, JawabanText: "latihan lagi"},
+	}
+	var got []string
+	for _, jawaban := range jawabanTerakhirPerSoal(jawabanList) {
+		got = append(got, jawaban.JawabanText)
+	}
+	want := []string{"x", "attempt lain", "baru", "latihan", "latihan lagi"}
+	if len(got) != len(want) {
+		t.Fatalf("kept %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("kept %q, want %q", got, want)
+			break
+		}
+	}
+}
+
+func TestCreateJawabanSiswaUpsertsPerAttempt(t *testing.T) {
+	env := newLingkunganTest(t)
+	ujian := env.buatUjian(t, nil)
+	soal, opsi := env.buatSoal(t, ujian, "Pilihan_Berganda", 100,
+		entity.JawabanSoal{Jawaban: "A"}, entity.JawabanSoal{Jawaban: "B", Benar: true}, entity.JawabanSoal{Jawaban: "C"})
+	const siswaID = 7
+
+	_, attempt, err := env.ujianAttempt.StartAttempt(ujian.IdUjian, siswaID, "masuk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pertama, err := env.jawabanSiswa.CreateJawabanSiswa(entity.JawabanSiswa{IdSoal: soal.IdSoal, IdSiswa: siswaID, IdJawabanSoal: opsi[0].IdJawabanSoal})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pertama.IdUjianAttempt == nil || *pertama.IdUjianAttempt != attempt.IdUjianAttempt {
+		t.Fatalf("answer stored with attempt %v, want %d", pertama.IdUjianAttempt, attempt.IdUjianAttempt)
+	}
+	// Retry dan jawaban yang diganti menimpa baris yang sama
+	kedua, err := env.jawabanSiswa.CreateJawabanSiswa(entity.JawabanSiswa{IdSoal: soal.IdSoal, IdSiswa: siswaID, IdJawabanSoal: opsi[2].IdJawabanSoal})
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch, err := env.jawabanSiswa.CreateBatchJawabanSiswa([]entity.JawabanSiswa{
+		{IdSoal: soal.IdSoal, IdSiswa: siswaID, IdJawabanSoal: opsi[0].IdJawabanSoal},
+		{IdSoal: soal.IdSoal, IdSiswa: siswaID, IdJawabanSoal: opsi[1].IdJawabanSoal},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kedua.IdJawabanSiswa != pertama.IdJawabanSiswa || len(batch) != 1 || batch[0].IdJawabanSiswa != pertama.IdJawabanSiswa {
+		t.Errorf("rows %d, %d and batch %+v; want every answer on row %d",
+			pertama.IdJawabanSiswa, kedua.IdJawabanSiswa, batch, pertama.IdJawabanSiswa)
+	}
+
+	var rows []entity.JawabanSiswa
+	if err := env.db.Where("id_siswa = ?", siswaID).Find(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].IdJawabanSoal != opsi[1].IdJawabanSoal {
+		t.Fatalf("stored %+v, want one row with the last choice", rows)
+	}
+
+	_, hasil, err := env.ujianAttempt.FinishAttempt(ujian.IdUjian, siswaID, "keluar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hasil.NilaiUjian != 100 {
+		t.Errorf("nilai = %v, want 100 for the last choice", hasil.NilaiUjian)
+	}
+}
+
+func TestJawabanUjianSiswaIncludesIsianAndPilihanKompleks(t *testing.T) {
+	env := newLingkunganTest(t)
+	ujian := env.buatUjian(t, nil)
+	isian, _ := env.buatSoal(t, ujian, entity.TipeSoalIsian, 50, entity.JawabanSoal{Jawaban: "Jakarta", Benar: true})
+	pk, opsi := env.buatSoal(t, ujian, entity.TipeSoalPilihanKompleks, 50,
+		entity.JawabanSoal{Jawaban: "A", Benar: true}, entity.JawabanSoal{Jawaban: "B"}, entity.JawabanSoal{Jawaban: "C", Benar: true})
+	const siswaID = 7
+
+	if _, _, err := env.ujianAttempt.StartAttempt(ujian.IdUjian, siswaID, "masuk"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.jawabanSiswa.CreateBatchJawabanSiswa([]entity.JawabanSiswa{
+		{IdSoal: isian.IdSoal, IdSiswa: siswaID, JawabanText: "Jakarta"},
+		{IdSoal: pk.IdSoal, IdSiswa: siswaID, Pilihan: []entity.JawabanSiswaPilihan{
+			{IdJawabanSoal: opsi[0].IdJawabanSoal}, {IdJawabanSoal: opsi[2].IdJawabanSoal},
+		}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Jawaban tanpa id_jawaban_soal tidak boleh hilang dari daftar jawaban ujian siswa
+	list, err := env.jawabanSiswa.GetJawabanSiswaByUjianIDAndSiswaID(ujian.IdUjian, siswaID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("got %d answers, want the Isian and the Pilihan_Kompleks answer", len(list))
+	}
+	for _, jawaban := range list {
+		switch jawaban.IdSoal {
+		case isian.IdSoal:
+			if jawaban.JawabanSiswa != "Jakarta" || jawaban.JawabanSoal != nil {
+				t.Errorf("Isian answer = %+v", jawaban)
+			}
+		case pk.IdSoal:
+			if len(jawaban.Pilihan) != 2 || jawaban.Pilihan[0] != opsi[0].IdJawabanSoal || jawaban.Pilihan[1] != opsi[2].IdJawabanSoal {
+				t.Errorf("Pilihan_Kompleks choices = %v, want %d and %d", jawaban.Pilihan, opsi[0].IdJawabanSoal, opsi[2].IdJawabanSoal)
+			}
+		}
+	}
+}
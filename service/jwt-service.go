@@ -2,77 +2,133 @@
 package service
 
 import (
+	"errors"
 	"fmt"
-	"os"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4" // Using the newer version of the JWT package
 )
 
+// Satu-satunya algoritma yang diterima; token dengan alg lain (termasuk "none") ditolak
+const jwtSigningAlgorithm = "HS256"
+
+var ErrInvalidToken = errors.New("invalid token")
+
 // JWTService is a contract of what jwtService can do
 type JWTService interface {
-	GenerateToken(userID string) string
-	ValidateToken(token string) (*jwt.Token, error)
-	GetUserIDByToken(token string) (string, error)
+	GenerateToken(subject TokenSubject) (string, error)
+	ValidateToken(token string) (*JWTClaims, error)
+	GetUserIDByToken(token string) (uint64, error)
+}
+
+// TokenSubject is the identity that a token is issued for
+type TokenSubject struct {
+	UserID     uint64
+	Email      string
+	Roles      []string
+	IdSiswa    uint64
+	IdGuru     uint64
+	IdOperator uint64
+	TokenID    string // jti, sama dengan UserSession.TokenID
 }
 
-type jwtCustomClaim struct {
-	UserID string `json:"user_id"`
+// JWTClaims are the claims carried by every access token
+type JWTClaims struct {
+	UserID     uint64   `json:"user_id"`
+	Email      string   `json:"email"`
+	Roles      []string `json:"roles"`
+	IdSiswa    uint64   `json:"id_siswa,omitempty"`
+	IdGuru     uint64   `json:"id_guru,omitempty"`
+	IdOperator uint64   `json:"id_operator,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// HasRole reports whether the token was issued with the given role
+func (c *JWTClaims) HasRole(role string) bool {
+	for _, r := range c.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// JWTConfig holds the settings used to sign and verify tokens
+type JWTConfig struct {
+	Secret     string
+	Issuer     string
+	Audience   string
+	TTL        time.Duration // umur access token
+	RefreshTTL time.Duration // umur refresh token / sesi login
+}
+
 type jwtService struct {
-	secretKey string
-	issuer    string
+	config JWTConfig
 }
 
 // NewJWTService creates a new instance of JWTService
-func NewJWTService() JWTService {
+func NewJWTService(config JWTConfig) JWTService {
 	return &jwtService{
-		secretKey: getSecretKey(),
-		issuer:    "cbt-api",
+		config: config,
 	}
 }
 
-func getSecretKey() string {
-	secretKey := os.Getenv("JWT_SECRET")
-	if secretKey == "" {
-		secretKey = "cbt-api-secret-key" // Default secret key
+func (j *jwtService) GenerateToken(subject TokenSubject) (string, error) {
+	now := time.Now()
+	claims := &JWTClaims{
+		UserID:     subject.UserID,
+		Email:      subject.Email,
+		Roles:      subject.Roles,
+		IdSiswa:    subject.IdSiswa,
+		IdGuru:     subject.IdGuru,
+		IdOperator: subject.IdOperator,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        subject.TokenID,
+			Subject:   strconv.FormatUint(subject.UserID, 10),
+			ExpiresAt: jwt.NewNumericDate(now.Add(j.config.TTL)),
+			Issuer:    j.config.Issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
 	}
-	return secretKey
+	if j.config.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{j.config.Audience}
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(jwtSigningAlgorithm), claims)
+	return token.SignedString([]byte(j.config.Secret))
 }
 
-func (j *jwtService) GenerateToken(userID string) string {
-	claims := &jwtCustomClaim{
-		userID,
-		jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			Issuer:    j.issuer,
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	t, err := token.SignedString([]byte(j.secretKey))
+func (j *jwtService) ValidateToken(token string) (*JWTClaims, error) {
+	claims := &JWTClaims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, func(t_ *jwt.Token) (interface{}, error) {
+		return []byte(j.config.Secret), nil
+	}, jwt.WithValidMethods([]string{jwtSigningAlgorithm}))
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if !parsed.Valid {
+		return nil, ErrInvalidToken
 	}
-	return t
-}
 
-func (j *jwtService) ValidateToken(token string) (*jwt.Token, error) {
-	return jwt.Parse(token, func(t_ *jwt.Token) (interface{}, error) {
-		if _, ok := t_.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method %v", t_.Header["alg"])
-		}
-		return []byte(j.secretKey), nil
-	})
+	// Parser hanya memeriksa exp/iat/nbf; issuer, audience dan keberadaan exp dicek di sini
+	if !claims.VerifyExpiresAt(time.Now(), true) {
+		return nil, fmt.Errorf("%w: missing expiry", ErrInvalidToken)
+	}
+	if !claims.VerifyIssuer(j.config.Issuer, true) {
+		return nil, fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	}
+	if j.config.Audience != "" && !claims.VerifyAudience(j.config.Audience, true) {
+		return nil, fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	}
+	return claims, nil
 }
 
-func (j *jwtService) GetUserIDByToken(token string) (string, error) {
-	t, err := j.ValidateToken(token)
+func (j *jwtService) GetUserIDByToken(token string) (uint64, error) {
+	claims, err := j.ValidateToken(token)
 	if err != nil {
-		return "", err
+		return 0, err
 	}
-	claims := t.Claims.(jwt.MapClaims)
-	return claims["user_id"].(string), nil
-}
\ No newline at end of file
+	return claims.UserID, nil
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestJWTServiceRoundTrip(t *testing.T) {
+	s := NewJWTService(JWTConfig{Secret: "rahasia", Issuer: "cbt-api", Audience: "cbt-web", TTL: time.Hour})
+	token, err := s.GenerateToken(TokenSubject{UserID: 3, Email: "siswa@example.com", Roles: []string{"siswa"}, IdSiswa: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := s.ValidateToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.UserID != 3 || claims.Email != "siswa@example.com" || !claims.HasRole("siswa") || claims.HasRole("guru") || claims.IdSiswa != 7 || claims.Subject != "3" {
+		t.Errorf("claims = %+v", claims)
+	}
+	if id, err := s.GetUserIDByToken(token); err != nil || id != 3 {
+		t.Errorf("GetUserIDByToken = %d, %v; want 3", id, err)
+	}
+}
+
+func TestJWTServiceRejects(t *testing.T) {
+	config := JWTConfig{Secret: "rahasia", Issuer: "cbt-api", Audience: "cbt-web", TTL: time.Hour}
+	s := NewJWTService(config)
+	subject := TokenSubject{UserID: 3, Roles: []string{"siswa"}}
+
+	tandatangani := func(method jwt.SigningMethod, key interface{}, claims jwt.Claims) string {
+		t.Helper()
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	claimsDasar := func() *JWTClaims {
+		now := time.Now()
+		return &JWTClaims{UserID: 3, RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    config.Issuer,
+			Audience:  jwt.ClaimStrings{config.Audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		}}
+	}
+
+	otherSecret, _ := NewJWTService(JWTConfig{Secret: "lain", Issuer: "cbt-api", Audience: "cbt-web", TTL: time.Hour}).GenerateToken(subject)
+	otherIssuer, _ := NewJWTService(JWTConfig{Secret: "rahasia", Issuer: "lain", Audience: "cbt-web", TTL: time.Hour}).GenerateToken(subject)
+	otherAudience, _ := NewJWTService(JWTConfig{Secret: "rahasia", Issuer: "cbt-api", Audience: "lain", TTL: time.Hour}).GenerateToken(subject)
+	expired, _ := NewJWTService(JWTConfig{Secret: "rahasia", Issuer: "cbt-api", Audience: "cbt-web", TTL: -time.Minute}).GenerateToken(subject)
+	noExpiry := claimsDasar()
+	noExpiry.ExpiresAt = nil
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"other secret", otherSecret},
+		{"other issuer", otherIssuer},
+		{"other audience", otherAudience},
+		{"expired", expired},
+		{"no expiry", tandatangani(jwt.SigningMethodHS256, []byte(config.Secret), noExpiry)},
+		{"HS512", tandatangani(jwt.SigningMethodHS512, []byte(config.Secret), claimsDasar())},
+		{"alg none", tandatangani(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, claimsDasar())},
+		{"garbage", "bukan.token.jwt"},
+	}
+	for _, tt := range tests {
+		if _, err := s.ValidateToken(tt.token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s = %v, want ErrInvalidToken", tt.name, err)
+		}
+	}
+}
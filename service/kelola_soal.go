@@ -0,0 +1,488 @@
+// service/kelola_soal.go
+package service
+
+import (
+	"cbt-api/entity"
+	"cbt-api/repository"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSoalTidakValid     = errors.New("data soal tidak valid")
+	ErrOpsiSoalTidakValid = errors.New("opsi jawaban soal tidak valid")
+	ErrSudahDijawabSiswa  = errors.New("sudah dijawab siswa sehingga tidak dapat dihapus")
+)
+
+// toleransiNilai absorbs rounding of decimal(5,2) nilai_per_soal when comparing sums with the grade
+const toleransiNilai = 0.005
+
+// CreateSoal adds a soal with its answer options to an ujian
+func (s *soalService) CreateSoal(ujianID uint64, input SoalDenganJawaban) (SoalDenganJawaban, error) {
+	ujian, err := s.ujianRepository.GetUjianByID(ujianID)
+	if err != nil {
+		return SoalDenganJawaban{}, err
+	}
+	if err := s.cekUjianBolehDiubah(ujianID); err != nil {
+		return SoalDenganJawaban{}, err
+	}
+
+	soal := input.Soal
+	soal.IdSoal = 0
+	soal.IdUjian = ujianID
+	soal.IdLatihan = 0
+	tipeSoal, err := s.siapkanSoal(&soal, input.Jawaban)
+	if err != nil {
+		return SoalDenganJawaban{}, err
+	}
+	if err := s.cekTotalNilai(ujian, 0, soal.NilaiPerSoal); err != nil {
+		return SoalDenganJawaban{}, err
+	}
+
+	now := time.Now()
+	soal.CreatedAt = now
+	soal.UpdatedAt = now
+	var result SoalDenganJawaban
+	err = s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		created, err := s.soalRepository.WithTx(tx).CreateSoal(soal)
+		if err != nil {
+			return err
+		}
+		opsi, err := s.simpanOpsi(tx, created, input.Jawaban, nil)
+		created.TipeSoal = tipeSoal
+		result = SoalDenganJawaban{Soal: created, Jawaban: opsi}
+		return err
+	})
+	return result, err
+}
+
+// UpdateSoal replaces the soal and its answer options. Options with an id_jawaban_soal are updated,
+// options without one are added and options left out are deleted, unless a siswa already chose them.
+func (s *soalService) UpdateSoal(soalID uint64, input SoalDenganJawaban) (SoalDenganJawaban, error) {
+	hasil, err := s.updateSoal(soalID, BankSoal{SoalDenganJawaban: input}, false)
+	return hasil.SoalDenganJawaban, err
+}
+
+// updateSoal implements UpdateSoal; with bank set the soal must be a bank soal and its mata pelajaran,
+// kelas and tags are replaced in the same transaction
+func (s *soalService) updateSoal(soalID uint64, input BankSoal, bank bool) (BankSoal, error) {
+	existing, err := s.soalRepository.FindById(soalID)
+	if err != nil {
+		return BankSoal{}, err
+	}
+	if bank && !adalahBankSoal(existing) {
+		return BankSoal{}, ErrBukanBankSoal
+	}
+	lama, err := s.jawabanSoalRepository.FindByIdSoal(soalID)
+	if err != nil {
+		return BankSoal{}, err
+	}
+
+	soal := input.Soal
+	soal.IdSoal = existing.IdSoal
+	soal.IdUjian = existing.IdUjian
+	soal.IdLatihan = existing.IdLatihan
+	if bank {
+		if err := siapkanBankSoal(&input); err != nil {
+			return BankSoal{}, err
+		}
+		soal.IdMataPelajaran = input.Soal.IdMataPelajaran
+		soal.IdKelas = input.Soal.IdKelas
+	} else {
+		soal.IdMataPelajaran = existing.IdMataPelajaran
+		soal.IdKelas = existing.IdKelas
+	}
+	soal.CreatedAt = existing.CreatedAt
+	soal.UpdatedAt = time.Now()
+	tipeSoal, err := s.siapkanSoal(&soal, input.Jawaban)
+	if err != nil {
+		return BankSoal{}, err
+	}
+	if err := s.cekSoalBolehDiubah(soal.IdSoal); err != nil {
+		return BankSoal{}, err
+	}
+	if err := s.cekTotalNilaiSoal(soal); err != nil {
+		return BankSoal{}, err
+	}
+
+	var result BankSoal
+	err = s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		updated, err := s.soalRepository.WithTx(tx).UpdateSoal(soal)
+		if err != nil {
+			return err
+		}
+		opsi, err := s.simpanOpsi(tx, updated, input.Jawaban, lama)
+		if err != nil {
+			return err
+		}
+		updated.TipeSoal = tipeSoal
+		result = BankSoal{SoalDenganJawaban: SoalDenganJawaban{Soal: updated, Jawaban: opsi}, Tag: input.Tag}
+		if !bank {
+			return nil
+		}
+		return s.bankSoalRepository.WithTx(tx).ReplaceTag(updated.IdSoal, input.Tag)
+	})
+	return result, err
+}
+
+// DeleteSoal deletes a soal with its answer options; soal that siswa already answered are kept
+func (s *soalService) DeleteSoal(soalID uint64) error {
+	soal, err := s.soalRepository.FindById(soalID)
+	if err != nil {
+		return err
+	}
+	if err := s.cekSoalBolehDiubah(soal.IdSoal); err != nil {
+		return err
+	}
+	dijawab, err := s.soalRepository.SudahDijawab(soalID)
+	if err != nil {
+		return err
+	}
+	if dijawab {
+		return fmt.Errorf("soal %w", ErrSudahDijawabSiswa)
+	}
+	return s.soalRepository.DeleteSoal(soalID)
+}
+
+// CreateJawabanSoal adds one answer option to a soal; the options of the soal must stay valid
+func (s *soalService) CreateJawabanSoal(soalID uint64, opsi entity.JawabanSoal) (entity.JawabanSoal, error) {
+	opsi.IdJawabanSoal = 0
+	return s.ubahOpsi(soalID, func(lama []entity.JawabanSoal) ([]entity.JawabanSoal, error) {
+		return append(lama, opsi), nil
+	}, func(hasil []entity.JawabanSoal) entity.JawabanSoal {
+		return hasil[len(hasil)-1]
+	})
+}
+
+// UpdateJawabanSoal replaces one answer option; the options of the soal must stay valid, so moving the
+// correct answer of a Pilihan_Berganda soal goes through UpdateSoal
+func (s *soalService) UpdateJawabanSoal(jawabanSoalID uint64, opsi entity.JawabanSoal) (entity.JawabanSoal, error) {
+	existing, err := s.jawabanSoalRepository.FindById(jawabanSoalID)
+	if err != nil {
+		return entity.JawabanSoal{}, err
+	}
+	opsi.IdJawabanSoal = jawabanSoalID
+	return s.ubahOpsi(existing.IdSoal, func(lama []entity.JawabanSoal) ([]entity.JawabanSoal, error) {
+		for i := range lama {
+			if lama[i].IdJawabanSoal == jawabanSoalID {
+				lama[i] = opsi
+			}
+		}
+		return lama, nil
+	}, func(hasil []entity.JawabanSoal) entity.JawabanSoal {
+		for _, o := range hasil {
+			if o.IdJawabanSoal == jawabanSoalID {
+				return o
+			}
+		}
+		return opsi
+	})
+}
+
+// DeleteJawabanSoal deletes one answer option unless a siswa already chose it; the remaining options
+// of the soal must stay valid
+func (s *soalService) DeleteJawabanSoal(jawabanSoalID uint64) error {
+	existing, err := s.jawabanSoalRepository.FindById(jawabanSoalID)
+	if err != nil {
+		return err
+	}
+	_, err = s.ubahOpsi(existing.IdSoal, func(lama []entity.JawabanSoal) ([]entity.JawabanSoal, error) {
+		sisa := make([]entity.JawabanSoal, 0, len(lama))
+		for _, o := range lama {
+			if o.IdJawabanSoal != jawabanSoalID {
+				sisa = append(sisa, o)
+			}
+		}
+		return sisa, nil
+	}, func([]entity.JawabanSoal) entity.JawabanSoal {
+		return entity.JawabanSoal{}
+	})
+	return err
+}
+
+// ubahOpsi applies a change to the options of a soal, validates the resulting set and saves it
+func (s *soalService) ubahOpsi(soalID uint64, ubah func([]entity.JawabanSoal) ([]entity.JawabanSoal, error), pilih func([]entity.JawabanSoal) entity.JawabanSoal) (entity.JawabanSoal, error) {
+	soal, err := s.soalRepository.FindByIdWithTipeSoal(soalID)
+	if err != nil {
+		return entity.JawabanSoal{}, err
+	}
+	if err := s.cekSoalBolehDiubah(soal.IdSoal); err != nil {
+		return entity.JawabanSoal{}, err
+	}
+	lama, err := s.jawabanSoalRepository.FindByIdSoal(soalID)
+	if err != nil {
+		return entity.JawabanSoal{}, err
+	}
+
+	baru, err := ubah(append([]entity.JawabanSoal(nil), lama...))
+	if err != nil {
+		return entity.JawabanSoal{}, err
+	}
+	if err := validasiOpsiSoal(soal.TipeSoal.NamaTipeUjian, baru); err != nil {
+		return entity.JawabanSoal{}, err
+	}
+
+	var hasil []entity.JawabanSoal
+	err = s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		hasil, err = s.simpanOpsi(tx, soal, baru, lama)
+		return err
+	})
+	if err != nil {
+		return entity.JawabanSoal{}, err
+	}
+	return pilih(hasil), nil
+}
+
+// simpanOpsi writes the new option set of the soal inside tx and returns the saved options
+func (s *soalService) simpanOpsi(tx *gorm.DB, soal entity.Soal, baru []entity.JawabanSoal, lama []entity.JawabanSoal) ([]entity.JawabanSoal, error) {
+	jawabanSoalRepo := s.jawabanSoalRepository.WithTx(tx)
+	lamaByID := make(map[uint64]entity.JawabanSoal, len(lama))
+	for _, o := range lama {
+		lamaByID[o.IdJawabanSoal] = o
+	}
+
+	now := time.Now()
+	dipakai := make(map[uint64]bool, len(baru))
+	hasil := make([]entity.JawabanSoal, 0, len(baru))
+	for _, opsi := range baru {
+		opsi.IdSoal = soal.IdSoal
+		opsi.IdTipeSoal = soal.IdTipeSoal
+		if opsi.TipePencocokan == "" {
+			opsi.TipePencocokan = entity.PencocokanTeks
+		}
+		opsi.UpdatedAt = now
+		if opsi.IdJawabanSoal == 0 {
+			opsi.CreatedAt = now
+			created, err := jawabanSoalRepo.CreateJawabanSoal(opsi)
+			if err != nil {
+				return nil, err
+			}
+			hasil = append(hasil, created)
+			continue
+		}
+
+		existing, ok := lamaByID[opsi.IdJawabanSoal]
+		if !ok || dipakai[opsi.IdJawabanSoal] {
+			return nil, fmt.Errorf("%w: opsi %d bukan milik soal %d", ErrOpsiSoalTidakValid, opsi.IdJawabanSoal, soal.IdSoal)
+		}
+		dipakai[opsi.IdJawabanSoal] = true
+		opsi.CreatedAt = existing.CreatedAt
+		updated, err := jawabanSoalRepo.UpdateJawabanSoal(opsi)
+		if err != nil {
+			return nil, err
+		}
+		hasil = append(hasil, updated)
+	}
+
+	for _, opsi := range lama {
+		if dipakai[opsi.IdJawabanSoal] {
+			continue
+		}
+		dipilih, err := jawabanSoalRepo.SudahDipilih(opsi.IdJawabanSoal)
+		if err != nil {
+			return nil, err
+		}
+		if dipilih {
+			return nil, fmt.Errorf("opsi %q %w", opsi.Jawaban, ErrSudahDijawabSiswa)
+		}
+		if err := jawabanSoalRepo.DeleteJawabanSoal(opsi.IdJawabanSoal); err != nil {
+			return nil, err
+		}
+	}
+	return hasil, nil
+}
+
+// siapkanSoal fills defaults, validates the soal and its options and returns its tipe soal
+func (s *soalService) siapkanSoal(soal *entity.Soal, opsi []entity.JawabanSoal) (entity.TipeSoal, error) {
+	if soal.ModePenilaian == "" {
+		soal.ModePenilaian = entity.ModePenilaianSemuaBenar
+	}
+	var masalah []string
+	if strings.TrimSpace(soal.Soal) == "" {
+		masalah = append(masalah, "soal wajib diisi")
+	}
+	if soal.NilaiPerSoal < 0 {
+		masalah = append(masalah, "nilai_per_soal tidak boleh negatif")
+	}
+	switch soal.ModePenilaian {
+	case entity.ModePenilaianSemuaBenar, entity.ModePenilaianProporsional, entity.ModePenilaianPenalti:
+	default:
+		masalah = append(masalah, "mode_penilaian harus Semua_Benar, Proporsional atau Penalti")
+	}
+	if len(masalah) > 0 {
+		return entity.TipeSoal{}, fmt.Errorf("%w: %s", ErrSoalTidakValid, strings.Join(masalah, "; "))
+	}
+
+	tipeSoal, err := s.soalRepository.FindTipeSoalById(soal.IdTipeSoal)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return tipeSoal, fmt.Errorf("%w: tipe soal %d tidak ditemukan", ErrSoalTidakValid, soal.IdTipeSoal)
+	}
+	if err != nil {
+		return tipeSoal, err
+	}
+	return tipeSoal, validasiOpsiSoal(tipeSoal.NamaTipeUjian, opsi)
+}
+
+// cekUjianBolehDiubah refuses changes to the soal of an ujian while a siswa is working on it
+func (s *soalService) cekUjianBolehDiubah(ujianID uint64) error {
+	return cekUjianBolehDiubah(s.ujianAttemptRepository, ujianID)
+}
+
+// cekUjianBolehDiubah returns ErrUjianSedangDikerjakan while a siswa has an attempt of the ujian in progress
+func cekUjianBolehDiubah(ujianAttemptRepo repository.UjianAttemptRepository, ujianID uint64) error {
+	berlangsung, err := ujianAttemptRepo.CountByUjian(ujianID, entity.StatusAttemptBerlangsung)
+	if err != nil {
+		return err
+	}
+	if berlangsung > 0 {
+		return ErrUjianSedangDikerjakan
+	}
+	return nil
+}
+
+// cekSoalBolehDiubah refuses changes to a soal while a siswa is working on an ujian that uses it, as
+// its own soal or as a linked bank soal
+func (s *soalService) cekSoalBolehDiubah(soalID uint64) error {
+	ujianIDs, err := s.soalRepository.FindIdUjianBySoal(soalID)
+	if err != nil {
+		return err
+	}
+	for _, ujianID := range ujianIDs {
+		if err := s.cekUjianBolehDiubah(ujianID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cekTotalNilaiSoal runs cekTotalNilai for the soal's own ujian, or for every ujian that links the bank
+// soal without overriding its nilai_per_soal
+func (s *soalService) cekTotalNilaiSoal(soal entity.Soal) error {
+	ujianIDs := []uint64{soal.IdUjian}
+	if soal.IdUjian == 0 {
+		tautan, err := s.bankSoalRepository.FindUjianSoalByIdSoal(soal.IdSoal)
+		if err != nil {
+			return err
+		}
+		ujianIDs = ujianIDs[:0]
+		for _, t := range tautan {
+			if t.NilaiPerSoal == nil {
+				ujianIDs = append(ujianIDs, t.IdUjian)
+			}
+		}
+	}
+	for _, ujianID := range ujianIDs {
+		ujian, err := s.ujianRepository.GetUjianByID(ujianID)
+		if err != nil {
+			return err
+		}
+		if err := s.cekTotalNilai(ujian, soal.IdSoal, soal.NilaiPerSoal); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cekTotalNilai makes sure the nilai_per_soal of the ujian's soal, with soalID set to nilai (0 for a
+// new soal), do not exceed the grade. The sum may stay below the grade while the ujian is being
+// built; ValidasiUjian reports whether it is complete.
+func (s *soalService) cekTotalNilai(ujian entity.Ujian, soalID uint64, nilai float64) error {
+	soalList, err := s.soalRepository.FindByIdUjian(ujian.IdUjian)
+	if err != nil {
+		return err
+	}
+	if total := totalNilaiPerSoal(soalList, soalID, nilai); melebihiGrade(total, ujian.Grade) {
+		return fmt.Errorf("%w: total nilai_per_soal %.2f, grade %.2f", ErrTotalNilaiMelebihiGrade, total, ujian.Grade)
+	}
+	return nil
+}
+
+// totalNilaiPerSoal sums nilai_per_soal, counting nilai instead for soalID (added when soalID is 0)
+func totalNilaiPerSoal(soalList []entity.Soal, soalID uint64, nilai float64) float64 {
+	total := nilai
+	for _, soal := range soalList {
+		if soalID == 0 || soal.IdSoal != soalID {
+			total += soal.NilaiPerSoal
+		}
+	}
+	return total
+}
+
+func melebihiGrade(total float64, grade float64) bool {
+	return total > grade+toleransiNilai
+}
+
+// validasiOpsiSoal checks the answer options of a soal: Pilihan_Berganda and Benar_Salah need exactly
+// one correct option, Pilihan_Kompleks at least one, and the accepted variants of an Isian soal must
+// be usable by the automatic matching
+func validasiOpsiSoal(tipeSoal string, opsi []entity.JawabanSoal) error {
+	var masalah []string
+	benar := 0
+	for _, o := range opsi {
+		if strings.TrimSpace(o.Jawaban) == "" {
+			masalah = append(masalah, "teks opsi wajib diisi")
+		}
+		if o.Benar {
+			benar++
+		}
+	}
+
+	switch tipeSoal {
+	case entity.TipeSoalPilihanBerganda:
+		if len(opsi) < 2 {
+			masalah = append(masalah, "soal Pilihan_Berganda butuh minimal 2 opsi")
+		}
+		if benar != 1 {
+			masalah = append(masalah, fmt.Sprintf("soal Pilihan_Berganda harus punya tepat 1 opsi benar, ada %d", benar))
+		}
+	case entity.TipeSoalBenarSalah:
+		if len(opsi) != 2 {
+			masalah = append(masalah, "soal Benar_Salah harus punya 2 opsi")
+		}
+		if benar != 1 {
+			masalah = append(masalah, fmt.Sprintf("soal Benar_Salah harus punya tepat 1 opsi benar, ada %d", benar))
+		}
+	case entity.TipeSoalPilihanKompleks:
+		if len(opsi) < 2 {
+			masalah = append(masalah, "soal Pilihan_Kompleks butuh minimal 2 opsi")
+		}
+		if benar < 1 {
+			masalah = append(masalah, "soal Pilihan_Kompleks butuh minimal 1 opsi benar")
+		}
+	case entity.TipeSoalIsian:
+		// Tanpa variasi jawaban, soal Isian dinilai manual oleh guru
+		for _, o := range opsi {
+			masalah = append(masalah, validasiVariasiIsian(o)...)
+		}
+	}
+
+	if len(masalah) > 0 {
+		return fmt.Errorf("%w: %s", ErrOpsiSoalTidakValid, strings.Join(masalah, "; "))
+	}
+	return nil
+}
+
+func validasiVariasiIsian(o entity.JawabanSoal) []string {
+	switch o.TipePencocokan {
+	case "", entity.PencocokanTeks:
+	case entity.PencocokanAngka:
+		if _, err := parseAngka(o.Jawaban); err != nil {
+			return []string{fmt.Sprintf("variasi %q bukan angka", o.Jawaban)}
+		}
+		if o.ToleransiAngka < 0 {
+			return []string{"toleransi_angka tidak boleh negatif"}
+		}
+	case entity.PencocokanRegex:
+		if _, err := regexp.Compile(o.Jawaban); err != nil {
+			return []string{fmt.Sprintf("pola %q tidak valid: %v", o.Jawaban, err)}
+		}
+	default:
+		return []string{"tipe_pencocokan harus Teks, Angka atau Regex"}
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"cbt-api/entity"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidasiOpsiSoal(t *testing.T) {
+	opsi := func(benar ...bool) []entity.JawabanSoal {
+		list := make([]entity.JawabanSoal, 0, len(benar))
+		for i, b := range benar {
+			list = append(list, entity.JawabanSoal{Jawaban: string(rune('A' + i)), Benar: b})
+		}
+		return list
+	}
+	tests := []struct {
+		name  string
+		tipe  string
+		opsi  []entity.JawabanSoal
+		valid bool
+	}{
+		{"PG with one correct option", entity.TipeSoalPilihanBerganda, opsi(false, true, false), true},
+		{"PG without a correct option", entity.TipeSoalPilihanBerganda, opsi(false, false), false},
+		{"PG with two correct options", entity.TipeSoalPilihanBerganda, opsi(true, true, false), false},
+		{"PG with a single option", entity.TipeSoalPilihanBerganda, opsi(true), false},
+		{"PG option without text", entity.TipeSoalPilihanBerganda, []entity.JawabanSoal{{Jawaban: " ", Benar: true}, {Jawaban: "B"}}, false},
+		{"BS with one correct option", entity.TipeSoalBenarSalah, opsi(true, false), true},
+		{"BS with both correct", entity.TipeSoalBenarSalah, opsi(true, true), false},
+		{"BS with neither correct", entity.TipeSoalBenarSalah, opsi(false, false), false},
+		{"BS with three options", entity.TipeSoalBenarSalah, opsi(true, false, false), false},
+		{"PK with two correct options", entity.TipeSoalPilihanKompleks, opsi(true, true, false), true},
+		{"PK without a correct option", entity.TipeSoalPilihanKompleks, opsi(false, false), false},
+		{"Isian without variants", entity.TipeSoalIsian, nil, true},
+		{"Isian number variant", entity.TipeSoalIsian, []entity.JawabanSoal{{Jawaban: "3,5", Benar: true, TipePencocokan: entity.PencocokanAngka}}, true},
+		{"Isian variant that is not a number", entity.TipeSoalIsian, []entity.JawabanSoal{{Jawaban: "tiga", Benar: true, TipePencocokan: entity.PencocokanAngka}}, false},
+		{"Isian negative tolerance", entity.TipeSoalIsian, []entity.JawabanSoal{{Jawaban: "3", Benar: true, TipePencocokan: entity.PencocokanAngka, ToleransiAngka: -1}}, false},
+		{"Isian invalid regex", entity.TipeSoalIsian, []entity.JawabanSoal{{Jawaban: "(", Benar: true, TipePencocokan: entity.PencocokanRegex}}, false},
+		{"Isian unknown matching", entity.TipeSoalIsian, []entity.JawabanSoal{{Jawaban: "x", Benar: true, TipePencocokan: "Fuzzy"}}, false},
+	}
+	for _, tt := range tests {
+		err := validasiOpsiSoal(tt.tipe, tt.opsi)
+		if tt.valid && err != nil {
+			t.Errorf("%s = %v, want valid", tt.name, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrOpsiSoalTidakValid) {
+			t.Errorf("%s = %v, want ErrOpsiSoalTidakValid", tt.name, err)
+		}
+	}
+}
+
+func TestTotalNilaiPerSoal(t *testing.T) {
+	soalList := []entity.Soal{{IdSoal: 1, NilaiPerSoal: 30}, {IdSoal: 2, NilaiPerSoal: 50}}
+	tests := []struct {
+		name   string
+		soalID uint64
+		nilai  float64
+		want   float64
+	}{
+		{"new soal is added", 0, 20, 100},
+		{"updated soal counts its new nilai once", 2, 60, 90},
+		{"soal of another ujian is added", 9, 10, 90},
+	}
+	for _, tt := range tests {
+		if got := totalNilaiPerSoal(soalList, tt.soalID, tt.nilai); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if melebihiGrade(100.004, 100) || !melebihiGrade(100.01, 100) {
+		t.Error("melebihiGrade should absorb decimal(5,2) rounding and nothing more")
+	}
+}
+
+func TestKelolaSoalUjian(t *testing.T) {
+	env := newLingkunganTest(t)
+	ujian := env.buatUjian(t, nil)
+	pg := env.tipeSoal[entity.TipeSoalPilihanBerganda].IdTipeSoal
+	input := func(nilai float64, opsi ...entity.JawabanSoal) SoalDenganJawaban {
+		return SoalDenganJawaban{Soal: entity.Soal{Soal: "Ibu kota?", NilaiPerSoal: nilai, IdTipeSoal: pg}, Jawaban: opsi}
+	}
+	benar, salah := entity.JawabanSoal{Jawaban: "Jakarta", Benar: true}, entity.JawabanSoal{Jawaban: "Bandung"}
+
+	if _, err := env.soal.CreateSoal(ujian.IdUjian, input(40, benar, benar)); !errors.Is(err, ErrOpsiSoalTidakValid) {
+		t.Errorf("PG with two correct options = %v, want ErrOpsiSoalTidakValid", err)
+	}
+	pertama, err := env.soal.CreateSoal(ujian.IdUjian, input(60, benar, salah))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pertama.Jawaban) != 2 || pertama.Soal.ModePenilaian != entity.ModePenilaianSemuaBenar {
+		t.Errorf("created soal = %+v", pertama)
+	}
+	if _, err := env.soal.CreateSoal(ujian.IdUjian, input(50, benar, salah)); !errors.Is(err, ErrTotalNilaiMelebihiGrade) {
+		t.Errorf("60 + 50 with grade 100 = %v, want ErrTotalNilaiMelebihiGrade", err)
+	}
+	// Soal yang diubah dihitung sekali dengan nilai barunya
+	if _, err := env.soal.UpdateSoal(pertama.Soal.IdSoal, input(100, benar, salah)); err != nil {
+		t.Errorf("raising the only soal to the grade = %v", err)
+	}
+
+	validasi, err := env.ujian.ValidasiUjian(ujian.IdUjian)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !validasi.Siap || validasi.TotalNilaiPerSoal != 100 {
+		t.Errorf("validasi = %+v, want ready with total 100", validasi)
+	}
+	if _, err := env.soal.UpdateSoal(pertama.Soal.IdSoal, input(70, benar, salah)); err != nil {
+		t.Fatal(err)
+	}
+	if validasi, _ := env.ujian.ValidasiUjian(ujian.IdUjian); validasi.Siap || len(validasi.Masalah) != 1 {
+		t.Errorf("validasi with total 70 of 100 = %+v, want one problem", validasi)
+	}
+
+	// Ujian baru bisa dimulai setelah total nilai_per_soal sama dengan grade
+	if _, _, err := env.ujianAttempt.StartAttempt(ujian.IdUjian, 7, "masuk"); !errors.Is(err, ErrUjianBelumSiap) {
+		t.Errorf("start with total 70 of 100 = %v, want ErrUjianBelumSiap", err)
+	}
+	if _, err := env.soal.CreateSoal(ujian.IdUjian, input(30, salah, benar)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Soal tidak boleh diubah saat siswa sedang mengerjakan ujian
+	if _, _, err := env.ujianAttempt.StartAttempt(ujian.IdUjian, 7, "masuk"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.soal.CreateSoal(ujian.IdUjian, input(30, benar, salah)); !errors.Is(err, ErrUjianSedangDikerjakan) {
+		t.Errorf("adding a soal during an attempt = %v, want ErrUjianSedangDikerjakan", err)
+	}
+	if err := env.soal.DeleteSoal(pertama.Soal.IdSoal); !errors.Is(err, ErrUjianSedangDikerjakan) {
+		t.Errorf("deleting a soal during an attempt = %v, want ErrUjianSedangDikerjakan", err)
+	}
+}
+
+func TestUpdateUjianKeepsGradeAboveSoal(t *testing.T) {
+	env := newLingkunganTest(t)
+	kursus := entity.Kursus{NamaKursus: "Matematika"}
+	env.buat(t, &kursus)
+	ujian := env.buatUjian(t, func(u *entity.Ujian) { u.IdKursus = kursus.IdKursus })
+	env.buatSoal(t, ujian, entity.TipeSoalIsian, 80)
+
+	ujian.PasswordMasuk, ujian.PasswordKeluar = "", ""
+	ujian.Grade = 50
+	if _, err := env.ujian.UpdateUjian(ujian); !errors.Is(err, ErrTotalNilaiMelebihiGrade) {
+		t.Errorf("grade below the soal total = %v, want ErrTotalNilaiMelebihiGrade", err)
+	}
+	ujian.Grade = 80
+	updated, err := env.ujian.UpdateUjian(ujian)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.PasswordMasuk == "" || updated.PasswordKeluar == "" {
+		t.Error("empty passwords cleared the stored hashes")
+	}
+	if _, _, err := env.ujianAttempt.StartAttempt(ujian.IdUjian, 7, "masuk"); err != nil {
+		t.Errorf("entry password after an update without passwords = %v", err)
+	}
+	// Ujian yang sedang dikerjakan tidak boleh diubah
+	ujian.Grade = 90
+	if _, err := env.ujian.UpdateUjian(ujian); !errors.Is(err, ErrUjianSedangDikerjakan) {
+		t.Errorf("update during an attempt = %v, want ErrUjianSedangDikerjakan", err)
+	}
+}
+
+func TestUjianJSONWithoutPasswordHashes(t *testing.T) {
+	env := newLingkunganTest(t)
+	kursus := entity.Kursus{NamaKursus: "Matematika"}
+	env.buat(t, &kursus)
+	created, err := env.ujian.CreateUjian(entity.Ujian{
+		NamaUjian:      "Ujian",
+		Acak:           entity.StatusTidakAktif,
+		StatusJawaban:  entity.StatusAktif,
+		Grade:          100,
+		PasswordMasuk:  "masuk",
+		PasswordKeluar: "keluar",
+		IdKursus:       kursus.IdKursus,
+		IdTipeUjian:    1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ujian, err := env.ujian.GetUjianByID(created.IdUjian)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ujian.PasswordMasuk == "" || ujian.PasswordMasuk == "masuk" {
+		t.Fatalf("stored entry password = %q, want a bcrypt hash", ujian.PasswordMasuk)
+	}
+	for _, value := range []interface{}{created, ujian} {
+		data, err := json.Marshal(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "password") || strings.Contains(string(data), ujian.PasswordMasuk) {
+			t.Errorf("ujian JSON carries a password: %s", data)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"cbt-api/entity"
+	"cbt-api/repository"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestKunciUjianTerlihat(t *testing.T) {
+	now := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	selesai := &entity.UjianAttempt{Status: entity.StatusAttemptSelesai}
+	berlangsung := &entity.UjianAttempt{Status: entity.StatusAttemptBerlangsung}
+	tests := []struct {
+		name    string
+		ujian   entity.Ujian
+		attempt *entity.UjianAttempt
+		want    bool
+	}{
+		{"submitted, no window", entity.Ujian{StatusJawaban: entity.StatusAktif}, selesai, true},
+		{"key disabled", entity.Ujian{StatusJawaban: entity.StatusTidakAktif}, selesai, false},
+		{"not started", entity.Ujian{StatusJawaban: entity.StatusAktif}, nil, false},
+		{"in progress", entity.Ujian{StatusJawaban: entity.StatusAktif}, berlangsung, false},
+		{"submitted before the window ends", entity.Ujian{StatusJawaban: entity.StatusAktif, WaktuSelesai: now.Add(time.Hour)}, selesai, false},
+		{"submitted after the window ended", entity.Ujian{StatusJawaban: entity.StatusAktif, WaktuSelesai: now.Add(-time.Minute)}, selesai, true},
+		{"window ended but not submitted", entity.Ujian{StatusJawaban: entity.StatusAktif, WaktuSelesai: now.Add(-time.Minute)}, berlangsung, false},
+	}
+	for _, tt := range tests {
+		if got := kunciUjianTerlihat(tt.ujian, tt.attempt, now); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSoalUjianSiswaHidesKeyUntilSubmit(t *testing.T) {
+	env := newLingkunganTest(t)
+	ujian := env.buatUjian(t, nil)
+	pg, _ := env.buatSoal(t, ujian, entity.TipeSoalPilihanBerganda, 50,
+		entity.JawabanSoal{Jawaban: "A"}, entity.JawabanSoal{Jawaban: "B", Benar: true})
+	_, kunciIsian := env.buatSoal(t, ujian, entity.TipeSoalIsian, 50, entity.JawabanSoal{Jawaban: "Jakarta", Benar: true})
+	const siswaID = 7
+
+	cekTersembunyi := func(tahap string) {
+		t.Helper()
+		list, err := env.soal.GetSoalUjianSiswa(ujian.IdUjian, siswaID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) != 2 {
+			t.Fatalf("%s: got %d soal, want 2", tahap, len(list))
+		}
+		for _, soal := range list {
+			if soal.KunciDitampilkan {
+				t.Errorf("%s: key shown for soal %d", tahap, soal.Soal.IdSoal)
+			}
+			for _, opsi := range soal.Jawaban {
+				if opsi.Benar != nil {
+					t.Errorf("%s: option %d carries benar", tahap, opsi.IdJawabanSoal)
+				}
+			}
+		}
+		if n := len(list[1].Jawaban); list[1].Soal.TipeSoal != entity.TipeSoalIsian || n != 0 {
+			t.Errorf("%s: Isian soal lists %d accepted answers, want none", tahap, n)
+		}
+		if _, err := env.soal.GetJawabanSoalSiswa(kunciIsian[0].IdJawabanSoal, siswaID); !errors.Is(err, ErrKunciJawabanTersembunyi) {
+			t.Errorf("%s: accepted Isian answer = %v, want ErrKunciJawabanTersembunyi", tahap, err)
+		}
+	}
+
+	cekTersembunyi("not started")
+	if _, _, err := env.ujianAttempt.StartAttempt(ujian.IdUjian, siswaID, "masuk"); err != nil {
+		t.Fatal(err)
+	}
+	cekTersembunyi("in progress")
+	if _, _, err := env.ujianAttempt.FinishAttempt(ujian.IdUjian, siswaID, "keluar"); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := env.soal.GetSoalUjianSiswa(ujian.IdUjian, siswaID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !list[0].KunciDitampilkan || list[0].Soal.IdSoal != pg.IdSoal || len(list[0].Jawaban) != 2 ||
+		list[0].Jawaban[1].Benar == nil || !*list[0].Jawaban[1].Benar {
+		t.Errorf("after submit the key should be shown: %+v", list[0])
+	}
+	if len(list[1].Jawaban) != 1 {
+		t.Errorf("after submit the Isian soal lists %d accepted answers, want 1", len(list[1].Jawaban))
+	}
+	if opsi, err := env.soal.GetJawabanSoalSiswa(kunciIsian[0].IdJawabanSoal, siswaID); err != nil || opsi.Benar == nil {
+		t.Errorf("accepted Isian answer after submit = %+v, %v", opsi, err)
+	}
+}
+
+func TestJawabanSiswaHidesKeyUntilWindowEnds(t *testing.T) {
+	env := newLingkunganTest(t)
+	ujian := env.buatUjian(t, func(u *entity.Ujian) {
+		u.WaktuMulai = time.Now().Add(-time.Hour)
+		u.WaktuSelesai = time.Now().Add(time.Hour)
+	})
+	soal, opsi := env.buatSoal(t, ujian, entity.TipeSoalPilihanBerganda, 100,
+		entity.JawabanSoal{Jawaban: "A"}, entity.JawabanSoal{Jawaban: "B", Benar: true})
+	const siswaID = 7
+
+	_, attempt, err := env.ujianAttempt.StartAttempt(ujian.IdUjian, siswaID, "masuk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.jawabanSiswa.CreateJawabanSiswa(entity.JawabanSiswa{
+		IdSoal: soal.IdSoal, IdSiswa: siswaID, IdJawabanSoal: opsi[0].IdJawabanSoal, IdUjianAttempt: &attempt.IdUjianAttempt,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	cekTersembunyi := func(tahap string) {
+		t.Helper()
+		list, err := env.jawabanSiswa.GetJawabanSiswaByUjianIDAndSiswaID(ujian.IdUjian, siswaID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) != 1 {
+			t.Fatalf("%s: got %d answers, want 1", tahap, len(list))
+		}
+		if list[0].KunciDitampilkan || list[0].JawabanSoal == nil || list[0].JawabanSoal.Benar != nil {
+			t.Errorf("%s: answer key shown: %+v", tahap, list[0].JawabanSoal)
+		}
+	}
+
+	cekTersembunyi("in progress")
+	if _, err := env.nilai.CalculateScore(ujian.IdUjian, siswaID); !errors.Is(err, ErrAttemptBelumSelesai) {
+		t.Errorf("CalculateScore in progress = %v, want ErrAttemptBelumSelesai", err)
+	}
+
+	if _, _, err := env.ujianAttempt.FinishAttempt(ujian.IdUjian, siswaID, "keluar"); err != nil {
+		t.Fatal(err)
+	}
+	cekTersembunyi("submitted, window open")
+	if _, err := env.nilai.CalculateScore(ujian.IdUjian, siswaID); !errors.Is(err, ErrUjianBelumBerakhir) {
+		t.Errorf("CalculateScore while the window is open = %v, want ErrUjianBelumBerakhir", err)
+	}
+
+	if err := env.db.Model(&entity.Ujian{}).Where("id_ujian = ?", ujian.IdUjian).
+		Update("waktu_selesai", time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatal(err)
+	}
+	list, err := env.jawabanSiswa.GetJawabanSiswaByUjianIDAndSiswaID(ujian.IdUjian, siswaID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !list[0].KunciDitampilkan || list[0].JawabanSoal == nil || list[0].JawabanSoal.Benar == nil || *list[0].JawabanSoal.Benar {
+		t.Errorf("after the window the wrong choice should be shown as wrong: %+v", list[0].JawabanSoal)
+	}
+	if nilai, err := env.nilai.CalculateScore(ujian.IdUjian, siswaID); err != nil || nilai != 0 {
+		t.Errorf("CalculateScore after the window = %v, %v; want 0, nil", nilai, err)
+	}
+}
+
+func TestJawabanLatihanHidesKeyOfSharedSoal(t *testing.T) {
+	env := newLingkunganTest(t)
+	jawabanLatihan := NewJawabanLatihanService(repository.NewJawabanLatihanRepository(env.db), repository.NewLatihanRepository(env.db),
+		repository.NewSoalRepository(env.db), repository.NewUjianRepository(env.db))
+	terbuka := entity.Latihan{Topik: "Terbuka", Acak: entity.StatusTidakAktif, StatusJawaban: entity.StatusAktif}
+	tertutup := entity.Latihan{Topik: "Tertutup", Acak: entity.StatusTidakAktif, StatusJawaban: entity.StatusTidakAktif}
+	env.buat(t, &terbuka)
+	env.buat(t, &tertutup)
+	// Soal bank yang dipakai kedua latihan
+	soal, opsi := env.buatSoal(t, entity.Ujian{}, entity.TipeSoalPilihanBerganda, 10,
+		entity.JawabanSoal{Jawaban: "A", Benar: true}, entity.JawabanSoal{Jawaban: "B"})
+	env.buat(t, &entity.LatihanSoal{IdLatihan: terbuka.IdLatihan, IdSoal: soal.IdSoal})
+	env.buat(t, &entity.LatihanSoal{IdLatihan: tertutup.IdLatihan, IdSoal: soal.IdSoal})
+
+	jawaban, err := jawabanLatihan.CreateJawabanLatihan(entity.JawabanSiswa{IdSoal: soal.IdSoal, IdSiswa: 7, IdJawabanSoal: opsi[1].IdJawabanSoal})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := jawabanLatihan.GetJawabanLatihanByID(jawaban.IdJawabanSiswa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.KunciDitampilkan || got.JawabanSoal == nil || got.JawabanSoal.Benar != nil {
+		t.Errorf("key of a soal shared with a closed latihan shown: %+v", got.JawabanSoal)
+	}
+	list, err := jawabanLatihan.GetJawabanLatihanByLatihanIDAndSiswaID(tertutup.IdLatihan, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].KunciDitampilkan {
+		t.Errorf("key shown for the closed latihan: %+v", list)
+	}
+
+	if err := env.db.Model(&tertutup).Update("status_jawaban", entity.StatusAktif).Error; err != nil {
+		t.Fatal(err)
+	}
+	bySiswa, err := jawabanLatihan.GetJawabanLatihanBySiswaID(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bySiswa) == 0 {
+		t.Fatal("no answers for the siswa")
+	}
+	for _, j := range bySiswa {
+		if !j.KunciDitampilkan || j.JawabanSoal == nil || j.JawabanSoal.Benar == nil || *j.JawabanSoal.Benar {
+			t.Errorf("once every latihan is open the wrong choice should be shown as wrong: %+v", j.JawabanSoal)
+		}
+	}
+}
+
+func TestKunciLatihanHiddenWhileUjianOpen(t *testing.T) {
+	env := newLingkunganTest(t)
+	latihanRepo := repository.NewLatihanRepository(env.db)
+	soalRepo := repository.NewSoalRepository(env.db)
+	ujianRepo := repository.NewUjianRepository(env.db)
+	jawabanLatihan := NewJawabanLatihanService(repository.NewJawabanLatihanRepository(env.db), latihanRepo, soalRepo, ujianRepo)
+	soalLatihan := NewSoalLatihanService(repository.NewSoalLatihanRepository(env.db), latihanRepo, soalRepo, ujianRepo)
+
+	latihan := entity.Latihan{Topik: "Aljabar", Acak: entity.StatusTidakAktif, StatusJawaban: entity.StatusAktif}
+	env.buat(t, &latihan)
+	ujian := env.buatUjian(t, func(u *entity.Ujian) {
+		u.WaktuMulai = time.Now().Add(-time.Hour)
+		u.WaktuSelesai = time.Now().Add(time.Hour)
+	})
+	// Soal bank yang dipakai latihan dan juga ujian yang masih berlangsung
+	soal, opsi := env.buatSoal(t, entity.Ujian{}, entity.TipeSoalPilihanBerganda, 10,
+		entity.JawabanSoal{Jawaban: "A", Benar: true}, entity.JawabanSoal{Jawaban: "B"})
+	env.buat(t, &entity.LatihanSoal{IdLatihan: latihan.IdLatihan, IdSoal: soal.IdSoal})
+	env.buat(t, &entity.UjianSoal{IdUjian: ujian.IdUjian, IdSoal: soal.IdSoal})
+	const siswaID = 7
+	jawaban := entity.JawabanSiswa{JawabanText: "B", IdSoal: soal.IdSoal, IdSiswa: siswaID, IdJawabanSoal: opsi[1].IdJawabanSoal}
+	env.buat(t, &jawaban)
+
+	cek := func(tahap string, want bool) {
+		t.Helper()
+		soalList, err := env.soal.GetSoalLatihanSiswa(latihan.IdLatihan, siswaID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		soalLatihanList, err := soalLatihan.GetSoalSiswaByLatihanID(latihan.IdLatihan, siswaID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		jawabanList, err := jawabanLatihan.GetJawabanLatihanByLatihanIDAndSiswaID(latihan.IdLatihan, siswaID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		satu, err := jawabanLatihan.GetJawabanLatihanByID(jawaban.IdJawabanSiswa)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(soalList) != 1 || len(soalLatihanList) != 1 || len(jawabanList) != 1 {
+			t.Fatalf("%s: got %d, %d soal and %d answers, want one each", tahap, len(soalList), len(soalLatihanList), len(jawabanList))
+		}
+		got := []bool{soalList[0].KunciDitampilkan, soalLatihanList[0].KunciDitampilkan, jawabanList[0].KunciDitampilkan, satu.KunciDitampilkan}
+		for i, terlihat := range got {
+			if terlihat != want {
+				t.Errorf("%s: endpoint %d shows the key = %v, want %v", tahap, i, terlihat, want)
+			}
+		}
+	}
+
+	cek("ujian open", false)
+	if err := env.db.Model(&entity.Ujian{}).Where("id_ujian = ?", ujian.IdUjian).Update("waktu_selesai", time.Time{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	cek("ujian without an end", false)
+	if err := env.db.Model(&entity.Ujian{}).Where("id_ujian = ?", ujian.IdUjian).Update("waktu_selesai", time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatal(err)
+	}
+	cek("ujian ended", true)
+	if err := env.db.Model(&latihan).Update("status_jawaban", entity.StatusTidakAktif).Error; err != nil {
+		t.Fatal(err)
+	}
+	cek("latihan key disabled", false)
+}
@@ -0,0 +1,75 @@
+// service/kunci_latihan.go
+package service
+
+import (
+	"cbt-api/entity"
+	"cbt-api/repository"
+	"time"
+)
+
+// kunciLatihan decides when a siswa may see the answer key of latihan soal. soalService,
+// soalLatihanService and jawabanLatihanService share it so the rules are the same on every endpoint.
+type kunciLatihan struct {
+	latihanRepository repository.LatihanRepository
+	soalRepository    repository.SoalRepository
+	ujianRepository   repository.UjianRepository
+}
+
+// latihanTerlihat reports whether the siswa may see the answer key of soalIDs, the soal of the latihan:
+// only when the latihan's StatusJawaban is Aktif, after the siswa has worked on it and under the rules
+// of soalTerlihat
+func (k kunciLatihan) latihanTerlihat(latihan entity.Latihan, soalIDs []uint64, siswaID uint64, now time.Time) (bool, error) {
+	if latihan.StatusJawaban != entity.StatusAktif {
+		return false, nil
+	}
+	dikerjakan, err := k.latihanRepository.SudahDikerjakan(latihan.IdLatihan, siswaID)
+	if err != nil || !dikerjakan {
+		return false, err
+	}
+	return k.soalTerlihat(soalIDs, now)
+}
+
+// soalTerlihat reports whether the answer key of latihan soal may be shown to a siswa who answered them.
+// Every latihan that contains one of the soal must have StatusJawaban Aktif, so a bank soal shared with a
+// latihan whose key is still hidden does not give it away, and no ujian that owns or links one of the
+// soal may still be open (ujianDitutup), so a latihan cannot pass on the key of an exam. Soal outside
+// any latihan never show their key.
+func (k kunciLatihan) soalTerlihat(soalIDs []uint64, now time.Time) (bool, error) {
+	ujianDiperiksa := make(map[uint64]bool)
+	for _, soalID := range soalIDs {
+		latihanList, err := k.latihanRepository.GetLatihanBySoalID(soalID)
+		if err != nil || len(latihanList) == 0 {
+			return false, err
+		}
+		for _, latihan := range latihanList {
+			if latihan.StatusJawaban != entity.StatusAktif {
+				return false, nil
+			}
+		}
+
+		ujianIDs, err := k.soalRepository.FindIdUjianBySoal(soalID)
+		if err != nil {
+			return false, err
+		}
+		for _, ujianID := range ujianIDs {
+			if ujianDiperiksa[ujianID] {
+				continue
+			}
+			ujian, err := k.ujianRepository.GetUjianByID(ujianID)
+			if err != nil {
+				return false, err
+			}
+			if !ujianDitutup(ujian, now) {
+				return false, nil
+			}
+			ujianDiperiksa[ujianID] = true
+		}
+	}
+	return true, nil
+}
+
+// ujianDitutup reports whether no siswa can work on the ujian anymore. An ujian without waktu_selesai
+// can always be started, so it never closes.
+func ujianDitutup(ujian entity.Ujian, now time.Time) bool {
+	return !ujian.WaktuSelesai.IsZero() && now.After(ujian.WaktuSelesai)
+}
@@ -0,0 +1,123 @@
+// service/kursus_service.go
+package service
+
+import (
+	"cbt-api/entity"
+	"cbt-api/repository"
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPasswordKursusSalah = errors.New("password kursus salah")
+	ErrKursusTidakAda      = errors.New("kursus tidak ditemukan")
+	ErrSiswaTidakAda       = errors.New("siswa tidak ditemukan")
+)
+
+// KursusService is a contract for kursus service
+type KursusService interface {
+	GetKursusByID(kursusID uint64) (entity.Kursus, error)
+	GetKursusBySiswa(siswaID uint64) ([]entity.KursusSiswa, error)
+	GetAvailableKursus(siswaID uint64) ([]entity.Kursus, error)
+	GetMateriByKursusID(kursusID uint64) ([]entity.Materi, error)
+	AccessKursus(kursusID uint64, password string) (entity.Kursus, error)
+	EnrollSiswa(kursusID uint64, siswaID uint64) (entity.Kursus, entity.KursusSiswa, error)
+	GetAllKursusSiswa() ([]entity.KursusSiswa, error)
+	GetKursusSiswaByID(id uint64) (entity.KursusSiswa, error)
+	CreateKursusSiswa(kursusSiswa entity.KursusSiswa) (entity.KursusSiswa, error)
+	UpdateKursusSiswa(kursusSiswa entity.KursusSiswa) (entity.KursusSiswa, error)
+	DeleteKursusSiswa(id uint64) error
+}
+
+type kursusService struct {
+	kursusRepository repository.KursusRepository
+	siswaRepository  repository.SiswaRepository
+}
+
+// NewKursusService creates a new instance of KursusService
+func NewKursusService(kursusRepo repository.KursusRepository, siswaRepo repository.SiswaRepository) KursusService {
+	return &kursusService{
+		kursusRepository: kursusRepo,
+		siswaRepository:  siswaRepo,
+	}
+}
+
+func (s *kursusService) GetKursusByID(kursusID uint64) (entity.Kursus, error) {
+	return s.kursusRepository.GetKursusByID(kursusID)
+}
+
+func (s *kursusService) GetKursusBySiswa(siswaID uint64) ([]entity.KursusSiswa, error) {
+	return s.kursusRepository.GetKursusSiswaBySiswaID(siswaID)
+}
+
+func (s *kursusService) GetAvailableKursus(siswaID uint64) ([]entity.Kursus, error) {
+	return s.kursusRepository.GetAvailableKursus(siswaID)
+}
+
+func (s *kursusService) GetMateriByKursusID(kursusID uint64) ([]entity.Materi, error) {
+	return s.kursusRepository.GetMateriByKursusID(kursusID)
+}
+
+// AccessKursus checks the kursus password entered by the siswa
+func (s *kursusService) AccessKursus(kursusID uint64, password string) (entity.Kursus, error) {
+	kursus, err := s.kursusRepository.GetKursusByID(kursusID)
+	if err != nil {
+		return kursus, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(kursus.Password), []byte(password)); err != nil {
+		return kursus, ErrPasswordKursusSalah
+	}
+	return kursus, nil
+}
+
+// EnrollSiswa registers the siswa in the kursus after checking both exist
+func (s *kursusService) EnrollSiswa(kursusID uint64, siswaID uint64) (entity.Kursus, entity.KursusSiswa, error) {
+	kursus, err := s.kursusRepository.GetKursusByID(kursusID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return kursus, entity.KursusSiswa{}, ErrKursusTidakAda
+		}
+		return kursus, entity.KursusSiswa{}, err
+	}
+
+	if _, err := s.siswaRepository.GetSiswaByID(siswaID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return kursus, entity.KursusSiswa{}, ErrSiswaTidakAda
+		}
+		return kursus, entity.KursusSiswa{}, err
+	}
+
+	kursusSiswa, err := s.kursusRepository.CreateKursusSiswa(entity.KursusSiswa{
+		IdSiswa:  siswaID,
+		IdKursus: kursusID,
+	})
+	return kursus, kursusSiswa, err
+}
+
+func (s *kursusService) GetAllKursusSiswa() ([]entity.KursusSiswa, error) {
+	return s.kursusRepository.GetAllKursusSiswa()
+}
+
+func (s *kursusService) GetKursusSiswaByID(id uint64) (entity.KursusSiswa, error) {
+	return s.kursusRepository.GetKursusSiswaByID(id)
+}
+
+func (s *kursusService) CreateKursusSiswa(kursusSiswa entity.KursusSiswa) (entity.KursusSiswa, error) {
+	return s.kursusRepository.CreateKursusSiswa(kursusSiswa)
+}
+
+// UpdateKursusSiswa saves the row and reloads it so kursus and siswa match the new ids
+func (s *kursusService) UpdateKursusSiswa(kursusSiswa entity.KursusSiswa) (entity.KursusSiswa, error) {
+	updated, err := s.kursusRepository.UpdateKursusSiswa(kursusSiswa)
+	if err != nil {
+		return updated, err
+	}
+	return s.kursusRepository.GetKursusSiswaByID(updated.IdKursusSiswa)
+}
+
+func (s *kursusService) DeleteKursusSiswa(id uint64) error {
+	return s.kursusRepository.DeleteKursusSiswa(id)
+}
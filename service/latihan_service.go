@@ -0,0 +1,27 @@
+// service/latihan_service.go
+package service
+
+import (
+	"cbt-api/entity"
+	"cbt-api/repository"
+)
+
+// LatihanService is a contract for latihan service
+type LatihanService interface {
+	GetLatihanByFilter(kurikulumID uint64, kelasID uint64, mataPelajaranID uint64) ([]entity.Latihan, error)
+}
+
+type latihanService struct {
+	latihanRepository repository.LatihanRepository
+}
+
+// NewLatihanService creates a new instance of LatihanService
+func NewLatihanService(latihanRepo repository.LatihanRepository) LatihanService {
+	return &latihanService{
+		latihanRepository: latihanRepo,
+	}
+}
+
+func (s *latihanService) GetLatihanByFilter(kurikulumID uint64, kelasID uint64, mataPelajaranID uint64) ([]entity.Latihan, error) {
+	return s.latihanRepository.GetLatihanByFilter(kurikulumID, kelasID, mataPelajaranID)
+}
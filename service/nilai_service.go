@@ -0,0 +1,383 @@
+// service/nilai_service.go
+package service
+
+import (
+	"cbt-api/entity"
+	"cbt-api/repository"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPersentaseTidakAda  = errors.New("persentase tidak ditemukan untuk tipe ujian dan kursus ini")
+	ErrNilaiKursusTidakAda = errors.New("nilai kursus tidak ditemukan")
+	ErrNilaiKursusKosong   = errors.New("tidak ada nilai untuk siswa pada kursus ini")
+	ErrNilaiTidakAda       = errors.New("nilai tidak ditemukan")
+)
+
+// NilaiKursusResult is the outcome of (re)calculating one nilai_kursus row
+type NilaiKursusResult struct {
+	NilaiKursus   entity.NilaiKursus
+	TotalNilaiRaw float64 // jumlah tipe_nilai sebelum dikali persentase
+	Persentase    float64
+	NilaiFinal    float64
+	PreviousScore float64
+	Created       bool
+}
+
+// NilaiResult is the outcome of (re)calculating the nilai row of a siswa in a kursus
+type NilaiResult struct {
+	Nilai         entity.Nilai
+	PreviousTotal float64
+	NewTotal      float64
+	Created       bool
+}
+
+// NilaiService is a contract for scoring: tipe_nilai per ujian, nilai_kursus per tipe ujian
+// weighted by persentase, and the final nilai per kursus
+type NilaiService interface {
+	CreateTipeNilai(tipeNilai entity.TipeNilai) (entity.TipeNilai, error)
+	CalculateScore(ujianID uint64, siswaID uint64) (float64, error)
+	HasAttempted(ujianID uint64, siswaID uint64) (bool, error)
+	GetJawabanSiswa(ujianID uint64, siswaID uint64) ([]entity.JawabanSiswa, error)
+	GetTipeNilaiByKursusAndSiswa(kursusID uint64, siswaID uint64) ([]entity.TipeNilai, error)
+	GetTotalNilaiPerTipeUjian(kursusID uint64, siswaID uint64) (map[uint64]float64, error)
+	GetNilaiByKursusAndSiswa(kursusID uint64, siswaID uint64) ([]entity.Nilai, error)
+	GetTotalNilai(kursusID uint64, siswaID uint64) (float64, int64, error)
+	SaveNilaiKursus(kursusID uint64, siswaID uint64, tipeUjianID uint64) (NilaiKursusResult, error)
+	UpdateNilaiKursus(kursusID uint64, siswaID uint64, tipeUjianID uint64) (NilaiKursusResult, error)
+	RecalculateNilaiKursus(kursusID uint64, siswaID uint64) ([]NilaiKursusResult, error)
+	SaveNilai(kursusID uint64, siswaID uint64) (NilaiResult, error)
+	UpdateNilai(kursusID uint64, siswaID uint64) (NilaiResult, error)
+	RecalculateNilai(kursusID uint64) ([]NilaiResult, error)
+	SubmitAttempt(ujian entity.Ujian, attempt entity.UjianAttempt) (HasilUjian, error)
+	GetAntrianIsian(ujianID uint64, sudahDinilai *bool) ([]AntrianIsian, error)
+	NilaiJawabanIsian(jawabanID uint64, nilai float64, feedback string, guruID uint64) (*HasilUjian, error)
+	AnalisisButirSoal(ujianID uint64) (AnalisisUjian, error)
+}
+
+type nilaiService struct {
+	transactor             repository.Transactor
+	nilaiRepository        repository.NilaiRepository
+	nilaiKursusRepository  repository.NilaiKursusRepository
+	tipeNilaiRepository    repository.TipeNilaiRepository
+	jawabanSiswaRepository repository.JawabanSiswaRepository
+	siswaRepository        repository.SiswaRepository
+	ujianRepository        repository.UjianRepository
+	ujianAttemptRepository repository.UjianAttemptRepository
+	soalRepository         repository.SoalRepository
+	jawabanSoalRepository  repository.JawabanSoalRepository
+}
+
+// NewNilaiService creates a new instance of NilaiService
+func NewNilaiService(
+	transactor repository.Transactor,
+	nilaiRepo repository.NilaiRepository,
+	nilaiKursusRepo repository.NilaiKursusRepository,
+	tipeNilaiRepo repository.TipeNilaiRepository,
+	jawabanSiswaRepo repository.JawabanSiswaRepository,
+	siswaRepo repository.SiswaRepository,
+	ujianRepo repository.UjianRepository,
+	ujianAttemptRepo repository.UjianAttemptRepository,
+	soalRepo repository.SoalRepository,
+	jawabanSoalRepo repository.JawabanSoalRepository,
+) NilaiService {
+	return &nilaiService{
+		transactor:             transactor,
+		nilaiRepository:        nilaiRepo,
+		nilaiKursusRepository:  nilaiKursusRepo,
+		tipeNilaiRepository:    tipeNilaiRepo,
+		jawabanSiswaRepository: jawabanSiswaRepo,
+		siswaRepository:        siswaRepo,
+		ujianRepository:        ujianRepo,
+		ujianAttemptRepository: ujianAttemptRepo,
+		soalRepository:         soalRepo,
+		jawabanSoalRepository:  jawabanSoalRepo,
+	}
+}
+
+func (s *nilaiService) CreateTipeNilai(tipeNilai entity.TipeNilai) (entity.TipeNilai, error) {
+	return s.tipeNilaiRepository.CreateTipeNilai(tipeNilai)
+}
+
+// CalculateScore grades the siswa's answers in the ujian with the ujian's scoring policy,
+// including manual scores of Isian soal. The score is only given once the siswa's latest attempt is
+// submitted and the exam window has ended (ujianSudahBerakhir), so it cannot be used to check answers
+// while the ujian is still being worked on.
+func (s *nilaiService) CalculateScore(ujianID uint64, siswaID uint64) (float64, error) {
+	ujian, err := s.ujianRepository.GetUjianByID(ujianID)
+	if err != nil {
+		return 0, err
+	}
+	attempt, err := s.ujianAttemptRepository.GetLatestAttempt(ujianID, siswaID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, ErrAttemptTidakAda
+	}
+	if err != nil {
+		return 0, err
+	}
+	if attempt.Status != entity.StatusAttemptSelesai {
+		return 0, ErrAttemptBelumSelesai
+	}
+	if !ujianSudahBerakhir(ujian, attempt, time.Now()) {
+		return 0, ErrUjianBelumBerakhir
+	}
+	hasil, err := s.hitungNilaiUjian(s.jawabanSiswaRepository, ujian, siswaID)
+	return hasil.NilaiUjian, err
+}
+
+// upsertTipeNilai stores the nilai of one ujian, updating the existing row if any
+func upsertTipeNilai(tipeNilaiRepo repository.TipeNilaiRepository, siswaID uint64, ujianID uint64, tipeUjianID uint64, nilai float64) (entity.TipeNilai, error) {
+	existing, err := tipeNilaiRepo.FindTipeNilai(siswaID, ujianID, tipeUjianID)
+	if err == nil {
+		existing.Nilai = nilai
+		return tipeNilaiRepo.UpdateTipeNilai(existing)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return existing, err
+	}
+
+	return tipeNilaiRepo.CreateTipeNilai(entity.TipeNilai{
+		Nilai:       nilai,
+		IdTipeUjian: tipeUjianID,
+		IdSiswa:     siswaID,
+		IdUjian:     ujianID,
+	})
+}
+
+// HasAttempted reports whether the siswa already has a nilai for the ujian
+func (s *nilaiService) HasAttempted(ujianID uint64, siswaID uint64) (bool, error) {
+	count, err := s.tipeNilaiRepository.CountBySiswaAndUjian(siswaID, ujianID)
+	return count > 0, err
+}
+
+func (s *nilaiService) GetJawabanSiswa(ujianID uint64, siswaID uint64) ([]entity.JawabanSiswa, error) {
+	return s.jawabanSiswaRepository.GetJawabanSiswaWithSoal(ujianID, siswaID)
+}
+
+func (s *nilaiService) GetTipeNilaiByKursusAndSiswa(kursusID uint64, siswaID uint64) ([]entity.TipeNilai, error) {
+	return s.tipeNilaiRepository.GetTipeNilaiByKursusAndSiswa(kursusID, siswaID)
+}
+
+// GetTotalNilaiPerTipeUjian sums the siswa's tipe_nilai in the kursus per id_tipe_ujian
+func (s *nilaiService) GetTotalNilaiPerTipeUjian(kursusID uint64, siswaID uint64) (map[uint64]float64, error) {
+	tipeNilai, err := s.tipeNilaiRepository.GetTipeNilaiByKursusAndSiswa(kursusID, siswaID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint64]float64)
+	for _, tn := range tipeNilai {
+		result[tn.IdTipeUjian] += tn.Nilai
+	}
+	return result, nil
+}
+
+func (s *nilaiService) GetNilaiByKursusAndSiswa(kursusID uint64, siswaID uint64) ([]entity.Nilai, error) {
+	return s.nilaiRepository.GetNilaiByKursusAndSiswa(kursusID, siswaID)
+}
+
+func (s *nilaiService) GetTotalNilai(kursusID uint64, siswaID uint64) (float64, int64, error) {
+	return s.nilaiRepository.SumNilaiTotal(kursusID, siswaID)
+}
+
+// SaveNilaiKursus creates or updates the nilai_kursus of one tipe ujian
+func (s *nilaiService) SaveNilaiKursus(kursusID uint64, siswaID uint64, tipeUjianID uint64) (NilaiKursusResult, error) {
+	var result NilaiKursusResult
+	err := s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = s.upsertNilaiKursus(tx, kursusID, siswaID, tipeUjianID, false)
+		return err
+	})
+	return result, err
+}
+
+// UpdateNilaiKursus recalculates a nilai_kursus that must already exist
+func (s *nilaiService) UpdateNilaiKursus(kursusID uint64, siswaID uint64, tipeUjianID uint64) (NilaiKursusResult, error) {
+	var result NilaiKursusResult
+	err := s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = s.upsertNilaiKursus(tx, kursusID, siswaID, tipeUjianID, true)
+		return err
+	})
+	return result, err
+}
+
+// RecalculateNilaiKursus recalculates nilai_kursus for every tipe ujian the siswa has a nilai for.
+// Tipe ujian without a persentase in this kursus are skipped.
+func (s *nilaiService) RecalculateNilaiKursus(kursusID uint64, siswaID uint64) ([]NilaiKursusResult, error) {
+	var results []NilaiKursusResult
+	err := s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		tipeUjianIDs, err := s.tipeNilaiRepository.WithTx(tx).GetTipeUjianIDsBySiswa(kursusID, siswaID)
+		if err != nil {
+			return err
+		}
+
+		for _, tipeUjianID := range tipeUjianIDs {
+			result, err := s.upsertNilaiKursus(tx, kursusID, siswaID, tipeUjianID, false)
+			if errors.Is(err, ErrPersentaseTidakAda) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			results = append(results, result)
+		}
+		return nil
+	})
+	return results, err
+}
+
+// upsertNilaiKursus computes SUM(tipe_nilai) * persentase / 100 and stores it.
+// With mustExist, a missing nilai_kursus is an error instead of being created.
+func (s *nilaiService) upsertNilaiKursus(tx *gorm.DB, kursusID uint64, siswaID uint64, tipeUjianID uint64, mustExist bool) (NilaiKursusResult, error) {
+	nilaiKursusRepo := s.nilaiKursusRepository.WithTx(tx)
+	tipeNilaiRepo := s.tipeNilaiRepository.WithTx(tx)
+
+	existing, err := nilaiKursusRepo.FindNilaiKursus(kursusID, siswaID, tipeUjianID)
+	found := err == nil
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return NilaiKursusResult{}, err
+	}
+	if !found && mustExist {
+		return NilaiKursusResult{}, ErrNilaiKursusTidakAda
+	}
+
+	totalNilai, err := tipeNilaiRepo.SumNilaiByTipeUjian(kursusID, siswaID, tipeUjianID)
+	if err != nil {
+		return NilaiKursusResult{}, err
+	}
+
+	persentase, err := nilaiKursusRepo.GetPersentase(kursusID, tipeUjianID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return NilaiKursusResult{}, ErrPersentaseTidakAda
+		}
+		return NilaiKursusResult{}, err
+	}
+
+	result := NilaiKursusResult{
+		TotalNilaiRaw: totalNilai,
+		Persentase:    persentase.Persentase,
+		NilaiFinal:    totalNilai * (persentase.Persentase / 100),
+	}
+
+	if found {
+		result.PreviousScore = existing.NilaiTipeUjian
+		existing.NilaiTipeUjian = result.NilaiFinal
+		result.NilaiKursus, err = nilaiKursusRepo.UpdateNilaiKursus(existing)
+		return result, err
+	}
+
+	result.Created = true
+	result.NilaiKursus, err = nilaiKursusRepo.CreateNilaiKursus(entity.NilaiKursus{
+		NilaiTipeUjian: result.NilaiFinal,
+		IdKursus:       kursusID,
+		IdSiswa:        siswaID,
+		IdTipeUjian:    tipeUjianID,
+	})
+	return result, err
+}
+
+// SaveNilai creates or updates the final nilai of the siswa from the sum of their nilai_kursus
+func (s *nilaiService) SaveNilai(kursusID uint64, siswaID uint64) (NilaiResult, error) {
+	if _, err := s.siswaRepository.GetSiswaByID(siswaID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return NilaiResult{}, ErrSiswaTidakAda
+		}
+		return NilaiResult{}, err
+	}
+
+	var result NilaiResult
+	err := s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = s.upsertNilai(tx, kursusID, siswaID, false)
+		return err
+	})
+	return result, err
+}
+
+// UpdateNilai recalculates a final nilai that must already exist
+func (s *nilaiService) UpdateNilai(kursusID uint64, siswaID uint64) (NilaiResult, error) {
+	var result NilaiResult
+	err := s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = s.upsertNilai(tx, kursusID, siswaID, true)
+		return err
+	})
+	return result, err
+}
+
+// RecalculateNilai recalculates the final nilai of every siswa that has nilai_kursus in the kursus
+func (s *nilaiService) RecalculateNilai(kursusID uint64) ([]NilaiResult, error) {
+	var results []NilaiResult
+	err := s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		siswaIDs, err := s.nilaiKursusRepository.WithTx(tx).GetSiswaIDsByKursus(kursusID)
+		if err != nil {
+			return err
+		}
+
+		for _, siswaID := range siswaIDs {
+			result, err := s.upsertNilai(tx, kursusID, siswaID, false)
+			if errors.Is(err, ErrNilaiKursusKosong) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			results = append(results, result)
+		}
+		return nil
+	})
+	return results, err
+}
+
+// upsertNilai stores SUM(nilai_kursus) as nilai_total.
+// With mustExist, a missing nilai is an error instead of being created.
+func (s *nilaiService) upsertNilai(tx *gorm.DB, kursusID uint64, siswaID uint64, mustExist bool) (NilaiResult, error) {
+	nilaiRepo := s.nilaiRepository.WithTx(tx)
+
+	existing, err := nilaiRepo.FindNilai(kursusID, siswaID)
+	found := err == nil
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return NilaiResult{}, err
+	}
+	if !found && mustExist {
+		return NilaiResult{}, ErrNilaiTidakAda
+	}
+
+	nilaiKursus, err := s.nilaiKursusRepository.WithTx(tx).GetNilaiKursusByKursusAndSiswa(kursusID, siswaID)
+	if err != nil {
+		return NilaiResult{}, err
+	}
+	if len(nilaiKursus) == 0 {
+		return NilaiResult{}, ErrNilaiKursusKosong
+	}
+
+	var totalNilai float64
+	for _, nilai := range nilaiKursus {
+		totalNilai += nilai.NilaiTipeUjian
+	}
+
+	now := time.Now()
+	result := NilaiResult{NewTotal: totalNilai}
+	if found {
+		result.PreviousTotal = existing.NilaiTotal
+		existing.NilaiTotal = totalNilai
+		existing.UpdatedAt = now
+		result.Nilai, err = nilaiRepo.UpdateNilai(existing)
+		return result, err
+	}
+
+	result.Created = true
+	result.Nilai, err = nilaiRepo.CreateNilai(entity.Nilai{
+		IdKursus:   kursusID,
+		IdSiswa:    siswaID,
+		NilaiTotal: totalNilai,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+	return result, err
+}
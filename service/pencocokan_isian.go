@@ -0,0 +1,91 @@
+// service/pencocokan_isian.go
+package service
+
+import (
+	"cbt-api/entity"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// cocokJawabanIsian reports whether the siswa's answer matches one of the accepted variants, i.e.
+// the jawaban_soal rows of the soal marked Benar
+func cocokJawabanIsian(jawaban string, variasi []entity.JawabanSoal) bool {
+	for _, kunci := range variasi {
+		if kunci.Benar && cocokVariasi(jawaban, kunci) {
+			return true
+		}
+	}
+	return false
+}
+
+func cocokVariasi(jawaban string, kunci entity.JawabanSoal) bool {
+	switch kunci.TipePencocokan {
+	case entity.PencocokanAngka:
+		return cocokAngka(jawaban, kunci.Jawaban, kunci.ToleransiAngka)
+	case entity.PencocokanRegex:
+		return cocokRegex(jawaban, kunci.Jawaban, !kunci.BedakanHurufBesar)
+	default:
+		return normalisasiJawaban(jawaban, kunci) == normalisasiJawaban(kunci.Jawaban, kunci)
+	}
+}
+
+// normalisasiJawaban trims and collapses whitespace, drops punctuation and symbols unless
+// PertahankanTandaBaca is set, and lowercases unless BedakanHurufBesar is set
+func normalisasiJawaban(teks string, kunci entity.JawabanSoal) string {
+	if !kunci.BedakanHurufBesar {
+		teks = strings.ToLower(teks)
+	}
+	if !kunci.PertahankanTandaBaca {
+		teks = strings.Map(func(r rune) rune {
+			if unicode.IsPunct(r) || unicode.IsSymbol(r) {
+				return ' '
+			}
+			return r
+		}, teks)
+	}
+	return strings.Join(strings.Fields(teks), " ")
+}
+
+// cocokAngka compares both sides as numbers; a decimal comma ("3,5") is accepted as well
+func cocokAngka(jawaban string, kunci string, toleransi float64) bool {
+	nilaiJawaban, err := parseAngka(jawaban)
+	if err != nil {
+		return false
+	}
+	nilaiKunci, err := parseAngka(kunci)
+	if err != nil {
+		return false
+	}
+	return math.Abs(nilaiJawaban-nilaiKunci) <= math.Abs(toleransi)
+}
+
+// parseAngka parses a number written with a decimal point or comma; NaN and infinities are refused
+func parseAngka(teks string) (float64, error) {
+	teks = strings.ReplaceAll(strings.TrimSpace(teks), ",", ".")
+	nilai, err := strconv.ParseFloat(teks, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(nilai) || math.IsInf(nilai, 0) {
+		return 0, fmt.Errorf("angka %q tidak valid", teks)
+	}
+	return nilai, nil
+}
+
+// cocokRegex requires the whole (trimmed) answer to match the pattern. Patterns that do not
+// compile never match, so the answer falls back to manual grading.
+func cocokRegex(jawaban string, pola string, abaikanHurufBesar bool) bool {
+	prefix := "^(?:"
+	if abaikanHurufBesar {
+		prefix = "(?i)" + prefix
+	}
+	re, err := regexp.Compile(prefix + pola + ")$")
+	if err != nil {
+		return false
+	}
+	return re.MatchString(strings.TrimSpace(jawaban))
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"cbt-api/entity"
+	"testing"
+)
+
+func TestCocokVariasi(t *testing.T) {
+	teks := entity.JawabanSoal{TipePencocokan: entity.PencocokanTeks}
+	tests := []struct {
+		name    string
+		jawaban string
+		kunci   entity.JawabanSoal
+		want    bool
+	}{
+		{"same text", "Jakarta", withJawaban(teks, "Jakarta"), true},
+		{"case and spacing ignored", "  jakarta   raya ", withJawaban(teks, "Jakarta Raya"), true},
+		{"punctuation ignored", "Jakarta!", withJawaban(teks, "jakarta."), true},
+		{"different text", "Bandung", withJawaban(teks, "Jakarta"), false},
+		{"case kept", "jakarta", entity.JawabanSoal{TipePencocokan: entity.PencocokanTeks, Jawaban: "Jakarta", BedakanHurufBesar: true}, false},
+		{"punctuation kept", "H2O", entity.JawabanSoal{TipePencocokan: entity.PencocokanTeks, Jawaban: "H2O.", PertahankanTandaBaca: true}, false},
+		{"empty type means text", "jakarta", entity.JawabanSoal{Jawaban: "Jakarta"}, true},
+
+		{"number", "3.5", entity.JawabanSoal{TipePencocokan: entity.PencocokanAngka, Jawaban: "3.5"}, true},
+		{"decimal comma", "3,5", entity.JawabanSoal{TipePencocokan: entity.PencocokanAngka, Jawaban: "3.50"}, true},
+		{"within tolerance", "3.14", entity.JawabanSoal{TipePencocokan: entity.PencocokanAngka, Jawaban: "3.1416", ToleransiAngka: 0.01}, true},
+		{"outside tolerance", "3.1", entity.JawabanSoal{TipePencocokan: entity.PencocokanAngka, Jawaban: "3.1416", ToleransiAngka: 0.01}, false},
+		{"negative tolerance counts as positive", "10.5", entity.JawabanSoal{TipePencocokan: entity.PencocokanAngka, Jawaban: "10", ToleransiAngka: -1}, true},
+		{"not a number", "tiga", entity.JawabanSoal{TipePencocokan: entity.PencocokanAngka, Jawaban: "3"}, false},
+		{"key not a number", "3", entity.JawabanSoal{TipePencocokan: entity.PencocokanAngka, Jawaban: "tiga"}, false},
+
+		{"regex whole answer", "warna merah", entity.JawabanSoal{TipePencocokan: entity.PencocokanRegex, Jawaban: "warna (merah|biru)"}, true},
+		{"regex partial match refused", "bukan warna merah", entity.JawabanSoal{TipePencocokan: entity.PencocokanRegex, Jawaban: "warna (merah|biru)"}, false},
+		{"regex alternation anchored", "ab", entity.JawabanSoal{TipePencocokan: entity.PencocokanRegex, Jawaban: "a|ab"}, true},
+		{"regex ignores case", "MERAH", entity.JawabanSoal{TipePencocokan: entity.PencocokanRegex, Jawaban: "merah"}, true},
+		{"regex keeps case", "MERAH", entity.JawabanSoal{TipePencocokan: entity.PencocokanRegex, Jawaban: "merah", BedakanHurufBesar: true}, false},
+		{"invalid regex", "(", entity.JawabanSoal{TipePencocokan: entity.PencocokanRegex, Jawaban: "("}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cocokVariasi(tt.jawaban, tt.kunci); got != tt.want {
+				t.Errorf("cocokVariasi(%q, %q) = %v, want %v", tt.jawaban, tt.kunci.Jawaban, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCocokJawabanIsian(t *testing.T) {
+	variasi := []entity.JawabanSoal{
+		{Jawaban: "Bandung", TipePencocokan: entity.PencocokanTeks},
+		{Jawaban: "Jakarta", TipePencocokan: entity.PencocokanTeks, Benar: true},
+		{Jawaban: "DKI Jakarta", TipePencocokan: entity.PencocokanTeks, Benar: true},
+	}
+	tests := []struct {
+		jawaban string
+		want    bool
+	}{
+		{"jakarta", true},
+		{"dki jakarta", true},
+		{"Bandung", false}, // variasi yang tidak ditandai benar diabaikan
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := cocokJawabanIsian(tt.jawaban, variasi); got != tt.want {
+			t.Errorf("cocokJawabanIsian(%q) = %v, want %v", tt.jawaban, got, tt.want)
+		}
+	}
+}
+
+func TestParseAngka(t *testing.T) {
+	tests := []struct {
+		teks    string
+		want    float64
+		wantErr bool
+	}{
+		{"2", 2, false},
+		{" 2.5 ", 2.5, false},
+		{"2,5", 2.5, false},
+		{"-1", -1, false},
+		{"", 0, true},
+		{"dua", 0, true},
+		{"NaN", 0, true},
+		{"nan", 0, true},
+		{"Inf", 0, true},
+		{"-Infinity", 0, true},
+		{"1e400", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseAngka(tt.teks)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseAngka(%q) = %v, %v; want %v, error %v", tt.teks, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func withJawaban(kunci entity.JawabanSoal, jawaban string) entity.JawabanSoal {
+	kunci.Jawaban = jawaban
+	return kunci
+}
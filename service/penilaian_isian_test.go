@@ -0,0 +1,105 @@
+package service
+
+import (
+	"cbt-api/entity"
+	"cbt-api/repository"
+	"errors"
+	"testing"
+)
+
+func TestNilaiJawabanIsian(t *testing.T) {
+	env := newLingkunganTest(t)
+	ujian := env.buatUjian(t, nil)
+	isian, _ := env.buatSoal(t, ujian, entity.TipeSoalIsian, 20, entity.JawabanSoal{Jawaban: "Jakarta", Benar: true})
+	pg, opsi := env.buatSoal(t, ujian, entity.TipeSoalPilihanBerganda, 80,
+		entity.JawabanSoal{Jawaban: "A", Benar: true}, entity.JawabanSoal{Jawaban: "B"})
+	const siswaID, guruID = 7, 3
+
+	_, attempt, err := env.ujianAttempt.StartAttempt(ujian.IdUjian, siswaID, "masuk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := env.jawabanSiswa.CreateBatchJawabanSiswa([]entity.JawabanSiswa{
+		{IdSoal: isian.IdSoal, IdSiswa: siswaID, JawabanText: "Batavia", IdUjianAttempt: &attempt.IdUjianAttempt},
+		{IdSoal: pg.IdSoal, IdSiswa: siswaID, IdJawabanSoal: opsi[0].IdJawabanSoal, IdUjianAttempt: &attempt.IdUjianAttempt},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jawabanIsian, jawabanPG := saved[0], saved[1]
+
+	if _, err := env.nilai.NilaiJawabanIsian(jawabanIsian.IdJawabanSiswa, 10, "", guruID); !errors.Is(err, ErrJawabanBelumDisubmit) {
+		t.Fatalf("grading before submit = %v, want ErrJawabanBelumDisubmit", err)
+	}
+
+	_, hasil, err := env.ujianAttempt.FinishAttempt(ujian.IdUjian, siswaID, "keluar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hasil.NilaiUjian != 80 || hasil.JumlahMenungguPenilaian != 1 {
+		t.Fatalf("after submit nilai %v, menunggu %d; want 80, 1", hasil.NilaiUjian, hasil.JumlahMenungguPenilaian)
+	}
+
+	if _, err := env.nilai.NilaiJawabanIsian(jawabanIsian.IdJawabanSiswa, 25, "", guruID); !errors.Is(err, ErrNilaiManualTidakValid) {
+		t.Errorf("score above nilai_per_soal = %v, want ErrNilaiManualTidakValid", err)
+	}
+	if _, err := env.nilai.NilaiJawabanIsian(jawabanPG.IdJawabanSiswa, 10, "", guruID); !errors.Is(err, ErrBukanJawabanIsian) {
+		t.Errorf("grading a Pilihan_Berganda answer = %v, want ErrBukanJawabanIsian", err)
+	}
+
+	graded, err := env.nilai.NilaiJawabanIsian(jawabanIsian.IdJawabanSiswa, 15, "Nama lama Jakarta", guruID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if graded == nil || graded.NilaiUjian != 95 || graded.JumlahMenungguPenilaian != 0 {
+		t.Fatalf("after grading hasil = %+v, want nilai 95 and nothing waiting", graded)
+	}
+	var tipeNilai entity.TipeNilai
+	if err := env.db.Where("id_ujian = ? AND id_siswa = ?", ujian.IdUjian, siswaID).Take(&tipeNilai).Error; err != nil {
+		t.Fatal(err)
+	}
+	if tipeNilai.Nilai != 95 {
+		t.Errorf("tipe_nilai.nilai = %v, want 95", tipeNilai.Nilai)
+	}
+}
+
+func TestUpsertJawabanSiswaClearsGrading(t *testing.T) {
+	env := newLingkunganTest(t)
+	ujian := env.buatUjian(t, nil)
+	isian, _ := env.buatSoal(t, ujian, entity.TipeSoalIsian, 20)
+	attempt := entity.UjianAttempt{IdUjian: ujian.IdUjian, IdSiswa: 7, Status: entity.StatusAttemptBerlangsung}
+	env.buat(t, &attempt)
+
+	repo := repository.NewJawabanSiswaRepository(env.db)
+	jawaban, err := repo.UpsertJawabanSiswa(entity.JawabanSiswa{
+		IdSoal: isian.IdSoal, IdSiswa: 7, JawabanText: "lama", IdUjianAttempt: &attempt.IdUjianAttempt,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nilai, guru := 10.0, uint64(3)
+	jawaban.NilaiManual, jawaban.Feedback, jawaban.DinilaiOleh = &nilai, "bagus", &guru
+	if err := repo.SaveNilaiManual(jawaban); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nilai yang dikirim siswa sendiri juga tidak boleh tersimpan
+	palsu := 20.0
+	changed, err := repo.UpsertJawabanSiswa(entity.JawabanSiswa{
+		IdSoal: isian.IdSoal, IdSiswa: 7, JawabanText: "baru", IdUjianAttempt: &attempt.IdUjianAttempt, NilaiManual: &palsu,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := repo.GetJawabanSiswaByID(changed.IdJawabanSiswa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.IdJawabanSiswa != jawaban.IdJawabanSiswa || stored.JawabanText != "baru" {
+		t.Fatalf("upsert stored %+v, want the same row with the new answer", stored)
+	}
+	if stored.NilaiManual != nil || stored.Feedback != "" || stored.DinilaiOleh != nil || stored.DinilaiPada != nil {
+		t.Errorf("grading kept after the answer changed: nilai %v, feedback %q, dinilai_oleh %v",
+			stored.NilaiManual, stored.Feedback, stored.DinilaiOleh)
+	}
+}
@@ -0,0 +1,386 @@
+// service/penilaian_ujian.go
+package service
+
+import (
+	"cbt-api/entity"
+	"cbt-api/repository"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrBukanJawabanIsian     = errors.New("jawaban bukan jawaban soal Isian pada ujian")
+	ErrNilaiManualTidakValid = errors.New("nilai harus di antara 0 dan nilai per soal")
+	ErrJawabanBelumDisubmit  = errors.New("jawaban baru dapat dinilai setelah siswa menyelesaikan ujian")
+)
+
+// NilaiSoal is the grading result of one soal in a submitted ujian
+type NilaiSoal struct {
+	IdSoal            uint64   `json:"id_soal"`
+	IdJawabanSoal     uint64   `json:"id_jawaban_soal"`
+	Pilihan           []uint64 `json:"pilihan,omitempty"` // opsi yang dipilih pada soal Pilihan_Kompleks
+	Isian             bool     `json:"isian"`
+	Dijawab           bool     `json:"dijawab"`
+	Benar             bool     `json:"benar"`
+	DinilaiOtomatis   bool     `json:"dinilai_otomatis"`   // jawaban Isian cocok dengan salah satu variasi kunci
+	MenungguPenilaian bool     `json:"menunggu_penilaian"` // jawaban Isian yang belum dinilai guru
+	NilaiPerSoal      float64  `json:"nilai_per_soal"`
+	Nilai             float64  `json:"nilai"`
+	Feedback          string   `json:"feedback,omitempty"`
+
+	proporsi float64 // bagian poin soal yang diperoleh, 0..1; dipakai analisis butir
+}
+
+// HasilUjian is the breakdown returned when a siswa submits an ujian. Persentase, NilaiKursus and
+// NilaiAkhir stay nil when the kursus has no persentase for the tipe ujian yet.
+type HasilUjian struct {
+	Attempt                 entity.UjianAttempt `json:"-"`
+	IdUjian                 uint64              `json:"id_ujian"`
+	IdSiswa                 uint64              `json:"id_siswa"`
+	IdKursus                uint64              `json:"id_kursus"`
+	IdTipeUjian             uint64              `json:"id_tipe_ujian"`
+	JumlahSoal              int                 `json:"jumlah_soal"`
+	JumlahDijawab           int                 `json:"jumlah_dijawab"`
+	JumlahBenar             int                 `json:"jumlah_benar"`
+	JumlahMenungguPenilaian int                 `json:"jumlah_menunggu_penilaian"`
+	NilaiMaksimal           float64             `json:"nilai_maksimal"`
+	NilaiMentah             float64             `json:"nilai_mentah"` // jumlah nilai soal sebelum batas bawah nol dan skala grade
+	NilaiUjian              float64             `json:"nilai_ujian"`
+	Soal                    []NilaiSoal         `json:"soal,omitempty"`
+	Persentase              *float64            `json:"persentase"`
+	NilaiKursus             *float64            `json:"nilai_kursus"`
+	NilaiAkhir              *float64            `json:"nilai_akhir"`
+}
+
+// AntrianIsian is one Isian answer in the manual grading queue of an ujian
+type AntrianIsian struct {
+	IdJawabanSiswa uint64     `json:"id_jawaban_siswa"`
+	IdUjianAttempt *uint64    `json:"id_ujian_attempt"`
+	IdSoal         uint64     `json:"id_soal"`
+	Soal           string     `json:"soal"`
+	NilaiPerSoal   float64    `json:"nilai_per_soal"`
+	IdSiswa        uint64     `json:"id_siswa"`
+	NamaSiswa      string     `json:"nama_siswa"`
+	JawabanSiswa   string     `json:"jawaban_siswa"`
+	CocokOtomatis  bool       `json:"cocok_otomatis"`
+	NilaiManual    *float64   `json:"nilai_manual"`
+	Feedback       string     `json:"feedback"`
+	DinilaiOleh    *uint64    `json:"dinilai_oleh"`
+	DinilaiPada    *time.Time `json:"dinilai_pada"`
+}
+
+// SubmitAttempt closes the attempt and, in the same transaction, grades the answers, stores the
+// ujian score as tipe_nilai, recalculates the weighted nilai_kursus of the tipe ujian and the
+// final nilai of the kursus. Either everything is saved or nothing is.
+func (s *nilaiService) SubmitAttempt(ujian entity.Ujian, attempt entity.UjianAttempt) (HasilUjian, error) {
+	var hasil HasilUjian
+	now := time.Now()
+	err := s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		closed, err := s.ujianAttemptRepository.WithTx(tx).CloseAttempt(attempt.IdUjianAttempt, now)
+		if err != nil {
+			return err
+		}
+		if !closed {
+			return ErrAttemptSelesai
+		}
+
+		hasil, err = s.simpanHasilUjian(tx, ujian, attempt.IdSiswa)
+		return err
+	})
+	if err != nil {
+		return hasil, err
+	}
+
+	// Rincian benar/salah per soal hanya ditampilkan ke siswa jika pembahasan ujian diaktifkan
+	if ujian.StatusJawaban != entity.StatusAktif {
+		hasil.Soal = nil
+	}
+
+	attempt.Status = entity.StatusAttemptSelesai
+	attempt.WaktuSubmit = &now
+	attempt.UpdatedAt = now
+	hasil.Attempt = attempt
+	return hasil, nil
+}
+
+// GetAntrianIsian lists the Isian answers of an ujian for manual grading. sudahDinilai filters on
+// graded/ungraded answers; nil returns both. An answer matching one of the accepted variants counts
+// as graded, so it only leaves the queue's "belum" view without a guru touching it.
+func (s *nilaiService) GetAntrianIsian(ujianID uint64, sudahDinilai *bool) ([]AntrianIsian, error) {
+	ujian, err := s.ujianRepository.GetUjianByID(ujianID)
+	if err != nil {
+		return nil, err
+	}
+	jawabanList, err := s.jawabanSiswaRepository.GetAntrianIsian(ujianID, nil)
+	if err != nil {
+		return nil, err
+	}
+	kunci, err := s.jawabanSoalRepository.FindByIdUjian(ujianID)
+	if err != nil {
+		return nil, err
+	}
+	variasi := kunciPerSoal(kunci)
+
+	antrian := make([]AntrianIsian, 0, len(jawabanList))
+	for _, jawaban := range jawabanList {
+		cocok := cocokJawabanIsian(jawaban.JawabanText, variasi[jawaban.IdSoal])
+		if sudahDinilai != nil && *sudahDinilai != (jawaban.NilaiManual != nil || cocok) {
+			continue
+		}
+		antrian = append(antrian, AntrianIsian{
+			IdJawabanSiswa: jawaban.IdJawabanSiswa,
+			IdUjianAttempt: jawaban.IdUjianAttempt,
+			IdSoal:         jawaban.IdSoal,
+			Soal:           jawaban.Soal.Soal,
+			NilaiPerSoal:   poinSoal(ujian, jawaban.Soal),
+			IdSiswa:        jawaban.IdSiswa,
+			NamaSiswa:      jawaban.Siswa.NamaSiswa,
+			JawabanSiswa:   jawaban.JawabanText,
+			CocokOtomatis:  cocok,
+			NilaiManual:    jawaban.NilaiManual,
+			Feedback:       jawaban.Feedback,
+			DinilaiOleh:    jawaban.DinilaiOleh,
+			DinilaiPada:    jawaban.DinilaiPada,
+		})
+	}
+	return antrian, nil
+}
+
+// NilaiJawabanIsian stores the guru's score and feedback for one Isian answer. The score ranges from 0
+// to the soal's points under the ujian's policy; guruID 0 (admin without a guru profile) leaves
+// dinilai_oleh empty. Answers can only be graded once the siswa has submitted the ujian, so a grade
+// never stays attached to an answer that is changed afterwards; tipe_nilai, nilai_kursus and nilai
+// are recalculated in the same transaction and the new breakdown is returned.
+func (s *nilaiService) NilaiJawabanIsian(jawabanID uint64, nilai float64, feedback string, guruID uint64) (*HasilUjian, error) {
+	jawaban, err := s.jawabanSiswaRepository.GetJawabanSiswaByID(jawabanID)
+	if err != nil {
+		return nil, err
+	}
+	ujianID, err := s.ujianJawaban(jawaban)
+	if err != nil {
+		return nil, err
+	}
+	if ujianID == 0 {
+		return nil, ErrBukanJawabanIsian
+	}
+
+	ujian, err := s.ujianRepository.GetUjianByID(ujianID)
+	if err != nil {
+		return nil, err
+	}
+	soalList, err := s.soalRepository.FindByIdUjianWithTipeSoal(ujian.IdUjian)
+	if err != nil {
+		return nil, err
+	}
+	var soal *entity.Soal
+	for i := range soalList {
+		if soalList[i].IdSoal == jawaban.IdSoal {
+			soal = &soalList[i]
+		}
+	}
+	if soal == nil || soal.TipeSoal.NamaTipeUjian != entity.TipeSoalIsian {
+		return nil, ErrBukanJawabanIsian
+	}
+	if nilai < 0 || nilai > poinSoal(ujian, *soal) {
+		return nil, ErrNilaiManualTidakValid
+	}
+
+	sudahSubmit, err := s.sudahSubmit(jawaban)
+	if err != nil {
+		return nil, err
+	}
+	if !sudahSubmit {
+		return nil, ErrJawabanBelumDisubmit
+	}
+
+	now := time.Now()
+	jawaban.NilaiManual = &nilai
+	jawaban.Feedback = feedback
+	jawaban.DinilaiOleh = nil
+	if guruID != 0 {
+		jawaban.DinilaiOleh = &guruID
+	}
+	jawaban.DinilaiPada = &now
+
+	var hasil *HasilUjian
+	err = s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		if err := s.jawabanSiswaRepository.WithTx(tx).SaveNilaiManual(jawaban); err != nil {
+			return err
+		}
+
+		result, err := s.simpanHasilUjian(tx, ujian, jawaban.IdSiswa)
+		hasil = &result
+		return err
+	})
+	return hasil, err
+}
+
+// ujianJawaban returns the ujian the answer was given in: the ujian of its attempt, which also covers
+// bank soal, or for answers saved before attempts existed the soal's own ujian. 0 means a latihan answer.
+func (s *nilaiService) ujianJawaban(jawaban entity.JawabanSiswa) (uint64, error) {
+	if jawaban.IdUjianAttempt == nil {
+		return jawaban.Soal.IdUjian, nil
+	}
+	attempt, err := s.ujianAttemptRepository.GetAttemptByID(*jawaban.IdUjianAttempt)
+	if err != nil {
+		return 0, err
+	}
+	return attempt.IdUjian, nil
+}
+
+// sudahSubmit reports whether the answer's ujian has already been graded for the siswa: its attempt
+// is Selesai, or, for answers saved before attempts existed, a tipe_nilai is already stored
+func (s *nilaiService) sudahSubmit(jawaban entity.JawabanSiswa) (bool, error) {
+	if jawaban.IdUjianAttempt != nil {
+		attempt, err := s.ujianAttemptRepository.GetAttemptByID(*jawaban.IdUjianAttempt)
+		if err != nil {
+			return false, err
+		}
+		return attempt.Status == entity.StatusAttemptSelesai, nil
+	}
+	return s.HasAttempted(jawaban.Soal.IdUjian, jawaban.IdSiswa)
+}
+
+// simpanHasilUjian grades the ujian and stores tipe_nilai, nilai_kursus and nilai inside tx
+func (s *nilaiService) simpanHasilUjian(tx *gorm.DB, ujian entity.Ujian, siswaID uint64) (HasilUjian, error) {
+	hasil, err := s.hitungNilaiUjian(s.jawabanSiswaRepository.WithTx(tx), ujian, siswaID)
+	if err != nil {
+		return hasil, err
+	}
+	hasil.IdKursus = ujian.IdKursus
+	hasil.IdTipeUjian = ujian.IdTipeUjian
+
+	_, err = upsertTipeNilai(s.tipeNilaiRepository.WithTx(tx), siswaID, ujian.IdUjian, ujian.IdTipeUjian, hasil.NilaiUjian)
+	if err != nil {
+		return hasil, err
+	}
+
+	nilaiKursus, err := s.upsertNilaiKursus(tx, ujian.IdKursus, siswaID, ujian.IdTipeUjian, false)
+	if errors.Is(err, ErrPersentaseTidakAda) {
+		return hasil, nil
+	}
+	if err != nil {
+		return hasil, err
+	}
+	hasil.Persentase = &nilaiKursus.Persentase
+	hasil.NilaiKursus = &nilaiKursus.NilaiFinal
+
+	nilai, err := s.upsertNilai(tx, ujian.IdKursus, siswaID, false)
+	if err != nil {
+		return hasil, err
+	}
+	hasil.NilaiAkhir = &nilai.NewTotal
+	return hasil, nil
+}
+
+// hitungNilaiUjian grades the siswa's answers in the ujian; it is the single place an ujian score is computed
+func (s *nilaiService) hitungNilaiUjian(jawabanSiswaRepo repository.JawabanSiswaRepository, ujian entity.Ujian, siswaID uint64) (HasilUjian, error) {
+	hasil := HasilUjian{IdUjian: ujian.IdUjian, IdSiswa: siswaID}
+
+	soalList, err := s.soalRepository.FindByIdUjianWithTipeSoal(ujian.IdUjian)
+	if err != nil {
+		return hasil, err
+	}
+	kunci, err := s.jawabanSoalRepository.FindByIdUjian(ujian.IdUjian)
+	if err != nil {
+		return hasil, err
+	}
+	jawabanList, err := jawabanSiswaRepo.FindByUjianAndSiswa(ujian.IdUjian, siswaID)
+	if err != nil {
+		return hasil, err
+	}
+
+	nilaiUjian(&hasil, ujian, soalList, kunci, jawabanList)
+	return hasil, nil
+}
+
+// nilaiUjian grades every soal of the ujian and applies the ujian's scoring policy. Pilihan soal are
+// checked against the answer key and Pilihan_Kompleks soal may earn partial credit following their
+// ModePenilaian. Isian soal use the guru's manual score when there is one, otherwise full marks when
+// the answer matches an accepted variant, otherwise they wait for manual grading and score zero
+// until graded. When a soal was answered more than once, the latest jawaban counts.
+func nilaiUjian(hasil *HasilUjian, ujian entity.Ujian, soalList []entity.Soal, kunci []entity.JawabanSoal, jawabanList []entity.JawabanSiswa) {
+	opsi := make(map[uint64]entity.JawabanSoal)
+	for _, jawabanSoal := range kunci {
+		opsi[jawabanSoal.IdJawabanSoal] = jawabanSoal
+	}
+	opsiPerSoal := kunciPerSoal(kunci)
+
+	jawabanTerakhir := make(map[uint64]entity.JawabanSiswa)
+	for _, jawaban := range jawabanList {
+		jawabanTerakhir[jawaban.IdSoal] = jawaban
+	}
+
+	hasil.Soal = make([]NilaiSoal, 0, len(soalList))
+	for _, soal := range soalList {
+		nilaiSoal := NilaiSoal{
+			IdSoal:       soal.IdSoal,
+			Isian:        soal.TipeSoal.NamaTipeUjian == entity.TipeSoalIsian,
+			NilaiPerSoal: poinSoal(ujian, soal),
+		}
+		jawaban, ok := jawabanTerakhir[soal.IdSoal]
+
+		// proporsi is the share of the soal's points earned by the answer, 0..1
+		var proporsi float64
+		switch {
+		case !ok:
+		case nilaiSoal.Isian:
+			nilaiSoal.Dijawab = strings.TrimSpace(jawaban.JawabanText) != ""
+			nilaiSoal.Feedback = jawaban.Feedback
+			if jawaban.NilaiManual != nil {
+				if nilaiSoal.NilaiPerSoal > 0 {
+					proporsi = *jawaban.NilaiManual / nilaiSoal.NilaiPerSoal
+				}
+			} else if nilaiSoal.Dijawab && cocokJawabanIsian(jawaban.JawabanText, opsiPerSoal[soal.IdSoal]) {
+				nilaiSoal.DinilaiOtomatis = true
+				proporsi = 1
+			} else if nilaiSoal.Dijawab {
+				nilaiSoal.MenungguPenilaian = true
+			}
+		case soal.TipeSoal.NamaTipeUjian == entity.TipeSoalPilihanKompleks:
+			for _, p := range jawaban.Pilihan {
+				nilaiSoal.Pilihan = append(nilaiSoal.Pilihan, p.IdJawabanSoal)
+			}
+			nilaiSoal.Dijawab = len(jawaban.Pilihan) > 0
+			proporsi = proporsiPilihanKompleks(soal, opsiPerSoal[soal.IdSoal], jawaban.Pilihan)
+		case jawaban.IdJawabanSoal != 0:
+			nilaiSoal.IdJawabanSoal = jawaban.IdJawabanSoal
+			nilaiSoal.Dijawab = true
+			// Opsi milik soal lain tidak pernah dihitung benar
+			pilihan, ok := opsi[jawaban.IdJawabanSoal]
+			if ok && pilihan.IdSoal == soal.IdSoal && pilihan.Benar {
+				proporsi = 1
+			}
+		}
+		nilaiSoal.proporsi = proporsi
+		nilaiSoal.Benar = proporsi >= 1
+		nilaiSoal.Nilai = nilaiSoalDenganAturan(ujian, nilaiSoal, proporsi)
+
+		if nilaiSoal.Benar {
+			hasil.JumlahBenar++
+		}
+		if nilaiSoal.Dijawab {
+			hasil.JumlahDijawab++
+		}
+		if nilaiSoal.MenungguPenilaian {
+			hasil.JumlahMenungguPenilaian++
+		}
+		hasil.NilaiMaksimal += nilaiSoal.NilaiPerSoal
+		hasil.NilaiMentah += nilaiSoal.Nilai
+		hasil.Soal = append(hasil.Soal, nilaiSoal)
+	}
+	hasil.JumlahSoal = len(soalList)
+	terapkanAturanUjian(hasil, ujian)
+}
+
+// kunciPerSoal groups the jawaban_soal rows of an ujian by soal
+func kunciPerSoal(kunci []entity.JawabanSoal) map[uint64][]entity.JawabanSoal {
+	perSoal := make(map[uint64][]entity.JawabanSoal)
+	for _, jawabanSoal := range kunci {
+		perSoal[jawabanSoal.IdSoal] = append(perSoal[jawabanSoal.IdSoal], jawabanSoal)
+	}
+	return perSoal
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"cbt-api/entity"
+	"errors"
+	"testing"
+)
+
+func TestFinishAttemptGradesAndStoresScore(t *testing.T) {
+	env := newLingkunganTest(t)
+	ujian := env.buatUjian(t, nil)
+	soal1, opsi1 := env.buatSoal(t, ujian, "Pilihan_Berganda", 40,
+		entity.JawabanSoal{Jawaban: "A", Benar: true}, entity.JawabanSoal{Jawaban: "B"})
+	soal2, opsi2 := env.buatSoal(t, ujian, "Pilihan_Berganda", 60,
+		entity.JawabanSoal{Jawaban: "A"}, entity.JawabanSoal{Jawaban: "B", Benar: true})
+	env.buat(t, &entity.Persentase{Persentase: 50, IdKursus: ujian.IdKursus, IdTipeUjian: ujian.IdTipeUjian})
+	const siswaID = 7
+
+	if _, _, err := env.ujianAttempt.FinishAttempt(ujian.IdUjian, siswaID, "keluar"); !errors.Is(err, ErrAttemptTidakAda) {
+		t.Fatalf("FinishAttempt before starting = %v, want ErrAttemptTidakAda", err)
+	}
+	if _, _, err := env.ujianAttempt.StartAttempt(ujian.IdUjian, siswaID, "masuk"); err != nil {
+		t.Fatalf("StartAttempt: %v", err)
+	}
+	_, err := env.jawabanSiswa.CreateBatchJawabanSiswa([]entity.JawabanSiswa{
+		{IdSoal: soal1.IdSoal, IdSiswa: siswaID, IdJawabanSoal: opsi1[0].IdJawabanSoal},
+		{IdSoal: soal2.IdSoal, IdSiswa: siswaID, IdJawabanSoal: opsi2[0].IdJawabanSoal},
+	})
+	if err != nil {
+		t.Fatalf("CreateBatchJawabanSiswa: %v", err)
+	}
+
+	if _, _, err := env.ujianAttempt.FinishAttempt(ujian.IdUjian, siswaID, "masuk"); !errors.Is(err, ErrPasswordUjianSalah) {
+		t.Fatalf("FinishAttempt with the entry password = %v, want ErrPasswordUjianSalah", err)
+	}
+	_, hasil, err := env.ujianAttempt.FinishAttempt(ujian.IdUjian, siswaID, "keluar")
+	if err != nil {
+		t.Fatalf("FinishAttempt: %v", err)
+	}
+	if hasil.NilaiUjian != 40 || hasil.JumlahBenar != 1 || hasil.JumlahDijawab != 2 || hasil.NilaiMaksimal != 100 {
+		t.Errorf("hasil = nilai %v, benar %d, dijawab %d, maksimal %v; want 40, 1, 2, 100",
+			hasil.NilaiUjian, hasil.JumlahBenar, hasil.JumlahDijawab, hasil.NilaiMaksimal)
+	}
+	if hasil.Attempt.Status != entity.StatusAttemptSelesai || hasil.Attempt.WaktuSubmit == nil {
+		t.Errorf("attempt = status %q, submit %v; want %q with a submit time",
+			hasil.Attempt.Status, hasil.Attempt.WaktuSubmit, entity.StatusAttemptSelesai)
+	}
+	// Nilai kursus = 40 * 50%, nilai akhir = jumlah nilai kursus
+	if hasil.NilaiKursus == nil || *hasil.NilaiKursus != 20 || hasil.NilaiAkhir == nil || *hasil.NilaiAkhir != 20 {
+		t.Errorf("nilai kursus %v, nilai akhir %v; want 20 and 20", hasil.NilaiKursus, hasil.NilaiAkhir)
+	}
+
+	var tipeNilai entity.TipeNilai
+	if err := env.db.Where("id_ujian = ? AND id_siswa = ?", ujian.IdUjian, siswaID).Take(&tipeNilai).Error; err != nil {
+		t.Fatalf("tipe_nilai not stored: %v", err)
+	}
+	if tipeNilai.Nilai != 40 {
+		t.Errorf("tipe_nilai.nilai = %v, want 40", tipeNilai.Nilai)
+	}
+	var nilai entity.Nilai
+	if err := env.db.Where("id_kursus = ? AND id_siswa = ?", ujian.IdKursus, siswaID).Take(&nilai).Error; err != nil || nilai.NilaiTotal != 20 {
+		t.Errorf("nilai = %v, %v; want nilai_total 20", nilai.NilaiTotal, err)
+	}
+
+	if _, _, err := env.ujianAttempt.FinishAttempt(ujian.IdUjian, siswaID, "keluar"); !errors.Is(err, ErrAttemptSelesai) {
+		t.Errorf("second FinishAttempt = %v, want ErrAttemptSelesai", err)
+	}
+	if _, err := env.jawabanSiswa.CreateJawabanSiswa(entity.JawabanSiswa{
+		IdSoal: soal2.IdSoal, IdSiswa: siswaID, IdJawabanSoal: opsi2[1].IdJawabanSoal,
+	}); err == nil {
+		t.Error("answer accepted after the attempt was submitted")
+	}
+}
+
+func TestNilaiUjian(t *testing.T) {
+	pg := entity.TipeSoal{NamaTipeUjian: entity.TipeSoalPilihanBerganda}
+	soalList := []entity.Soal{
+		{IdSoal: 1, NilaiPerSoal: 10, TipeSoal: pg},
+		{IdSoal: 2, NilaiPerSoal: 20, TipeSoal: pg},
+		{IdSoal: 3, NilaiPerSoal: 30, TipeSoal: pg},
+		{IdSoal: 4, NilaiPerSoal: 40, TipeSoal: pg},
+	}
+	kunci := []entity.JawabanSoal{
+		{IdJawabanSoal: 11, IdSoal: 1, Benar: true},
+		{IdJawabanSoal: 12, IdSoal: 1},
+		{IdJawabanSoal: 21, IdSoal: 2, Benar: true},
+		{IdJawabanSoal: 22, IdSoal: 2},
+		{IdJawabanSoal: 31, IdSoal: 3},
+		{IdJawabanSoal: 32, IdSoal: 3, Benar: true},
+	}
+	jawabanList := []entity.JawabanSiswa{
+		{IdSoal: 1, IdJawabanSoal: 12},
+		{IdSoal: 1, IdJawabanSoal: 11}, // jawaban terakhir yang dihitung
+		{IdSoal: 2, IdJawabanSoal: 11}, // opsi benar milik soal lain
+		{IdSoal: 3, IdJawabanSoal: 32},
+	}
+
+	var hasil HasilUjian
+	nilaiUjian(&hasil, entity.Ujian{Grade: 100}, soalList, kunci, jawabanList)
+
+	want := []struct {
+		nilai   float64
+		benar   bool
+		dijawab bool
+	}{{10, true, true}, {0, false, true}, {30, true, true}, {0, false, false}}
+	for i, w := range want {
+		got := hasil.Soal[i]
+		if got.Nilai != w.nilai || got.Benar != w.benar || got.Dijawab != w.dijawab {
+			t.Errorf("soal %d = nilai %v, benar %v, dijawab %v; want %v, %v, %v",
+				got.IdSoal, got.Nilai, got.Benar, got.Dijawab, w.nilai, w.benar, w.dijawab)
+		}
+	}
+	if hasil.NilaiUjian != 40 || hasil.NilaiMaksimal != 100 || hasil.JumlahSoal != 4 || hasil.JumlahBenar != 2 || hasil.JumlahDijawab != 3 {
+		t.Errorf("hasil = nilai %v, maksimal %v, soal %d, benar %d, dijawab %d; want 40, 100, 4, 2, 3",
+			hasil.NilaiUjian, hasil.NilaiMaksimal, hasil.JumlahSoal, hasil.JumlahBenar, hasil.JumlahDijawab)
+	}
+}
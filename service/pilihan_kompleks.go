@@ -0,0 +1,58 @@
+// service/pilihan_kompleks.go
+package service
+
+import (
+	"cbt-api/entity"
+)
+
+// proporsiPilihanKompleks returns the share (0..1) of the soal's points earned by the options chosen
+// for a Pilihan_Kompleks soal, following the soal's ModePenilaian. Chosen options that do not belong
+// to the soal are ignored.
+//
+//   - Semua_Benar: full marks only when exactly the correct options are chosen
+//   - Proporsional: correct options chosen / max(correct options, options chosen), so choosing
+//     every option never earns full marks
+//   - Penalti: (correct options chosen - wrong options chosen) / correct options, never below zero
+func proporsiPilihanKompleks(soal entity.Soal, opsiSoal []entity.JawabanSoal, pilihan []entity.JawabanSiswaPilihan) float64 {
+	dipilih := make(map[uint64]bool, len(pilihan))
+	for _, p := range pilihan {
+		dipilih[p.IdJawabanSoal] = true
+	}
+
+	var jumlahKunci, benarDipilih, salahDipilih int
+	for _, opsi := range opsiSoal {
+		if opsi.Benar {
+			jumlahKunci++
+		}
+		if !dipilih[opsi.IdJawabanSoal] {
+			continue
+		}
+		if opsi.Benar {
+			benarDipilih++
+		} else {
+			salahDipilih++
+		}
+	}
+	if jumlahKunci == 0 {
+		return 0
+	}
+
+	switch soal.ModePenilaian {
+	case entity.ModePenilaianProporsional:
+		pembagi := jumlahKunci
+		if benarDipilih+salahDipilih > pembagi {
+			pembagi = benarDipilih + salahDipilih
+		}
+		return float64(benarDipilih) / float64(pembagi)
+	case entity.ModePenilaianPenalti:
+		if benarDipilih <= salahDipilih {
+			return 0
+		}
+		return float64(benarDipilih-salahDipilih) / float64(jumlahKunci)
+	default:
+		if benarDipilih == jumlahKunci && salahDipilih == 0 {
+			return 1
+		}
+		return 0
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"cbt-api/entity"
+	"math"
+	"testing"
+)
+
+func TestProporsiPilihanKompleks(t *testing.T) {
+	// Opsi 1-3 benar, 4-5 salah
+	opsi := []entity.JawabanSoal{
+		{IdJawabanSoal: 1, Benar: true},
+		{IdJawabanSoal: 2, Benar: true},
+		{IdJawabanSoal: 3, Benar: true},
+		{IdJawabanSoal: 4},
+		{IdJawabanSoal: 5},
+	}
+	tests := []struct {
+		name                        string
+		dipilih                     []uint64
+		semuaBenar, propor, penalti float64
+	}{
+		{"all correct", []uint64{1, 2, 3}, 1, 1, 1},
+		{"some correct", []uint64{1, 2}, 0, 2.0 / 3, 2.0 / 3},
+		{"all correct plus a wrong one", []uint64{1, 2, 3, 4}, 0, 3.0 / 4, 2.0 / 3},
+		{"every option", []uint64{1, 2, 3, 4, 5}, 0, 3.0 / 5, 1.0 / 3},
+		{"as many wrong as correct", []uint64{1, 4}, 0, 1.0 / 3, 0},
+		{"only wrong", []uint64{4, 5}, 0, 0, 0},
+		{"nothing chosen", nil, 0, 0, 0},
+		{"options of another soal ignored", []uint64{1, 2, 3, 99}, 1, 1, 1},
+		{"duplicates counted once", []uint64{1, 1, 2, 2, 3}, 1, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pilihan []entity.JawabanSiswaPilihan
+			for _, id := range tt.dipilih {
+				pilihan = append(pilihan, entity.JawabanSiswaPilihan{IdJawabanSoal: id})
+			}
+			for mode, want := range map[string]float64{
+				entity.ModePenilaianSemuaBenar:   tt.semuaBenar,
+				entity.ModePenilaianProporsional: tt.propor,
+				entity.ModePenilaianPenalti:      tt.penalti,
+			} {
+				got := proporsiPilihanKompleks(entity.Soal{ModePenilaian: mode}, opsi, pilihan)
+				if math.Abs(got-want) > 1e-9 {
+					t.Errorf("%s = %v, want %v", mode, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestProporsiPilihanKompleksTanpaKunci(t *testing.T) {
+	opsi := []entity.JawabanSoal{{IdJawabanSoal: 1}, {IdJawabanSoal: 2}}
+	pilihan := []entity.JawabanSiswaPilihan{{IdJawabanSoal: 1}}
+	if got := proporsiPilihanKompleks(entity.Soal{ModePenilaian: entity.ModePenilaianProporsional}, opsi, pilihan); got != 0 {
+		t.Errorf("soal without a correct option = %v, want 0", got)
+	}
+}
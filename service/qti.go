@@ -0,0 +1,1171 @@
+// service/qti.go
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"cbt-api/entity"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPaketQTITidakValid = errors.New("paket QTI tidak valid")
+	ErrAlamatMediaDitolak = errors.New("alamat media bukan alamat publik")
+)
+
+const (
+	nsQTI   = "http://www.imsglobal.org/xsd/imsqti_v2p1"
+	nsIMSCP = "http://www.imsglobal.org/xsd/imscp_v1p1"
+
+	tipeResourceTest = "imsqti_test_xmlv2p1"
+	tipeResourceItem = "imsqti_item_xmlv2p1"
+
+	templateMatchCorrect = "http://www.imsglobal.org/question/qti_v2p1/rptemplates/match_correct"
+	templateMapResponse  = "http://www.imsglobal.org/question/qti_v2p1/rptemplates/map_response"
+
+	// batasUkuranMedia is the largest image put into an exported package
+	batasUkuranMedia     = 10 << 20
+	batasWaktuUnduhMedia = 15 * time.Second
+	batasRedirectMedia   = 5
+
+	// batasUkuranFileQTI and batasTotalFileQTI cap the uncompressed XML read from an imported package
+	batasUkuranFileQTI = 5 << 20
+	batasTotalFileQTI  = 50 << 20
+)
+
+// ImportQTI is an uploaded IMS QTI 2.1 package and the ujian its soal go into. Empty fields of Ujian
+// are taken from the package where it has them: the title, the time limit, shuffling and the grade,
+// which defaults to the sum of the item scores.
+type ImportQTI struct {
+	Ujian  entity.Ujian
+	Data   []byte
+	DryRun bool
+}
+
+// ItemQTITidakDidukung is an item of the package that was skipped because of its interaction type
+type ItemQTITidakDidukung struct {
+	Item      string `json:"item"`
+	Interaksi string `json:"interaksi"`
+}
+
+// KesalahanItemQTI lists the validation errors of one item of the package
+type KesalahanItemQTI struct {
+	Item  string   `json:"item"`
+	Pesan []string `json:"pesan"`
+}
+
+// HasilImportQTI reports what a QTI import did, or would do in dry-run mode
+type HasilImportQTI struct {
+	DryRun        bool                   `json:"dry_run"`
+	Disimpan      bool                   `json:"disimpan"`
+	Ujian         entity.Ujian           `json:"ujian"`
+	JumlahSoal    int                    `json:"jumlah_soal"`
+	TidakDidukung []ItemQTITidakDidukung `json:"tidak_didukung"`
+	Peringatan    []string               `json:"peringatan"`
+	Kesalahan     []KesalahanItemQTI     `json:"kesalahan"`
+	Soal          []SoalDenganJawaban    `json:"soal"`
+}
+
+// ExportQTI writes the ujian as an IMS QTI 2.1 package: imsmanifest.xml, an assessmentTest, one
+// assessmentItem per soal and the images referenced by Soal.ImageUrl. Images that cannot be
+// downloaded stay referenced by their URL and are reported in MediaGagal. Isian soal matched by regex
+// cannot be expressed and are skipped.
+func (s *soalService) ExportQTI(ujianID uint64) (HasilEksporSoal, error) {
+	ujian, err := s.ujianRepository.GetUjianByID(ujianID)
+	if err != nil {
+		return HasilEksporSoal{}, err
+	}
+	soalList, err := s.soalRepository.FindByIdUjianWithTipeSoal(ujianID)
+	if err != nil {
+		return HasilEksporSoal{}, err
+	}
+	daftar, err := s.withJawaban(soalList)
+	if err != nil {
+		return HasilEksporSoal{}, err
+	}
+
+	hasil := HasilEksporSoal{
+		NamaFile:   fmt.Sprintf("ujian-%d-qti.zip", ujianID),
+		Dilewati:   make([]uint64, 0),
+		MediaGagal: make([]string, 0),
+	}
+	acak := ujian.Acak == entity.StatusAktif
+	test := testQTIXML{
+		Xmlns:      nsQTI,
+		Identifier: fmt.Sprintf("ujian-%d", ujianID),
+		Title:      ujian.NamaUjian,
+	}
+	if ujian.Durasi > 0 {
+		test.BatasWaktu = &batasWaktuQTI{MaxTime: ujian.Durasi * 60}
+	}
+	test.Bagian.Identifier = "bagian-1"
+	test.Bagian.NavigationMode = "nonlinear"
+	test.Bagian.SubmissionMode = "simultaneous"
+	test.Bagian.Seksi = seksiQTI{Identifier: "seksi-1", Title: ujian.NamaUjian, Visible: true}
+	if acak {
+		test.Bagian.Seksi.Urutan = &urutanQTI{Shuffle: true}
+	}
+	manifest := manifestQTI{
+		Xmlns:         nsIMSCP,
+		Identifier:    fmt.Sprintf("manifest-ujian-%d", ujianID),
+		Schema:        "QTIv2.1 Package",
+		SchemaVersion: "1.0.0",
+	}
+	resourceTest := resourceQTI{Identifier: test.Identifier, Type: tipeResourceTest, Href: "assessmentTest.xml", Files: []fileQTI{{Href: "assessmentTest.xml"}}}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	var resourceItem []resourceQTI
+	for _, item := range daftar {
+		identifier := fmt.Sprintf("soal-%d", item.Soal.IdSoal)
+		href := "items/" + identifier + ".xml"
+		files := []fileQTI{{Href: href}}
+
+		var gambar string
+		if item.Soal.ImageUrl != "" {
+			data, ext, err := s.unduhMedia(item.Soal.ImageUrl)
+			if err != nil {
+				gambar = item.Soal.ImageUrl
+				hasil.MediaGagal = append(hasil.MediaGagal, item.Soal.ImageUrl)
+			} else {
+				media := "media/" + identifier + ext
+				if err := tulisZip(zw, media, data); err != nil {
+					return HasilEksporSoal{}, err
+				}
+				gambar = "../" + media
+				files = append(files, fileQTI{Href: media})
+			}
+		}
+
+		itemXML, ok := itemQTIDariSoal(identifier, item, gambar, acak)
+		if !ok {
+			hasil.Dilewati = append(hasil.Dilewati, item.Soal.IdSoal)
+			continue
+		}
+		if err := tulisXMLZip(zw, href, itemXML); err != nil {
+			return HasilEksporSoal{}, err
+		}
+		test.Bagian.Seksi.Item = append(test.Bagian.Seksi.Item, itemRefQTI{Identifier: identifier, Href: href})
+		resourceTest.Dependencies = append(resourceTest.Dependencies, dependencyQTI{IdentifierRef: identifier})
+		resourceItem = append(resourceItem, resourceQTI{Identifier: identifier, Type: tipeResourceItem, Href: href, Files: files})
+	}
+	manifest.Resources = append([]resourceQTI{resourceTest}, resourceItem...)
+
+	if err := tulisXMLZip(zw, "assessmentTest.xml", test); err != nil {
+		return HasilEksporSoal{}, err
+	}
+	if err := tulisXMLZip(zw, "imsmanifest.xml", manifest); err != nil {
+		return HasilEksporSoal{}, err
+	}
+	if err := zw.Close(); err != nil {
+		return HasilEksporSoal{}, err
+	}
+	hasil.Data = buf.Bytes()
+	return hasil, nil
+}
+
+// ImportQTI creates an ujian with the soal of a QTI 2.1 package. Choice, text entry and extended text
+// interactions become Pilihan_Berganda, Benar_Salah, Pilihan_Kompleks and Isian soal; items with any
+// other interaction are skipped and listed in TidakDidukung. Images inside the package cannot be
+// stored and are reported in Peringatan. The ujian and its soal are saved in one transaction, and
+// nothing is saved when an item is invalid or with DryRun.
+func (s *soalService) ImportQTI(input ImportQTI) (HasilImportQTI, error) {
+	hasil := HasilImportQTI{
+		DryRun:        input.DryRun,
+		TidakDidukung: make([]ItemQTITidakDidukung, 0),
+		Peringatan:    make([]string, 0),
+		Kesalahan:     make([]KesalahanItemQTI, 0),
+		Soal:          make([]SoalDenganJawaban, 0),
+	}
+	paket, err := bacaPaketQTI(input.Data)
+	if err != nil {
+		return hasil, err
+	}
+	tipeSoalList, err := s.soalRepository.FindAllTipeSoal()
+	if err != nil {
+		return hasil, err
+	}
+	tipeByNama := petaTipeSoal(tipeSoalList)
+
+	var daftar []SoalDenganJawaban
+	var asal []string
+	var total float64
+	for _, item := range paket.item {
+		if item.err != nil {
+			hasil.Kesalahan = append(hasil.Kesalahan, KesalahanItemQTI{Item: item.href, Pesan: []string{item.err.Error()}})
+			continue
+		}
+		if interaksi, ok := interaksiTidakDidukung(item.interaksi); !ok {
+			hasil.TidakDidukung = append(hasil.TidakDidukung, ItemQTITidakDidukung{Item: item.href, Interaksi: interaksi})
+			continue
+		}
+		soal, err := item.soal(tipeByNama)
+		if err != nil {
+			hasil.Kesalahan = append(hasil.Kesalahan, KesalahanItemQTI{Item: item.href, Pesan: []string{err.Error()}})
+			continue
+		}
+		if item.gambar != "" {
+			if strings.HasPrefix(item.gambar, "http://") || strings.HasPrefix(item.gambar, "https://") {
+				soal.Soal.ImageUrl = item.gambar
+			} else {
+				hasil.Peringatan = append(hasil.Peringatan, fmt.Sprintf("gambar %s pada %s tidak disimpan, isi image_url soal setelah import", item.gambar, item.href))
+			}
+		}
+		if _, err := s.siapkanSoal(&soal.Soal, soal.Jawaban); err != nil {
+			if !errors.Is(err, ErrSoalTidakValid) && !errors.Is(err, ErrOpsiSoalTidakValid) {
+				return hasil, err
+			}
+			hasil.Kesalahan = append(hasil.Kesalahan, KesalahanItemQTI{Item: item.href, Pesan: []string{err.Error()}})
+			continue
+		}
+		total += soal.Soal.NilaiPerSoal
+		daftar = append(daftar, soal)
+		asal = append(asal, item.href)
+	}
+	if len(daftar) == 0 && len(hasil.Kesalahan) == 0 {
+		hasil.Kesalahan = append(hasil.Kesalahan, KesalahanItemQTI{Pesan: []string{"paket tidak berisi soal yang didukung"}})
+	}
+
+	ujian := input.Ujian
+	if strings.TrimSpace(ujian.NamaUjian) == "" {
+		ujian.NamaUjian = paket.judul
+	}
+	if ujian.Durasi == 0 {
+		ujian.Durasi = paket.durasi
+	}
+	if ujian.Acak == "" {
+		ujian.Acak = entity.StatusTidakAktif
+		if paket.acak {
+			ujian.Acak = entity.StatusAktif
+		}
+	}
+	if ujian.StatusJawaban == "" {
+		ujian.StatusJawaban = entity.StatusTidakAktif
+	}
+	if ujian.Grade == 0 {
+		ujian.Grade = total
+	}
+	hasil.Ujian = ujian
+	hasil.Ujian.PasswordMasuk, hasil.Ujian.PasswordKeluar = "", ""
+	if ujian.PasswordMasuk == "" || ujian.PasswordKeluar == "" {
+		return hasil, fmt.Errorf("%w: password_masuk dan password_keluar wajib diisi", ErrDataUjianTidakValid)
+	}
+	if err := validasiDataUjian(s.kursusRepository, ujian); err != nil {
+		return hasil, err
+	}
+	if melebihiGrade(total, ujian.Grade) {
+		err := fmt.Errorf("%w: total nilai_per_soal %.2f, grade %.2f", ErrTotalNilaiMelebihiGrade, total, ujian.Grade)
+		hasil.Kesalahan = append(hasil.Kesalahan, KesalahanItemQTI{Pesan: []string{err.Error()}})
+	}
+
+	hasil.JumlahSoal = len(daftar)
+	hasil.Soal = append(hasil.Soal, daftar...)
+	if len(hasil.Kesalahan) > 0 {
+		return hasil, ErrImportSoalTidakValid
+	}
+	if input.DryRun {
+		return hasil, nil
+	}
+
+	if err := hashPasswordUjian(&ujian); err != nil {
+		return hasil, err
+	}
+	now := time.Now()
+	ujian.CreatedAt = now
+	ujian.UpdatedAt = now
+	if ujian.TanggalUjian.IsZero() {
+		ujian.TanggalUjian = now
+	}
+	err = s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		created, err := s.ujianRepository.WithTx(tx).CreateUjian(ujian)
+		if err != nil {
+			return err
+		}
+		hasil.Ujian = created
+
+		soalRepo := s.soalRepository.WithTx(tx)
+		for i := range hasil.Soal {
+			soal := hasil.Soal[i].Soal
+			soal.IdUjian = created.IdUjian
+			soal.CreatedAt = now
+			soal.UpdatedAt = now
+			tersimpan, err := soalRepo.CreateSoal(soal)
+			if err != nil {
+				return fmt.Errorf("%s: %w", asal[i], err)
+			}
+			opsi, err := s.simpanOpsi(tx, tersimpan, hasil.Soal[i].Jawaban, nil)
+			if err != nil {
+				return fmt.Errorf("%s: %w", asal[i], err)
+			}
+			tersimpan.TipeSoal = soal.TipeSoal
+			hasil.Soal[i] = SoalDenganJawaban{Soal: tersimpan, Jawaban: opsi}
+		}
+		return nil
+	})
+	if err != nil {
+		return hasil, err
+	}
+	hasil.Disimpan = true
+	return hasil, nil
+}
+
+// unduhMedia downloads the image at url and returns it with a file extension
+func (s *soalService) unduhMedia(alamat string) ([]byte, string, error) {
+	u, err := url.Parse(alamat)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, "", fmt.Errorf("image_url %q bukan alamat http", alamat)
+	}
+	resp, err := s.klienMedia.Get(alamat)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unduh %s: status %d", alamat, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, batasUkuranMedia+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > batasUkuranMedia {
+		return nil, "", fmt.Errorf("unduh %s: ukuran melebihi batas", alamat)
+	}
+
+	ext := path.Ext(u.Path)
+	if ext == "" {
+		if daftar, _ := mime.ExtensionsByType(http.DetectContentType(data)); len(daftar) > 0 {
+			ext = daftar[0]
+		}
+	}
+	return data, ext, nil
+}
+
+// newKlienMedia returns the client that downloads images for an export. image_url is set by guru, so
+// the client only connects to public addresses: the check runs on the resolved IP of every connection,
+// redirects included, and no proxy is used so the check cannot be bypassed.
+func newKlienMedia() *http.Client {
+	dialer := &net.Dialer{Timeout: batasWaktuUnduhMedia, Control: tolakAlamatNonPublik}
+	return &http.Client{
+		Timeout: batasWaktuUnduhMedia,
+		Transport: &http.Transport{
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   batasWaktuUnduhMedia,
+			ResponseHeaderTimeout: batasWaktuUnduhMedia,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= batasRedirectMedia {
+				return fmt.Errorf("unduh %s: terlalu banyak redirect", via[0].URL)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("%w: redirect ke %s", ErrAlamatMediaDitolak, req.URL)
+			}
+			return nil
+		},
+	}
+}
+
+// tolakAlamatNonPublik is a net.Dialer Control that refuses loopback, private, link-local, multicast
+// and unspecified addresses
+func tolakAlamatNonPublik(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrAlamatMediaDitolak, host)
+	}
+	if !alamatPublik(ip) {
+		return fmt.Errorf("%w: %s", ErrAlamatMediaDitolak, ip)
+	}
+	return nil
+}
+
+// alamatPublik reports whether the IP is routable on the internet
+func alamatPublik(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsValid() && ip.IsGlobalUnicast() && !ip.IsPrivate() && !ip.IsLoopback() &&
+		!ip.IsLinkLocalUnicast() && !alamatSharedCGNAT.Contains(ip)
+}
+
+// alamatSharedCGNAT is 100.64.0.0/10 (RFC 6598), which IsPrivate does not cover
+var alamatSharedCGNAT = netip.MustParsePrefix("100.64.0.0/10")
+
+func tulisZip(zw *zip.Writer, nama string, data []byte) error {
+	w, err := zw.Create(nama)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func tulisXMLZip(zw *zip.Writer, nama string, v interface{}) error {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return tulisZip(zw, nama, append([]byte(xml.Header), data...))
+}
+
+// itemQTIDariSoal builds the assessmentItem of a soal; gambar is the src of its image, if any
+func itemQTIDariSoal(identifier string, item SoalDenganJawaban, gambar string, acak bool) (itemQTIXML, bool) {
+	nilai := strconv.FormatFloat(item.Soal.NilaiPerSoal, 'f', -1, 64)
+	x := itemQTIXML{
+		Xmlns:      nsQTI,
+		Identifier: identifier,
+		Title:      fmt.Sprintf("Soal %d", item.Soal.IdSoal),
+		Respon:     responDeclarationQTI{Identifier: "RESPONSE", Cardinality: "single"},
+		Outcome: []outcomeDeclarationQTI{
+			{Identifier: "SCORE", Cardinality: "single", BaseType: "float", Default: []string{"0"}},
+			{Identifier: "MAXSCORE", Cardinality: "single", BaseType: "float", Default: []string{nilai}},
+		},
+	}
+	for _, baris := range strings.Split(strings.ReplaceAll(item.Soal.Soal, "\r\n", "\n"), "\n") {
+		if baris = strings.TrimSpace(baris); baris != "" {
+			x.Body.Paragraf = append(x.Body.Paragraf, paragrafQTI{Teks: baris})
+		}
+	}
+	if gambar != "" {
+		x.Body.Paragraf = append(x.Body.Paragraf, paragrafQTI{Gambar: &gambarQTI{Src: gambar}})
+	}
+
+	switch item.Soal.TipeSoal.NamaTipeUjian {
+	case entity.TipeSoalPilihanBerganda, entity.TipeSoalBenarSalah, entity.TipeSoalPilihanKompleks:
+		x.Respon.BaseType = "identifier"
+		pilihan := &choiceQTI{ResponseIdentifier: "RESPONSE", Shuffle: acak, MaxChoices: 1}
+		if item.Soal.TipeSoal.NamaTipeUjian == entity.TipeSoalPilihanKompleks {
+			x.Respon.Cardinality = "multiple"
+			pilihan.MaxChoices = 0
+		}
+		for i, o := range item.Jawaban {
+			id := labelOpsiQTI(i)
+			pilihan.Pilihan = append(pilihan.Pilihan, pilihanQTI{Identifier: id, Teks: o.Jawaban})
+			if o.Benar {
+				x.Respon.Benar = append(x.Respon.Benar, id)
+			}
+		}
+		x.Body.Pilihan = pilihan
+		x.Pemrosesan = &pemrosesanQTI{Template: templateMatchCorrect}
+	case entity.TipeSoalIsian:
+		var teks, angka []entity.JawabanSoal
+		for _, o := range item.Jawaban {
+			if !o.Benar {
+				continue
+			}
+			switch o.TipePencocokan {
+			case entity.PencocokanRegex:
+				return x, false
+			case entity.PencocokanAngka:
+				angka = append(angka, o)
+			default:
+				teks = append(teks, o)
+			}
+		}
+		switch {
+		case len(teks) > 0 && len(angka) > 0:
+			return x, false
+		case len(angka) > 0:
+			// Toleransi hanya bisa dinyatakan lewat responseProcessing sendiri
+			x.Respon.BaseType = "float"
+			x.Respon.Benar = []string{angka[0].Jawaban}
+			kondisi := &kondisiQTI{Set: setOutcomeQTI{Identifier: "SCORE", Variabel: variabelQTI{Identifier: "MAXSCORE"}}}
+			for _, o := range angka {
+				toleransi := strconv.FormatFloat(o.ToleransiAngka, 'f', -1, 64)
+				kondisi.Sama = append(kondisi.Sama, samaQTI{
+					ToleranceMode: "absolute",
+					Tolerance:     toleransi + " " + toleransi,
+					Variabel:      variabelQTI{Identifier: "RESPONSE"},
+					Nilai:         nilaiDasarQTI{BaseType: "float", Nilai: o.Jawaban},
+				})
+			}
+			x.Body.Paragraf = append(x.Body.Paragraf, paragrafQTI{IsianSingkat: &textEntryQTI{ResponseIdentifier: "RESPONSE"}})
+			x.Pemrosesan = &pemrosesanQTI{Kondisi: kondisi}
+		case len(teks) > 0:
+			x.Respon.BaseType = "string"
+			x.Respon.Benar = []string{teks[0].Jawaban}
+			x.Respon.Mapping = &mappingQTI{}
+			for _, o := range teks {
+				x.Respon.Mapping.Entri = append(x.Respon.Mapping.Entri, mapEntryQTI{MapKey: o.Jawaban, MappedValue: item.Soal.NilaiPerSoal, CaseSensitive: o.BedakanHurufBesar})
+			}
+			x.Body.Paragraf = append(x.Body.Paragraf, paragrafQTI{IsianSingkat: &textEntryQTI{ResponseIdentifier: "RESPONSE"}})
+			x.Pemrosesan = &pemrosesanQTI{Template: templateMapResponse}
+		default:
+			// Tanpa variasi jawaban soal dinilai manual, seperti esai
+			x.Respon.BaseType = "string"
+			x.Body.Esai = &extendedTextQTI{ResponseIdentifier: "RESPONSE"}
+		}
+	default:
+		return x, false
+	}
+	return x, true
+}
+
+// labelOpsiQTI names the choice identifiers A, B, … Z, then O27, O28, …
+func labelOpsiQTI(i int) string {
+	if i < 26 {
+		return string(rune('A' + i))
+	}
+	return fmt.Sprintf("O%d", i+1)
+}
+
+// paketQTI is what ImportQTI needs from a package: the test's title, time limit in minutes and
+// shuffling, and its items in test order
+type paketQTI struct {
+	judul  string
+	durasi int
+	acak   bool
+	item   []itemPaketQTI
+}
+
+// bacaPaketQTI opens the zip and reads the test and its items. The test and items are found through
+// imsmanifest.xml, or by their root element when the package has no manifest.
+func bacaPaketQTI(data []byte) (paketQTI, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return paketQTI{}, fmt.Errorf("%w: %v", ErrPaketQTITidakValid, err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[path.Clean(f.Name)] = f
+	}
+	// Ukuran di header zip bisa dipalsukan, jadi yang dibatasi adalah byte yang benar-benar dibaca
+	var totalDibaca int64
+	baca := func(nama string) ([]byte, error) {
+		f, ada := files[path.Clean(nama)]
+		if !ada {
+			return nil, fmt.Errorf("file %s tidak ada di paket", nama)
+		}
+		if f.UncompressedSize64 > batasUkuranFileQTI {
+			return nil, fmt.Errorf("%w: file %s lebih dari %d MB", ErrPaketQTITidakValid, nama, batasUkuranFileQTI>>20)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		isi, err := io.ReadAll(io.LimitReader(rc, batasUkuranFileQTI+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(isi) > batasUkuranFileQTI {
+			return nil, fmt.Errorf("%w: file %s lebih dari %d MB", ErrPaketQTITidakValid, nama, batasUkuranFileQTI>>20)
+		}
+		totalDibaca += int64(len(isi))
+		if totalDibaca > batasTotalFileQTI {
+			return nil, fmt.Errorf("%w: isi paket lebih dari %d MB", ErrPaketQTITidakValid, batasTotalFileQTI>>20)
+		}
+		return isi, nil
+	}
+
+	var hrefTest string
+	var hrefItem []string
+	isi, err := baca("imsmanifest.xml")
+	if errors.Is(err, ErrPaketQTITidakValid) {
+		return paketQTI{}, err
+	}
+	if err == nil {
+		var manifest manifestQTI
+		if err := xml.Unmarshal(isi, &manifest); err != nil {
+			return paketQTI{}, fmt.Errorf("%w: imsmanifest.xml: %v", ErrPaketQTITidakValid, err)
+		}
+		for _, r := range manifest.Resources {
+			switch {
+			case strings.HasPrefix(r.Type, tipeResourceTest) && hrefTest == "":
+				hrefTest = r.Href
+			case strings.HasPrefix(r.Type, tipeResourceItem):
+				hrefItem = append(hrefItem, r.Href)
+			}
+		}
+	} else {
+		nama := make([]string, 0, len(files))
+		for n := range files {
+			nama = append(nama, n)
+		}
+		sort.Strings(nama)
+		for _, n := range nama {
+			if !strings.HasSuffix(strings.ToLower(n), ".xml") {
+				continue
+			}
+			isi, err := baca(n)
+			if err != nil {
+				return paketQTI{}, err
+			}
+			switch elemenAkar(isi) {
+			case "assessmentTest":
+				if hrefTest == "" {
+					hrefTest = n
+				}
+			case "assessmentItem":
+				hrefItem = append(hrefItem, n)
+			}
+		}
+	}
+
+	var paket paketQTI
+	if hrefTest != "" {
+		isi, err := baca(hrefTest)
+		if errors.Is(err, ErrPaketQTITidakValid) {
+			return paketQTI{}, err
+		}
+		if err != nil {
+			return paketQTI{}, fmt.Errorf("%w: %v", ErrPaketQTITidakValid, err)
+		}
+		test, err := bacaTestQTI(isi)
+		if err != nil {
+			return paketQTI{}, fmt.Errorf("%w: %s: %v", ErrPaketQTITidakValid, hrefTest, err)
+		}
+		paket.judul, paket.durasi, paket.acak = test.judul, test.durasi, test.acak
+		// Urutan soal mengikuti test; href item relatif terhadap file test
+		hrefItem = hrefItem[:0]
+		for _, href := range test.item {
+			hrefItem = append(hrefItem, path.Join(path.Dir(hrefTest), href))
+		}
+	}
+	if len(hrefItem) == 0 {
+		return paketQTI{}, fmt.Errorf("%w: tidak ada assessmentItem", ErrPaketQTITidakValid)
+	}
+
+	for _, href := range hrefItem {
+		item := itemPaketQTI{href: href}
+		if isi, err := baca(href); errors.Is(err, ErrPaketQTITidakValid) {
+			return paketQTI{}, err
+		} else if err != nil {
+			item.err = err
+		} else if item.err = item.bacaXML(isi); item.err == nil && item.gambar != "" && !strings.Contains(item.gambar, "://") {
+			item.gambar = path.Join(path.Dir(href), item.gambar)
+		}
+		paket.item = append(paket.item, item)
+	}
+	return paket, nil
+}
+
+// elemenAkar returns the local name of the root element of an XML document
+func elemenAkar(data []byte) string {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return ""
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local
+		}
+	}
+}
+
+type testQTI struct {
+	judul  string
+	durasi int
+	acak   bool
+	item   []string
+}
+
+func bacaTestQTI(data []byte) (testQTI, error) {
+	var test testQTI
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return test, nil
+		}
+		if err != nil {
+			return test, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "assessmentTest":
+			test.judul = atributXML(start, "title")
+		case "timeLimits":
+			// maxTime dalam detik, Durasi dalam menit
+			if detik, err := strconv.ParseFloat(atributXML(start, "maxTime"), 64); err == nil && detik > 0 {
+				test.durasi = int((detik + 59) / 60)
+			}
+		case "ordering":
+			test.acak = test.acak || atributXML(start, "shuffle") == "true"
+		case "assessmentItemRef":
+			test.item = append(test.item, atributXML(start, "href"))
+		}
+	}
+}
+
+func atributXML(start xml.StartElement, nama string) string {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == nama {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// itemPaketQTI is one assessmentItem of an imported package
+type itemPaketQTI struct {
+	href       string
+	err        error
+	teks       string
+	gambar     string
+	interaksi  []string
+	pilihan    []pilihanQTI
+	maxChoices int
+	respon     []responDeclarationQTI
+	outcome    []outcomeDeclarationQTI
+	sama       []samaQTI
+}
+
+// bacaXML reads the parts of an assessmentItem that map onto a soal: the text and first image of the
+// item body, its interactions, the response and outcome declarations and any equal comparisons of
+// the response processing
+func (item *itemPaketQTI) bacaXML(data []byte) error {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	var teks strings.Builder
+	diBody := false
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			nama := t.Name.Local
+			switch {
+			case nama == "responseDeclaration":
+				var r responDeclarationQTI
+				if err := d.DecodeElement(&r, &t); err != nil {
+					return err
+				}
+				item.respon = append(item.respon, r)
+			case nama == "outcomeDeclaration":
+				var o outcomeDeclarationQTI
+				if err := d.DecodeElement(&o, &t); err != nil {
+					return err
+				}
+				item.outcome = append(item.outcome, o)
+			case nama == "equal":
+				var sama samaQTI
+				if err := d.DecodeElement(&sama, &t); err != nil {
+					return err
+				}
+				item.sama = append(item.sama, sama)
+			case nama == "itemBody":
+				diBody = true
+			case !diBody:
+			case nama == "choiceInteraction":
+				var pilihan struct {
+					MaxChoices string `xml:"maxChoices,attr"`
+					Prompt     struct {
+						Isi string `xml:",innerxml"`
+					} `xml:"prompt"`
+					Pilihan []struct {
+						Identifier string `xml:"identifier,attr"`
+						Isi        string `xml:",innerxml"`
+					} `xml:"simpleChoice"`
+				}
+				if err := d.DecodeElement(&pilihan, &t); err != nil {
+					return err
+				}
+				item.interaksi = append(item.interaksi, nama)
+				item.maxChoices, _ = strconv.Atoi(pilihan.MaxChoices)
+				if prompt := teksXML(pilihan.Prompt.Isi); prompt != "" {
+					teks.WriteString("\n" + prompt + "\n")
+				}
+				for _, p := range pilihan.Pilihan {
+					item.pilihan = append(item.pilihan, pilihanQTI{Identifier: p.Identifier, Teks: teksXML(p.Isi)})
+				}
+			case strings.HasSuffix(nama, "Interaction"):
+				item.interaksi = append(item.interaksi, nama)
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			case nama == "rubricBlock" || nama == "feedbackBlock" || nama == "feedbackInline" || nama == "templateBlock":
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			case nama == "img":
+				if item.gambar == "" {
+					item.gambar = atributXML(t, "src")
+				}
+			case nama == "br" || nama == "p" || nama == "div" || nama == "li":
+				teks.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "itemBody":
+				diBody = false
+			case "p", "div", "li":
+				teks.WriteString("\n")
+			}
+		case xml.CharData:
+			if diBody {
+				teks.Write(t)
+			}
+		}
+	}
+	item.teks = rapikanTeks(teks.String())
+	return nil
+}
+
+// interaksiTidakDidukung reports the interactions of an item when they cannot become one soal
+func interaksiTidakDidukung(interaksi []string) (string, bool) {
+	switch {
+	case len(interaksi) == 0:
+		return "tanpa interaksi", false
+	case len(interaksi) > 1:
+		return strings.Join(interaksi, ", "), false
+	}
+	switch interaksi[0] {
+	case "choiceInteraction", "textEntryInteraction", "extendedTextInteraction":
+		return interaksi[0], true
+	}
+	return interaksi[0], false
+}
+
+// soal converts the item into a soal with its options
+func (item *itemPaketQTI) soal(tipeByNama map[string]entity.TipeSoal) (SoalDenganJawaban, error) {
+	var respon responDeclarationQTI
+	if len(item.respon) > 0 {
+		respon = item.respon[0]
+	}
+
+	var namaTipe string
+	var opsi []entity.JawabanSoal
+	switch item.interaksi[0] {
+	case "choiceInteraction":
+		benar := make(map[string]bool)
+		for _, id := range respon.Benar {
+			benar[strings.TrimSpace(id)] = true
+		}
+		if len(benar) == 0 && respon.Mapping != nil {
+			for _, entri := range respon.Mapping.Entri {
+				if entri.MappedValue > 0 {
+					benar[entri.MapKey] = true
+				}
+			}
+		}
+		for _, p := range item.pilihan {
+			opsi = append(opsi, entity.JawabanSoal{Jawaban: p.Teks, Benar: benar[p.Identifier]})
+		}
+		switch {
+		case respon.Cardinality == "multiple" || (item.maxChoices != 1 && len(benar) > 1):
+			namaTipe = entity.TipeSoalPilihanKompleks
+		case adalahBenarSalah(opsi):
+			namaTipe = entity.TipeSoalBenarSalah
+		default:
+			namaTipe = entity.TipeSoalPilihanBerganda
+		}
+	case "textEntryInteraction":
+		namaTipe = entity.TipeSoalIsian
+		if respon.BaseType == "float" || respon.BaseType == "integer" {
+			opsi = item.variasiAngka(respon)
+		} else {
+			opsi = variasiTeks(respon)
+		}
+	case "extendedTextInteraction":
+		namaTipe = entity.TipeSoalIsian
+	}
+
+	tipe, err := cariTipeSoal(tipeByNama, namaTipe)
+	if err != nil {
+		return SoalDenganJawaban{}, err
+	}
+	return SoalDenganJawaban{
+		Soal: entity.Soal{
+			Soal:         item.teks,
+			NilaiPerSoal: item.nilai(respon),
+			IdTipeSoal:   tipe.IdTipeSoal,
+		},
+		Jawaban: opsi,
+	}, nil
+}
+
+// variasiAngka reads the accepted numbers from the equal comparisons written by ExportQTI, or else
+// from the correct response and mapping without tolerance
+func (item *itemPaketQTI) variasiAngka(respon responDeclarationQTI) []entity.JawabanSoal {
+	var opsi []entity.JawabanSoal
+	for _, sama := range item.sama {
+		o := entity.JawabanSoal{Jawaban: strings.TrimSpace(sama.Nilai.Nilai), Benar: true, TipePencocokan: entity.PencocokanAngka}
+		if bagian := strings.Fields(sama.Tolerance); len(bagian) > 0 && sama.ToleranceMode == "absolute" {
+			o.ToleransiAngka, _ = parseAngka(bagian[len(bagian)-1])
+		}
+		opsi = append(opsi, o)
+	}
+	if len(opsi) > 0 {
+		return opsi
+	}
+	for _, teks := range variasiTeks(respon) {
+		teks.TipePencocokan = entity.PencocokanAngka
+		opsi = append(opsi, teks)
+	}
+	return opsi
+}
+
+// variasiTeks reads the accepted answers from the correct response and the positively mapped keys
+func variasiTeks(respon responDeclarationQTI) []entity.JawabanSoal {
+	var opsi []entity.JawabanSoal
+	ada := make(map[string]bool)
+	tambah := func(teks string, bedakan bool) {
+		if teks = strings.TrimSpace(teks); teks != "" && !ada[teks] {
+			ada[teks] = true
+			opsi = append(opsi, entity.JawabanSoal{Jawaban: teks, Benar: true, BedakanHurufBesar: bedakan})
+		}
+	}
+	if respon.Mapping != nil {
+		for _, entri := range respon.Mapping.Entri {
+			if entri.MappedValue > 0 {
+				tambah(entri.MapKey, entri.CaseSensitive)
+			}
+		}
+	}
+	for _, teks := range respon.Benar {
+		tambah(teks, false)
+	}
+	return opsi
+}
+
+// nilai returns the points of the item: MAXSCORE, else the highest mapped value, else 1
+func (item *itemPaketQTI) nilai(respon responDeclarationQTI) float64 {
+	for _, o := range item.outcome {
+		if o.Identifier == "MAXSCORE" && len(o.Default) > 0 {
+			if nilai, err := parseAngka(o.Default[0]); err == nil {
+				return nilai
+			}
+		}
+	}
+	var tertinggi float64
+	if respon.Mapping != nil {
+		for _, entri := range respon.Mapping.Entri {
+			if entri.MappedValue > tertinggi {
+				tertinggi = entri.MappedValue
+			}
+		}
+	}
+	if tertinggi > 0 {
+		return tertinggi
+	}
+	return 1
+}
+
+// teksXML returns the character data of an XHTML fragment
+func teksXML(isi string) string {
+	d := xml.NewDecoder(strings.NewReader("<x>" + isi + "</x>"))
+	d.Strict = false
+	var teks strings.Builder
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			teks.Write(t)
+		case xml.StartElement:
+			if t.Name.Local == "br" || t.Name.Local == "p" {
+				teks.WriteString("\n")
+			}
+		}
+	}
+	return rapikanTeks(teks.String())
+}
+
+// rapikanTeks collapses the whitespace within every line and drops empty lines
+func rapikanTeks(teks string) string {
+	var baris []string
+	for _, b := range strings.Split(teks, "\n") {
+		if b = strings.Join(strings.Fields(b), " "); b != "" {
+			baris = append(baris, b)
+		}
+	}
+	return strings.Join(baris, "\n")
+}
+
+// Elemen XML paket QTI. Atribut xmlns ditulis langsung supaya struct yang sama bisa dibaca dari
+// paket yang memakai prefix namespace lain.
+type manifestQTI struct {
+	XMLName       xml.Name      `xml:"manifest"`
+	Xmlns         string        `xml:"xmlns,attr"`
+	Identifier    string        `xml:"identifier,attr"`
+	Schema        string        `xml:"metadata>schema"`
+	SchemaVersion string        `xml:"metadata>schemaversion"`
+	Organizations struct{}      `xml:"organizations"`
+	Resources     []resourceQTI `xml:"resources>resource"`
+}
+
+type resourceQTI struct {
+	Identifier   string          `xml:"identifier,attr"`
+	Type         string          `xml:"type,attr"`
+	Href         string          `xml:"href,attr"`
+	Files        []fileQTI       `xml:"file"`
+	Dependencies []dependencyQTI `xml:"dependency"`
+}
+
+type fileQTI struct {
+	Href string `xml:"href,attr"`
+}
+
+type dependencyQTI struct {
+	IdentifierRef string `xml:"identifierref,attr"`
+}
+
+type testQTIXML struct {
+	XMLName    xml.Name       `xml:"assessmentTest"`
+	Xmlns      string         `xml:"xmlns,attr"`
+	Identifier string         `xml:"identifier,attr"`
+	Title      string         `xml:"title,attr"`
+	BatasWaktu *batasWaktuQTI `xml:"timeLimits,omitempty"`
+	Bagian     struct {
+		Identifier     string   `xml:"identifier,attr"`
+		NavigationMode string   `xml:"navigationMode,attr"`
+		SubmissionMode string   `xml:"submissionMode,attr"`
+		Seksi          seksiQTI `xml:"assessmentSection"`
+	} `xml:"testPart"`
+}
+
+type batasWaktuQTI struct {
+	MaxTime int `xml:"maxTime,attr"`
+}
+
+type seksiQTI struct {
+	Identifier string       `xml:"identifier,attr"`
+	Title      string       `xml:"title,attr"`
+	Visible    bool         `xml:"visible,attr"`
+	Urutan     *urutanQTI   `xml:"ordering,omitempty"`
+	Item       []itemRefQTI `xml:"assessmentItemRef"`
+}
+
+type urutanQTI struct {
+	Shuffle bool `xml:"shuffle,attr"`
+}
+
+type itemRefQTI struct {
+	Identifier string `xml:"identifier,attr"`
+	Href       string `xml:"href,attr"`
+}
+
+type itemQTIXML struct {
+	XMLName       xml.Name                `xml:"assessmentItem"`
+	Xmlns         string                  `xml:"xmlns,attr"`
+	Identifier    string                  `xml:"identifier,attr"`
+	Title         string                  `xml:"title,attr"`
+	Adaptive      bool                    `xml:"adaptive,attr"`
+	TimeDependent bool                    `xml:"timeDependent,attr"`
+	Respon        responDeclarationQTI    `xml:"responseDeclaration"`
+	Outcome       []outcomeDeclarationQTI `xml:"outcomeDeclaration"`
+	Body          bodyQTI                 `xml:"itemBody"`
+	Pemrosesan    *pemrosesanQTI          `xml:"responseProcessing,omitempty"`
+}
+
+type responDeclarationQTI struct {
+	Identifier  string      `xml:"identifier,attr"`
+	Cardinality string      `xml:"cardinality,attr"`
+	BaseType    string      `xml:"baseType,attr"`
+	Benar       []string    `xml:"correctResponse>value,omitempty"`
+	Mapping     *mappingQTI `xml:"mapping,omitempty"`
+}
+
+type mappingQTI struct {
+	DefaultValue float64       `xml:"defaultValue,attr"`
+	Entri        []mapEntryQTI `xml:"mapEntry"`
+}
+
+type mapEntryQTI struct {
+	MapKey        string  `xml:"mapKey,attr"`
+	MappedValue   float64 `xml:"mappedValue,attr"`
+	CaseSensitive bool    `xml:"caseSensitive,attr"`
+}
+
+type outcomeDeclarationQTI struct {
+	Identifier  string   `xml:"identifier,attr"`
+	Cardinality string   `xml:"cardinality,attr"`
+	BaseType    string   `xml:"baseType,attr"`
+	Default     []string `xml:"defaultValue>value"`
+}
+
+type bodyQTI struct {
+	Paragraf []paragrafQTI    `xml:"p"`
+	Pilihan  *choiceQTI       `xml:"choiceInteraction,omitempty"`
+	Esai     *extendedTextQTI `xml:"extendedTextInteraction,omitempty"`
+}
+
+type paragrafQTI struct {
+	Teks         string        `xml:",chardata"`
+	Gambar       *gambarQTI    `xml:"img,omitempty"`
+	IsianSingkat *textEntryQTI `xml:"textEntryInteraction,omitempty"`
+}
+
+type gambarQTI struct {
+	Src string `xml:"src,attr"`
+	Alt string `xml:"alt,attr"`
+}
+
+type choiceQTI struct {
+	ResponseIdentifier string       `xml:"responseIdentifier,attr"`
+	Shuffle            bool         `xml:"shuffle,attr"`
+	MaxChoices         int          `xml:"maxChoices,attr"`
+	Pilihan            []pilihanQTI `xml:"simpleChoice"`
+}
+
+type pilihanQTI struct {
+	Identifier string `xml:"identifier,attr"`
+	Teks       string `xml:",chardata"`
+}
+
+type textEntryQTI struct {
+	ResponseIdentifier string `xml:"responseIdentifier,attr"`
+}
+
+type extendedTextQTI struct {
+	ResponseIdentifier string `xml:"responseIdentifier,attr"`
+}
+
+type pemrosesanQTI struct {
+	Template string      `xml:"template,attr,omitempty"`
+	Kondisi  *kondisiQTI `xml:"responseCondition,omitempty"`
+}
+
+type kondisiQTI struct {
+	Sama []samaQTI     `xml:"responseIf>or>equal"`
+	Set  setOutcomeQTI `xml:"responseIf>setOutcomeValue"`
+}
+
+type samaQTI struct {
+	ToleranceMode string        `xml:"toleranceMode,attr"`
+	Tolerance     string        `xml:"tolerance,attr,omitempty"`
+	Variabel      variabelQTI   `xml:"variable"`
+	Nilai         nilaiDasarQTI `xml:"baseValue"`
+}
+
+type variabelQTI struct {
+	Identifier string `xml:"identifier,attr"`
+}
+
+type nilaiDasarQTI struct {
+	BaseType string `xml:"baseType,attr"`
+	Nilai    string `xml:",chardata"`
+}
+
+type setOutcomeQTI struct {
+	Identifier string      `xml:"identifier,attr"`
+	Variabel   variabelQTI `xml:"variable"`
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"cbt-api/entity"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQTIRoundTrip(t *testing.T) {
+	env := newLingkunganTest(t)
+	kursus := entity.Kursus{NamaKursus: "Geografi"}
+	env.buat(t, &kursus)
+	ujian := env.buatUjian(t, func(u *entity.Ujian) {
+		u.NamaUjian = "Ujian QTI"
+		u.IdKursus = kursus.IdKursus
+		u.Acak = entity.StatusAktif
+		u.Durasi = 30
+	})
+	const gambar = "http://127.0.0.1:1/peta.png"
+	pg, _ := env.buatSoal(t, ujian, entity.TipeSoalPilihanBerganda, 20,
+		entity.JawabanSoal{Jawaban: "Bandung"}, entity.JawabanSoal{Jawaban: "Jakarta", Benar: true})
+	env.db.Model(&pg).Update("image_url", gambar)
+	env.buatSoal(t, ujian, entity.TipeSoalBenarSalah, 10,
+		entity.JawabanSoal{Jawaban: "Benar", Benar: true}, entity.JawabanSoal{Jawaban: "Salah"})
+	env.buatSoal(t, ujian, entity.TipeSoalIsian, 20,
+		entity.JawabanSoal{Jawaban: "Jakarta", Benar: true}, entity.JawabanSoal{Jawaban: "DKI Jakarta", Benar: true})
+	env.buatSoal(t, ujian, entity.TipeSoalIsian, 20,
+		entity.JawabanSoal{Jawaban: "3.14", Benar: true, TipePencocokan: entity.PencocokanAngka, ToleransiAngka: 0.01})
+	// match_correct QTI bernilai semua atau tidak sama sekali, sama dengan Semua_Benar
+	env.buatSoal(t, ujian, entity.TipeSoalPilihanKompleks, 30,
+		entity.JawabanSoal{Jawaban: "2", Benar: true}, entity.JawabanSoal{Jawaban: "4"}, entity.JawabanSoal{Jawaban: "5", Benar: true})
+	regex, _ := env.buatSoal(t, ujian, entity.TipeSoalIsian, 5,
+		entity.JawabanSoal{Jawaban: "a+", Benar: true, TipePencocokan: entity.PencocokanRegex})
+
+	ekspor, err := env.soal.ExportQTI(ujian.IdUjian)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ekspor.Dilewati, []uint64{regex.IdSoal}) {
+		t.Errorf("skipped %v, want the regex soal %d", ekspor.Dilewati, regex.IdSoal)
+	}
+	// Alamat loopback tidak pernah diunduh; gambar tetap dirujuk lewat URL
+	if !reflect.DeepEqual(ekspor.MediaGagal, []string{gambar}) {
+		t.Errorf("media not downloaded = %v, want [%s]", ekspor.MediaGagal, gambar)
+	}
+
+	input := ImportQTI{
+		Ujian:  entity.Ujian{PasswordMasuk: "masuk", PasswordKeluar: "keluar", IdKursus: kursus.IdKursus, IdTipeUjian: 1},
+		Data:   ekspor.Data,
+		DryRun: true,
+	}
+	impor, err := env.soal.ImportQTI(input)
+	if err != nil {
+		t.Fatalf("ImportQTI dry run: %v %+v", err, impor.Kesalahan)
+	}
+	if impor.Ujian.NamaUjian != "Ujian QTI" || impor.Ujian.Durasi != 30 || impor.Ujian.Acak != entity.StatusAktif || impor.Ujian.Grade != 100 {
+		t.Errorf("ujian read back as nama %q, durasi %d, acak %q, grade %v",
+			impor.Ujian.NamaUjian, impor.Ujian.Durasi, impor.Ujian.Acak, impor.Ujian.Grade)
+	}
+
+	asli, err := env.soal.GetSoalByUjian(ujian.IdUjian)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tipeByID := make(map[uint64]string)
+	for nama, tipe := range env.tipeSoal {
+		tipeByID[tipe.IdTipeSoal] = nama
+	}
+	var want, got []ringkasanSoal
+	for _, soal := range asli {
+		if soal.Soal.IdSoal != regex.IdSoal {
+			want = append(want, ringkas(soal, tipeByID))
+		}
+	}
+	for _, soal := range impor.Soal {
+		got = append(got, ringkas(soal, tipeByID))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("soal read back =\n%+v\nwant\n%+v", got, want)
+	}
+	for i, soal := range impor.Soal {
+		if soal.Soal.NilaiPerSoal != asli[i].Soal.NilaiPerSoal || soal.Soal.ModePenilaian != asli[i].Soal.ModePenilaian {
+			t.Errorf("soal %d: nilai %v, mode %q; want %v, %q", i, soal.Soal.NilaiPerSoal, soal.Soal.ModePenilaian,
+				asli[i].Soal.NilaiPerSoal, asli[i].Soal.ModePenilaian)
+		}
+	}
+	if impor.Soal[0].Soal.ImageUrl != gambar {
+		t.Errorf("image_url read back as %q, want %q", impor.Soal[0].Soal.ImageUrl, gambar)
+	}
+
+	input.DryRun = false
+	impor, err = env.soal.ImportQTI(input)
+	if err != nil || !impor.Disimpan {
+		t.Fatalf("ImportQTI = %v, disimpan %v", err, impor.Disimpan)
+	}
+	if impor.Ujian.IdUjian == ujian.IdUjian || jumlahSoalUjian(t, env, impor.Ujian.IdUjian) != 5 {
+		t.Errorf("import created ujian %d with %d soal, want a new ujian with 5",
+			impor.Ujian.IdUjian, jumlahSoalUjian(t, env, impor.Ujian.IdUjian))
+	}
+}
+
+// zipTest builds a package from name and content pairs
+func zipTest(t *testing.T, files ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for i := 0; i+1 < len(files); i += 2 {
+		if err := tulisZip(zw, files[i], []byte(files[i+1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestBacaPaketQTIBatasUkuran(t *testing.T) {
+	besar := "<manifest>" + strings.Repeat(" ", batasUkuranFileQTI) + "</manifest>"
+	if _, err := bacaPaketQTI(zipTest(t, "imsmanifest.xml", besar)); !errors.Is(err, ErrPaketQTITidakValid) {
+		t.Errorf("oversized manifest = %v, want ErrPaketQTITidakValid", err)
+	}
+
+	// Setiap file di bawah batas, tetapi totalnya melebihi batas paket
+	item := `<assessmentItem xmlns="` + nsQTI + `">` + strings.Repeat(" ", batasUkuranFileQTI-100) + `</assessmentItem>`
+	var files []string
+	for i := 0; i <= batasTotalFileQTI/batasUkuranFileQTI; i++ {
+		files = append(files, "items/soal-"+string(rune('a'+i))+".xml", item)
+	}
+	if _, err := bacaPaketQTI(zipTest(t, files...)); !errors.Is(err, ErrPaketQTITidakValid) || !strings.Contains(err.Error(), "isi paket") {
+		t.Errorf("package over the total limit = %v, want ErrPaketQTITidakValid", err)
+	}
+
+	if _, err := bacaPaketQTI([]byte("bukan zip")); !errors.Is(err, ErrPaketQTITidakValid) {
+		t.Errorf("not a zip = %v, want ErrPaketQTITidakValid", err)
+	}
+	if _, err := bacaPaketQTI(zipTest(t, "catatan.txt", "kosong")); !errors.Is(err, ErrPaketQTITidakValid) {
+		t.Errorf("package without items = %v, want ErrPaketQTITidakValid", err)
+	}
+}
+
+func TestAlamatPublik(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"8.8.8.8", true},
+		{"2606:4700:4700::1111", true},
+		{"::ffff:8.8.8.8", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"224.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := alamatPublik(netip.MustParseAddr(tt.ip)); got != tt.want {
+			t.Errorf("alamatPublik(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestUnduhMediaTolakAlamatLokal(t *testing.T) {
+	diakses := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		diakses = true
+		w.Write([]byte("\x89PNG\r\n\x1a\n"))
+	}))
+	defer srv.Close()
+
+	s := &soalService{klienMedia: newKlienMedia()}
+	if _, _, err := s.unduhMedia(srv.URL + "/gambar.png"); !errors.Is(err, ErrAlamatMediaDitolak) {
+		t.Errorf("download from %s = %v, want ErrAlamatMediaDitolak", srv.URL, err)
+	}
+	if diakses {
+		t.Error("the local server was reached")
+	}
+	if _, _, err := s.unduhMedia("file:///etc/passwd"); err == nil {
+		t.Error("file URL accepted")
+	}
+}
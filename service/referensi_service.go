@@ -0,0 +1,37 @@
+// service/referensi_service.go
+package service
+
+import (
+	"cbt-api/entity"
+	"cbt-api/repository"
+)
+
+// ReferensiService is a contract for reference data service
+type ReferensiService interface {
+	GetAllKelas() ([]entity.Kelas, error)
+	GetAllKurikulum() ([]entity.Kurikulum, error)
+	GetMataPelajaranByKurikulumID(kurikulumID uint64) ([]entity.MataPelajaran, error)
+}
+
+type referensiService struct {
+	referensiRepository repository.ReferensiRepository
+}
+
+// NewReferensiService creates a new instance of ReferensiService
+func NewReferensiService(referensiRepo repository.ReferensiRepository) ReferensiService {
+	return &referensiService{
+		referensiRepository: referensiRepo,
+	}
+}
+
+func (s *referensiService) GetAllKelas() ([]entity.Kelas, error) {
+	return s.referensiRepository.GetAllKelas()
+}
+
+func (s *referensiService) GetAllKurikulum() ([]entity.Kurikulum, error) {
+	return s.referensiRepository.GetAllKurikulum()
+}
+
+func (s *referensiService) GetMataPelajaranByKurikulumID(kurikulumID uint64) ([]entity.MataPelajaran, error) {
+	return s.referensiRepository.GetMataPelajaranByKurikulumID(kurikulumID)
+}
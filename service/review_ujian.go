@@ -0,0 +1,120 @@
+// service/review_ujian.go
+package service
+
+import (
+	"cbt-api/dto"
+	"cbt-api/entity"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPembahasanTidakAktif = errors.New("pembahasan ujian tidak diaktifkan")
+	ErrUjianBelumBerakhir   = errors.New("pembahasan tersedia setelah waktu ujian berakhir")
+)
+
+// ReviewSoal is one soal of a finished attempt with the siswa's answer, the answer key and the points earned
+type ReviewSoal struct {
+	NilaiSoal
+	Nomor        int                  `json:"nomor"`
+	Soal         dto.SoalDTO          `json:"soal"`
+	JawabanSiswa string               `json:"jawaban_siswa"`
+	Opsi         []dto.OpsiJawabanDTO `json:"opsi"`
+	Kunci        []uint64             `json:"kunci"` // id_jawaban_soal yang benar, atau variasi jawaban soal Isian
+}
+
+// ReviewUjian is the post-exam review of one attempt, in the soal and option order the siswa saw
+type ReviewUjian struct {
+	Attempt   entity.UjianAttempt `json:"attempt"`
+	NamaUjian string              `json:"nama_ujian"`
+	Hasil     HasilUjian          `json:"hasil"`
+	Soal      []ReviewSoal        `json:"soal"`
+}
+
+// ReviewAttempt returns the review of one of the siswa's attempts. It is only available when the
+// ujian's StatusJawaban is Aktif and the exam window has ended, so answers cannot be passed on to
+// siswa who are still working.
+func (s *ujianAttemptService) ReviewAttempt(attemptID uint64, siswaID uint64) (ReviewUjian, error) {
+	attempt, err := s.ujianAttemptRepository.GetAttemptByID(attemptID)
+	if err != nil {
+		return ReviewUjian{}, err
+	}
+	// Attempt siswa lain diperlakukan seperti tidak ada
+	if attempt.IdSiswa != siswaID {
+		return ReviewUjian{}, gorm.ErrRecordNotFound
+	}
+
+	ujian, err := s.ujianRepository.GetUjianByID(attempt.IdUjian)
+	if err != nil {
+		return ReviewUjian{}, err
+	}
+	if ujian.StatusJawaban != entity.StatusAktif {
+		return ReviewUjian{}, ErrPembahasanTidakAktif
+	}
+	if !ujianSudahBerakhir(ujian, attempt, time.Now()) {
+		return ReviewUjian{}, ErrUjianBelumBerakhir
+	}
+
+	soalList, err := s.soalRepository.FindByIdUjianWithTipeSoal(ujian.IdUjian)
+	if err != nil {
+		return ReviewUjian{}, err
+	}
+	kunci, err := s.jawabanSoalRepository.FindByIdUjian(ujian.IdUjian)
+	if err != nil {
+		return ReviewUjian{}, err
+	}
+	jawabanList, err := s.jawabanSiswaRepository.FindByAttempt(attempt.IdUjianAttempt)
+	if err != nil {
+		return ReviewUjian{}, err
+	}
+
+	hasil := HasilUjian{IdUjian: ujian.IdUjian, IdSiswa: siswaID, IdKursus: ujian.IdKursus, IdTipeUjian: ujian.IdTipeUjian}
+	nilaiUjian(&hasil, ujian, soalList, kunci, jawabanList)
+	nilaiPerSoal := make(map[uint64]NilaiSoal, len(hasil.Soal))
+	for _, nilaiSoal := range hasil.Soal {
+		nilaiPerSoal[nilaiSoal.IdSoal] = nilaiSoal
+	}
+	hasil.Soal = nil
+
+	jawabanPerSoal := make(map[uint64]entity.JawabanSiswa, len(jawabanList))
+	for _, jawaban := range jawabanList {
+		jawabanPerSoal[jawaban.IdSoal] = jawaban
+	}
+
+	review := ReviewUjian{
+		Attempt:   attempt,
+		NamaUjian: ujian.NamaUjian,
+		Hasil:     hasil,
+		Soal:      make([]ReviewSoal, 0, len(soalList)),
+	}
+	urutanSoal, urutanOpsi := urutanUjianSiswa(ujian, siswaID, &attempt, soalList, kunci)
+	for i, item := range urutkanSoalDenganJawaban(soalList, kunci, urutanSoal, urutanOpsi) {
+		soal := dto.NewSoalSiswaDTO(item.Soal, item.Jawaban, true)
+		reviewSoal := ReviewSoal{
+			NilaiSoal:    nilaiPerSoal[item.Soal.IdSoal],
+			Nomor:        i + 1,
+			Soal:         soal.Soal,
+			JawabanSiswa: jawabanPerSoal[item.Soal.IdSoal].JawabanText,
+			Opsi:         soal.Jawaban,
+			Kunci:        make([]uint64, 0, 1),
+		}
+		for _, opsi := range item.Jawaban {
+			if opsi.Benar {
+				reviewSoal.Kunci = append(reviewSoal.Kunci, opsi.IdJawabanSoal)
+			}
+		}
+		review.Soal = append(review.Soal, reviewSoal)
+	}
+	return review, nil
+}
+
+// ujianSudahBerakhir reports whether the exam window is over for the attempt: after Ujian.WaktuSelesai
+// when the ujian has one, otherwise once the attempt is submitted or its time limit has passed
+func ujianSudahBerakhir(ujian entity.Ujian, attempt entity.UjianAttempt, now time.Time) bool {
+	if !ujian.WaktuSelesai.IsZero() {
+		return now.After(ujian.WaktuSelesai)
+	}
+	return attempt.Status == entity.StatusAttemptSelesai || attemptExpired(attempt, now)
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"cbt-api/entity"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestUjianSudahBerakhir(t *testing.T) {
+	now := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	lewat, nanti := now.Add(-time.Minute), now.Add(time.Minute)
+	tests := []struct {
+		name    string
+		ujian   entity.Ujian
+		attempt entity.UjianAttempt
+		want    bool
+	}{
+		{"window still open", entity.Ujian{WaktuSelesai: nanti}, entity.UjianAttempt{Status: entity.StatusAttemptSelesai}, false},
+		{"window closed", entity.Ujian{WaktuSelesai: lewat}, entity.UjianAttempt{Status: entity.StatusAttemptBerlangsung}, true},
+		{"no window, submitted", entity.Ujian{}, entity.UjianAttempt{Status: entity.StatusAttemptSelesai}, true},
+		{"no window, in progress", entity.Ujian{}, entity.UjianAttempt{Status: entity.StatusAttemptBerlangsung, BatasWaktu: &nanti}, false},
+		{"no window, time limit passed", entity.Ujian{}, entity.UjianAttempt{Status: entity.StatusAttemptBerlangsung, BatasWaktu: &lewat}, true},
+		{"no window, no time limit", entity.Ujian{}, entity.UjianAttempt{Status: entity.StatusAttemptBerlangsung}, false},
+	}
+	for _, tt := range tests {
+		if got := ujianSudahBerakhir(tt.ujian, tt.attempt, now); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReviewAttempt(t *testing.T) {
+	env := newLingkunganTest(t)
+	ujian := env.buatUjian(t, nil)
+	soal, opsi := env.buatSoal(t, ujian, entity.TipeSoalPilihanBerganda, 100,
+		entity.JawabanSoal{Jawaban: "A"}, entity.JawabanSoal{Jawaban: "B", Benar: true})
+	const siswaID = 7
+
+	_, attempt, err := env.ujianAttempt.StartAttempt(ujian.IdUjian, siswaID, "masuk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.jawabanSiswa.CreateJawabanSiswa(entity.JawabanSiswa{
+		IdSoal: soal.IdSoal, IdSiswa: siswaID, IdJawabanSoal: opsi[0].IdJawabanSoal, IdUjianAttempt: &attempt.IdUjianAttempt,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := env.ujianAttempt.ReviewAttempt(attempt.IdUjianAttempt, siswaID); !errors.Is(err, ErrUjianBelumBerakhir) {
+		t.Errorf("review in progress = %v, want ErrUjianBelumBerakhir", err)
+	}
+	if _, _, err := env.ujianAttempt.FinishAttempt(ujian.IdUjian, siswaID, "keluar"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.ujianAttempt.ReviewAttempt(attempt.IdUjianAttempt, siswaID+1); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("review of another siswa's attempt = %v, want ErrRecordNotFound", err)
+	}
+
+	review, err := env.ujianAttempt.ReviewAttempt(attempt.IdUjianAttempt, siswaID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(review.Soal) != 1 {
+		t.Fatalf("review has %d soal, want 1", len(review.Soal))
+	}
+	got := review.Soal[0]
+	if got.Nomor != 1 || got.Benar || got.IdJawabanSoal != opsi[0].IdJawabanSoal || !reflect.DeepEqual(got.Kunci, []uint64{opsi[1].IdJawabanSoal}) {
+		t.Errorf("review soal = nomor %d, benar %v, dipilih %d, kunci %v", got.Nomor, got.Benar, got.IdJawabanSoal, got.Kunci)
+	}
+
+	if err := env.db.Model(&entity.Ujian{}).Where("id_ujian = ?", ujian.IdUjian).
+		Update("status_jawaban", entity.StatusTidakAktif).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.ujianAttempt.ReviewAttempt(attempt.IdUjianAttempt, siswaID); !errors.Is(err, ErrPembahasanTidakAktif) {
+		t.Errorf("review with the key disabled = %v, want ErrPembahasanTidakAktif", err)
+	}
+}
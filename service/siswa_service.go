@@ -0,0 +1,27 @@
+// service/siswa_service.go
+package service
+
+import (
+	"cbt-api/entity"
+	"cbt-api/repository"
+)
+
+// SiswaService is a contract for siswa service
+type SiswaService interface {
+	GetSiswaWithKelas(siswaID uint64) (entity.Siswa, error)
+}
+
+type siswaService struct {
+	siswaRepository repository.SiswaRepository
+}
+
+// NewSiswaService creates a new instance of SiswaService
+func NewSiswaService(siswaRepo repository.SiswaRepository) SiswaService {
+	return &siswaService{
+		siswaRepository: siswaRepo,
+	}
+}
+
+func (s *siswaService) GetSiswaWithKelas(siswaID uint64) (entity.Siswa, error) {
+	return s.siswaRepository.GetSiswaWithKelas(siswaID)
+}
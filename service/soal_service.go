@@ -0,0 +1,280 @@
+// service/soal_service.go
+package service
+
+import (
+	"cbt-api/dto"
+	"cbt-api/entity"
+	"cbt-api/repository"
+	"errors"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrKunciJawabanTersembunyi = errors.New("kunci jawaban belum boleh ditampilkan")
+
+// SoalDenganJawaban is one soal together with its answer options
+type SoalDenganJawaban struct {
+	Soal    entity.Soal          `json:"soal"`
+	Jawaban []entity.JawabanSoal `json:"jawaban"`
+}
+
+// SoalService is a contract for soal service
+type SoalService interface {
+	GetSoalWithJawaban(soalID uint64) (SoalDenganJawaban, error)
+	GetSoalByUjian(ujianID uint64) ([]SoalDenganJawaban, error)
+	GetSoalByLatihan(latihanID uint64) ([]SoalDenganJawaban, error)
+	GetSoalSiswa(soalID uint64, siswaID uint64) (dto.SoalSiswaDTO, error)
+	GetSoalUjianSiswa(ujianID uint64, siswaID uint64) ([]dto.SoalSiswaDTO, error)
+	GetSoalLatihanSiswa(latihanID uint64, siswaID uint64) ([]dto.SoalSiswaDTO, error)
+	GetJawabanSoalByID(jawabanSoalID uint64) (entity.JawabanSoal, error)
+	GetJawabanSoalSiswa(jawabanSoalID uint64, siswaID uint64) (dto.OpsiJawabanDTO, error)
+	CreateSoal(ujianID uint64, input SoalDenganJawaban) (SoalDenganJawaban, error)
+	UpdateSoal(soalID uint64, input SoalDenganJawaban) (SoalDenganJawaban, error)
+	DeleteSoal(soalID uint64) error
+	CreateJawabanSoal(soalID uint64, opsi entity.JawabanSoal) (entity.JawabanSoal, error)
+	UpdateJawabanSoal(jawabanSoalID uint64, opsi entity.JawabanSoal) (entity.JawabanSoal, error)
+	DeleteJawabanSoal(jawabanSoalID uint64) error
+	ImportSoal(input ImportSoal) (HasilImportSoal, error)
+	ExportSoal(input EksporSoal) (HasilEksporSoal, error)
+	ExportQTI(ujianID uint64) (HasilEksporSoal, error)
+	ImportQTI(input ImportQTI) (HasilImportQTI, error)
+	GetBankSoal(filter FilterBankSoal) ([]BankSoal, error)
+	CreateBankSoal(input BankSoal) (BankSoal, error)
+	UpdateBankSoal(soalID uint64, input BankSoal) (BankSoal, error)
+	TautkanSoalUjian(ujianID uint64, soalID uint64, nilaiPerSoal *float64) (entity.UjianSoal, error)
+	LepasSoalUjian(ujianID uint64, soalID uint64) error
+	TautkanSoalLatihan(latihanID uint64, soalID uint64) (entity.LatihanSoal, error)
+	LepasSoalLatihan(latihanID uint64, soalID uint64) error
+}
+
+type soalService struct {
+	transactor             repository.Transactor
+	soalRepository         repository.SoalRepository
+	jawabanSoalRepository  repository.JawabanSoalRepository
+	ujianRepository        repository.UjianRepository
+	latihanRepository      repository.LatihanRepository
+	ujianAttemptRepository repository.UjianAttemptRepository
+	kursusRepository       repository.KursusRepository
+	bankSoalRepository     repository.BankSoalRepository
+	kunciLatihan           kunciLatihan
+	klienMedia             *http.Client
+}
+
+// NewSoalService creates a new instance of SoalService
+func NewSoalService(
+	transactor repository.Transactor,
+	soalRepo repository.SoalRepository,
+	jawabanSoalRepo repository.JawabanSoalRepository,
+	ujianRepo repository.UjianRepository,
+	latihanRepo repository.LatihanRepository,
+	ujianAttemptRepo repository.UjianAttemptRepository,
+	kursusRepo repository.KursusRepository,
+	bankSoalRepo repository.BankSoalRepository,
+) SoalService {
+	return &soalService{
+		transactor:             transactor,
+		soalRepository:         soalRepo,
+		jawabanSoalRepository:  jawabanSoalRepo,
+		ujianRepository:        ujianRepo,
+		latihanRepository:      latihanRepo,
+		ujianAttemptRepository: ujianAttemptRepo,
+		kursusRepository:       kursusRepo,
+		bankSoalRepository:     bankSoalRepo,
+		kunciLatihan:           kunciLatihan{latihanRepository: latihanRepo, soalRepository: soalRepo, ujianRepository: ujianRepo},
+		klienMedia:             newKlienMedia(),
+	}
+}
+
+func (s *soalService) GetSoalWithJawaban(soalID uint64) (SoalDenganJawaban, error) {
+	soal, err := s.soalRepository.FindById(soalID)
+	if err != nil {
+		return SoalDenganJawaban{}, err
+	}
+
+	jawaban, err := s.jawabanSoalRepository.FindByIdSoal(soalID)
+	return SoalDenganJawaban{Soal: soal, Jawaban: jawaban}, err
+}
+
+func (s *soalService) GetSoalByUjian(ujianID uint64) ([]SoalDenganJawaban, error) {
+	soalList, err := s.soalRepository.FindByIdUjian(ujianID)
+	if err != nil {
+		return nil, err
+	}
+	return s.withJawaban(soalList)
+}
+
+func (s *soalService) GetSoalByLatihan(latihanID uint64) ([]SoalDenganJawaban, error) {
+	soalList, err := s.soalRepository.FindByIdLatihan(latihanID)
+	if err != nil {
+		return nil, err
+	}
+	return s.withJawaban(soalList)
+}
+
+// GetSoalSiswa returns one soal with its options as shown to the siswa
+func (s *soalService) GetSoalSiswa(soalID uint64, siswaID uint64) (dto.SoalSiswaDTO, error) {
+	soal, err := s.soalRepository.FindByIdWithTipeSoal(soalID)
+	if err != nil {
+		return dto.SoalSiswaDTO{}, err
+	}
+	jawaban, err := s.jawabanSoalRepository.FindByIdSoal(soalID)
+	if err != nil {
+		return dto.SoalSiswaDTO{}, err
+	}
+	terlihat, err := s.kunciTerlihat(soal, siswaID)
+	if err != nil {
+		return dto.SoalSiswaDTO{}, err
+	}
+	return dto.NewSoalSiswaDTO(soal, jawaban, terlihat), nil
+}
+
+// GetSoalUjianSiswa returns the soal of the ujian in the order the siswa sees them: the order stored
+// in the siswa's attempt, or before the siswa starts, the order the attempt will get. The answer key
+// is included under the rules of kunciUjianTerlihat.
+func (s *soalService) GetSoalUjianSiswa(ujianID uint64, siswaID uint64) ([]dto.SoalSiswaDTO, error) {
+	ujian, err := s.ujianRepository.GetUjianByID(ujianID)
+	if err != nil {
+		return nil, err
+	}
+	soalList, err := s.soalRepository.FindByIdUjianWithTipeSoal(ujianID)
+	if err != nil {
+		return nil, err
+	}
+	kunci, err := s.jawabanSoalRepository.FindByIdUjian(ujianID)
+	if err != nil {
+		return nil, err
+	}
+	attempt, err := s.attemptTerakhir(ujianID, siswaID)
+	if err != nil {
+		return nil, err
+	}
+
+	urutanSoal, urutanOpsi := urutanUjianSiswa(ujian, siswaID, attempt, soalList, kunci)
+	return toSoalSiswaList(urutkanSoalDenganJawaban(soalList, kunci, urutanSoal, urutanOpsi), kunciUjianTerlihat(ujian, attempt, time.Now())), nil
+}
+
+// GetSoalLatihanSiswa returns the soal of the latihan in the siswa's order, shuffled per siswa when
+// Latihan.Acak is Aktif. Latihan has no attempt, so the order is derived from the seed every time.
+// The answer key is included under the rules of kunciLatihan.
+func (s *soalService) GetSoalLatihanSiswa(latihanID uint64, siswaID uint64) ([]dto.SoalSiswaDTO, error) {
+	latihan, err := s.latihanRepository.GetLatihanByID(latihanID)
+	if err != nil {
+		return nil, err
+	}
+	soalList, err := s.soalRepository.FindByIdLatihanWithTipeSoal(latihanID)
+	if err != nil {
+		return nil, err
+	}
+	var kunci []entity.JawabanSoal
+	for _, soal := range soalList {
+		jawaban, err := s.jawabanSoalRepository.FindByIdSoal(soal.IdSoal)
+		if err != nil {
+			return nil, err
+		}
+		kunci = append(kunci, jawaban...)
+	}
+	soalIDs := make([]uint64, 0, len(soalList))
+	for _, soal := range soalList {
+		soalIDs = append(soalIDs, soal.IdSoal)
+	}
+	terlihat, err := s.kunciLatihan.latihanTerlihat(latihan, soalIDs, siswaID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	urutanSoal, urutanOpsi := susunUrutan(soalList, kunci, latihan.Acak == entity.StatusAktif, seedAcak("latihan", latihanID, siswaID))
+	return toSoalSiswaList(urutkanSoalDenganJawaban(soalList, kunci, urutanSoal, urutanOpsi), terlihat), nil
+}
+
+func (s *soalService) GetJawabanSoalByID(jawabanSoalID uint64) (entity.JawabanSoal, error) {
+	return s.jawabanSoalRepository.FindById(jawabanSoalID)
+}
+
+// GetJawabanSoalSiswa returns one answer option as shown to the siswa. Options of an Isian soal are
+// accepted answers and stay hidden until the answer key may be shown.
+func (s *soalService) GetJawabanSoalSiswa(jawabanSoalID uint64, siswaID uint64) (dto.OpsiJawabanDTO, error) {
+	jawabanSoal, err := s.jawabanSoalRepository.FindById(jawabanSoalID)
+	if err != nil {
+		return dto.OpsiJawabanDTO{}, err
+	}
+	soal, err := s.soalRepository.FindByIdWithTipeSoal(jawabanSoal.IdSoal)
+	if err != nil {
+		return dto.OpsiJawabanDTO{}, err
+	}
+	terlihat, err := s.kunciTerlihat(soal, siswaID)
+	if err != nil {
+		return dto.OpsiJawabanDTO{}, err
+	}
+	if soal.TipeSoal.NamaTipeUjian == entity.TipeSoalIsian && !terlihat {
+		return dto.OpsiJawabanDTO{}, ErrKunciJawabanTersembunyi
+	}
+	return dto.NewOpsiJawabanDTO(jawabanSoal, terlihat), nil
+}
+
+// kunciTerlihat reports whether the siswa may see the answer key of the soal. Soal that belong to
+// neither an ujian nor a latihan directly never show their key to a siswa.
+func (s *soalService) kunciTerlihat(soal entity.Soal, siswaID uint64) (bool, error) {
+	switch {
+	case soal.IdUjian != 0:
+		ujian, err := s.ujianRepository.GetUjianByID(soal.IdUjian)
+		if err != nil {
+			return false, err
+		}
+		attempt, err := s.attemptTerakhir(soal.IdUjian, siswaID)
+		if err != nil {
+			return false, err
+		}
+		return kunciUjianTerlihat(ujian, attempt, time.Now()), nil
+	case soal.IdLatihan != 0:
+		latihan, err := s.latihanRepository.GetLatihanByID(soal.IdLatihan)
+		if err != nil {
+			return false, err
+		}
+		return s.kunciLatihan.latihanTerlihat(latihan, []uint64{soal.IdSoal}, siswaID, time.Now())
+	default:
+		return false, nil
+	}
+}
+
+// attemptTerakhir returns the siswa's latest attempt of the ujian, or nil when the siswa has not started it
+func (s *soalService) attemptTerakhir(ujianID uint64, siswaID uint64) (*entity.UjianAttempt, error) {
+	attempt, err := s.ujianAttemptRepository.GetLatestAttempt(ujianID, siswaID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+// kunciUjianTerlihat reports whether the answer key of the ujian may be shown: only when the ujian's
+// StatusJawaban is Aktif, after the siswa has submitted the attempt and once the exam window has ended
+// (ujianSudahBerakhir), the same gate as the review, so siswa who finish early cannot pass the key on
+func kunciUjianTerlihat(ujian entity.Ujian, attempt *entity.UjianAttempt, now time.Time) bool {
+	return ujian.StatusJawaban == entity.StatusAktif && attempt != nil &&
+		attempt.Status == entity.StatusAttemptSelesai && ujianSudahBerakhir(ujian, *attempt, now)
+}
+
+func toSoalSiswaList(soalList []SoalDenganJawaban, tampilkanKunci bool) []dto.SoalSiswaDTO {
+	result := make([]dto.SoalSiswaDTO, 0, len(soalList))
+	for _, soal := range soalList {
+		result = append(result, dto.NewSoalSiswaDTO(soal.Soal, soal.Jawaban, tampilkanKunci))
+	}
+	return result
+}
+
+// withJawaban loads the answer options of every soal, keeping the soal order
+func (s *soalService) withJawaban(soalList []entity.Soal) ([]SoalDenganJawaban, error) {
+	var result []SoalDenganJawaban
+	for _, soal := range soalList {
+		jawaban, err := s.jawabanSoalRepository.FindByIdSoal(soal.IdSoal)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, SoalDenganJawaban{Soal: soal, Jawaban: jawaban})
+	}
+	return result, nil
+}
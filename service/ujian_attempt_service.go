@@ -0,0 +1,410 @@
+// service/ujian_attempt_service.go
+package service
+
+import (
+	"cbt-api/entity"
+	"cbt-api/repository"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPasswordUjianSalah  = errors.New("password ujian salah")
+	ErrUjianBelumDimulai   = errors.New("ujian belum dimulai")
+	ErrUjianSudahBerakhir  = errors.New("waktu ujian sudah berakhir")
+	ErrAttemptTidakAda     = errors.New("siswa belum memulai ujian ini")
+	ErrAttemptSelesai      = errors.New("ujian sudah diselesaikan oleh siswa")
+	ErrAttemptBelumSelesai = errors.New("nilai tersedia setelah siswa menyelesaikan ujian")
+	ErrWaktuAttemptHabis   = errors.New("batas waktu pengerjaan siswa sudah habis")
+	ErrSoalBukanUjian      = errors.New("soal tidak termasuk dalam ujian ini")
+	ErrUjianBelumSiap      = errors.New("ujian belum siap dikerjakan")
+)
+
+// JawabanDraft is one answer saved so far in an attempt that is still in progress
+type JawabanDraft struct {
+	IdSoal        uint64    `json:"id_soal"`
+	IdJawabanSoal uint64    `json:"id_jawaban_soal"`
+	Pilihan       []uint64  `json:"pilihan"`
+	JawabanSiswa  string    `json:"jawaban_siswa"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// ResumeUjian is everything the app needs to continue an attempt after a crash or reload
+type ResumeUjian struct {
+	Attempt    entity.UjianAttempt `json:"attempt"`
+	SisaWaktu  *int64              `json:"sisa_waktu"` // detik; nil jika ujian tidak punya batas waktu
+	UrutanSoal []uint64            `json:"urutan_soal"`
+	UrutanOpsi map[uint64][]uint64 `json:"urutan_opsi"` // id_jawaban_soal per id_soal
+	Jawaban    []JawabanDraft      `json:"jawaban"`
+}
+
+// UjianAttemptService is a contract for ujian attempt service
+type UjianAttemptService interface {
+	StartAttempt(ujianID uint64, siswaID uint64, passwordMasuk string) (entity.Ujian, entity.UjianAttempt, error)
+	FinishAttempt(ujianID uint64, siswaID uint64, passwordKeluar string) (entity.Ujian, HasilUjian, error)
+	SaveDraftJawaban(ujianID uint64, jawaban entity.JawabanSiswa) (JawabanDraft, error)
+	ResumeAttempt(ujianID uint64, siswaID uint64) (ResumeUjian, error)
+	GetLatestAttempt(ujianID uint64, siswaID uint64) (entity.UjianAttempt, error)
+	GetAttemptsByUjianID(ujianID uint64) ([]entity.UjianAttempt, error)
+	ReviewAttempt(attemptID uint64, siswaID uint64) (ReviewUjian, error)
+	ValidateSubmission(jawabanList []entity.JawabanSiswa) error
+}
+
+type ujianAttemptService struct {
+	ujianAttemptRepository repository.UjianAttemptRepository
+	ujianRepository        repository.UjianRepository
+	soalRepository         repository.SoalRepository
+	jawabanSoalRepository  repository.JawabanSoalRepository
+	jawabanSiswaRepository repository.JawabanSiswaRepository
+	nilaiService           NilaiService
+}
+
+// NewUjianAttemptService creates a new instance of UjianAttemptService
+func NewUjianAttemptService(
+	ujianAttemptRepo repository.UjianAttemptRepository,
+	ujianRepo repository.UjianRepository,
+	soalRepo repository.SoalRepository,
+	jawabanSoalRepo repository.JawabanSoalRepository,
+	jawabanSiswaRepo repository.JawabanSiswaRepository,
+	nilaiService NilaiService,
+) UjianAttemptService {
+	return &ujianAttemptService{
+		ujianAttemptRepository: ujianAttemptRepo,
+		ujianRepository:        ujianRepo,
+		soalRepository:         soalRepo,
+		jawabanSoalRepository:  jawabanSoalRepo,
+		jawabanSiswaRepository: jawabanSiswaRepo,
+		nilaiService:           nilaiService,
+	}
+}
+
+// StartAttempt checks the entry password and the ujian window, then records a new attempt with the
+// soal and option order of the siswa, shuffled when Ujian.Acak is Aktif. If the siswa already has an
+// attempt in progress, that attempt is returned so the exam can be resumed; a siswa has at most one
+// attempt per ujian, which the unique index on ujian_attempt keeps true for concurrent starts.
+// A new attempt is only started on an ujian that passes ValidasiUjian, so the nilai_per_soal of its
+// soal add up to the grade.
+func (s *ujianAttemptService) StartAttempt(ujianID uint64, siswaID uint64, passwordMasuk string) (entity.Ujian, entity.UjianAttempt, error) {
+	ujian, err := s.ujianRepository.GetUjianByID(ujianID)
+	if err != nil {
+		return ujian, entity.UjianAttempt{}, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(ujian.PasswordMasuk), []byte(passwordMasuk)); err != nil {
+		return ujian, entity.UjianAttempt{}, ErrPasswordUjianSalah
+	}
+
+	now := time.Now()
+	if !ujian.WaktuMulai.IsZero() && now.Before(ujian.WaktuMulai) {
+		return ujian, entity.UjianAttempt{}, ErrUjianBelumDimulai
+	}
+	if !ujian.WaktuSelesai.IsZero() && now.After(ujian.WaktuSelesai) {
+		return ujian, entity.UjianAttempt{}, ErrUjianSudahBerakhir
+	}
+
+	existing, err := s.ujianAttemptRepository.GetLatestAttempt(ujianID, siswaID)
+	if err == nil {
+		return ujian, existing, lanjutkanAttempt(existing, now)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return ujian, entity.UjianAttempt{}, err
+	}
+
+	soalList, err := s.soalRepository.FindByIdUjianWithTipeSoal(ujianID)
+	if err != nil {
+		return ujian, entity.UjianAttempt{}, err
+	}
+	kunci, err := s.jawabanSoalRepository.FindByIdUjian(ujianID)
+	if err != nil {
+		return ujian, entity.UjianAttempt{}, err
+	}
+	if masalah := masalahUjian(ujian, soalList, kunci); len(masalah) > 0 {
+		return ujian, entity.UjianAttempt{}, fmt.Errorf("%w: %s", ErrUjianBelumSiap, strings.Join(masalah, "; "))
+	}
+	urutanSoal, urutanOpsi := urutanUjianSiswa(ujian, siswaID, nil, soalList, kunci)
+
+	attempt := entity.UjianAttempt{
+		IdUjian:    ujianID,
+		IdSiswa:    siswaID,
+		WaktuMulai: now,
+		BatasWaktu: hitungBatasWaktu(ujian, now),
+		Status:     entity.StatusAttemptBerlangsung,
+		UrutanSoal: formatUrutanSoal(urutanSoal),
+		UrutanOpsi: formatUrutanOpsi(urutanSoal, urutanOpsi),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	created, err := s.ujianAttemptRepository.CreateAttempt(attempt)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		// Permintaan lain dari siswa yang sama membuat attempt lebih dulu; attempt itu yang dilanjutkan
+		existing, err := s.ujianAttemptRepository.GetLatestAttempt(ujianID, siswaID)
+		if err != nil {
+			return ujian, entity.UjianAttempt{}, err
+		}
+		return ujian, existing, lanjutkanAttempt(existing, now)
+	}
+	return ujian, created, err
+}
+
+// lanjutkanAttempt reports whether the siswa's existing attempt can be resumed
+func lanjutkanAttempt(attempt entity.UjianAttempt, now time.Time) error {
+	if attempt.Status == entity.StatusAttemptSelesai {
+		return ErrAttemptSelesai
+	}
+	if attemptExpired(attempt, now) {
+		return ErrWaktuAttemptHabis
+	}
+	return nil
+}
+
+// FinishAttempt checks the exit password, then submits the siswa's attempt: the attempt is
+// closed and graded, and tipe_nilai, nilai_kursus and nilai are updated in one transaction
+func (s *ujianAttemptService) FinishAttempt(ujianID uint64, siswaID uint64, passwordKeluar string) (entity.Ujian, HasilUjian, error) {
+	ujian, err := s.ujianRepository.GetUjianByID(ujianID)
+	if err != nil {
+		return ujian, HasilUjian{}, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(ujian.PasswordKeluar), []byte(passwordKeluar)); err != nil {
+		return ujian, HasilUjian{}, ErrPasswordUjianSalah
+	}
+
+	attempt, err := s.ujianAttemptRepository.GetLatestAttempt(ujianID, siswaID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ujian, HasilUjian{}, ErrAttemptTidakAda
+		}
+		return ujian, HasilUjian{}, err
+	}
+	if attempt.Status == entity.StatusAttemptSelesai {
+		return ujian, HasilUjian{Attempt: attempt}, ErrAttemptSelesai
+	}
+
+	// Siswa yang keluar setelah batas waktu tetap dinilai, waktu submit-nya yang menunjukkan keterlambatan
+	hasil, err := s.nilaiService.SubmitAttempt(ujian, attempt)
+	return ujian, hasil, err
+}
+
+// SaveDraftJawaban autosaves the answer of one soal while the attempt is in progress. Saving the
+// same soal again replaces the draft; after the attempt is submitted the drafts are locked.
+func (s *ujianAttemptService) SaveDraftJawaban(ujianID uint64, jawaban entity.JawabanSiswa) (JawabanDraft, error) {
+	ujianIDs, err := s.soalRepository.FindIdUjianBySoal(jawaban.IdSoal)
+	if err != nil {
+		return JawabanDraft{}, err
+	}
+	if !containsUint64(ujianIDs, ujianID) {
+		return JawabanDraft{}, ErrSoalBukanUjian
+	}
+
+	// Soal bank bisa dipakai beberapa ujian; attempt diambil dari ujian yang sedang dikerjakan ini
+	attemptID, err := s.attemptJawaban([]uint64{ujianID}, jawaban.IdSiswa, make(map[attemptKey]uint64), time.Now())
+	if err != nil {
+		return JawabanDraft{}, err
+	}
+	jawaban.IdUjianAttempt = &attemptID
+
+	saved, err := s.jawabanSiswaRepository.UpsertJawabanSiswa(jawaban)
+	return toJawabanDraft(saved), err
+}
+
+// ResumeAttempt returns the attempt in progress with its saved answers, the remaining time and
+// the soal and option order that was fixed when the attempt started
+func (s *ujianAttemptService) ResumeAttempt(ujianID uint64, siswaID uint64) (ResumeUjian, error) {
+	attempt, err := s.ujianAttemptRepository.GetLatestAttempt(ujianID, siswaID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ResumeUjian{}, ErrAttemptTidakAda
+		}
+		return ResumeUjian{}, err
+	}
+	if attempt.Status == entity.StatusAttemptSelesai {
+		return ResumeUjian{Attempt: attempt}, ErrAttemptSelesai
+	}
+	now := time.Now()
+	if attemptExpired(attempt, now) {
+		return ResumeUjian{Attempt: attempt}, ErrWaktuAttemptHabis
+	}
+
+	ujian, err := s.ujianRepository.GetUjianByID(ujianID)
+	if err != nil {
+		return ResumeUjian{}, err
+	}
+	soalList, err := s.soalRepository.FindByIdUjianWithTipeSoal(ujianID)
+	if err != nil {
+		return ResumeUjian{}, err
+	}
+	kunci, err := s.jawabanSoalRepository.FindByIdUjian(ujianID)
+	if err != nil {
+		return ResumeUjian{}, err
+	}
+	jawabanList, err := s.jawabanSiswaRepository.FindByAttempt(attempt.IdUjianAttempt)
+	if err != nil {
+		return ResumeUjian{}, err
+	}
+
+	resume := ResumeUjian{
+		Attempt: attempt,
+		Jawaban: make([]JawabanDraft, 0, len(jawabanList)),
+	}
+	resume.UrutanSoal, resume.UrutanOpsi = urutanUjianSiswa(ujian, siswaID, &attempt, soalList, kunci)
+	if attempt.BatasWaktu != nil {
+		sisa := int64(attempt.BatasWaktu.Sub(now) / time.Second)
+		if sisa < 0 {
+			sisa = 0
+		}
+		resume.SisaWaktu = &sisa
+	}
+	for _, jawaban := range jawabanList {
+		resume.Jawaban = append(resume.Jawaban, toJawabanDraft(jawaban))
+	}
+	return resume, nil
+}
+
+func (s *ujianAttemptService) GetLatestAttempt(ujianID uint64, siswaID uint64) (entity.UjianAttempt, error) {
+	return s.ujianAttemptRepository.GetLatestAttempt(ujianID, siswaID)
+}
+
+func (s *ujianAttemptService) GetAttemptsByUjianID(ujianID uint64) ([]entity.UjianAttempt, error) {
+	return s.ujianAttemptRepository.GetAttemptsByUjianID(ujianID)
+}
+
+type attemptKey struct {
+	ujianID uint64
+	siswaID uint64
+}
+
+// ValidateSubmission makes sure every jawaban belongs to an attempt that is still in progress
+// and within its deadline, and sets IdUjianAttempt on each of them. The attempt sent by the client
+// is never kept: soal that are not part of an ujian (latihan) are not checked and get a nil attempt.
+func (s *ujianAttemptService) ValidateSubmission(jawabanList []entity.JawabanSiswa) error {
+	soalUjian := make(map[uint64][]uint64)
+	checked := make(map[attemptKey]uint64)
+	now := time.Now()
+
+	for i, jawaban := range jawabanList {
+		ujianIDs, ok := soalUjian[jawaban.IdSoal]
+		if !ok {
+			var err error
+			ujianIDs, err = s.soalRepository.FindIdUjianBySoal(jawaban.IdSoal)
+			if err != nil {
+				return err
+			}
+			soalUjian[jawaban.IdSoal] = ujianIDs
+		}
+		if len(ujianIDs) == 0 {
+			jawabanList[i].IdUjianAttempt = nil
+			continue
+		}
+
+		attemptID, err := s.attemptJawaban(ujianIDs, jawaban.IdSiswa, checked, now)
+		if err != nil {
+			return err
+		}
+		jawabanList[i].IdUjianAttempt = &attemptID
+	}
+
+	return nil
+}
+
+// attemptJawaban returns the siswa's attempt in progress that an answer to a soal of the given ujian
+// belongs to. A bank soal linked to several ujian belongs to the one the siswa is working on; when
+// there is none, the error describes the last ujian checked. checked caches attempts per ujian.
+func (s *ujianAttemptService) attemptJawaban(ujianIDs []uint64, siswaID uint64, checked map[attemptKey]uint64, now time.Time) (uint64, error) {
+	errAttempt := ErrAttemptTidakAda
+	for _, ujianID := range ujianIDs {
+		key := attemptKey{ujianID: ujianID, siswaID: siswaID}
+		if attemptID, ok := checked[key]; ok {
+			return attemptID, nil
+		}
+
+		attempt, err := s.ujianAttemptRepository.GetLatestAttempt(ujianID, siswaID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if attempt.Status == entity.StatusAttemptSelesai {
+			errAttempt = ErrAttemptSelesai
+			continue
+		}
+		if attemptExpired(attempt, now) {
+			errAttempt = ErrWaktuAttemptHabis
+			continue
+		}
+
+		checked[key] = attempt.IdUjianAttempt
+		return attempt.IdUjianAttempt, nil
+	}
+	return 0, errAttempt
+}
+
+func containsUint64(list []uint64, id uint64) bool {
+	for _, v := range list {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// hitungBatasWaktu returns the earlier of (mulai + Durasi menit) and Ujian.WaktuSelesai
+func hitungBatasWaktu(ujian entity.Ujian, mulai time.Time) *time.Time {
+	var batas time.Time
+	if !ujian.WaktuSelesai.IsZero() {
+		batas = ujian.WaktuSelesai
+	}
+	if ujian.Durasi > 0 {
+		batasDurasi := mulai.Add(time.Duration(ujian.Durasi) * time.Minute)
+		if batas.IsZero() || batasDurasi.Before(batas) {
+			batas = batasDurasi
+		}
+	}
+	if batas.IsZero() {
+		return nil
+	}
+	return &batas
+}
+
+func attemptExpired(attempt entity.UjianAttempt, now time.Time) bool {
+	return attempt.BatasWaktu != nil && now.After(*attempt.BatasWaktu)
+}
+
+func toJawabanDraft(jawaban entity.JawabanSiswa) JawabanDraft {
+	pilihan := make([]uint64, 0, len(jawaban.Pilihan))
+	for _, p := range jawaban.Pilihan {
+		pilihan = append(pilihan, p.IdJawabanSoal)
+	}
+	return JawabanDraft{
+		IdSoal:        jawaban.IdSoal,
+		IdJawabanSoal: jawaban.IdJawabanSoal,
+		Pilihan:       pilihan,
+		JawabanSiswa:  jawaban.JawabanText,
+		UpdatedAt:     jawaban.UpdatedAt,
+	}
+}
+
+func formatUrutanSoal(urutan []uint64) string {
+	ids := make([]string, 0, len(urutan))
+	for _, id := range urutan {
+		ids = append(ids, strconv.FormatUint(id, 10))
+	}
+	return strings.Join(ids, ",")
+}
+
+// urutanSoalAttempt returns the stored question order of the attempt. Soal that were added to the
+// ujian after the attempt started are appended, soal that were removed are dropped. Attempts
+// without a stored order use the database order.
+func urutanSoalAttempt(attempt entity.UjianAttempt, soalList []entity.Soal) []uint64 {
+	ids := make([]uint64, 0, len(soalList))
+	for _, soal := range soalList {
+		ids = append(ids, soal.IdSoal)
+	}
+	return terapkanUrutan(parseUrutanSoal(attempt.UrutanSoal), ids)
+}
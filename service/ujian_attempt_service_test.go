@@ -0,0 +1,396 @@
+package service
+
+import (
+	"cbt-api/entity"
+	"cbt-api/repository"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// ujianRepoPalsu serves ujian from a map; methods the tests do not need are left to the embedded interface
+type ujianRepoPalsu struct {
+	repository.UjianRepository
+	ujian map[uint64]entity.Ujian
+}
+
+func (r *ujianRepoPalsu) GetUjianByID(ujianID uint64) (entity.Ujian, error) {
+	ujian, ok := r.ujian[ujianID]
+	if !ok {
+		return ujian, gorm.ErrRecordNotFound
+	}
+	return ujian, nil
+}
+
+type soalRepoPalsu struct {
+	repository.SoalRepository
+	soal map[uint64]entity.Soal
+}
+
+func (r *soalRepoPalsu) FindById(id uint64) (entity.Soal, error) {
+	soal, ok := r.soal[id]
+	if !ok {
+		return soal, gorm.ErrRecordNotFound
+	}
+	return soal, nil
+}
+
+func (r *soalRepoPalsu) FindByIdUjianWithTipeSoal(ujianID uint64) ([]entity.Soal, error) {
+	var soalList []entity.Soal
+	for id := uint64(1); id <= uint64(len(r.soal)); id++ {
+		if soal, ok := r.soal[id]; ok && soal.IdUjian == ujianID {
+			soalList = append(soalList, soal)
+		}
+	}
+	return soalList, nil
+}
+
+// FindIdUjianBySoal only knows the soal's own ujian; the fake has no bank links
+func (r *soalRepoPalsu) FindIdUjianBySoal(id uint64) ([]uint64, error) {
+	soal, err := r.FindById(id)
+	if err != nil || soal.IdUjian == 0 {
+		return nil, err
+	}
+	return []uint64{soal.IdUjian}, nil
+}
+
+// jawabanSoalRepoPalsu has no options; the fake soal are graded elsewhere
+type jawabanSoalRepoPalsu struct {
+	repository.JawabanSoalRepository
+}
+
+func (r *jawabanSoalRepoPalsu) FindByIdUjian(ujianID uint64) ([]entity.JawabanSoal, error) {
+	return nil, nil
+}
+
+type attemptRepoPalsu struct {
+	repository.UjianAttemptRepository
+	attempts []entity.UjianAttempt
+}
+
+func (r *attemptRepoPalsu) CreateAttempt(attempt entity.UjianAttempt) (entity.UjianAttempt, error) {
+	attempt.IdUjianAttempt = uint64(len(r.attempts) + 1)
+	r.attempts = append(r.attempts, attempt)
+	return attempt, nil
+}
+
+func (r *attemptRepoPalsu) GetLatestAttempt(ujianID uint64, siswaID uint64) (entity.UjianAttempt, error) {
+	for i := len(r.attempts) - 1; i >= 0; i-- {
+		if r.attempts[i].IdUjian == ujianID && r.attempts[i].IdSiswa == siswaID {
+			return r.attempts[i], nil
+		}
+	}
+	return entity.UjianAttempt{}, gorm.ErrRecordNotFound
+}
+
+func hashPassword(t *testing.T, password string) string {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(hash)
+}
+
+func TestHitungBatasWaktu(t *testing.T) {
+	mulai := time.Date(2026, 5, 4, 8, 0, 0, 0, time.UTC)
+	selesai := mulai.Add(time.Hour)
+	tests := []struct {
+		name  string
+		ujian entity.Ujian
+		want  time.Time
+	}{
+		{"no limit", entity.Ujian{}, time.Time{}},
+		{"duration only", entity.Ujian{Durasi: 90}, mulai.Add(90 * time.Minute)},
+		{"window only", entity.Ujian{WaktuSelesai: selesai}, selesai},
+		{"duration ends first", entity.Ujian{Durasi: 30, WaktuSelesai: selesai}, mulai.Add(30 * time.Minute)},
+		{"window ends first", entity.Ujian{Durasi: 90, WaktuSelesai: selesai}, selesai},
+	}
+	for _, tt := range tests {
+		got := hitungBatasWaktu(tt.ujian, mulai)
+		if (got == nil) != tt.want.IsZero() || (got != nil && !got.Equal(tt.want)) {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStartAttempt(t *testing.T) {
+	now := time.Now()
+	ujianRepo := &ujianRepoPalsu{ujian: map[uint64]entity.Ujian{
+		1: {IdUjian: 1, PasswordMasuk: hashPassword(t, "masuk"), Durasi: 60, Grade: 100},
+		2: {IdUjian: 2, PasswordMasuk: hashPassword(t, "masuk"), WaktuMulai: now.Add(time.Hour)},
+		3: {IdUjian: 3, PasswordMasuk: hashPassword(t, "masuk"), WaktuSelesai: now.Add(-time.Hour)},
+		4: {IdUjian: 4, PasswordMasuk: hashPassword(t, "masuk"), Grade: 100},
+		5: {IdUjian: 5, PasswordMasuk: hashPassword(t, "masuk"), Grade: 100},
+	}}
+	// Soal Isian tanpa variasi jawaban dinilai manual, jadi tidak butuh opsi
+	isian := entity.TipeSoal{NamaTipeUjian: entity.TipeSoalIsian}
+	soalRepo := &soalRepoPalsu{soal: map[uint64]entity.Soal{
+		1: {IdSoal: 1, IdUjian: 1, NilaiPerSoal: 60, TipeSoal: isian},
+		2: {IdSoal: 2, IdUjian: 1, NilaiPerSoal: 40, TipeSoal: isian},
+		3: {IdSoal: 3, IdUjian: 4, NilaiPerSoal: 60, TipeSoal: isian},
+	}}
+	attemptRepo := &attemptRepoPalsu{}
+	s := NewUjianAttemptService(attemptRepo, ujianRepo, soalRepo, &jawabanSoalRepoPalsu{}, nil, nil)
+
+	if _, _, err := s.StartAttempt(1, 7, "salah"); !errors.Is(err, ErrPasswordUjianSalah) {
+		t.Errorf("wrong password = %v, want ErrPasswordUjianSalah", err)
+	}
+	if _, _, err := s.StartAttempt(2, 7, "masuk"); !errors.Is(err, ErrUjianBelumDimulai) {
+		t.Errorf("before the window = %v, want ErrUjianBelumDimulai", err)
+	}
+	if _, _, err := s.StartAttempt(3, 7, "masuk"); !errors.Is(err, ErrUjianSudahBerakhir) {
+		t.Errorf("after the window = %v, want ErrUjianSudahBerakhir", err)
+	}
+	if _, _, err := s.StartAttempt(4, 7, "masuk"); !errors.Is(err, ErrUjianBelumSiap) {
+		t.Errorf("soal worth 60 of grade 100 = %v, want ErrUjianBelumSiap", err)
+	}
+	if _, _, err := s.StartAttempt(5, 7, "masuk"); !errors.Is(err, ErrUjianBelumSiap) {
+		t.Errorf("ujian without soal = %v, want ErrUjianBelumSiap", err)
+	}
+	if len(attemptRepo.attempts) != 0 {
+		t.Fatalf("rejected starts recorded %d attempts", len(attemptRepo.attempts))
+	}
+
+	_, attempt, err := s.StartAttempt(1, 7, "masuk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempt.Status != entity.StatusAttemptBerlangsung || attempt.BatasWaktu == nil ||
+		!attempt.BatasWaktu.Equal(attempt.WaktuMulai.Add(time.Hour)) {
+		t.Errorf("attempt = status %q, batas %v; want Berlangsung ending an hour after %v",
+			attempt.Status, attempt.BatasWaktu, attempt.WaktuMulai)
+	}
+
+	// Memulai lagi melanjutkan attempt yang sama
+	_, lanjut, err := s.StartAttempt(1, 7, "masuk")
+	if err != nil || lanjut.IdUjianAttempt != attempt.IdUjianAttempt || len(attemptRepo.attempts) != 1 {
+		t.Errorf("restart = attempt %d, %v with %d attempts; want attempt %d resumed",
+			lanjut.IdUjianAttempt, err, len(attemptRepo.attempts), attempt.IdUjianAttempt)
+	}
+
+	lewat := now.Add(-time.Minute)
+	attemptRepo.attempts[0].BatasWaktu = &lewat
+	if _, _, err := s.StartAttempt(1, 7, "masuk"); !errors.Is(err, ErrWaktuAttemptHabis) {
+		t.Errorf("restart after the time limit = %v, want ErrWaktuAttemptHabis", err)
+	}
+	attemptRepo.attempts[0].Status = entity.StatusAttemptSelesai
+	if _, _, err := s.StartAttempt(1, 7, "masuk"); !errors.Is(err, ErrAttemptSelesai) {
+		t.Errorf("restart after submit = %v, want ErrAttemptSelesai", err)
+	}
+}
+
+func TestValidateSubmission(t *testing.T) {
+	lewat := time.Now().Add(-time.Minute)
+	soalRepo := &soalRepoPalsu{soal: map[uint64]entity.Soal{
+		1: {IdSoal: 1, IdUjian: 1},
+		2: {IdSoal: 2, IdUjian: 2},
+		3: {IdSoal: 3, IdUjian: 3},
+		4: {IdSoal: 4, IdUjian: 4},
+		5: {IdSoal: 5}, // soal latihan
+	}}
+	attemptRepo := &attemptRepoPalsu{attempts: []entity.UjianAttempt{
+		{IdUjianAttempt: 1, IdUjian: 1, IdSiswa: 7, Status: entity.StatusAttemptBerlangsung},
+		{IdUjianAttempt: 2, IdUjian: 2, IdSiswa: 7, Status: entity.StatusAttemptSelesai},
+		{IdUjianAttempt: 3, IdUjian: 3, IdSiswa: 7, Status: entity.StatusAttemptBerlangsung, BatasWaktu: &lewat},
+	}}
+	s := NewUjianAttemptService(attemptRepo, &ujianRepoPalsu{}, soalRepo, &jawabanSoalRepoPalsu{}, nil, nil)
+
+	tests := []struct {
+		name   string
+		soalID uint64
+		want   error
+	}{
+		{"attempt in progress", 1, nil},
+		{"attempt submitted", 2, ErrAttemptSelesai},
+		{"time limit passed", 3, ErrWaktuAttemptHabis},
+		{"no attempt", 4, ErrAttemptTidakAda},
+		{"latihan soal", 5, nil},
+	}
+	for _, tt := range tests {
+		err := s.ValidateSubmission([]entity.JawabanSiswa{{IdSoal: tt.soalID, IdSiswa: 7}})
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	// Attempt diisi server, termasuk untuk jawaban kedua pada attempt yang sama; attempt kiriman klien
+	// pada soal latihan dibuang
+	palsu := uint64(99)
+	jawabanList := []entity.JawabanSiswa{
+		{IdSoal: 1, IdSiswa: 7, IdUjianAttempt: &palsu},
+		{IdSoal: 1, IdSiswa: 7},
+		{IdSoal: 5, IdSiswa: 7, IdUjianAttempt: &palsu},
+	}
+	if err := s.ValidateSubmission(jawabanList); err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []uint64{1, 1, 0} {
+		got := uint64(0)
+		if jawabanList[i].IdUjianAttempt != nil {
+			got = *jawabanList[i].IdUjianAttempt
+		}
+		if got != want {
+			t.Errorf("jawaban %d attempt = %d, want %d", i, got, want)
+		}
+	}
+}
+
+func TestStartAttemptConcurrentOnce(t *testing.T) {
+	env := newLingkunganTest(t)
+	ujian := env.buatUjian(t, func(u *entity.Ujian) { u.Durasi = 60 })
+	env.buatSoal(t, ujian, entity.TipeSoalIsian, 100)
+	const siswaID = 7
+
+	// Siswa yang menekan mulai berkali-kali mendapat satu attempt yang sama
+	const n = 8
+	var wg sync.WaitGroup
+	ids := make([]uint64, n)
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, attempt, err := env.ujianAttempt.StartAttempt(ujian.IdUjian, siswaID, "masuk")
+			ids[i], errs[i] = attempt.IdUjianAttempt, err
+		}(i)
+	}
+	wg.Wait()
+	for i := range ids {
+		if errs[i] != nil {
+			t.Fatalf("start %d: %v", i, errs[i])
+		}
+		if ids[i] != ids[0] {
+			t.Errorf("start %d got attempt %d, start 0 got %d", i, ids[i], ids[0])
+		}
+	}
+	var count int64
+	if err := env.db.Model(&entity.UjianAttempt{}).Where("id_ujian = ? AND id_siswa = ?", ujian.IdUjian, siswaID).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("stored %d attempts, want 1", count)
+	}
+
+	_, err := repository.NewUjianAttemptRepository(env.db).CreateAttempt(entity.UjianAttempt{
+		IdUjian: ujian.IdUjian, IdSiswa: siswaID, Status: entity.StatusAttemptBerlangsung,
+	})
+	if !errors.Is(err, gorm.ErrDuplicatedKey) {
+		t.Errorf("second attempt = %v, want gorm.ErrDuplicatedKey", err)
+	}
+}
+
+func TestSaveDraftAndResumeAttempt(t *testing.T) {
+	env := newLingkunganTest(t)
+	ujian := env.buatUjian(t, func(u *entity.Ujian) { u.Durasi = 60 })
+	soalPG, opsi := env.buatSoal(t, ujian, "Pilihan_Berganda", 50,
+		entity.JawabanSoal{Jawaban: "A", Benar: true}, entity.JawabanSoal{Jawaban: "B"})
+	soalIsian, _ := env.buatSoal(t, ujian, "Isian", 50, entity.JawabanSoal{Jawaban: "Jakarta", Benar: true})
+	lain := env.buatUjian(t, nil)
+	soalLain, _ := env.buatSoal(t, lain, "Isian", 100)
+	const siswaID = 7
+
+	if _, err := env.ujianAttempt.ResumeAttempt(ujian.IdUjian, siswaID); !errors.Is(err, ErrAttemptTidakAda) {
+		t.Errorf("resume before start = %v, want ErrAttemptTidakAda", err)
+	}
+	if _, err := env.ujianAttempt.SaveDraftJawaban(ujian.IdUjian, entity.JawabanSiswa{IdSoal: soalPG.IdSoal, IdSiswa: siswaID}); !errors.Is(err, ErrAttemptTidakAda) {
+		t.Errorf("autosave before start = %v, want ErrAttemptTidakAda", err)
+	}
+	_, attempt, err := env.ujianAttempt.StartAttempt(ujian.IdUjian, siswaID, "masuk")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := env.ujianAttempt.SaveDraftJawaban(ujian.IdUjian, entity.JawabanSiswa{IdSoal: soalLain.IdSoal, IdSiswa: siswaID}); !errors.Is(err, ErrSoalBukanUjian) {
+		t.Errorf("autosave of another ujian's soal = %v, want ErrSoalBukanUjian", err)
+	}
+	simpan := func(jawaban entity.JawabanSiswa) {
+		t.Helper()
+		jawaban.IdSiswa = siswaID
+		if _, err := env.ujianAttempt.SaveDraftJawaban(ujian.IdUjian, jawaban); err != nil {
+			t.Fatal(err)
+		}
+	}
+	simpan(entity.JawabanSiswa{IdSoal: soalPG.IdSoal, IdJawabanSoal: opsi[1].IdJawabanSoal})
+	simpan(entity.JawabanSiswa{IdSoal: soalIsian.IdSoal, JawabanText: "Bandung"})
+	// Menyimpan lagi menimpa draft soal yang sama
+	simpan(entity.JawabanSiswa{IdSoal: soalPG.IdSoal, IdJawabanSoal: opsi[0].IdJawabanSoal})
+
+	resume, err := env.ujianAttempt.ResumeAttempt(ujian.IdUjian, siswaID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resume.Attempt.IdUjianAttempt != attempt.IdUjianAttempt || resume.SisaWaktu == nil ||
+		*resume.SisaWaktu <= 0 || *resume.SisaWaktu > 3600 {
+		t.Errorf("resume = attempt %d, sisa %v; want attempt %d with up to an hour left",
+			resume.Attempt.IdUjianAttempt, resume.SisaWaktu, attempt.IdUjianAttempt)
+	}
+	if len(resume.UrutanSoal) != 2 || resume.UrutanSoal[0] != soalPG.IdSoal || resume.UrutanSoal[1] != soalIsian.IdSoal {
+		t.Errorf("urutan soal = %v, want [%d %d]", resume.UrutanSoal, soalPG.IdSoal, soalIsian.IdSoal)
+	}
+	if len(resume.Jawaban) != 2 || resume.Jawaban[0].IdJawabanSoal != opsi[0].IdJawabanSoal || resume.Jawaban[1].JawabanSiswa != "Bandung" {
+		t.Errorf("drafts = %+v, want the last choice and the isian text", resume.Jawaban)
+	}
+
+	if _, _, err := env.ujianAttempt.FinishAttempt(ujian.IdUjian, siswaID, "keluar"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.ujianAttempt.SaveDraftJawaban(ujian.IdUjian, entity.JawabanSiswa{IdSoal: soalPG.IdSoal, IdSiswa: siswaID}); !errors.Is(err, ErrAttemptSelesai) {
+		t.Errorf("autosave after submit = %v, want ErrAttemptSelesai", err)
+	}
+	if _, err := env.ujianAttempt.ResumeAttempt(ujian.IdUjian, siswaID); !errors.Is(err, ErrAttemptSelesai) {
+		t.Errorf("resume after submit = %v, want ErrAttemptSelesai", err)
+	}
+}
+
+func TestUrutanSoalAttempt(t *testing.T) {
+	soalList := []entity.Soal{{IdSoal: 1}, {IdSoal: 2}, {IdSoal: 3}, {IdSoal: 4}}
+	tests := []struct {
+		name   string
+		urutan string
+		want   []uint64
+	}{
+		{"no stored order", "", []uint64{1, 2, 3, 4}},
+		{"stored order", "3,1,4,2", []uint64{3, 1, 4, 2}},
+		{"soal added later", "2,1", []uint64{2, 1, 3, 4}},
+		{"soal removed", "4,9,2,1,3", []uint64{4, 2, 1, 3}},
+		{"duplicates and junk", "2, 2,x,1", []uint64{2, 1, 3, 4}},
+	}
+	for _, tt := range tests {
+		got := urutanSoalAttempt(entity.UjianAttempt{UrutanSoal: tt.urutan}, soalList)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestStartAttemptNeedsValidOptions(t *testing.T) {
+	env := newLingkunganTest(t)
+	ujian := env.buatUjian(t, nil)
+	// Ditulis langsung ke basis data, melewati validasi CreateSoal seperti data lama
+	env.buatSoal(t, ujian, entity.TipeSoalPilihanBerganda, 50,
+		entity.JawabanSoal{Jawaban: "A", Benar: true}, entity.JawabanSoal{Jawaban: "B", Benar: true})
+	env.buatSoal(t, ujian, entity.TipeSoalBenarSalah, 50,
+		entity.JawabanSoal{Jawaban: "Benar"}, entity.JawabanSoal{Jawaban: "Salah"})
+
+	_, _, err := env.ujianAttempt.StartAttempt(ujian.IdUjian, 7, "masuk")
+	if !errors.Is(err, ErrUjianBelumSiap) {
+		t.Fatalf("PG with two correct options and BS without one = %v, want ErrUjianBelumSiap", err)
+	}
+	if !strings.Contains(err.Error(), "Pilihan_Berganda harus punya tepat 1 opsi benar, ada 2") ||
+		!strings.Contains(err.Error(), "Benar_Salah harus punya tepat 1 opsi benar, ada 0") {
+		t.Errorf("error = %v, want both soal named", err)
+	}
+}
@@ -0,0 +1,264 @@
+// service/ujian_service.go
+package service
+
+import (
+	"cbt-api/entity"
+	"cbt-api/repository"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrDataUjianTidakValid     = errors.New("data ujian tidak valid")
+	ErrTotalNilaiMelebihiGrade = errors.New("total nilai_per_soal melebihi grade ujian")
+	ErrUjianSedangDikerjakan   = errors.New("ujian sedang dikerjakan siswa")
+	ErrUjianSudahDikerjakan    = errors.New("ujian sudah dikerjakan siswa sehingga tidak dapat dihapus")
+)
+
+// ValidasiUjian tells the guru whether an ujian is ready to be taken: every soal has valid options and
+// the nilai_per_soal of all soal add up to the ujian's grade
+type ValidasiUjian struct {
+	IdUjian           uint64   `json:"id_ujian"`
+	Grade             float64  `json:"grade"`
+	TotalNilaiPerSoal float64  `json:"total_nilai_per_soal"`
+	JumlahSoal        int      `json:"jumlah_soal"`
+	Siap              bool     `json:"siap"`
+	Masalah           []string `json:"masalah"`
+}
+
+// UjianService is a contract for ujian service
+type UjianService interface {
+	GetUjianByID(ujianID uint64) (entity.Ujian, error)
+	GetUjianByKursusID(kursusID uint64) ([]entity.Ujian, error)
+	CreateUjian(ujian entity.Ujian) (entity.Ujian, error)
+	UpdateUjian(ujian entity.Ujian) (entity.Ujian, error)
+	DeleteUjian(ujianID uint64) error
+	ValidasiUjian(ujianID uint64) (ValidasiUjian, error)
+}
+
+type ujianService struct {
+	ujianRepository        repository.UjianRepository
+	soalRepository         repository.SoalRepository
+	jawabanSoalRepository  repository.JawabanSoalRepository
+	ujianAttemptRepository repository.UjianAttemptRepository
+	kursusRepository       repository.KursusRepository
+}
+
+// NewUjianService creates a new instance of UjianService
+func NewUjianService(
+	ujianRepo repository.UjianRepository,
+	soalRepo repository.SoalRepository,
+	jawabanSoalRepo repository.JawabanSoalRepository,
+	ujianAttemptRepo repository.UjianAttemptRepository,
+	kursusRepo repository.KursusRepository,
+) UjianService {
+	return &ujianService{
+		ujianRepository:        ujianRepo,
+		soalRepository:         soalRepo,
+		jawabanSoalRepository:  jawabanSoalRepo,
+		ujianAttemptRepository: ujianAttemptRepo,
+		kursusRepository:       kursusRepo,
+	}
+}
+
+func (s *ujianService) GetUjianByID(ujianID uint64) (entity.Ujian, error) {
+	return s.ujianRepository.GetUjianByID(ujianID)
+}
+
+func (s *ujianService) GetUjianByKursusID(kursusID uint64) ([]entity.Ujian, error) {
+	return s.ujianRepository.GetUjianByKursusID(kursusID)
+}
+
+// CreateUjian validates the ujian and stores it with bcrypt hashes of the plain entry and exit passwords
+func (s *ujianService) CreateUjian(ujian entity.Ujian) (entity.Ujian, error) {
+	if ujian.PasswordMasuk == "" || ujian.PasswordKeluar == "" {
+		return ujian, fmt.Errorf("%w: password_masuk dan password_keluar wajib diisi", ErrDataUjianTidakValid)
+	}
+	if err := validasiDataUjian(s.kursusRepository, ujian); err != nil {
+		return ujian, err
+	}
+	if err := hashPasswordUjian(&ujian); err != nil {
+		return ujian, err
+	}
+
+	now := time.Now()
+	ujian.IdUjian = 0
+	ujian.CreatedAt = now
+	ujian.UpdatedAt = now
+	if ujian.TanggalUjian.IsZero() {
+		ujian.TanggalUjian = now
+	}
+	return s.ujianRepository.CreateUjian(ujian)
+}
+
+// UpdateUjian replaces the ujian's data. Empty passwords keep the stored hashes, and the grade may not
+// drop below the nilai_per_soal of the soal already in the ujian. An ujian that a siswa is working on
+// cannot be changed.
+func (s *ujianService) UpdateUjian(ujian entity.Ujian) (entity.Ujian, error) {
+	existing, err := s.ujianRepository.GetUjianByID(ujian.IdUjian)
+	if err != nil {
+		return ujian, err
+	}
+	if err := validasiDataUjian(s.kursusRepository, ujian); err != nil {
+		return ujian, err
+	}
+	if err := cekUjianBolehDiubah(s.ujianAttemptRepository, ujian.IdUjian); err != nil {
+		return ujian, err
+	}
+
+	soalList, err := s.soalRepository.FindByIdUjian(ujian.IdUjian)
+	if err != nil {
+		return ujian, err
+	}
+	if total := totalNilaiPerSoal(soalList, 0, 0); melebihiGrade(total, ujian.Grade) {
+		return ujian, fmt.Errorf("%w: total nilai_per_soal %.2f, grade %.2f", ErrTotalNilaiMelebihiGrade, total, ujian.Grade)
+	}
+
+	if err := hashPasswordUjian(&ujian); err != nil {
+		return ujian, err
+	}
+	if ujian.PasswordMasuk == "" {
+		ujian.PasswordMasuk = existing.PasswordMasuk
+	}
+	if ujian.PasswordKeluar == "" {
+		ujian.PasswordKeluar = existing.PasswordKeluar
+	}
+	if ujian.TanggalUjian.IsZero() {
+		ujian.TanggalUjian = existing.TanggalUjian
+	}
+	ujian.CreatedAt = existing.CreatedAt
+	ujian.UpdatedAt = time.Now()
+	return s.ujianRepository.UpdateUjian(ujian)
+}
+
+// DeleteUjian deletes an ujian with its soal. Ujian that siswa already worked on are kept so their
+// answers and scores stay intact.
+func (s *ujianService) DeleteUjian(ujianID uint64) error {
+	if _, err := s.ujianRepository.GetUjianByID(ujianID); err != nil {
+		return err
+	}
+	attempts, err := s.ujianAttemptRepository.CountByUjian(ujianID, "")
+	if err != nil {
+		return err
+	}
+	if attempts > 0 {
+		return ErrUjianSudahDikerjakan
+	}
+
+	// Jawaban yang disimpan sebelum ada attempt juga dihitung
+	soalList, err := s.soalRepository.FindByIdUjian(ujianID)
+	if err != nil {
+		return err
+	}
+	for _, soal := range soalList {
+		dijawab, err := s.soalRepository.SudahDijawab(soal.IdSoal)
+		if err != nil {
+			return err
+		}
+		if dijawab {
+			return ErrUjianSudahDikerjakan
+		}
+	}
+	return s.ujianRepository.DeleteUjian(ujianID)
+}
+
+// ValidasiUjian checks the options of every soal and that the nilai_per_soal add up to the grade
+func (s *ujianService) ValidasiUjian(ujianID uint64) (ValidasiUjian, error) {
+	ujian, err := s.ujianRepository.GetUjianByID(ujianID)
+	if err != nil {
+		return ValidasiUjian{}, err
+	}
+	soalList, err := s.soalRepository.FindByIdUjianWithTipeSoal(ujianID)
+	if err != nil {
+		return ValidasiUjian{}, err
+	}
+	kunci, err := s.jawabanSoalRepository.FindByIdUjian(ujianID)
+	if err != nil {
+		return ValidasiUjian{}, err
+	}
+
+	hasil := ValidasiUjian{
+		IdUjian:           ujianID,
+		Grade:             ujian.Grade,
+		TotalNilaiPerSoal: totalNilaiPerSoal(soalList, 0, 0),
+		JumlahSoal:        len(soalList),
+		Masalah:           masalahUjian(ujian, soalList, kunci),
+	}
+	hasil.Siap = len(hasil.Masalah) == 0
+	return hasil, nil
+}
+
+// masalahUjian lists what keeps an ujian from being taken: no soal, nilai_per_soal that do not add up
+// to the grade and soal with invalid options. soalList needs its TipeSoal loaded.
+func masalahUjian(ujian entity.Ujian, soalList []entity.Soal, kunci []entity.JawabanSoal) []string {
+	masalah := make([]string, 0)
+	if len(soalList) == 0 {
+		masalah = append(masalah, "ujian belum punya soal")
+	}
+	if total := totalNilaiPerSoal(soalList, 0, 0); math.Abs(total-ujian.Grade) > toleransiNilai {
+		masalah = append(masalah, fmt.Sprintf("total nilai_per_soal %.2f belum sama dengan grade %.2f", total, ujian.Grade))
+	}
+	opsiPerSoal := kunciPerSoal(kunci)
+	for _, soal := range soalList {
+		if err := validasiOpsiSoal(soal.TipeSoal.NamaTipeUjian, opsiPerSoal[soal.IdSoal]); err != nil {
+			masalah = append(masalah, fmt.Sprintf("soal %d: %s", soal.IdSoal, strings.TrimPrefix(err.Error(), ErrOpsiSoalTidakValid.Error()+": ")))
+		}
+	}
+	return masalah
+}
+
+// validasiDataUjian checks the fields of an ujian and that its kursus exists
+func validasiDataUjian(kursusRepo repository.KursusRepository, ujian entity.Ujian) error {
+	var masalah []string
+	if strings.TrimSpace(ujian.NamaUjian) == "" {
+		masalah = append(masalah, "nama_ujian wajib diisi")
+	}
+	if ujian.Acak != entity.StatusAktif && ujian.Acak != entity.StatusTidakAktif {
+		masalah = append(masalah, "acak harus Aktif atau Tidak Aktif")
+	}
+	if ujian.StatusJawaban != entity.StatusAktif && ujian.StatusJawaban != entity.StatusTidakAktif {
+		masalah = append(masalah, "status_jawaban harus Aktif atau Tidak Aktif")
+	}
+	if ujian.Grade <= 0 {
+		masalah = append(masalah, "grade harus lebih dari 0")
+	}
+	if ujian.Durasi < 0 {
+		masalah = append(masalah, "durasi tidak boleh negatif")
+	}
+	if !ujian.WaktuMulai.IsZero() && !ujian.WaktuSelesai.IsZero() && !ujian.WaktuSelesai.After(ujian.WaktuMulai) {
+		masalah = append(masalah, "waktu_selesai harus setelah waktu_mulai")
+	}
+	if ujian.IdTipeUjian == 0 {
+		masalah = append(masalah, "id_tipe_ujian wajib diisi")
+	}
+	if len(masalah) > 0 {
+		return fmt.Errorf("%w: %s", ErrDataUjianTidakValid, strings.Join(masalah, "; "))
+	}
+
+	_, err := kursusRepo.GetKursusByID(ujian.IdKursus)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: kursus %d tidak ditemukan", ErrDataUjianTidakValid, ujian.IdKursus)
+	}
+	return err
+}
+
+// hashPasswordUjian replaces the plain passwords that are set with their bcrypt hashes
+func hashPasswordUjian(ujian *entity.Ujian) error {
+	for _, password := range []*string{&ujian.PasswordMasuk, &ujian.PasswordKeluar} {
+		if *password == "" {
+			continue
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(*password), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+		*password = string(hash)
+	}
+	return nil
+}